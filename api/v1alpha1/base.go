@@ -2,12 +2,13 @@ package v1alpha1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // StorageConfig defines the storage configuration for persistent volumes
 type StorageConfig struct {
 	// Size of the persistent volume (default: "10G")
-	//+kubebuilder:default="10G"
 	Size string `json:"size,omitempty"`
 
 	// Storage class name for the volume
@@ -19,19 +20,249 @@ type Persistence struct {
 	// Storage configuration
 	StorageConfig StorageConfig `json:"storageConfig,omitempty"`
 
-	//+kubebuilder:default=false
-	PreserveOnDelete bool `json:"preserveOnDelete,omitempty"`
+	// PreserveOnDelete keeps the volume when the game server is deleted (default: false)
+	PreserveOnDelete *bool `json:"preserveOnDelete,omitempty"`
+}
+
+// EditorExposureType defines how the code-server editor is reachable
+// +kubebuilder:validation:Enum=ClusterIP;Ingress
+type EditorExposureType string
+
+const (
+	// EditorExposureClusterIP exposes the editor through a cluster-internal Service only
+	EditorExposureClusterIP EditorExposureType = "ClusterIP"
+	// EditorExposureIngress exposes the editor through an Ingress in front of the ClusterIP Service
+	EditorExposureIngress EditorExposureType = "Ingress"
+)
+
+// EditorIngress configures the Ingress exposing the code-server editor
+type EditorIngress struct {
+	// Host is the hostname the editor is served on
+	Host string `json:"host"`
+
+	// IngressClassName selects the ingress controller
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+
+	// TLSSecretName enables TLS for the host using the certificate in this Secret
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+
+	// Annotations for the Ingress (e.g. cert-manager.io/cluster-issuer)
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Editor configures the code-server editor sidecar
+type Editor struct {
+	// Enabled adds the code-server sidecar to the game pod (default: true)
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Image is the code-server image repository (default: "codercom/code-server")
+	Image string `json:"image,omitempty"`
+
+	// Tag pins the code-server image version (default: "latest")
+	Tag string `json:"tag,omitempty"`
+
+	// Resources for the code-server container
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Exposure selects how the editor is reachable (default: ClusterIP), it is never added to the game LoadBalancer
+	Exposure EditorExposureType `json:"exposure,omitempty"`
+
+	// Ingress configuration, required when exposure is Ingress
+	Ingress *EditorIngress `json:"ingress,omitempty"`
+}
+
+// Query configures the Steam A2S query the operator uses to check readiness and read player counts
+type Query struct {
+	// Port is the UDP query port of the game server container, the game default is used when unset
+	//+kubebuilder:validation:Minimum=0
+	//+kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port,omitempty"`
+
+	// PeriodSeconds between two queries (default: 30)
+	//+kubebuilder:default=30
+	//+kubebuilder:validation:Minimum=5
+	PeriodSeconds int32 `json:"periodSeconds,omitempty"`
+}
+
+// RCon configures the remote console used to run GameServerCommands
+type RCon struct {
+	// Port is the RCon port of the game server container, the game default is used when unset
+	//+kubebuilder:validation:Minimum=0
+	//+kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port,omitempty"`
+
+	// PasswordSecretRef references the Secret key holding the RCon password, RCon is disabled when unset
+	PasswordSecretRef *corev1.SecretKeySelector `json:"passwordSecretRef,omitempty"`
+}
+
+// Shutdown configures how the game server is stopped when its pod is replaced
+type Shutdown struct {
+	// GracePeriodSeconds is the pod termination grace period, the time LinuxGSM gets to stop
+	// the server and write persistence after SIGTERM (default: 120)
+	//+kubebuilder:default=120
+	//+kubebuilder:validation:Minimum=30
+	GracePeriodSeconds int32 `json:"gracePeriodSeconds,omitempty"`
+
+	// WarningSeconds are the countdown marks, in seconds before the pod is replaced, at which
+	// players are warned over RCON when the operator rolls out a change (e.g. [300, 60, 10]).
+	// The pod is replaced right away when empty or when RCON is not configured
+	//+kubebuilder:validation:MaxItems=10
+	WarningSeconds []int32 `json:"warningSeconds,omitempty"`
+
+	// Message is broadcast at each countdown mark, {seconds} is replaced with the remaining time
+	//+kubebuilder:default="Server restarting in {seconds} seconds"
+	Message string `json:"message,omitempty"`
+}
+
+// Schedule configures planned restarts and when changes replacing the game pod are rolled out
+type Schedule struct {
+	// Restarts is a cron expression (e.g. "0 6 * * *") at which the game server is restarted
+	Restarts string `json:"restarts,omitempty"`
+
+	// TimeZone the cron expressions are evaluated in, an IANA name such as "Europe/Berlin" (default: UTC)
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// MaintenanceWindows restrict when changes replacing the game pod, such as image or config changes,
+	// are rolled out. Changes made outside of a window wait for the next window or scheduled restart.
+	// Changes are rolled out right away when empty
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+}
+
+// MaintenanceWindow is a recurring period in which the game pod may be replaced
+type MaintenanceWindow struct {
+	// Start is a cron expression for the beginning of the window (e.g. "0 4 * * 1")
+	Start string `json:"start"`
+
+	// DurationMinutes is the length of the window
+	//+kubebuilder:validation:Minimum=1
+	DurationMinutes int32 `json:"durationMinutes"`
+}
+
+// UpdatePolicy selects how game updates are installed
+// +kubebuilder:validation:Enum=Never;OnRestart;Auto
+type UpdatePolicy string
+
+const (
+	// UpdatePolicyNever disables update checks, the operator never restarts the server for an update
+	UpdatePolicyNever UpdatePolicy = "Never"
+	// UpdatePolicyOnRestart installs updates whenever the game pod is replaced for another reason
+	UpdatePolicyOnRestart UpdatePolicy = "OnRestart"
+	// UpdatePolicyAuto replaces the game pod as soon as a new build is available, within maintenance windows
+	UpdatePolicyAuto UpdatePolicy = "Auto"
+)
+
+// Updates configures how new builds of the game are detected and installed
+type Updates struct {
+	// Policy selects when updates are installed (default: OnRestart)
+	//+kubebuilder:default=OnRestart
+	Policy UpdatePolicy `json:"policy,omitempty"`
+
+	// CheckIntervalMinutes between two checks for a new build (default: 60)
+	//+kubebuilder:default=60
+	//+kubebuilder:validation:Minimum=5
+	CheckIntervalMinutes int32 `json:"checkIntervalMinutes,omitempty"`
+}
+
+// GameServerPhase is the lifecycle phase of a game server
+// +kubebuilder:validation:Enum=Pending;Running;Paused
+type GameServerPhase string
+
+const (
+	// GameServerPending means the game server is starting or does not answer its query port
+	GameServerPending GameServerPhase = "Pending"
+	// GameServerRunning means the game server answers its query port
+	GameServerRunning GameServerPhase = "Running"
+	// GameServerPaused means the game server is scaled to zero by spec.paused or after being idle
+	GameServerPaused GameServerPhase = "Paused"
+)
+
+// Idle configures the automatic pause of servers without players
+type Idle struct {
+	// ShutdownAfterMinutes without players after which the server is paused, 0 disables the idle shutdown
+	//+kubebuilder:validation:Minimum=0
+	ShutdownAfterMinutes int32 `json:"shutdownAfterMinutes,omitempty"`
+
+	// WakeOnConnect fronts the server ports with a proxy that wakes a paused server when a player
+	// connects or queries it, and answers queries until the game is up (default: false)
+	WakeOnConnect *bool `json:"wakeOnConnect,omitempty"`
+}
+
+// LogStream is a log of the game server streamed to container logs
+// +kubebuilder:validation:Enum=console;rpt;adm
+type LogStream string
+
+const (
+	// LogStreamConsole is the server console output LinuxGSM writes to its log directory
+	LogStreamConsole LogStream = "console"
+	// LogStreamRPT is the DayZ and Arma script and engine log
+	LogStreamRPT LogStream = "rpt"
+	// LogStreamADM is the DayZ admin log of player connections, hits and kills
+	LogStreamADM LogStream = "adm"
+)
+
+// Logs configures streaming the log files the game writes to its volume
+type Logs struct {
+	// Streams are streamed to the logs of a sidecar container each, named log-<stream>, so kubectl logs
+	// and cluster log pipelines read them. Streams the game does not write are ignored
+	Streams []LogStream `json:"streams,omitempty"`
+
+	// Events records Kubernetes Events on the game server for players connecting, disconnecting and
+	// being killed, parsed from the admin log, which is streamed too (default: false)
+	Events *bool `json:"events,omitempty"`
+}
+
+// NotificationEvent is an event of a game server NotificationChannels are notified of
+// +kubebuilder:validation:Enum=started;stopped;crashed;updated;players
+type NotificationEvent string
+
+const (
+	// NotificationStarted is sent when the game server answers its query port
+	NotificationStarted NotificationEvent = "started"
+	// NotificationStopped is sent when the game server is paused, by spec.paused or after being idle
+	NotificationStopped NotificationEvent = "stopped"
+	// NotificationCrashed is sent when the game container exits with an error
+	NotificationCrashed NotificationEvent = "crashed"
+	// NotificationUpdated is sent when a new build of the game is installed
+	NotificationUpdated NotificationEvent = "updated"
+	// NotificationPlayers is sent when the player count reaches one of the player thresholds
+	NotificationPlayers NotificationEvent = "players"
+)
+
+// Notification subscribes a NotificationChannel to events of the game server
+// +kubebuilder:validation:XValidation:rule="!self.events.exists(e, e == 'players') || (has(self.playerThresholds) && size(self.playerThresholds) > 0)",message="the players event needs playerThresholds"
+type Notification struct {
+	// ChannelRef references the NotificationChannel in the namespace of the game server
+	ChannelRef corev1.LocalObjectReference `json:"channelRef"`
+
+	// Events the channel is notified of
+	//+kubebuilder:validation:MinItems=1
+	Events []NotificationEvent `json:"events"`
+
+	// PlayerThresholds are the player counts the players event is sent at, when the count rises to them
+	PlayerThresholds []int32 `json:"playerThresholds,omitempty"`
 }
 
 // Base contains common configuration fields for game server CRDs
 type Base struct {
+	// TemplateRef references the GameServerTemplate of the namespace the unset fields are taken from
+	TemplateRef *corev1.LocalObjectReference `json:"templateRef,omitempty"`
+
+	// ClassName selects the GameServerClass, overriding the class of the template
+	ClassName string `json:"className,omitempty"`
+
+	// Image of the game server, the LinuxGSM image of the game is used when empty
+	Image string `json:"image,omitempty"`
+
 	Persistence Persistence `json:"persistence,omitempty"`
 
 	Ports []corev1.ServicePort `json:"ports,omitempty"`
 
 	LoadBalancerIP string `json:"loadBalancerIP,omitempty"`
 
-	Resources corev1.ResourceRequirements `json:"resources"`
+	// ServiceAnnotations for the game Services
+	ServiceAnnotations map[string]string `json:"serviceAnnotations,omitempty"`
+
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
 
 	// NodeSelector is a selector which must be true for the pod to fit on a node
 	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
@@ -45,6 +276,117 @@ type Base struct {
 	// Annotations for the pod template
 	Annotations map[string]string `json:"annotations,omitempty"`
 
-	// EditorPassword is the password for the code-server editor
+	// Editor configures the code-server editor sidecar
+	Editor Editor `json:"editor,omitempty"`
+
+	// EditorPassword is the password for the code-server editor.
+	// It is stored in the generated <name>-editor Secret, a random password is generated when empty
 	EditorPassword string `json:"editorPassword,omitempty"`
+
+	// EditorPasswordSecretRef references an existing Secret key holding the code-server password.
+	// When set, no Secret is generated and EditorPassword is ignored
+	EditorPasswordSecretRef *corev1.SecretKeySelector `json:"editorPasswordSecretRef,omitempty"`
+
+	// Query configures the readiness and player count query
+	Query Query `json:"query,omitempty"`
+
+	// RCon configures the remote console used by GameServerCommand
+	RCon RCon `json:"rcon,omitempty"`
+
+	// Shutdown configures the warnings and grace period when the game pod is replaced
+	Shutdown Shutdown `json:"shutdown,omitempty"`
+
+	// Schedule configures restarts and maintenance windows
+	Schedule Schedule `json:"schedule,omitempty"`
+
+	// Updates configures update checks and when updates are installed
+	Updates Updates `json:"updates,omitempty"`
+
+	// Paused scales the game server to zero while keeping its volume, Services and LoadBalancer address
+	// (default: false)
+	Paused *bool `json:"paused,omitempty"`
+
+	// Idle configures the automatic pause of servers without players and waking them on connect
+	Idle Idle `json:"idle,omitempty"`
+
+	// PlayerLists reference the PlayerLists of the namespace applied to the server, lists of the same
+	// type are merged
+	PlayerLists []corev1.LocalObjectReference `json:"playerLists,omitempty"`
+
+	// Logs configures streaming the game log files to container logs and Events
+	Logs Logs `json:"logs,omitempty"`
+
+	// Notifications subscribe NotificationChannels to events of the game server
+	Notifications []Notification `json:"notifications,omitempty"`
+}
+
+// BaseStatus contains common observed state fields for game server CRDs
+type BaseStatus struct {
+	// Phase is the lifecycle phase of the game server
+	Phase GameServerPhase `json:"phase,omitempty"`
+
+	// Conditions represent the latest available observations of an object's state
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// EditorSecretName is the name of the Secret holding the code-server password
+	EditorSecretName string `json:"editorSecretName,omitempty"`
+
+	// Players is the number of players connected, as reported by the query port
+	Players int32 `json:"players,omitempty"`
+
+	// MaxPlayers is the number of player slots, as reported by the query port
+	MaxPlayers int32 `json:"maxPlayers,omitempty"`
+
+	// Map is the map or mission the server is running
+	Map string `json:"map,omitempty"`
+
+	// Version is the game server version
+	Version string `json:"version,omitempty"`
+
+	// Shutdown tracks the countdown before the game pod is replaced, it is unset when none is running
+	Shutdown *ShutdownStatus `json:"shutdown,omitempty"`
+
+	// NextRestartTime is when the next scheduled restart is due
+	NextRestartTime *metav1.Time `json:"nextRestartTime,omitempty"`
+
+	// Updates shows the installed and the latest available game build
+	Updates *UpdateStatus `json:"updates,omitempty"`
+
+	// IdleSince is when the last player left the server, it is unset while players are connected
+	IdleSince *metav1.Time `json:"idleSince,omitempty"`
+
+	// Sleeping is true while the server is paused for being idle, until a connection wakes it
+	Sleeping bool `json:"sleeping,omitempty"`
+
+	// LastCrashTime is when the game container last exited with an error
+	LastCrashTime *metav1.Time `json:"lastCrashTime,omitempty"`
+
+	// BanListHash is the hash of the bans last enforced on the connected players, bans are only pushed
+	// over RCon when it changes
+	BanListHash string `json:"banListHash,omitempty"`
+
+	// EffectiveSpec is the spec the server runs with, after merging its GameServerTemplate and
+	// GameServerClass. It is kept schemaless to stay within the size limits of the CRD
+	EffectiveSpec *apiextensionsv1.JSON `json:"effectiveSpec,omitempty"`
+}
+
+// ShutdownStatus is the progress of a shutdown countdown
+type ShutdownStatus struct {
+	// StartTime is when the countdown started
+	StartTime metav1.Time `json:"startTime"`
+
+	// WarningsSent is the number of countdown marks announced to players
+	WarningsSent int32 `json:"warningsSent,omitempty"`
+}
+
+// UpdateStatus is the installed and the latest available game build
+type UpdateStatus struct {
+	// InstalledBuildID is the build the game pod was last started with
+	InstalledBuildID string `json:"installedBuildID,omitempty"`
+
+	// AvailableBuildID is the latest public build
+	AvailableBuildID string `json:"availableBuildID,omitempty"`
+
+	// LastCheckTime is when the available build was last checked
+	LastCheckTime *metav1.Time `json:"lastCheckTime,omitempty"`
 }
@@ -0,0 +1,254 @@
+package v1alpha1
+
+import (
+	"encoding/json"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"github.com/templarfelix/gameserver-operator/api/v1beta1"
+)
+
+// ConvertBaseTo converts the flat v1alpha1 Base into the grouped v1beta1 Base. The image is part of the
+// game settings in v1beta1 and converted with the game.
+func ConvertBaseTo(src *Base, dst *v1beta1.Base) {
+	dst.TemplateRef = src.TemplateRef
+	dst.ClassName = src.ClassName
+	dst.Network = v1beta1.Network{
+		Ports:              src.Ports,
+		LoadBalancerIP:     src.LoadBalancerIP,
+		ServiceAnnotations: src.ServiceAnnotations,
+	}
+	dst.Storage = v1beta1.Storage{
+		Size:             src.Persistence.StorageConfig.Size,
+		StorageClassName: src.Persistence.StorageConfig.StorageClassName,
+		PreserveOnDelete: src.Persistence.PreserveOnDelete,
+	}
+	dst.Scheduling = v1beta1.Scheduling{
+		Resources:      src.Resources,
+		NodeSelector:   src.NodeSelector,
+		Tolerations:    src.Tolerations,
+		Affinity:       src.Affinity,
+		PodAnnotations: src.Annotations,
+	}
+	dst.Editor = v1beta1.Editor{
+		Enabled:           src.Editor.Enabled,
+		Image:             src.Editor.Image,
+		Tag:               src.Editor.Tag,
+		Resources:         src.Editor.Resources,
+		Exposure:          v1beta1.EditorExposureType(src.Editor.Exposure),
+		Password:          src.EditorPassword,
+		PasswordSecretRef: src.EditorPasswordSecretRef,
+	}
+	dst.Query = v1beta1.Query{
+		Port:          src.Query.Port,
+		PeriodSeconds: src.Query.PeriodSeconds,
+	}
+	dst.RCon = v1beta1.RCon{
+		Port:              src.RCon.Port,
+		PasswordSecretRef: src.RCon.PasswordSecretRef,
+	}
+	dst.Shutdown = v1beta1.Shutdown{
+		GracePeriodSeconds: src.Shutdown.GracePeriodSeconds,
+		WarningSeconds:     src.Shutdown.WarningSeconds,
+		Message:            src.Shutdown.Message,
+	}
+	dst.Schedule = v1beta1.Schedule{
+		Restarts: src.Schedule.Restarts,
+		TimeZone: src.Schedule.TimeZone,
+	}
+	for _, window := range src.Schedule.MaintenanceWindows {
+		dst.Schedule.MaintenanceWindows = append(dst.Schedule.MaintenanceWindows, v1beta1.MaintenanceWindow(window))
+	}
+	dst.Updates = v1beta1.Updates{
+		Policy:               v1beta1.UpdatePolicy(src.Updates.Policy),
+		CheckIntervalMinutes: src.Updates.CheckIntervalMinutes,
+	}
+	dst.Paused = src.Paused
+	dst.Idle = v1beta1.Idle(src.Idle)
+	dst.PlayerLists = src.PlayerLists
+	dst.Logs = v1beta1.Logs{Events: src.Logs.Events}
+	for _, stream := range src.Logs.Streams {
+		dst.Logs.Streams = append(dst.Logs.Streams, v1beta1.LogStream(stream))
+	}
+	for _, notification := range src.Notifications {
+		converted := v1beta1.Notification{ChannelRef: notification.ChannelRef, PlayerThresholds: notification.PlayerThresholds}
+		for _, event := range notification.Events {
+			converted.Events = append(converted.Events, v1beta1.NotificationEvent(event))
+		}
+		dst.Notifications = append(dst.Notifications, converted)
+	}
+	if src.Editor.Ingress != nil {
+		dst.Editor.Ingress = &v1beta1.EditorIngress{
+			Host:             src.Editor.Ingress.Host,
+			IngressClassName: src.Editor.Ingress.IngressClassName,
+			TLSSecretName:    src.Editor.Ingress.TLSSecretName,
+			Annotations:      src.Editor.Ingress.Annotations,
+		}
+	}
+}
+
+// ConvertBaseFrom converts the grouped v1beta1 Base into the flat v1alpha1 Base
+func ConvertBaseFrom(src *v1beta1.Base, dst *Base) {
+	dst.TemplateRef = src.TemplateRef
+	dst.ClassName = src.ClassName
+	dst.Ports = src.Network.Ports
+	dst.LoadBalancerIP = src.Network.LoadBalancerIP
+	dst.ServiceAnnotations = src.Network.ServiceAnnotations
+	dst.Persistence = Persistence{
+		StorageConfig: StorageConfig{
+			Size:             src.Storage.Size,
+			StorageClassName: src.Storage.StorageClassName,
+		},
+		PreserveOnDelete: src.Storage.PreserveOnDelete,
+	}
+	dst.Resources = src.Scheduling.Resources
+	dst.NodeSelector = src.Scheduling.NodeSelector
+	dst.Tolerations = src.Scheduling.Tolerations
+	dst.Affinity = src.Scheduling.Affinity
+	dst.Annotations = src.Scheduling.PodAnnotations
+	dst.Editor = Editor{
+		Enabled:   src.Editor.Enabled,
+		Image:     src.Editor.Image,
+		Tag:       src.Editor.Tag,
+		Resources: src.Editor.Resources,
+		Exposure:  EditorExposureType(src.Editor.Exposure),
+	}
+	if src.Editor.Ingress != nil {
+		dst.Editor.Ingress = &EditorIngress{
+			Host:             src.Editor.Ingress.Host,
+			IngressClassName: src.Editor.Ingress.IngressClassName,
+			TLSSecretName:    src.Editor.Ingress.TLSSecretName,
+			Annotations:      src.Editor.Ingress.Annotations,
+		}
+	}
+	dst.EditorPassword = src.Editor.Password
+	dst.EditorPasswordSecretRef = src.Editor.PasswordSecretRef
+	dst.Query = Query{
+		Port:          src.Query.Port,
+		PeriodSeconds: src.Query.PeriodSeconds,
+	}
+	dst.RCon = RCon{
+		Port:              src.RCon.Port,
+		PasswordSecretRef: src.RCon.PasswordSecretRef,
+	}
+	dst.Shutdown = Shutdown{
+		GracePeriodSeconds: src.Shutdown.GracePeriodSeconds,
+		WarningSeconds:     src.Shutdown.WarningSeconds,
+		Message:            src.Shutdown.Message,
+	}
+	dst.Schedule = Schedule{
+		Restarts: src.Schedule.Restarts,
+		TimeZone: src.Schedule.TimeZone,
+	}
+	for _, window := range src.Schedule.MaintenanceWindows {
+		dst.Schedule.MaintenanceWindows = append(dst.Schedule.MaintenanceWindows, MaintenanceWindow(window))
+	}
+	dst.Updates = Updates{
+		Policy:               UpdatePolicy(src.Updates.Policy),
+		CheckIntervalMinutes: src.Updates.CheckIntervalMinutes,
+	}
+	dst.Paused = src.Paused
+	dst.Idle = Idle(src.Idle)
+	dst.PlayerLists = src.PlayerLists
+	dst.Logs = Logs{Events: src.Logs.Events}
+	for _, stream := range src.Logs.Streams {
+		dst.Logs.Streams = append(dst.Logs.Streams, LogStream(stream))
+	}
+	for _, notification := range src.Notifications {
+		converted := Notification{ChannelRef: notification.ChannelRef, PlayerThresholds: notification.PlayerThresholds}
+		for _, event := range notification.Events {
+			converted.Events = append(converted.Events, NotificationEvent(event))
+		}
+		dst.Notifications = append(dst.Notifications, converted)
+	}
+}
+
+// ConvertBaseStatusTo converts the v1alpha1 BaseStatus into the v1beta1 BaseStatus
+func ConvertBaseStatusTo(src *BaseStatus, dst *v1beta1.BaseStatus) {
+	dst.Phase = v1beta1.GameServerPhase(src.Phase)
+	dst.Conditions = src.Conditions
+	dst.EditorSecretName = src.EditorSecretName
+	dst.Players = src.Players
+	dst.MaxPlayers = src.MaxPlayers
+	dst.Map = src.Map
+	dst.Version = src.Version
+	dst.Shutdown = nil
+	if src.Shutdown != nil {
+		dst.Shutdown = &v1beta1.ShutdownStatus{StartTime: src.Shutdown.StartTime, WarningsSent: src.Shutdown.WarningsSent}
+	}
+	dst.NextRestartTime = src.NextRestartTime
+	dst.Updates = nil
+	if src.Updates != nil {
+		updates := v1beta1.UpdateStatus(*src.Updates)
+		dst.Updates = &updates
+	}
+	dst.IdleSince = src.IdleSince
+	dst.Sleeping = src.Sleeping
+	dst.LastCrashTime = src.LastCrashTime
+	dst.BanListHash = src.BanListHash
+	dst.EffectiveSpec = effectiveSpecTo(src.EffectiveSpec)
+}
+
+// ConvertBaseStatusFrom converts the v1beta1 BaseStatus into the v1alpha1 BaseStatus
+func ConvertBaseStatusFrom(src *v1beta1.BaseStatus, dst *BaseStatus) {
+	dst.Phase = GameServerPhase(src.Phase)
+	dst.Conditions = src.Conditions
+	dst.EditorSecretName = src.EditorSecretName
+	dst.Players = src.Players
+	dst.MaxPlayers = src.MaxPlayers
+	dst.Map = src.Map
+	dst.Version = src.Version
+	dst.Shutdown = nil
+	if src.Shutdown != nil {
+		dst.Shutdown = &ShutdownStatus{StartTime: src.Shutdown.StartTime, WarningsSent: src.Shutdown.WarningsSent}
+	}
+	dst.NextRestartTime = src.NextRestartTime
+	dst.Updates = nil
+	if src.Updates != nil {
+		updates := UpdateStatus(*src.Updates)
+		dst.Updates = &updates
+	}
+	dst.IdleSince = src.IdleSince
+	dst.Sleeping = src.Sleeping
+	dst.LastCrashTime = src.LastCrashTime
+	dst.BanListHash = src.BanListHash
+	dst.EffectiveSpec = effectiveSpecFrom(src.EffectiveSpec)
+}
+
+// effectiveSpecTo converts the v1alpha1 Base of status.effectiveSpec into a v1beta1 Base, it is kept as
+// is when it does not parse
+func effectiveSpecTo(src *apiextensionsv1.JSON) *apiextensionsv1.JSON {
+	if src == nil {
+		return nil
+	}
+	base := &Base{}
+	if err := json.Unmarshal(src.Raw, base); err != nil {
+		return src.DeepCopy()
+	}
+	converted := &v1beta1.Base{}
+	ConvertBaseTo(base, converted)
+	raw, err := json.Marshal(converted)
+	if err != nil {
+		return src.DeepCopy()
+	}
+	return &apiextensionsv1.JSON{Raw: raw}
+}
+
+// effectiveSpecFrom converts the v1beta1 Base of status.effectiveSpec into a v1alpha1 Base, it is kept
+// as is when it does not parse
+func effectiveSpecFrom(src *apiextensionsv1.JSON) *apiextensionsv1.JSON {
+	if src == nil {
+		return nil
+	}
+	base := &v1beta1.Base{}
+	if err := json.Unmarshal(src.Raw, base); err != nil {
+		return src.DeepCopy()
+	}
+	converted := &Base{}
+	ConvertBaseFrom(base, converted)
+	raw, err := json.Marshal(converted)
+	if err != nil {
+		return src.DeepCopy()
+	}
+	return &apiextensionsv1.JSON{Raw: raw}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// FleetAutoscalerSpec defines how a FleetAutoscaler scales its fleet
+// +kubebuilder:validation:XValidation:rule="!has(self.minReplicas) || self.minReplicas <= self.maxReplicas",message="minReplicas must not be greater than maxReplicas"
+type FleetAutoscalerSpec struct {
+	// FleetRef references the GameServerFleet of the namespace whose replicas are set
+	FleetRef corev1.LocalObjectReference `json:"fleetRef"`
+
+	// BufferSize is how many empty game servers are kept next to the ones with players, either a
+	// number or a percentage of the replicas such as "20%"
+	//+kubebuilder:validation:XIntOrString
+	//+kubebuilder:validation:XValidation:rule="type(self) == int ? self >= 0 : self.matches('^[0-9]{1,2}%$')",message="bufferSize must be a positive number or a percentage below 100%"
+	BufferSize intstr.IntOrString `json:"bufferSize"`
+
+	// MinReplicas is the least number of game servers
+	//+kubebuilder:validation:Minimum=0
+	MinReplicas int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the most game servers
+	//+kubebuilder:validation:Minimum=0
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// ScaleUpCooldownSeconds is how long after scaling the fleet is not scaled up again (default: 30)
+	//+kubebuilder:default=30
+	//+kubebuilder:validation:Minimum=0
+	ScaleUpCooldownSeconds int32 `json:"scaleUpCooldownSeconds,omitempty"`
+
+	// ScaleDownCooldownSeconds is how long after scaling the fleet is not scaled down again, so players
+	// leaving between rounds do not remove servers right away (default: 300)
+	//+kubebuilder:default=300
+	//+kubebuilder:validation:Minimum=0
+	ScaleDownCooldownSeconds int32 `json:"scaleDownCooldownSeconds,omitempty"`
+}
+
+// FleetAutoscalerStatus defines the observed state of FleetAutoscaler
+type FleetAutoscalerStatus struct {
+	// CurrentReplicas is the number of game servers of the fleet
+	CurrentReplicas int32 `json:"currentReplicas"`
+
+	// OccupiedReplicas is the number of game servers with players or held by a GameServerAllocation
+	OccupiedReplicas int32 `json:"occupiedReplicas"`
+
+	// DesiredReplicas is the number of game servers the fleet is scaled to once the cooldown is over
+	DesiredReplicas int32 `json:"desiredReplicas"`
+
+	// LastScaleTime is when the fleet was last scaled
+	LastScaleTime *metav1.Time `json:"lastScaleTime,omitempty"`
+
+	// Conditions explain the scaling decisions: AbleToScale reports a missing fleet or a cooldown, and
+	// ScalingLimited the desired replicas being bounded by minReplicas or maxReplicas
+	//+listType=map
+	//+listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ObservedGeneration is the generation of the autoscaler the status was computed for
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Fleet",type=string,JSONPath=`.spec.fleetRef.name`
+//+kubebuilder:printcolumn:name="Buffer",type=string,JSONPath=`.spec.bufferSize`
+//+kubebuilder:printcolumn:name="Min",type=integer,JSONPath=`.spec.minReplicas`
+//+kubebuilder:printcolumn:name="Max",type=integer,JSONPath=`.spec.maxReplicas`
+//+kubebuilder:printcolumn:name="Occupied",type=integer,JSONPath=`.status.occupiedReplicas`
+//+kubebuilder:printcolumn:name="Current",type=integer,JSONPath=`.status.currentReplicas`
+//+kubebuilder:printcolumn:name="Desired",type=integer,JSONPath=`.status.desiredReplicas`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// FleetAutoscaler is the Schema for the fleetautoscalers API. It scales a GameServerFleet to keep a
+// buffer of empty game servers next to the ones players are on, from the player counts of the servers.
+type FleetAutoscaler struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   FleetAutoscalerSpec   `json:"spec,omitempty"`
+	Status FleetAutoscalerStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// FleetAutoscalerList contains a list of FleetAutoscaler
+type FleetAutoscalerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FleetAutoscaler `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FleetAutoscaler{}, &FleetAutoscalerList{})
+}
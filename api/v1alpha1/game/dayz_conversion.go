@@ -0,0 +1,118 @@
+package v1alpha1
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+	gamev1beta1 "github.com/templarfelix/gameserver-operator/api/v1beta1/game"
+)
+
+var _ conversion.Convertible = &Dayz{}
+
+// ConvertTo converts this Dayz to the v1beta1 hub version
+func (src *Dayz) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*gamev1beta1.Dayz)
+	if !ok {
+		return fmt.Errorf("unsupported conversion hub %T", dstRaw)
+	}
+
+	dst.ObjectMeta = src.ObjectMeta
+	gameserverv1alpha1.ConvertBaseTo(&src.Spec.Base, &dst.Spec.Base)
+	dst.Spec.Game = gamev1beta1.DayzGame{
+		Image:                     src.Spec.Image,
+		Config:                    gamev1beta1.DayzConfig(src.Spec.Config),
+		SteamCredentialsSecretRef: src.Spec.SteamCredentialsSecretRef,
+		Settings:                  (*gamev1beta1.DayzServerSettings)(src.Spec.Settings),
+	}
+	for _, mod := range src.Spec.Mods {
+		dst.Spec.Game.Mods = append(dst.Spec.Game.Mods, gamev1beta1.DayzMod(mod))
+	}
+	if src.Spec.Economy != nil {
+		dst.Spec.Game.Economy = &gamev1beta1.DayzEconomy{}
+		for _, t := range src.Spec.Economy.Types {
+			dst.Spec.Game.Economy.Types = append(dst.Spec.Game.Economy.Types, gamev1beta1.DayzEconomyType(t))
+		}
+	}
+	dst.Spec.Game.Mission = convertDayzMissionTo(src.Spec.Mission)
+	gameserverv1alpha1.ConvertBaseStatusTo(&src.Status.BaseStatus, &dst.Status.BaseStatus)
+	dst.Status.Mods = nil
+	for _, mod := range src.Status.Mods {
+		dst.Status.Mods = append(dst.Status.Mods, gamev1beta1.DayzModStatus(mod))
+	}
+	return nil
+}
+
+// ConvertFrom converts the v1beta1 hub version to this Dayz
+func (dst *Dayz) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*gamev1beta1.Dayz)
+	if !ok {
+		return fmt.Errorf("unsupported conversion hub %T", srcRaw)
+	}
+
+	dst.ObjectMeta = src.ObjectMeta
+	gameserverv1alpha1.ConvertBaseFrom(&src.Spec.Base, &dst.Spec.Base)
+	dst.Spec.Image = src.Spec.Game.Image
+	dst.Spec.Config = DayzConfig(src.Spec.Game.Config)
+	dst.Spec.SteamCredentialsSecretRef = src.Spec.Game.SteamCredentialsSecretRef
+	dst.Spec.Settings = (*DayzServerSettings)(src.Spec.Game.Settings)
+	dst.Spec.Mods = nil
+	for _, mod := range src.Spec.Game.Mods {
+		dst.Spec.Mods = append(dst.Spec.Mods, DayzMod(mod))
+	}
+	dst.Spec.Economy = nil
+	if src.Spec.Game.Economy != nil {
+		dst.Spec.Economy = &DayzEconomy{}
+		for _, t := range src.Spec.Game.Economy.Types {
+			dst.Spec.Economy.Types = append(dst.Spec.Economy.Types, DayzEconomyType(t))
+		}
+	}
+	dst.Spec.Mission = convertDayzMissionFrom(src.Spec.Game.Mission)
+	gameserverv1alpha1.ConvertBaseStatusFrom(&src.Status.BaseStatus, &dst.Status.BaseStatus)
+	dst.Status.Mods = nil
+	for _, mod := range src.Status.Mods {
+		dst.Status.Mods = append(dst.Status.Mods, DayzModStatus(mod))
+	}
+	return nil
+}
+
+func convertDayzMissionTo(src *DayzMission) *gamev1beta1.DayzMission {
+	if src == nil {
+		return nil
+	}
+	dst := &gamev1beta1.DayzMission{}
+	if src.Source != nil {
+		dst.Source = &gamev1beta1.DayzMissionSource{
+			Git:     (*gamev1beta1.DayzGitSource)(src.Source.Git),
+			Archive: (*gamev1beta1.DayzArchiveSource)(src.Source.Archive),
+		}
+	}
+	if src.Gameplay != nil {
+		dst.Gameplay = &gamev1beta1.DayzGameplay{}
+		for _, patch := range src.Gameplay.Patches {
+			dst.Gameplay.Patches = append(dst.Gameplay.Patches, gamev1beta1.DayzJSONPatch(patch))
+		}
+	}
+	return dst
+}
+
+func convertDayzMissionFrom(src *gamev1beta1.DayzMission) *DayzMission {
+	if src == nil {
+		return nil
+	}
+	dst := &DayzMission{}
+	if src.Source != nil {
+		dst.Source = &DayzMissionSource{
+			Git:     (*DayzGitSource)(src.Source.Git),
+			Archive: (*DayzArchiveSource)(src.Source.Archive),
+		}
+	}
+	if src.Gameplay != nil {
+		dst.Gameplay = &DayzGameplay{}
+		for _, patch := range src.Gameplay.Patches {
+			dst.Gameplay.Patches = append(dst.Gameplay.Patches, DayzJSONPatch(patch))
+		}
+	}
+	return dst
+}
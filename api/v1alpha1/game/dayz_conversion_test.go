@@ -0,0 +1,98 @@
+package v1alpha1
+
+import (
+	"math/rand"
+
+	fuzz "github.com/google/gofuzz"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metafuzzer "k8s.io/apimachinery/pkg/apis/meta/fuzzer"
+	"k8s.io/apimachinery/pkg/runtime"
+	runtimeserializer "k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	gamev1beta1 "github.com/templarfelix/gameserver-operator/api/v1beta1/game"
+)
+
+const fuzzIterations = 500
+
+// conversionFuzzerFuncs keeps fuzzed values representable in the API, fields without JSON
+// representation (e.g. internal quantity caches) would otherwise make DeepEqual fail
+func conversionFuzzerFuncs(_ runtimeserializer.CodecFactory) []interface{} {
+	return []interface{}{
+		func(q *resource.Quantity, c fuzz.Continue) {
+			*q = *resource.NewQuantity(c.Int63n(1<<40), resource.DecimalSI)
+		},
+		func(i *intstr.IntOrString, c fuzz.Continue) {
+			if c.RandBool() {
+				*i = intstr.FromInt32(c.Int31())
+			} else {
+				*i = intstr.FromString(c.RandString())
+			}
+		},
+	}
+}
+
+func newConversionFuzzer() *fuzz.Fuzzer {
+	codecs := runtimeserializer.NewCodecFactory(runtime.NewScheme())
+	return fuzzer.FuzzerFor(
+		fuzzer.MergeFuzzerFuncs(metafuzzer.Funcs, conversionFuzzerFuncs),
+		rand.NewSource(GinkgoRandomSeed()),
+		codecs,
+	)
+}
+
+var _ = Describe("Dayz conversion", func() {
+	It("should round-trip v1alpha1 through the v1beta1 hub without loss", func() {
+		f := newConversionFuzzer()
+		for i := 0; i < fuzzIterations; i++ {
+			original := &Dayz{}
+			f.Fuzz(original)
+
+			hub := &gamev1beta1.Dayz{}
+			Expect(original.DeepCopy().ConvertTo(hub)).To(Succeed())
+			restored := &Dayz{}
+			Expect(restored.ConvertFrom(hub)).To(Succeed())
+
+			Expect(apiequality.Semantic.DeepEqual(original, restored)).To(BeTrue(),
+				"v1alpha1 -> v1beta1 -> v1alpha1 changed the object:\n%#v\n%#v", original, restored)
+		}
+	})
+
+	It("should round-trip the v1beta1 hub through v1alpha1 without loss", func() {
+		f := newConversionFuzzer()
+		for i := 0; i < fuzzIterations; i++ {
+			original := &gamev1beta1.Dayz{}
+			f.Fuzz(original)
+
+			spoke := &Dayz{}
+			Expect(spoke.ConvertFrom(original.DeepCopy())).To(Succeed())
+			restored := &gamev1beta1.Dayz{}
+			Expect(spoke.ConvertTo(restored)).To(Succeed())
+
+			Expect(apiequality.Semantic.DeepEqual(original, restored)).To(BeTrue(),
+				"v1beta1 -> v1alpha1 -> v1beta1 changed the object:\n%#v\n%#v", original, restored)
+		}
+	})
+
+	It("should group the flat v1alpha1 fields", func() {
+		dayz := &Dayz{}
+		dayz.Spec.Image = "gameservermanagers/gameserver:dayz"
+		dayz.Spec.LoadBalancerIP = "10.0.0.10"
+		dayz.Spec.Persistence.StorageConfig.StorageClassName = "standard"
+		dayz.Spec.EditorPassword = "secret"
+		dayz.Spec.Config = DayzConfig{"/data/serverfiles/cfg/dayzserver.server.cfg": "maxPlayers = 60;"}
+
+		hub := &gamev1beta1.Dayz{}
+		Expect(dayz.ConvertTo(hub)).To(Succeed())
+		Expect(hub.Spec.Game.Image).To(Equal("gameservermanagers/gameserver:dayz"))
+		Expect(hub.Spec.Network.LoadBalancerIP).To(Equal("10.0.0.10"))
+		Expect(hub.Spec.Storage.StorageClassName).To(Equal("standard"))
+		Expect(hub.Spec.Editor.Password).To(Equal("secret"))
+		Expect(hub.Spec.Game.Config).To(HaveKey("/data/serverfiles/cfg/dayzserver.server.cfg"))
+	})
+})
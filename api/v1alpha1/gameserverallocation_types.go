@@ -0,0 +1,129 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// AllocatedLabel is set to "true" on a game server held by a GameServerAllocation, fleets do not
+	// remove nor update it and scheduled restarts wait until it is released
+	AllocatedLabel = "gameserver.templarfelix.com/allocated"
+
+	// AllocationAnnotation names the GameServerAllocation holding a game server
+	AllocationAnnotation = "gameserver.templarfelix.com/allocation"
+)
+
+// AllocationState is the state of a GameServerAllocation
+// +kubebuilder:validation:Enum=Allocated;UnAllocated;Released
+type AllocationState string
+
+const (
+	// AllocationAllocated means the allocation holds a game server
+	AllocationAllocated AllocationState = "Allocated"
+	// AllocationUnAllocated means no ready and empty game server matched, create a new allocation to retry
+	AllocationUnAllocated AllocationState = "UnAllocated"
+	// AllocationReleased means the game server was handed back to its fleet
+	AllocationReleased AllocationState = "Released"
+)
+
+// GameServerAllocationSpec selects the game server to allocate
+// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec is immutable, create a new GameServerAllocation instead"
+type GameServerAllocationSpec struct {
+	// FleetRef references the GameServerFleet of the namespace to allocate from, any fleet of the
+	// namespace when unset
+	FleetRef *corev1.LocalObjectReference `json:"fleetRef,omitempty"`
+
+	// Selector matches the labels of the game servers, all servers of the fleets match when unset
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// EmptyTimeoutSeconds releases the game server when no player joined within this time after the
+	// allocation (default: 300). It is released as well once the players who joined left
+	//+kubebuilder:default=300
+	//+kubebuilder:validation:Minimum=1
+	EmptyTimeoutSeconds int32 `json:"emptyTimeoutSeconds,omitempty"`
+}
+
+// GameServerAllocationPort is a port players connect to
+type GameServerAllocationPort struct {
+	Name     string          `json:"name,omitempty"`
+	Port     int32           `json:"port"`
+	Protocol corev1.Protocol `json:"protocol,omitempty"`
+}
+
+// GameServerAllocationStatus defines the observed state of GameServerAllocation
+type GameServerAllocationStatus struct {
+	// State of the allocation
+	State AllocationState `json:"state,omitempty"`
+
+	// GameServerRef is the allocated game server
+	GameServerRef *GameServerReference `json:"gameServerRef,omitempty"`
+
+	// Fleet is the GameServerFleet of the allocated game server
+	Fleet string `json:"fleet,omitempty"`
+
+	// Address is the LoadBalancer IP or hostname of the game server
+	Address string `json:"address,omitempty"`
+
+	// Ports of the game server
+	Ports []GameServerAllocationPort `json:"ports,omitempty"`
+
+	// AllocationTime is when the game server was allocated
+	AllocationTime *metav1.Time `json:"allocationTime,omitempty"`
+
+	// PlayersJoined is true once a player was seen on the game server
+	PlayersJoined bool `json:"playersJoined,omitempty"`
+
+	// ReleaseTime is when the game server was released
+	ReleaseTime *metav1.Time `json:"releaseTime,omitempty"`
+
+	// Message explains the state
+	Message string `json:"message,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+//+kubebuilder:printcolumn:name="Game Server",type=string,JSONPath=`.status.gameServerRef.name`
+//+kubebuilder:printcolumn:name="Address",type=string,JSONPath=`.status.address`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// GameServerAllocation is the Schema for the gameserverallocations API. It claims a ready and empty
+// game server of a fleet for a match and reports its address, the server is handed back to the fleet
+// when the allocation is deleted or the server is empty again.
+type GameServerAllocation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GameServerAllocationSpec   `json:"spec,omitempty"`
+	Status GameServerAllocationStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// GameServerAllocationList contains a list of GameServerAllocation
+type GameServerAllocationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GameServerAllocation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GameServerAllocation{}, &GameServerAllocationList{})
+}
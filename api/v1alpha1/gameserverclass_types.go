@@ -0,0 +1,95 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GameServerClassScheduling places the game pods of the class on nodes
+type GameServerClassScheduling struct {
+	// NodeSelector is a selector which must be true for the pod to fit on a node
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations are the tolerations for the pod
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity is the affinity for the pod
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// PodAnnotations for the pod template
+	PodAnnotations map[string]string `json:"podAnnotations,omitempty"`
+}
+
+// GameServerClassExposure configures the Services publishing the game ports
+type GameServerClassExposure struct {
+	// ServiceAnnotations for the game Services, e.g. the address pool or load balancer settings of the cluster
+	ServiceAnnotations map[string]string `json:"serviceAnnotations,omitempty"`
+}
+
+// GameServerClassEditor is the code-server editor policy of the class
+type GameServerClassEditor struct {
+	// Enabled adds the code-server sidecar to the game pods
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Exposure selects how the editor is reachable
+	Exposure EditorExposureType `json:"exposure,omitempty"`
+}
+
+// GameServerClassSpec defines the cluster defaults of the game servers of a class. They override the
+// GameServerTemplate of a server and are overridden by the spec of the server.
+type GameServerClassSpec struct {
+	// Scheduling places the game pods
+	Scheduling GameServerClassScheduling `json:"scheduling,omitempty"`
+
+	// StorageClassName of the game volumes
+	StorageClassName string `json:"storageClassName,omitempty"`
+
+	// Exposure configures the game Services
+	Exposure GameServerClassExposure `json:"exposure,omitempty"`
+
+	// Editor configures the code-server editor
+	Editor GameServerClassEditor `json:"editor,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:printcolumn:name="Storage Class",type=string,JSONPath=`.spec.storageClassName`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// GameServerClass is the Schema for the gameserverclasses API. Game servers of every namespace select
+// it in spec.className, directly or through their GameServerTemplate.
+type GameServerClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec GameServerClassSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// GameServerClassList contains a list of GameServerClass
+type GameServerClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GameServerClass `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GameServerClass{}, &GameServerClassList{})
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GameServerCommandType is an administrative command sent to the game server over RCON
+// +kubebuilder:validation:Enum=say;kick;ban;lock;unlock;shutdown;save;raw
+type GameServerCommandType string
+
+const (
+	// CommandSay broadcasts a message to all players, or to Player when set
+	CommandSay GameServerCommandType = "say"
+	// CommandKick kicks Player
+	CommandKick GameServerCommandType = "kick"
+	// CommandBan bans Player for BanMinutes
+	CommandBan GameServerCommandType = "ban"
+	// CommandLock prevents new players from joining
+	CommandLock GameServerCommandType = "lock"
+	// CommandUnlock allows players to join again
+	CommandUnlock GameServerCommandType = "unlock"
+	// CommandShutdown shuts the server down, it is restarted by the Deployment
+	CommandShutdown GameServerCommandType = "shutdown"
+	// CommandSave persists the world, for games that have a save command
+	CommandSave GameServerCommandType = "save"
+	// CommandRaw sends Raw to the RCON console as-is
+	CommandRaw GameServerCommandType = "raw"
+)
+
+// GameServerCommandPhase is the execution state of a GameServerCommand
+type GameServerCommandPhase string
+
+const (
+	// CommandPending means the command has not been executed yet
+	CommandPending GameServerCommandPhase = "Pending"
+	// CommandSucceeded means the game server accepted the command
+	CommandSucceeded GameServerCommandPhase = "Succeeded"
+	// CommandFailed means the command could not be executed, see status.message
+	CommandFailed GameServerCommandPhase = "Failed"
+)
+
+// GameServerReference points to a game server in the same namespace
+type GameServerReference struct {
+	// Kind of the game server
+	//+kubebuilder:validation:Enum=Dayz
+	//+kubebuilder:default=Dayz
+	Kind string `json:"kind,omitempty"`
+
+	// Name of the game server
+	Name string `json:"name"`
+}
+
+// GameServerCommandSpec defines the command to run
+// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec is immutable, create a new GameServerCommand instead"
+// +kubebuilder:validation:XValidation:rule="self.command != 'say' || (has(self.message) && self.message != '')",message="message is required for say"
+// +kubebuilder:validation:XValidation:rule="!(self.command in ['kick', 'ban']) || (has(self.player) && self.player != '')",message="player is required for kick and ban"
+// +kubebuilder:validation:XValidation:rule="self.command != 'raw' || (has(self.raw) && self.raw != '')",message="raw is required for raw"
+type GameServerCommandSpec struct {
+	// GameServerRef is the game server the command is sent to
+	GameServerRef GameServerReference `json:"gameServerRef"`
+
+	// Command to run
+	Command GameServerCommandType `json:"command"`
+
+	// Message broadcast by say
+	Message string `json:"message,omitempty"`
+
+	// Player targeted by kick and ban, or by say instead of everyone.
+	// Either the player number shown by the RCon players command or the exact player name
+	Player string `json:"player,omitempty"`
+
+	// Reason shown to kicked or banned players
+	Reason string `json:"reason,omitempty"`
+
+	// BanMinutes is the ban duration, 0 bans permanently
+	//+kubebuilder:validation:Minimum=0
+	BanMinutes int32 `json:"banMinutes,omitempty"`
+
+	// Raw is the console command sent by the raw command
+	Raw string `json:"raw,omitempty"`
+}
+
+// GameServerCommandStatus defines the observed state of GameServerCommand
+type GameServerCommandStatus struct {
+	// Phase is Pending until the command was executed
+	Phase GameServerCommandPhase `json:"phase,omitempty"`
+
+	// Response returned by the game server
+	Response string `json:"response,omitempty"`
+
+	// Message explains why the command is pending or failed
+	Message string `json:"message,omitempty"`
+
+	// CompletionTime is when the command succeeded or failed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Server",type=string,JSONPath=`.spec.gameServerRef.name`
+//+kubebuilder:printcolumn:name="Command",type=string,JSONPath=`.spec.command`
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// GameServerCommand is the Schema for the gameservercommands API. It runs one administrative
+// command on a game server over RCON and records the response.
+type GameServerCommand struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GameServerCommandSpec   `json:"spec,omitempty"`
+	Status GameServerCommandStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// GameServerCommandList contains a list of GameServerCommand
+type GameServerCommandList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GameServerCommand `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GameServerCommand{}, &GameServerCommandList{})
+}
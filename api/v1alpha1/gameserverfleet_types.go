@@ -0,0 +1,146 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// FleetLabel names the GameServerFleet a game server belongs to
+	FleetLabel = "gameserver.templarfelix.com/fleet"
+
+	// FleetIndexLabel is the index of a game server in its fleet, it numbers the name, ports and hostname
+	FleetIndexLabel = "gameserver.templarfelix.com/fleet-index"
+
+	// FleetTemplateHashAnnotation is the hash of the fleet template a game server was last updated to
+	FleetTemplateHashAnnotation = "gameserver.templarfelix.com/fleet-template-hash"
+)
+
+// GameServerFleetMetadata are labels and annotations added to the game servers of a fleet
+type GameServerFleetMetadata struct {
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// GameServerFleetTemplate describes the game servers of a fleet
+type GameServerFleetTemplate struct {
+	// Kind of the game servers
+	//+kubebuilder:validation:Enum=Dayz
+	//+kubebuilder:default=Dayz
+	Kind string `json:"kind,omitempty"`
+
+	// Metadata added to the game servers
+	Metadata GameServerFleetMetadata `json:"metadata,omitempty"`
+
+	// Spec of the game servers, in the v1alpha1 schema of the kind. It is validated when the servers
+	// are created
+	//+kubebuilder:pruning:PreserveUnknownFields
+	Spec apiextensionsv1.JSON `json:"spec"`
+}
+
+// GameServerFleetStrategy configures how template changes are rolled out across the fleet
+type GameServerFleetStrategy struct {
+	// MaxUnavailable is how many game servers may be updating or not ready while the template change
+	// is rolled out (default: 1)
+	//+kubebuilder:default=1
+	//+kubebuilder:validation:Minimum=1
+	MaxUnavailable int32 `json:"maxUnavailable,omitempty"`
+}
+
+// GameServerFleetSpec defines the desired state of GameServerFleet
+type GameServerFleetSpec struct {
+	// Replicas is the number of game servers
+	//+kubebuilder:validation:Minimum=0
+	Replicas int32 `json:"replicas"`
+
+	// Template the game servers are created from, named <fleet>-<index>
+	Template GameServerFleetTemplate `json:"template"`
+
+	// Hostname of the game servers, {index} and {name} are replaced with the index and name of each
+	// server. The hostname of the template followed by " #{index}" is used when empty, or {name}
+	// without one
+	Hostname string `json:"hostname,omitempty"`
+
+	// PortStride is added to the Service ports of the template times the index of each server, so
+	// servers sharing a LoadBalancer address get unique ports. The container ports are not changed.
+	// The template must list its ports when set
+	//+kubebuilder:validation:Minimum=0
+	PortStride int32 `json:"portStride,omitempty"`
+
+	// Strategy configures rolling template changes
+	Strategy GameServerFleetStrategy `json:"strategy,omitempty"`
+}
+
+// GameServerFleetStatus defines the observed state of GameServerFleet
+type GameServerFleetStatus struct {
+	// Replicas is the number of game servers of the fleet
+	Replicas int32 `json:"replicas"`
+
+	// ReadyReplicas is the number of game servers answering their query port
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+
+	// UpdatedReplicas is the number of game servers running the current template
+	UpdatedReplicas int32 `json:"updatedReplicas,omitempty"`
+
+	// AllocatedReplicas is the number of game servers held by a GameServerAllocation
+	AllocatedReplicas int32 `json:"allocatedReplicas,omitempty"`
+
+	// Players is the number of players on all game servers of the fleet
+	Players int32 `json:"players,omitempty"`
+
+	// Selector selects the game servers of the fleet, for the scale subresource
+	Selector string `json:"selector,omitempty"`
+
+	// ObservedGeneration is the generation of the fleet the status was computed for
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas,selectorpath=.status.selector
+//+kubebuilder:printcolumn:name="Kind",type=string,JSONPath=`.spec.template.kind`
+//+kubebuilder:printcolumn:name="Desired",type=integer,JSONPath=`.spec.replicas`
+//+kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.readyReplicas`
+//+kubebuilder:printcolumn:name="Updated",type=integer,JSONPath=`.status.updatedReplicas`
+//+kubebuilder:printcolumn:name="Allocated",type=integer,JSONPath=`.status.allocatedReplicas`
+//+kubebuilder:printcolumn:name="Players",type=integer,JSONPath=`.status.players`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// GameServerFleet is the Schema for the gameserverfleets API. It keeps a number of identical game
+// servers and rolls template changes out across them.
+type GameServerFleet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GameServerFleetSpec   `json:"spec,omitempty"`
+	Status GameServerFleetStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// GameServerFleetList contains a list of GameServerFleet
+type GameServerFleetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GameServerFleet `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GameServerFleet{}, &GameServerFleetList{})
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GameServerTemplateSpec defines the defaults of the game servers referencing the template in
+// spec.templateRef. Every common game server field can be set, className selects the GameServerClass
+// of servers not naming one.
+// +kubebuilder:validation:XValidation:rule="!has(self.templateRef)",message="templates cannot reference other templates"
+type GameServerTemplateSpec struct {
+	Base `json:",inline"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:printcolumn:name="Class",type=string,JSONPath=`.spec.className`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// GameServerTemplate is the Schema for the gameservertemplates API. It holds the ports, resources,
+// scheduling and other settings shared by the game servers of a namespace.
+type GameServerTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec GameServerTemplateSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// GameServerTemplateList contains a list of GameServerTemplate
+type GameServerTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GameServerTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GameServerTemplate{}, &GameServerTemplateList{})
+}
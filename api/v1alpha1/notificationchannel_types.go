@@ -0,0 +1,92 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NotificationChannelType is the service a NotificationChannel posts to
+// +kubebuilder:validation:Enum=discord;slack;webhook
+type NotificationChannelType string
+
+const (
+	// NotificationChannelDiscord posts messages to a Discord channel webhook
+	NotificationChannelDiscord NotificationChannelType = "discord"
+	// NotificationChannelSlack posts messages to a Slack incoming webhook
+	NotificationChannelSlack NotificationChannelType = "slack"
+	// NotificationChannelWebhook posts a JSON document describing the event to any URL
+	NotificationChannelWebhook NotificationChannelType = "webhook"
+)
+
+// NotificationChannelSpec defines where notifications are delivered
+// +kubebuilder:validation:XValidation:rule="!has(self.signingSecretRef) || self.type == 'webhook'",message="signingSecretRef is only used by webhook channels"
+type NotificationChannelSpec struct {
+	// Type of the service the notifications are posted to
+	Type NotificationChannelType `json:"type"`
+
+	// URLSecretRef references the Secret key holding the webhook URL, Discord and Slack webhook URLs
+	// allow anyone to post
+	URLSecretRef corev1.SecretKeySelector `json:"urlSecretRef"`
+
+	// SigningSecretRef references the Secret key generic webhooks are signed with. The HMAC-SHA256 of
+	// the body is sent in the X-Gameserver-Signature header as sha256=<hex>
+	SigningSecretRef *corev1.SecretKeySelector `json:"signingSecretRef,omitempty"`
+}
+
+// NotificationChannelStatus defines the observed state of NotificationChannel
+type NotificationChannelStatus struct {
+	// LastDeliveryTime is when a notification was last delivered
+	LastDeliveryTime *metav1.Time `json:"lastDeliveryTime,omitempty"`
+
+	// LastFailureTime is when a notification was last dropped after its retries
+	LastFailureTime *metav1.Time `json:"lastFailureTime,omitempty"`
+
+	// LastError is why the last dropped notification failed
+	LastError string `json:"lastError,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`
+//+kubebuilder:printcolumn:name="Last Delivery",type=date,JSONPath=`.status.lastDeliveryTime`
+//+kubebuilder:printcolumn:name="Last Error",type=string,JSONPath=`.status.lastError`,priority=1
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// NotificationChannel is the Schema for the notificationchannels API. Game servers subscribe to it in
+// spec.notifications and the operator posts their events to it.
+type NotificationChannel struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NotificationChannelSpec   `json:"spec,omitempty"`
+	Status NotificationChannelStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NotificationChannelList contains a list of NotificationChannel
+type NotificationChannelList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NotificationChannel `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NotificationChannel{}, &NotificationChannelList{})
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PlayerListType is what a PlayerList grants or denies to its players
+// +kubebuilder:validation:Enum=whitelist;ban;priority;admin
+type PlayerListType string
+
+const (
+	// PlayerListWhitelist lists the players allowed to join servers with a whitelist enabled
+	PlayerListWhitelist PlayerListType = "whitelist"
+	// PlayerListBan lists the players who cannot join
+	PlayerListBan PlayerListType = "ban"
+	// PlayerListPriority lists the players skipping the join queue
+	PlayerListPriority PlayerListType = "priority"
+	// PlayerListAdmin lists the server administrators, for games with an admin list file
+	PlayerListAdmin PlayerListType = "admin"
+)
+
+// PlayerListEntry is a player of a PlayerList
+type PlayerListEntry struct {
+	// ID is the Steam64 id of the player, or the BattlEye GUID
+	//+kubebuilder:validation:Pattern=`^([0-9]{17}|[0-9a-fA-F]{32})$`
+	ID string `json:"id"`
+
+	// Name is a note of who the player is, it is not matched against the player name
+	Name string `json:"name,omitempty"`
+
+	// Reason is why the player is listed, shown to kicked players
+	Reason string `json:"reason,omitempty"`
+
+	// Expires is when the entry is removed from the game servers, it never expires when unset
+	Expires *metav1.Time `json:"expires,omitempty"`
+}
+
+// PlayerListSpec defines the players of a PlayerList
+type PlayerListSpec struct {
+	// Type of the list
+	Type PlayerListType `json:"type"`
+
+	// Entries are the listed players
+	//+listType=map
+	//+listMapKey=id
+	Entries []PlayerListEntry `json:"entries,omitempty"`
+}
+
+// PlayerListStatus defines the observed state of PlayerList
+type PlayerListStatus struct {
+	// ActiveEntries is the number of entries which did not expire
+	ActiveEntries int32 `json:"activeEntries"`
+
+	// NextExpiry is when the next entry expires
+	NextExpiry *metav1.Time `json:"nextExpiry,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`
+//+kubebuilder:printcolumn:name="Active",type=integer,JSONPath=`.status.activeEntries`
+//+kubebuilder:printcolumn:name="Next Expiry",type=date,JSONPath=`.status.nextExpiry`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// PlayerList is the Schema for the playerlists API. It holds a whitelist, ban list, priority list or
+// admin list which game servers reference in spec.playerLists, the operator renders it into the list
+// files of each game.
+type PlayerList struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PlayerListSpec   `json:"spec,omitempty"`
+	Status PlayerListStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PlayerListList contains a list of PlayerList
+type PlayerListList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PlayerList `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PlayerList{}, &PlayerListList{})
+}
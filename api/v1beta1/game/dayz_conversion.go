@@ -0,0 +1,4 @@
+package v1beta1
+
+// Hub marks v1beta1 as the conversion hub for Dayz, other versions convert to and from it
+func (*Dayz) Hub() {}
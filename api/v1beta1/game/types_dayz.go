@@ -0,0 +1,323 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	gameserverv1beta1 "github.com/templarfelix/gameserver-operator/api/v1beta1"
+)
+
+// DayzGame holds the DayZ specific settings
+type DayzGame struct {
+	// Image of the game server, the LinuxGSM image of the game is used when empty
+	Image string `json:"image,omitempty"`
+
+	// Config maps file paths below /data to their content
+	Config DayzConfig `json:"config,omitempty"`
+
+	// Mods are Steam Workshop mods, loaded in list order
+	Mods []DayzMod `json:"mods,omitempty"`
+
+	// SteamCredentialsSecretRef references a Secret with the keys username and password of a Steam account
+	// owning DayZ, it is required to download Workshop mods
+	SteamCredentialsSecretRef *corev1.LocalObjectReference `json:"steamCredentialsSecretRef,omitempty"`
+
+	// Settings are rendered into the server.cfg of the server, over the server.cfg of the config
+	Settings *DayzServerSettings `json:"settings,omitempty"`
+
+	// Economy patches the Central Economy files of the mission before each start of the server
+	Economy *DayzEconomy `json:"economy,omitempty"`
+
+	// Mission downloads a custom mission and patches its gameplay settings
+	Mission *DayzMission `json:"mission,omitempty"`
+}
+
+// DayzMod is a Steam Workshop mod loaded by the server
+type DayzMod struct {
+	// WorkshopID is the id of the Workshop item
+	//+kubebuilder:validation:Pattern=`^[0-9]+$`
+	WorkshopID string `json:"workshopID"`
+
+	// Name is the @Mod folder the mod is linked to in serverfiles (default: @<workshopID>)
+	//+kubebuilder:validation:Pattern=`^@[A-Za-z0-9_.-]+$`
+	Name string `json:"name,omitempty"`
+
+	// ServerSide loads the mod with -servermod, players do not need it
+	ServerSide bool `json:"serverSide,omitempty"`
+}
+
+// DayzServerSettings are the common server.cfg settings, see
+// https://community.bistudio.com/wiki/DayZ:Server_Configuration
+type DayzServerSettings struct {
+	// Hostname is the server name shown in the server browser
+	//+kubebuilder:validation:MaxLength=256
+	Hostname string `json:"hostname,omitempty"`
+
+	// PasswordSecretRef selects the password players join with
+	PasswordSecretRef *corev1.SecretKeySelector `json:"passwordSecretRef,omitempty"`
+
+	// AdminPasswordSecretRef selects the password to become a server admin
+	AdminPasswordSecretRef *corev1.SecretKeySelector `json:"adminPasswordSecretRef,omitempty"`
+
+	// MaxPlayers is the maximum amount of players
+	//+kubebuilder:validation:Minimum=1
+	//+kubebuilder:validation:Maximum=127
+	MaxPlayers int32 `json:"maxPlayers,omitempty"`
+
+	// Mission is the mission template loaded on startup, <MissionName>.<TerrainName> such as
+	// dayzOffline.chernarusplus
+	//+kubebuilder:validation:Pattern=`^[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+$`
+	Mission string `json:"mission,omitempty"`
+
+	// InstanceID identifies the storage folder of the persistence files
+	//+kubebuilder:validation:Minimum=1
+	InstanceID int32 `json:"instanceID,omitempty"`
+
+	// ServerTime is the initial in-game time, SystemTime for the time of the node or YYYY/MM/DD/HH/MM
+	//+kubebuilder:validation:Pattern=`^(SystemTime|[0-9]{4}/[0-9]{1,2}/[0-9]{1,2}/[0-9]{1,2}/[0-9]{1,2})$`
+	ServerTime string `json:"serverTime,omitempty"`
+
+	// ServerTimeAcceleration is the in-game time multiplier, from 0.1 to 64
+	//+kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	ServerTimeAcceleration string `json:"serverTimeAcceleration,omitempty"`
+
+	// ServerNightTimeAcceleration multiplies ServerTimeAcceleration at night, from 0.1 to 64
+	//+kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	ServerNightTimeAcceleration string `json:"serverNightTimeAcceleration,omitempty"`
+
+	// ServerTimePersistent saves the in-game time and restores it on the next start
+	ServerTimePersistent *bool `json:"serverTimePersistent,omitempty"`
+
+	// Disable3rdPerson forces the first person view
+	Disable3rdPerson *bool `json:"disable3rdPerson,omitempty"`
+
+	// DisableCrosshair hides the crosshair
+	DisableCrosshair *bool `json:"disableCrosshair,omitempty"`
+
+	// DisableVoN disables voice over network
+	DisableVoN *bool `json:"disableVoN,omitempty"`
+
+	// EnableWhitelist only lets players of whitelist.txt join
+	EnableWhitelist *bool `json:"enableWhitelist,omitempty"`
+
+	// MaxPing kicks players with a higher ping, in milliseconds
+	//+kubebuilder:validation:Minimum=1
+	MaxPing int32 `json:"maxPing,omitempty"`
+
+	// EnableCfgGameplayFile makes the server read cfggameplay.json of the mission, it is enabled
+	// unless set to false when mission.gameplay has patches
+	EnableCfgGameplayFile *bool `json:"enableCfgGameplayFile,omitempty"`
+
+	// Motd are the messages of the day shown in the in-game chat
+	Motd []string `json:"motd,omitempty"`
+
+	// MotdIntervalSeconds is the time between two messages of the day
+	//+kubebuilder:validation:Minimum=1
+	MotdIntervalSeconds int32 `json:"motdIntervalSeconds,omitempty"`
+}
+
+// DayzEconomy are patches merged into the Central Economy files of the mission
+type DayzEconomy struct {
+	// Types override the settings of the types in db/types.xml of the mission, by class name, and add
+	// the types the mission does not have
+	Types []DayzEconomyType `json:"types,omitempty"`
+}
+
+// DayzEconomyType overrides the settings of a type of types.xml, unset fields are kept. Types added
+// to the mission take the defaults lifetime 14400, quantmin and quantmax -1, cost 100 and 0 otherwise.
+type DayzEconomyType struct {
+	// Name is the class name of the item, e.g. AKM
+	//+kubebuilder:validation:Pattern=`^[A-Za-z0-9_]+$`
+	Name string `json:"name"`
+
+	// Nominal is the number of items the economy keeps on the map
+	//+kubebuilder:validation:Minimum=0
+	Nominal *int32 `json:"nominal,omitempty"`
+
+	// Min is the number of items below which the economy respawns them
+	//+kubebuilder:validation:Minimum=0
+	Min *int32 `json:"min,omitempty"`
+
+	// LifetimeSeconds is how long an item stays on the ground
+	//+kubebuilder:validation:Minimum=0
+	LifetimeSeconds *int32 `json:"lifetimeSeconds,omitempty"`
+
+	// RestockSeconds is the time between two respawns of the item
+	//+kubebuilder:validation:Minimum=0
+	RestockSeconds *int32 `json:"restockSeconds,omitempty"`
+
+	// Category replaces the category of the item, e.g. weapons
+	Category string `json:"category,omitempty"`
+
+	// Usages replace the usages of the item, the kinds of places it spawns at such as Military
+	Usages []string `json:"usages,omitempty"`
+
+	// Values replace the values of the item, the map tiers it spawns in such as Tier3
+	Values []string `json:"values,omitempty"`
+}
+
+// DayzMission downloads a custom mission and patches the gameplay settings of the mission. The
+// mission the server loads is chosen with settings.mission.
+type DayzMission struct {
+	// Source downloads the mission into mpmissions/<mission> before each start of the server. Files of
+	// the mission folder the source does not have, such as the persistence in storage_1, are kept.
+	Source *DayzMissionSource `json:"source,omitempty"`
+
+	// Gameplay patches cfggameplay.json of the mission and enables it with enableCfgGameplayFile
+	Gameplay *DayzGameplay `json:"gameplay,omitempty"`
+}
+
+// DayzMissionSource is where a custom mission is downloaded from, exactly one of git and archive
+// +kubebuilder:validation:XValidation:rule="has(self.git) != has(self.archive)",message="exactly one of git and archive must be set"
+type DayzMissionSource struct {
+	// Git clones the mission from a Git repository
+	Git *DayzGitSource `json:"git,omitempty"`
+
+	// Archive downloads the mission as a zip or tar archive
+	Archive *DayzArchiveSource `json:"archive,omitempty"`
+}
+
+// DayzGitSource is a Git repository holding a mission
+type DayzGitSource struct {
+	// URL of the repository, e.g. https://github.com/example/missions.git
+	//+kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+
+	// Ref is the branch, tag or commit checked out (default: the default branch of the repository)
+	//+kubebuilder:validation:Pattern=`^[A-Za-z0-9_./-]+$`
+	Ref string `json:"ref,omitempty"`
+
+	// Path is the folder of the mission in the repository (default: the repository root)
+	//+kubebuilder:validation:Pattern=`^[A-Za-z0-9_. /-]+$`
+	Path string `json:"path,omitempty"`
+
+	// CredentialsSecretRef references a Secret with the keys username and password used to clone a
+	// private repository, the password can be an access token
+	CredentialsSecretRef *corev1.LocalObjectReference `json:"credentialsSecretRef,omitempty"`
+}
+
+// DayzArchiveSource is a zip or tar archive, optionally compressed, holding a mission
+type DayzArchiveSource struct {
+	// URL the archive is downloaded from
+	//+kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+
+	// SHA256 is the checksum the archive must have, in hex
+	//+kubebuilder:validation:Pattern=`^[a-f0-9]{64}$`
+	SHA256 string `json:"sha256,omitempty"`
+
+	// Path is the folder of the mission in the archive (default: the archive root)
+	//+kubebuilder:validation:Pattern=`^[A-Za-z0-9_. /-]+$`
+	Path string `json:"path,omitempty"`
+}
+
+// DayzGameplay are patches applied to cfggameplay.json of the mission, see
+// https://community.bistudio.com/wiki/DayZ:Gameplay_Settings
+type DayzGameplay struct {
+	// Patches are JSON patch operations (RFC 6902) applied in list order to cfggameplay.json, which
+	// is created empty when the mission has none
+	Patches []DayzJSONPatch `json:"patches,omitempty"`
+}
+
+// DayzJSONPatch is a JSON patch operation, e.g. op: replace, path: /GeneralData/disableBaseDamage,
+// value: true
+type DayzJSONPatch struct {
+	//+kubebuilder:validation:Enum=add;remove;replace;move;copy;test
+	Op string `json:"op"`
+
+	// Path is the JSON pointer of the value operated on
+	//+kubebuilder:validation:Pattern=`^(/.*)?$`
+	Path string `json:"path"`
+
+	// From is the JSON pointer of the value moved or copied
+	//+kubebuilder:validation:Pattern=`^(/.*)?$`
+	From string `json:"from,omitempty"`
+
+	// Value is added, replaced or tested
+	Value *apiextensionsv1.JSON `json:"value,omitempty"`
+}
+
+// DayzModStatus is a mod installed in the running game pod
+type DayzModStatus struct {
+	// WorkshopID is the id of the Workshop item
+	WorkshopID string `json:"workshopID"`
+
+	// Name is the @Mod folder of the mod
+	Name string `json:"name"`
+
+	// Updated is when the installed version was published on the Workshop, unset until the mod is installed
+	Updated *metav1.Time `json:"updated,omitempty"`
+}
+
+// DayzSpec defines the desired state of Dayz
+type DayzSpec struct {
+	gameserverv1beta1.Base `json:",inline"`
+
+	// Game holds the DayZ specific settings
+	Game DayzGame `json:"game,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+
+// DayzConfig defines configuration as a map of file paths to content
+type DayzConfig map[string]string
+
+// DayzStatus defines the observed state of Dayz
+type DayzStatus struct {
+	gameserverv1beta1.BaseStatus `json:",inline"`
+
+	// Mods are the Workshop mods installed in the running game pod
+	Mods []DayzModStatus `json:"mods,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Players",type=integer,JSONPath=`.status.players`
+//+kubebuilder:printcolumn:name="Max",type=integer,JSONPath=`.status.maxPlayers`
+//+kubebuilder:printcolumn:name="Map",type=string,JSONPath=`.status.map`,priority=1
+//+kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.status.version`,priority=1
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+//+kubebuilder:storageversion
+
+// Dayz is the Schema for the dayzs API
+type Dayz struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DayzSpec   `json:"spec,omitempty"`
+	Status DayzStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// DayzList contains a list of Dayz
+type DayzList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Dayz `json:"items"`
+}
+
+func init() {
+	gameserverv1beta1.SchemeBuilder.Register(&Dayz{}, &DayzList{})
+}
@@ -0,0 +1,482 @@
+//go:build !ignore_autogenerated
+
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Dayz) DeepCopyInto(out *Dayz) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Dayz.
+func (in *Dayz) DeepCopy() *Dayz {
+	if in == nil {
+		return nil
+	}
+	out := new(Dayz)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Dayz) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DayzArchiveSource) DeepCopyInto(out *DayzArchiveSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DayzArchiveSource.
+func (in *DayzArchiveSource) DeepCopy() *DayzArchiveSource {
+	if in == nil {
+		return nil
+	}
+	out := new(DayzArchiveSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in DayzConfig) DeepCopyInto(out *DayzConfig) {
+	{
+		in := &in
+		*out = make(DayzConfig, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DayzConfig.
+func (in DayzConfig) DeepCopy() DayzConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DayzConfig)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DayzEconomy) DeepCopyInto(out *DayzEconomy) {
+	*out = *in
+	if in.Types != nil {
+		in, out := &in.Types, &out.Types
+		*out = make([]DayzEconomyType, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DayzEconomy.
+func (in *DayzEconomy) DeepCopy() *DayzEconomy {
+	if in == nil {
+		return nil
+	}
+	out := new(DayzEconomy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DayzEconomyType) DeepCopyInto(out *DayzEconomyType) {
+	*out = *in
+	if in.Nominal != nil {
+		in, out := &in.Nominal, &out.Nominal
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Min != nil {
+		in, out := &in.Min, &out.Min
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LifetimeSeconds != nil {
+		in, out := &in.LifetimeSeconds, &out.LifetimeSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RestockSeconds != nil {
+		in, out := &in.RestockSeconds, &out.RestockSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Usages != nil {
+		in, out := &in.Usages, &out.Usages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DayzEconomyType.
+func (in *DayzEconomyType) DeepCopy() *DayzEconomyType {
+	if in == nil {
+		return nil
+	}
+	out := new(DayzEconomyType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DayzGame) DeepCopyInto(out *DayzGame) {
+	*out = *in
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(DayzConfig, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Mods != nil {
+		in, out := &in.Mods, &out.Mods
+		*out = make([]DayzMod, len(*in))
+		copy(*out, *in)
+	}
+	if in.SteamCredentialsSecretRef != nil {
+		in, out := &in.SteamCredentialsSecretRef, &out.SteamCredentialsSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.Settings != nil {
+		in, out := &in.Settings, &out.Settings
+		*out = new(DayzServerSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Economy != nil {
+		in, out := &in.Economy, &out.Economy
+		*out = new(DayzEconomy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Mission != nil {
+		in, out := &in.Mission, &out.Mission
+		*out = new(DayzMission)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DayzGame.
+func (in *DayzGame) DeepCopy() *DayzGame {
+	if in == nil {
+		return nil
+	}
+	out := new(DayzGame)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DayzGameplay) DeepCopyInto(out *DayzGameplay) {
+	*out = *in
+	if in.Patches != nil {
+		in, out := &in.Patches, &out.Patches
+		*out = make([]DayzJSONPatch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DayzGameplay.
+func (in *DayzGameplay) DeepCopy() *DayzGameplay {
+	if in == nil {
+		return nil
+	}
+	out := new(DayzGameplay)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DayzGitSource) DeepCopyInto(out *DayzGitSource) {
+	*out = *in
+	if in.CredentialsSecretRef != nil {
+		in, out := &in.CredentialsSecretRef, &out.CredentialsSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DayzGitSource.
+func (in *DayzGitSource) DeepCopy() *DayzGitSource {
+	if in == nil {
+		return nil
+	}
+	out := new(DayzGitSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DayzJSONPatch) DeepCopyInto(out *DayzJSONPatch) {
+	*out = *in
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DayzJSONPatch.
+func (in *DayzJSONPatch) DeepCopy() *DayzJSONPatch {
+	if in == nil {
+		return nil
+	}
+	out := new(DayzJSONPatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DayzList) DeepCopyInto(out *DayzList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Dayz, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DayzList.
+func (in *DayzList) DeepCopy() *DayzList {
+	if in == nil {
+		return nil
+	}
+	out := new(DayzList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DayzList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DayzMission) DeepCopyInto(out *DayzMission) {
+	*out = *in
+	if in.Source != nil {
+		in, out := &in.Source, &out.Source
+		*out = new(DayzMissionSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Gameplay != nil {
+		in, out := &in.Gameplay, &out.Gameplay
+		*out = new(DayzGameplay)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DayzMission.
+func (in *DayzMission) DeepCopy() *DayzMission {
+	if in == nil {
+		return nil
+	}
+	out := new(DayzMission)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DayzMissionSource) DeepCopyInto(out *DayzMissionSource) {
+	*out = *in
+	if in.Git != nil {
+		in, out := &in.Git, &out.Git
+		*out = new(DayzGitSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Archive != nil {
+		in, out := &in.Archive, &out.Archive
+		*out = new(DayzArchiveSource)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DayzMissionSource.
+func (in *DayzMissionSource) DeepCopy() *DayzMissionSource {
+	if in == nil {
+		return nil
+	}
+	out := new(DayzMissionSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DayzMod) DeepCopyInto(out *DayzMod) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DayzMod.
+func (in *DayzMod) DeepCopy() *DayzMod {
+	if in == nil {
+		return nil
+	}
+	out := new(DayzMod)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DayzModStatus) DeepCopyInto(out *DayzModStatus) {
+	*out = *in
+	if in.Updated != nil {
+		in, out := &in.Updated, &out.Updated
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DayzModStatus.
+func (in *DayzModStatus) DeepCopy() *DayzModStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DayzModStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DayzServerSettings) DeepCopyInto(out *DayzServerSettings) {
+	*out = *in
+	if in.PasswordSecretRef != nil {
+		in, out := &in.PasswordSecretRef, &out.PasswordSecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdminPasswordSecretRef != nil {
+		in, out := &in.AdminPasswordSecretRef, &out.AdminPasswordSecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServerTimePersistent != nil {
+		in, out := &in.ServerTimePersistent, &out.ServerTimePersistent
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Disable3rdPerson != nil {
+		in, out := &in.Disable3rdPerson, &out.Disable3rdPerson
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DisableCrosshair != nil {
+		in, out := &in.DisableCrosshair, &out.DisableCrosshair
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DisableVoN != nil {
+		in, out := &in.DisableVoN, &out.DisableVoN
+		*out = new(bool)
+		**out = **in
+	}
+	if in.EnableWhitelist != nil {
+		in, out := &in.EnableWhitelist, &out.EnableWhitelist
+		*out = new(bool)
+		**out = **in
+	}
+	if in.EnableCfgGameplayFile != nil {
+		in, out := &in.EnableCfgGameplayFile, &out.EnableCfgGameplayFile
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Motd != nil {
+		in, out := &in.Motd, &out.Motd
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DayzServerSettings.
+func (in *DayzServerSettings) DeepCopy() *DayzServerSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(DayzServerSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DayzSpec) DeepCopyInto(out *DayzSpec) {
+	*out = *in
+	in.Base.DeepCopyInto(&out.Base)
+	in.Game.DeepCopyInto(&out.Game)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DayzSpec.
+func (in *DayzSpec) DeepCopy() *DayzSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DayzSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DayzStatus) DeepCopyInto(out *DayzStatus) {
+	*out = *in
+	in.BaseStatus.DeepCopyInto(&out.BaseStatus)
+	if in.Mods != nil {
+		in, out := &in.Mods, &out.Mods
+		*out = make([]DayzModStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DayzStatus.
+func (in *DayzStatus) DeepCopy() *DayzStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DayzStatus)
+	in.DeepCopyInto(out)
+	return out
+}
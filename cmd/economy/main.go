@@ -0,0 +1,81 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command economy merges the spec.economy patches of a DayZ server into the Central Economy files of
+// its mission and applies the gameplay patches to its cfggameplay.json. The operator runs it as an
+// init container before the server starts, it fails the pod on a malformed file and reports the
+// outcome in its termination message.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/templarfelix/gameserver-operator/internal/economy"
+)
+
+func main() {
+	var typesFile, typesJSON, gameplayFile, gameplayJSON, terminationLog string
+	flag.StringVar(&typesFile, "types-file", "", "The types.xml file of the mission.")
+	flag.StringVar(&typesJSON, "types", "[]", "The type patches as a JSON list.")
+	flag.StringVar(&gameplayFile, "gameplay-file", "", "The cfggameplay.json file of the mission.")
+	flag.StringVar(&gameplayJSON, "gameplay", "[]", "The JSON patch applied to the cfggameplay.json file.")
+	flag.StringVar(&terminationLog, "termination-log", "/dev/termination-log", "The file the outcome is reported in.")
+	flag.Parse()
+
+	if typesFile == "" && gameplayFile == "" {
+		fmt.Fprintln(os.Stderr, "--types-file or --gameplay-file is required")
+		os.Exit(2)
+	}
+
+	// Each file is reported, the first one which is not merged gives the reason
+	reason := economy.ReasonMerged
+	var messages []string
+	report := func(path string, result fmt.Stringer, err error) {
+		fileReason, message := economy.Report(path, result, err)
+		if reason == economy.ReasonMerged {
+			reason = fileReason
+		}
+		messages = append(messages, message)
+	}
+
+	if typesFile != "" {
+		var patches []economy.TypePatch
+		if err := json.Unmarshal([]byte(typesJSON), &patches); err != nil {
+			report(typesFile, economy.Result{}, fmt.Errorf("invalid --types: %w", err))
+		} else {
+			result, err := economy.MergeTypesFile(typesFile, patches)
+			report(typesFile, result, err)
+		}
+	}
+	if gameplayFile != "" {
+		result, err := economy.PatchGameplayFile(gameplayFile, []byte(gameplayJSON))
+		report(gameplayFile, result, err)
+	}
+
+	termination := economy.FormatReport(reason, strings.Join(messages, "; "))
+	fmt.Println(termination)
+	if writeErr := os.WriteFile(terminationLog, []byte(termination), 0o644); writeErr != nil {
+		fmt.Fprintln(os.Stderr, "unable to write the termination log:", writeErr)
+	}
+	switch reason {
+	case economy.ReasonMalformedXML, economy.ReasonMalformedJSON, economy.ReasonMergeFailed:
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command logs streams a log file the game server writes to its volume to stdout, where kubectl logs
+// and cluster log pipelines read it. With --events it parses the DayZ admin log and records player
+// connections, disconnections and kills as Events on the game server. The operator runs it as a
+// sidecar of game pods with spec.logs.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/templarfelix/gameserver-operator/internal/gamelog"
+)
+
+func main() {
+	var file, apiVersion, kind, namespace, name, uid string
+	var events bool
+	flag.StringVar(&file, "file", "", "A glob of the log files, the most recently modified one is streamed.")
+	flag.BoolVar(&events, "events", false, "Record the player events of the DayZ admin log as Events on the game server.")
+	flag.StringVar(&apiVersion, "api-version", "gameserver.templarfelix.com/v1alpha1", "The API version of the game server resource.")
+	flag.StringVar(&kind, "kind", "", "The kind of the game server, e.g. Dayz.")
+	flag.StringVar(&namespace, "namespace", "", "The namespace of the game server.")
+	flag.StringVar(&name, "name", "", "The name of the game server.")
+	flag.StringVar(&uid, "uid", "", "The UID of the game server.")
+	opts := zap.Options{}
+	opts.BindFlags(flag.CommandLine)
+	flag.Parse()
+
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	logger := ctrl.Log.WithName("logs")
+
+	if file == "" {
+		logger.Info("--file is required")
+		os.Exit(2)
+	}
+	if events && (kind == "" || namespace == "" || name == "") {
+		logger.Info("--kind, --namespace and --name are required with --events")
+		os.Exit(2)
+	}
+
+	var recorder *gamelog.EventRecorder
+	if events {
+		c, err := client.New(ctrl.GetConfigOrDie(), client.Options{})
+		if err != nil {
+			logger.Error(err, "unable to create Kubernetes client")
+			os.Exit(1)
+		}
+		recorder = &gamelog.EventRecorder{
+			Client: c,
+			Object: corev1.ObjectReference{
+				APIVersion: apiVersion,
+				Kind:       kind,
+				Namespace:  namespace,
+				Name:       name,
+				UID:        types.UID(uid),
+			},
+			Component: "gameserver-logs",
+		}
+	}
+
+	logger.Info("Streaming log", "file", file, "events", events)
+	ctx := ctrl.SetupSignalHandler()
+	follower := &gamelog.Follower{Pattern: file}
+	err := follower.Follow(ctx, func(line string) {
+		fmt.Println(line)
+		if recorder == nil {
+			return
+		}
+		if event, ok := gamelog.ParseADM(line); ok {
+			if err := recorder.Record(ctx, event); err != nil {
+				logger.Error(err, "unable to record player event", "reason", event.Type)
+			}
+		}
+	})
+	if err != nil {
+		logger.Error(err, "log streaming failed")
+		os.Exit(1)
+	}
+}
@@ -19,6 +19,7 @@ package main
 import (
 	"flag"
 	"os"
+	"path/filepath"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -31,10 +32,15 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+	gameserverv1beta1 "github.com/templarfelix/gameserver-operator/api/v1beta1"
 
+	"github.com/templarfelix/gameserver-operator/internal/allocator"
+	"github.com/templarfelix/gameserver-operator/internal/controller"
 	gamecontroller "github.com/templarfelix/gameserver-operator/internal/controller/game"
+	"github.com/templarfelix/gameserver-operator/internal/notify"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -47,6 +53,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(gameserverv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(gameserverv1beta1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -54,11 +61,30 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var wakeProxyImage string
+	var economyImage string
+	var logsImage string
+	var allocationAddr string
+	var allocationCertDir string
+	var allocationInsecure bool
+	defaultImage := operatorImage(controller.DefaultOperatorImage)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&wakeProxyImage, "wake-proxy-image", defaultImage,
+		"The image running the wake proxy of idle game servers, by default the image of this manager.")
+	flag.StringVar(&economyImage, "economy-image", defaultImage,
+		"The image merging DayZ economy patches into missions, by default the image of this manager.")
+	flag.StringVar(&logsImage, "logs-image", defaultImage,
+		"The image streaming game server logs, by default the image of this manager.")
+	flag.StringVar(&allocationAddr, "allocation-bind-address", "0",
+		"The address the allocation API binds to, for example :8082. Set this to '0' to disable it.")
+	flag.StringVar(&allocationCertDir, "allocation-cert-dir", filepath.Join(os.TempDir(), "k8s-webhook-server", "serving-certs"),
+		"The directory with the tls.crt and tls.key the allocation API is served with, by default the webhook certificate.")
+	flag.BoolVar(&allocationInsecure, "allocation-insecure", false,
+		"Serve the allocation API over plain HTTP, the bearer tokens of its clients are then sent in clear text.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -72,7 +98,9 @@ func main() {
 		Metrics: metricsserver.Options{
 			BindAddress: metricsAddr,
 		},
-		WebhookServer:          nil,
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Port: 9443,
+		}),
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "21ce7824.templarfelix.com",
@@ -93,13 +121,93 @@ func main() {
 		os.Exit(1)
 	}
 
+	notifier := &notify.Dispatcher{Client: mgr.GetClient()}
+	if err = mgr.Add(notifier); err != nil {
+		setupLog.Error(err, "unable to set up notifications")
+		os.Exit(1)
+	}
+
 	if err = (&gamecontroller.DayzReconciler{
+		Client:         mgr.GetClient(),
+		Scheme:         mgr.GetScheme(),
+		WakeProxyImage: wakeProxyImage,
+		EconomyImage:   economyImage,
+		LogsImage:      logsImage,
+		Recorder:       mgr.GetEventRecorderFor("dayz-controller"),
+		Notifier:       notifier,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Dayz")
+		os.Exit(1)
+	}
+	if err = (&controller.GameServerCommandReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "Dayz")
+		setupLog.Error(err, "unable to create controller", "controller", "GameServerCommand")
 		os.Exit(1)
 	}
+	if err = (&controller.PlayerListReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PlayerList")
+		os.Exit(1)
+	}
+	if err = (&controller.GameServerFleetReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("gameserverfleet-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "GameServerFleet")
+		os.Exit(1)
+	}
+	if err = (&controller.FleetAutoscalerReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("fleetautoscaler-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "FleetAutoscaler")
+		os.Exit(1)
+	}
+	if err = (&controller.GameServerAllocationReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		APIReader: mgr.GetAPIReader(),
+		Recorder:  mgr.GetEventRecorderFor("gameserverallocation-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "GameServerAllocation")
+		os.Exit(1)
+	}
+	if allocationAddr != "0" {
+		if err = mgr.Add(&allocator.Server{
+			Client:   mgr.GetClient(),
+			Addr:     allocationAddr,
+			CertDir:  allocationCertDir,
+			Insecure: allocationInsecure,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up the allocation API")
+			os.Exit(1)
+		}
+	}
+	// Webhooks need serving certificates (provided by cert-manager in config/default),
+	// set ENABLE_WEBHOOKS=false to run the manager locally without them. The conversion webhook of the
+	// Dayz CRD is served by the same server, the API server then needs a deployed manager to read Dayz.
+	if os.Getenv("ENABLE_WEBHOOKS") == "false" {
+		setupLog.Info("webhooks are disabled, Dayz resources can only be read while the conversion webhook of a deployed manager is reachable")
+	} else {
+		if err = (&gamecontroller.DayzValidator{Client: mgr.GetClient()}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Dayz")
+			os.Exit(1)
+		}
+		if err = (&controller.GameServerTemplateValidator{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "GameServerTemplate")
+			os.Exit(1)
+		}
+		if err = (&controller.GameServerClassValidator{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "GameServerClass")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -117,3 +225,12 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// operatorImage returns the image of this manager from the OPERATOR_IMAGE variable set in
+// config/manager, or fallback when it is not set
+func operatorImage(fallback string) string {
+	if image := os.Getenv("OPERATOR_IMAGE"); image != "" {
+		return image
+	}
+	return fallback
+}
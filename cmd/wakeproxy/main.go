@@ -0,0 +1,109 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command wakeproxy fronts the ports of a game server paused after being idle and wakes it when a
+// player connects. It is deployed by the operator for servers with spec.idle.wakeOnConnect.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/templarfelix/gameserver-operator/internal/a2s"
+	"github.com/templarfelix/gameserver-operator/internal/wakeproxy"
+)
+
+// portFlags collects repeated --port flags
+type portFlags []wakeproxy.Port
+
+func (p *portFlags) String() string {
+	var ports []string
+	for _, port := range *p {
+		ports = append(ports, fmt.Sprintf("%s/%d:%d", port.Protocol, port.Listen, port.Upstream))
+	}
+	return strings.Join(ports, ",")
+}
+
+func (p *portFlags) Set(value string) error {
+	port, err := wakeproxy.ParsePort(value)
+	if err != nil {
+		return err
+	}
+	*p = append(*p, port)
+	return nil
+}
+
+func main() {
+	var ports portFlags
+	var upstream, group, version, resource, namespace, name string
+	var queryPort int
+	flag.Var(&ports, "port", "A forwarded port as <protocol>/<port>[:<upstream port>], may be repeated.")
+	flag.StringVar(&upstream, "upstream", "", "The host of the game server.")
+	flag.IntVar(&queryPort, "query-port", 27016, "The A2S query port of the game server.")
+	flag.StringVar(&group, "group", "gameserver.templarfelix.com", "The API group of the game server resource.")
+	flag.StringVar(&version, "version", "v1alpha1", "The API version of the game server resource.")
+	flag.StringVar(&resource, "resource", "", "The plural resource name of the game server, e.g. dayzs.")
+	flag.StringVar(&namespace, "namespace", "", "The namespace of the game server.")
+	flag.StringVar(&name, "name", "", "The name of the game server.")
+	opts := zap.Options{}
+	opts.BindFlags(flag.CommandLine)
+	flag.Parse()
+
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	logger := ctrl.Log.WithName("wakeproxy")
+
+	if upstream == "" || resource == "" || namespace == "" || name == "" || len(ports) == 0 {
+		logger.Info("--upstream, --resource, --namespace, --name and at least one --port are required")
+		os.Exit(2)
+	}
+
+	client, err := dynamic.NewForConfig(ctrl.GetConfigOrDie())
+	if err != nil {
+		logger.Error(err, "unable to create Kubernetes client")
+		os.Exit(1)
+	}
+
+	proxy := &wakeproxy.Proxy{
+		Upstream:  upstream,
+		Ports:     ports,
+		QueryPort: queryPort,
+		Waker: &wakeproxy.KubernetesWaker{
+			Client:    client,
+			Resource:  schema.GroupVersionResource{Group: group, Version: version, Resource: resource},
+			Namespace: namespace,
+			Name:      name,
+		},
+		Fallback: a2s.Info{Name: name},
+	}
+	if err := proxy.Listen(); err != nil {
+		logger.Error(err, "unable to listen")
+		os.Exit(1)
+	}
+
+	logger.Info("Starting wake proxy", "upstream", upstream, "gameserver", namespace+"/"+name)
+	ctx := ctrl.LoggerInto(ctrl.SetupSignalHandler(), logger)
+	if err := proxy.Serve(ctx); err != nil {
+		logger.Error(err, "wake proxy failed")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,364 @@
+// Package a2s implements the Steam server query protocol (A2S_INFO and A2S_PLAYER) used to check
+// whether a game server is actually up and how many players are connected.
+// Protocol reference: https://developer.valvesoftware.com/wiki/Server_queries
+package a2s
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"time"
+)
+
+// Packet headers and message types of the query protocol
+const (
+	singlePacketHeader int32 = -1
+	splitPacketHeader  int32 = -2
+
+	infoRequest     byte = 'T'
+	infoResponse    byte = 'I'
+	playerRequest   byte = 'U'
+	playerResponse  byte = 'D'
+	challengeHeader byte = 'A'
+
+	infoPayload = "Source Engine Query\x00"
+
+	// maxSplitPackets bounds the number of fragments accepted for one response
+	maxSplitPackets = 32
+
+	// DefaultTimeout bounds a full query including challenge round trips
+	DefaultTimeout = 3 * time.Second
+)
+
+var (
+	// ErrMalformedResponse is returned when a response cannot be decoded
+	ErrMalformedResponse = errors.New("a2s: malformed response")
+	// ErrCompressedResponse is returned for bzip2 compressed split responses, which are not supported
+	ErrCompressedResponse = errors.New("a2s: compressed split responses are not supported")
+)
+
+// Info is the decoded A2S_INFO response
+type Info struct {
+	Protocol    uint8
+	Name        string
+	Map         string
+	Folder      string
+	Game        string
+	AppID       uint16
+	Players     uint8
+	MaxPlayers  uint8
+	Bots        uint8
+	ServerType  byte
+	Environment byte
+	Visibility  bool
+	VAC         bool
+	Version     string
+
+	// Extra data, only set when flagged by the server
+	Port     uint16
+	SteamID  uint64
+	Keywords string
+	GameID   uint64
+}
+
+// Player is one entry of the A2S_PLAYER response
+type Player struct {
+	Index    uint8
+	Name     string
+	Score    int32
+	Duration time.Duration
+}
+
+// Client queries game servers over UDP
+type Client struct {
+	// Timeout bounds a full query, DefaultTimeout is used when zero
+	Timeout time.Duration
+}
+
+// QueryInfo sends A2S_INFO to addr (host:port), answering a challenge when the server asks for one
+func (c *Client) QueryInfo(ctx context.Context, addr string) (*Info, error) {
+	conn, err := c.dial(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	request := append([]byte{0xFF, 0xFF, 0xFF, 0xFF, infoRequest}, infoPayload...)
+	payload, err := exchange(conn, request, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) == 0 || payload[0] != infoResponse {
+		return nil, fmt.Errorf("%w: unexpected info response type", ErrMalformedResponse)
+	}
+	return decodeInfo(payload[1:])
+}
+
+// QueryPlayers sends A2S_PLAYER to addr (host:port) and returns the connected players
+func (c *Client) QueryPlayers(ctx context.Context, addr string) ([]Player, error) {
+	conn, err := c.dial(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	request := []byte{0xFF, 0xFF, 0xFF, 0xFF, playerRequest}
+	payload, err := exchange(conn, request, []byte{0xFF, 0xFF, 0xFF, 0xFF})
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) == 0 || payload[0] != playerResponse {
+		return nil, fmt.Errorf("%w: unexpected player response type", ErrMalformedResponse)
+	}
+	return decodePlayers(payload[1:])
+}
+
+func (c *Client) dial(ctx context.Context, addr string) (net.Conn, error) {
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+
+	dialer := net.Dialer{Deadline: deadline}
+	conn, err := dialer.DialContext(ctx, "udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// exchange sends request (with the initial challenge appended, if any) and returns the response
+// payload without packet header, resending once with the server challenge when one is issued
+func exchange(conn net.Conn, request, challenge []byte) ([]byte, error) {
+	payload, err := roundTrip(conn, append(request, challenge...))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(payload) == 5 && payload[0] == challengeHeader {
+		payload, err = roundTrip(conn, append(request, payload[1:5]...))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return payload, nil
+}
+
+func roundTrip(conn net.Conn, request []byte) ([]byte, error) {
+	if _, err := conn.Write(request); err != nil {
+		return nil, err
+	}
+	return readResponse(conn)
+}
+
+// readResponse reads a single or split response and returns its payload without the -1 header
+func readResponse(conn net.Conn) ([]byte, error) {
+	buf := make([]byte, 65535)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	if n < 4 {
+		return nil, fmt.Errorf("%w: packet too short", ErrMalformedResponse)
+	}
+
+	switch int32(binary.LittleEndian.Uint32(buf[:4])) {
+	case singlePacketHeader:
+		return append([]byte(nil), buf[4:n]...), nil
+	case splitPacketHeader:
+		return readSplitResponse(conn, buf, n)
+	default:
+		return nil, fmt.Errorf("%w: unknown packet header", ErrMalformedResponse)
+	}
+}
+
+// readSplitResponse collects all fragments of a split (Source engine) response and reassembles them
+func readSplitResponse(conn net.Conn, buf []byte, n int) ([]byte, error) {
+	fragments := map[uint8][]byte{}
+	var id int32
+	var total uint8
+
+	for {
+		// Header: -2, id (int32), total (byte), number (byte), size (int16)
+		if n < 12 {
+			return nil, fmt.Errorf("%w: split packet too short", ErrMalformedResponse)
+		}
+		packetID := int32(binary.LittleEndian.Uint32(buf[4:8]))
+		if uint32(packetID)&0x80000000 != 0 {
+			return nil, ErrCompressedResponse
+		}
+		if len(fragments) == 0 {
+			id, total = packetID, buf[8]
+			if total == 0 || total > maxSplitPackets {
+				return nil, fmt.Errorf("%w: invalid split packet count %d", ErrMalformedResponse, total)
+			}
+		}
+		if packetID == id && buf[9] < total {
+			fragments[buf[9]] = append([]byte(nil), buf[12:n]...)
+		}
+		if len(fragments) == int(total) {
+			break
+		}
+
+		var err error
+		if n, err = conn.Read(buf); err != nil {
+			return nil, err
+		}
+		if n < 4 || int32(binary.LittleEndian.Uint32(buf[:4])) != splitPacketHeader {
+			return nil, fmt.Errorf("%w: expected split packet", ErrMalformedResponse)
+		}
+	}
+
+	numbers := make([]int, 0, len(fragments))
+	for number := range fragments {
+		numbers = append(numbers, int(number))
+	}
+	sort.Ints(numbers)
+
+	var assembled bytes.Buffer
+	for _, number := range numbers {
+		assembled.Write(fragments[uint8(number)])
+	}
+	payload := assembled.Bytes()
+	if len(payload) < 4 || int32(binary.LittleEndian.Uint32(payload[:4])) != singlePacketHeader {
+		return nil, fmt.Errorf("%w: reassembled payload has no header", ErrMalformedResponse)
+	}
+	return payload[4:], nil
+}
+
+func decodeInfo(data []byte) (*Info, error) {
+	r := &reader{data: data}
+	info := &Info{}
+
+	info.Protocol = r.byte()
+	info.Name = r.string()
+	info.Map = r.string()
+	info.Folder = r.string()
+	info.Game = r.string()
+	info.AppID = r.uint16()
+	info.Players = r.byte()
+	info.MaxPlayers = r.byte()
+	info.Bots = r.byte()
+	info.ServerType = r.byte()
+	info.Environment = r.byte()
+	info.Visibility = r.byte() == 1
+	info.VAC = r.byte() == 1
+	info.Version = r.string()
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	// Extra data flag is optional
+	if r.remaining() == 0 {
+		return info, nil
+	}
+	edf := r.byte()
+	if edf&0x80 != 0 {
+		info.Port = r.uint16()
+	}
+	if edf&0x10 != 0 {
+		info.SteamID = r.uint64()
+	}
+	if edf&0x40 != 0 {
+		r.uint16() // SourceTV port
+		r.string() // SourceTV name
+	}
+	if edf&0x20 != 0 {
+		info.Keywords = r.string()
+	}
+	if edf&0x01 != 0 {
+		info.GameID = r.uint64()
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return info, nil
+}
+
+func decodePlayers(data []byte) ([]Player, error) {
+	r := &reader{data: data}
+	count := r.byte()
+	players := make([]Player, 0, count)
+
+	for i := 0; i < int(count) && r.err == nil; i++ {
+		player := Player{
+			Index: r.byte(),
+			Name:  r.string(),
+			Score: int32(r.uint32()),
+		}
+		seconds := math.Float32frombits(r.uint32())
+		player.Duration = time.Duration(float64(seconds) * float64(time.Second))
+		players = append(players, player)
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return players, nil
+}
+
+// reader decodes little endian values and null terminated strings, remembering the first error
+type reader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *reader) remaining() int {
+	return len(r.data) - r.pos
+}
+
+func (r *reader) next(n int) []byte {
+	if r.err != nil {
+		return make([]byte, n)
+	}
+	if r.remaining() < n {
+		r.err = fmt.Errorf("%w: unexpected end of data", ErrMalformedResponse)
+		return make([]byte, n)
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *reader) byte() byte {
+	return r.next(1)[0]
+}
+
+func (r *reader) uint16() uint16 {
+	return binary.LittleEndian.Uint16(r.next(2))
+}
+
+func (r *reader) uint32() uint32 {
+	return binary.LittleEndian.Uint32(r.next(4))
+}
+
+func (r *reader) uint64() uint64 {
+	return binary.LittleEndian.Uint64(r.next(8))
+}
+
+func (r *reader) string() string {
+	if r.err != nil {
+		return ""
+	}
+	end := bytes.IndexByte(r.data[r.pos:], 0)
+	if end < 0 {
+		r.err = fmt.Errorf("%w: unterminated string", ErrMalformedResponse)
+		return ""
+	}
+	s := string(r.data[r.pos : r.pos+end])
+	r.pos += end + 1
+	return s
+}
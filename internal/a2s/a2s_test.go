@@ -0,0 +1,114 @@
+package a2s_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/templarfelix/gameserver-operator/internal/a2s"
+	"github.com/templarfelix/gameserver-operator/internal/a2s/a2stest"
+)
+
+var _ = Describe("Client", func() {
+	var (
+		ctx    context.Context
+		server *a2stest.Server
+		client *a2s.Client
+		info   a2s.Info
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		client = &a2s.Client{Timeout: 500 * time.Millisecond}
+		info = a2s.Info{
+			Protocol:   17,
+			Name:       "Test DayZ Server",
+			Map:        "chernarusplus",
+			Folder:     "dayz",
+			Game:       "DayZ",
+			AppID:      24492,
+			Players:    3,
+			MaxPlayers: 60,
+			ServerType: 'd',
+			VAC:        true,
+			Version:    "1.26.159040",
+			Port:       2302,
+			Keywords:   "battleye,external,privHive",
+			GameID:     221100,
+		}
+
+		var err error
+		server, err = a2stest.NewServer(info, nil)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(server.Close)
+	})
+
+	Describe("QueryInfo", func() {
+		It("should answer the challenge and decode the info response", func() {
+			got, err := client.QueryInfo(ctx, server.Addr())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*got).To(Equal(info))
+		})
+
+		It("should query servers that do not issue a challenge", func() {
+			server.SetRequireChallenge(false)
+			got, err := client.QueryInfo(ctx, server.Addr())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(got.Map).To(Equal("chernarusplus"))
+		})
+
+		It("should reassemble split responses", func() {
+			server.SetSplitSize(16)
+			got, err := client.QueryInfo(ctx, server.Addr())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*got).To(Equal(info))
+		})
+
+		It("should time out when the server does not answer", func() {
+			server.SetSilent(true)
+			_, err := client.QueryInfo(ctx, server.Addr())
+			var netErr net.Error
+			Expect(errors.As(err, &netErr)).To(BeTrue())
+			Expect(netErr.Timeout()).To(BeTrue())
+		})
+	})
+
+	Describe("QueryPlayers", func() {
+		It("should decode the connected players", func() {
+			server.SetPlayers([]a2s.Player{
+				{Index: 0, Name: "Survivor", Score: 4, Duration: 90 * time.Second},
+				{Index: 1, Name: "Bandit", Score: -1, Duration: 1500 * time.Millisecond},
+			})
+
+			players, err := client.QueryPlayers(ctx, server.Addr())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(players).To(HaveLen(2))
+			Expect(players[0].Name).To(Equal("Survivor"))
+			Expect(players[0].Duration).To(Equal(90 * time.Second))
+			Expect(players[1].Score).To(Equal(int32(-1)))
+			Expect(players[1].Duration).To(Equal(1500 * time.Millisecond))
+		})
+
+		It("should handle responses split across many packets", func() {
+			players := make([]a2s.Player, 40)
+			for i := range players {
+				players[i] = a2s.Player{Index: uint8(i), Name: strings.Repeat("x", 20)}
+			}
+			server.SetPlayers(players)
+			server.SetSplitSize(256)
+
+			got, err := client.QueryPlayers(ctx, server.Addr())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(got).To(HaveLen(40))
+
+			serverInfo, err := client.QueryInfo(ctx, server.Addr())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(serverInfo.Players).To(Equal(uint8(40)))
+		})
+	})
+})
@@ -0,0 +1,172 @@
+// Package a2stest provides a local A2S responder for testing query clients and controllers
+// without a real game server.
+package a2stest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"sync"
+
+	"github.com/templarfelix/gameserver-operator/internal/a2s"
+)
+
+// Server answers A2S_INFO and A2S_PLAYER requests on a local UDP port
+type Server struct {
+	conn *net.UDPConn
+	wg   sync.WaitGroup
+
+	mu               sync.Mutex
+	info             a2s.Info
+	players          []a2s.Player
+	challenge        [4]byte
+	requireChallenge bool
+	splitSize        int
+	silent           bool
+}
+
+// NewServer starts a responder on 127.0.0.1 reporting the given info and players
+func NewServer(info a2s.Info, players []a2s.Player) (*Server, error) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		requireChallenge: true,
+		conn:             conn,
+		info:             info,
+		players:          players,
+		challenge:        [4]byte{0x4B, 0x15, 0x2E, 0x01},
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Addr returns the host:port the server listens on
+func (s *Server) Addr() string {
+	return s.conn.LocalAddr().String()
+}
+
+// Port returns the UDP port the server listens on
+func (s *Server) Port() int32 {
+	return int32(s.conn.LocalAddr().(*net.UDPAddr).Port)
+}
+
+// SetInfo replaces the reported server info
+func (s *Server) SetInfo(info a2s.Info) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.info = info
+}
+
+// SetPlayers replaces the reported players, the info player count follows the list
+func (s *Server) SetPlayers(players []a2s.Player) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.players = players
+	s.info.Players = uint8(len(players))
+}
+
+// SetRequireChallenge controls whether A2S_INFO requests are answered with a challenge first,
+// A2S_PLAYER always requires one
+func (s *Server) SetRequireChallenge(require bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requireChallenge = require
+}
+
+// SetSplitSize splits responses into fragments of at most size payload bytes, 0 disables splitting
+func (s *Server) SetSplitSize(size int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.splitSize = size
+}
+
+// SetSilent makes the server drop all requests, simulating a server that is down
+func (s *Server) SetSilent(silent bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.silent = silent
+}
+
+// Close stops the server
+func (s *Server) Close() error {
+	err := s.conn.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	buf := make([]byte, 1400)
+	for {
+		n, addr, err := s.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		if response, splitSize := s.handle(buf[:n]); response != nil {
+			s.send(addr, response, splitSize)
+		}
+	}
+}
+
+// handle returns the response to request and the split size to send it with
+func (s *Server) handle(request []byte) ([]byte, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.silent || len(request) < 5 || !bytes.Equal(request[:4], []byte{0xFF, 0xFF, 0xFF, 0xFF}) {
+		return nil, 0
+	}
+
+	switch request[4] {
+	case 'T':
+		if s.requireChallenge && !bytes.HasSuffix(request, s.challenge[:]) {
+			return s.challengeResponse(), 0
+		}
+		return s.infoResponse(), s.splitSize
+	case 'U':
+		if !bytes.HasSuffix(request, s.challenge[:]) {
+			return s.challengeResponse(), 0
+		}
+		return s.playerResponse(), s.splitSize
+	}
+	return nil, 0
+}
+
+func (s *Server) challengeResponse() []byte {
+	return append([]byte{0xFF, 0xFF, 0xFF, 0xFF, 'A'}, s.challenge[:]...)
+}
+
+func (s *Server) infoResponse() []byte {
+	return a2s.EncodeInfo(&s.info)
+}
+
+func (s *Server) playerResponse() []byte {
+	return a2s.EncodePlayers(s.players)
+}
+
+// send writes the response, split into Source engine fragments when splitSize is set
+func (s *Server) send(addr *net.UDPAddr, response []byte, splitSize int) {
+	if splitSize <= 0 || len(response) <= splitSize {
+		_, _ = s.conn.WriteToUDP(response, addr)
+		return
+	}
+
+	total := (len(response) + splitSize - 1) / splitSize
+	for i := 0; i < total; i++ {
+		end := (i + 1) * splitSize
+		if end > len(response) {
+			end = len(response)
+		}
+		var b bytes.Buffer
+		_ = binary.Write(&b, binary.LittleEndian, int32(-2))
+		_ = binary.Write(&b, binary.LittleEndian, int32(0x1234))
+		b.Write([]byte{byte(total), byte(i)})
+		_ = binary.Write(&b, binary.LittleEndian, int16(splitSize))
+		b.Write(response[i*splitSize : end])
+		_, _ = s.conn.WriteToUDP(b.Bytes(), addr)
+	}
+}
@@ -0,0 +1,87 @@
+package a2s
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+// RequestType returns the type of a single-packet query request, 0 for anything else
+func RequestType(packet []byte) byte {
+	if len(packet) < 5 || int32(binary.LittleEndian.Uint32(packet[:4])) != singlePacketHeader {
+		return 0
+	}
+	return packet[4]
+}
+
+// IsInfoRequest reports whether packet is an A2S_INFO request
+func IsInfoRequest(packet []byte) bool {
+	return RequestType(packet) == infoRequest
+}
+
+// IsPlayerRequest reports whether packet is an A2S_PLAYER request
+func IsPlayerRequest(packet []byte) bool {
+	return RequestType(packet) == playerRequest
+}
+
+// EncodeInfo returns the single-packet A2S_INFO response reporting info
+func EncodeInfo(info *Info) []byte {
+	var b bytes.Buffer
+	b.Write([]byte{0xFF, 0xFF, 0xFF, 0xFF, infoResponse, info.Protocol})
+	writeString(&b, info.Name)
+	writeString(&b, info.Map)
+	writeString(&b, info.Folder)
+	writeString(&b, info.Game)
+	_ = binary.Write(&b, binary.LittleEndian, info.AppID)
+	b.Write([]byte{info.Players, info.MaxPlayers, info.Bots, info.ServerType, info.Environment,
+		boolByte(info.Visibility), boolByte(info.VAC)})
+	writeString(&b, info.Version)
+
+	edf := byte(0x80)
+	if info.SteamID != 0 {
+		edf |= 0x10
+	}
+	if info.Keywords != "" {
+		edf |= 0x20
+	}
+	if info.GameID != 0 {
+		edf |= 0x01
+	}
+	b.WriteByte(edf)
+	_ = binary.Write(&b, binary.LittleEndian, info.Port)
+	if info.SteamID != 0 {
+		_ = binary.Write(&b, binary.LittleEndian, info.SteamID)
+	}
+	if info.Keywords != "" {
+		writeString(&b, info.Keywords)
+	}
+	if info.GameID != 0 {
+		_ = binary.Write(&b, binary.LittleEndian, info.GameID)
+	}
+	return b.Bytes()
+}
+
+// EncodePlayers returns the single-packet A2S_PLAYER response listing players
+func EncodePlayers(players []Player) []byte {
+	var b bytes.Buffer
+	b.Write([]byte{0xFF, 0xFF, 0xFF, 0xFF, playerResponse, byte(len(players))})
+	for _, player := range players {
+		b.WriteByte(player.Index)
+		writeString(&b, player.Name)
+		_ = binary.Write(&b, binary.LittleEndian, player.Score)
+		_ = binary.Write(&b, binary.LittleEndian, math.Float32bits(float32(player.Duration.Seconds())))
+	}
+	return b.Bytes()
+}
+
+func writeString(b *bytes.Buffer, s string) {
+	b.WriteString(s)
+	b.WriteByte(0)
+}
+
+func boolByte(v bool) byte {
+	if v {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,268 @@
+// Package allocator serves the HTTP allocation API of the operator: matchmakers create a
+// GameServerAllocation and receive the address of the allocated game server in the same request.
+package allocator
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+)
+
+// Defaults of the Server
+const (
+	DefaultTimeout = 10 * time.Second
+
+	// pollInterval is how often a request checks whether its allocation was processed
+	pollInterval = 100 * time.Millisecond
+
+	// maxBodySize bounds the request body
+	maxBodySize = 64 << 10
+)
+
+//+kubebuilder:rbac:groups=gameserver.templarfelix.com,resources=gameserverallocations,verbs=get;list;watch;create;delete
+//+kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+//+kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// Server serves the allocation API on Addr. It runs as a manager Runnable on every replica, the
+// allocations it creates are processed by the GameServerAllocation controller of the leader.
+//
+//	POST   /v1/namespaces/{namespace}/gameserverallocations         creates an allocation and waits for it
+//	GET    /v1/namespaces/{namespace}/gameserverallocations/{name}  returns an allocation
+//	DELETE /v1/namespaces/{namespace}/gameserverallocations/{name}  releases the game server
+//
+// Requests authenticate with a Kubernetes bearer token allowed to create, get or delete
+// gameserverallocations in the namespace, so the API is served over TLS unless Insecure is set.
+type Server struct {
+	// Client creates and reads the GameServerAllocations
+	Client client.Client
+
+	// Addr is the address the server listens on
+	Addr string
+
+	// CertDir holds the tls.crt and tls.key the API is served with, they are reloaded when they change
+	CertDir string
+
+	// Insecure serves the API over plain HTTP without a certificate, the bearer tokens are then sent in
+	// clear text
+	Insecure bool
+
+	// Timeout bounds how long a request waits for its allocation, DefaultTimeout when zero
+	Timeout time.Duration
+
+	// Authorize reports whether token may verb gameserverallocations in namespace. Tokens are checked
+	// with a TokenReview and a SubjectAccessReview when nil
+	Authorize func(ctx context.Context, token, verb, namespace string) (bool, error)
+}
+
+// Start serves the API until ctx is done. It fails without a certificate in CertDir unless Insecure
+// is set.
+func (s *Server) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx)
+	server := &http.Server{Addr: s.Addr, Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	if !s.Insecure {
+		watcher, err := certwatcher.New(filepath.Join(s.CertDir, "tls.crt"), filepath.Join(s.CertDir, "tls.key"))
+		if err != nil {
+			return fmt.Errorf("no serving certificate for the allocation API in %s: %w", s.CertDir, err)
+		}
+		go func() {
+			if err := watcher.Start(ctx); err != nil {
+				logger.Error(err, "Failed to watch the serving certificate of the allocation API")
+			}
+		}()
+		server.TLSConfig = &tls.Config{GetCertificate: watcher.GetCertificate, MinVersion: tls.VersionTLS12}
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+	logger.Info("Serving the allocation API", "address", s.Addr, "tls", !s.Insecure)
+	var err error
+	if s.Insecure {
+		err = server.ListenAndServe()
+	} else {
+		err = server.ListenAndServeTLS("", "")
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// NeedLeaderElection runs the server on every replica
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// Handler returns the handler of the API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/namespaces/{namespace}/gameserverallocations", s.authorized("create", s.create))
+	mux.HandleFunc("GET /v1/namespaces/{namespace}/gameserverallocations/{name}", s.authorized("get", s.get))
+	mux.HandleFunc("DELETE /v1/namespaces/{namespace}/gameserverallocations/{name}", s.authorized("delete", s.delete))
+	return mux
+}
+
+// create creates the GameServerAllocation of the body and answers once it is processed: 201 when a
+// game server was allocated, 409 when none matched and 504 when it is still pending
+func (s *Server) create(w http.ResponseWriter, r *http.Request) {
+	allocation := &gameserverv1alpha1.GameServerAllocation{}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(allocation); err != nil {
+			http.Error(w, fmt.Sprintf("invalid GameServerAllocation: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+	allocation.Namespace = r.PathValue("namespace")
+	allocation.ResourceVersion = ""
+	allocation.Status = gameserverv1alpha1.GameServerAllocationStatus{}
+	if allocation.Name == "" && allocation.GenerateName == "" {
+		allocation.GenerateName = "allocation-"
+	}
+	if err := s.Client.Create(r.Context(), allocation); err != nil {
+		writeAPIError(w, err)
+		return
+	}
+
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	key := types.NamespacedName{Namespace: allocation.Namespace, Name: allocation.Name}
+	err := wait.PollUntilContextTimeout(r.Context(), pollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		if err := s.Client.Get(ctx, key, allocation); err != nil {
+			return false, client.IgnoreNotFound(err)
+		}
+		return allocation.Status.State != "", nil
+	})
+
+	switch {
+	case err != nil:
+		writeAllocation(w, http.StatusGatewayTimeout, allocation)
+	case allocation.Status.State == gameserverv1alpha1.AllocationAllocated:
+		writeAllocation(w, http.StatusCreated, allocation)
+	default:
+		writeAllocation(w, http.StatusConflict, allocation)
+	}
+}
+
+// get returns the GameServerAllocation of the path
+func (s *Server) get(w http.ResponseWriter, r *http.Request) {
+	allocation := &gameserverv1alpha1.GameServerAllocation{}
+	key := types.NamespacedName{Namespace: r.PathValue("namespace"), Name: r.PathValue("name")}
+	if err := s.Client.Get(r.Context(), key, allocation); err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	writeAllocation(w, http.StatusOK, allocation)
+}
+
+// delete deletes the GameServerAllocation of the path, which releases its game server
+func (s *Server) delete(w http.ResponseWriter, r *http.Request) {
+	allocation := &gameserverv1alpha1.GameServerAllocation{}
+	allocation.Namespace, allocation.Name = r.PathValue("namespace"), r.PathValue("name")
+	if err := s.Client.Delete(r.Context(), allocation); err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// authorized runs next for requests whose bearer token may verb gameserverallocations in the
+// namespace of the path
+func (s *Server) authorized(verb string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			http.Error(w, "a bearer token is required", http.StatusUnauthorized)
+			return
+		}
+		authorize := s.Authorize
+		if authorize == nil {
+			authorize = s.review
+		}
+		allowed, err := authorize(r.Context(), token, verb, r.PathValue("namespace"))
+		if err != nil {
+			log.FromContext(r.Context()).Error(err, "Failed to authorize an allocation request")
+			http.Error(w, "authorization failed", http.StatusInternalServerError)
+			return
+		}
+		if !allowed {
+			http.Error(w, fmt.Sprintf("not allowed to %s gameserverallocations in namespace %q", verb, r.PathValue("namespace")), http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// review authenticates token with a TokenReview and authorizes its user with a SubjectAccessReview
+func (s *Server) review(ctx context.Context, token, verb, namespace string) (bool, error) {
+	tokenReview := &authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: token}}
+	if err := s.Client.Create(ctx, tokenReview); err != nil {
+		return false, err
+	}
+	if !tokenReview.Status.Authenticated {
+		return false, nil
+	}
+
+	user := tokenReview.Status.User
+	extra := map[string]authorizationv1.ExtraValue{}
+	for key, values := range user.Extra {
+		extra[key] = authorizationv1.ExtraValue(values)
+	}
+	accessReview := &authorizationv1.SubjectAccessReview{Spec: authorizationv1.SubjectAccessReviewSpec{
+		User:   user.Username,
+		UID:    user.UID,
+		Groups: user.Groups,
+		Extra:  extra,
+		ResourceAttributes: &authorizationv1.ResourceAttributes{
+			Namespace: namespace,
+			Verb:      verb,
+			Group:     gameserverv1alpha1.GroupVersion.Group,
+			Resource:  "gameserverallocations",
+		},
+	}}
+	if err := s.Client.Create(ctx, accessReview); err != nil {
+		return false, err
+	}
+	return accessReview.Status.Allowed, nil
+}
+
+// writeAllocation writes allocation as JSON with status code
+func writeAllocation(w http.ResponseWriter, code int, allocation *gameserverv1alpha1.GameServerAllocation) {
+	allocation.APIVersion = gameserverv1alpha1.GroupVersion.String()
+	allocation.Kind = "GameServerAllocation"
+	allocation.ManagedFields = nil
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(allocation)
+}
+
+// writeAPIError answers with the status code of the Kubernetes API error err
+func writeAPIError(w http.ResponseWriter, err error) {
+	var status apierrors.APIStatus
+	if errors.As(err, &status) && status.Status().Code != 0 {
+		http.Error(w, err.Error(), int(status.Status().Code))
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
@@ -0,0 +1,189 @@
+package allocator_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+	"github.com/templarfelix/gameserver-operator/internal/allocator"
+)
+
+var _ = Describe("Server", func() {
+	var (
+		c       client.Client
+		handler http.Handler
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(gameserverv1alpha1.AddToScheme(scheme)).To(Succeed())
+		c = fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&gameserverv1alpha1.GameServerAllocation{}).Build()
+		server := &allocator.Server{
+			Client:  c,
+			Timeout: time.Second,
+			Authorize: func(_ context.Context, token, verb, namespace string) (bool, error) {
+				return token == "matchmaker" && namespace == "games", nil
+			},
+		}
+		handler = server.Handler()
+	})
+
+	request := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+	// process sets state on the first allocation created, as the controller does
+	process := func(state gameserverv1alpha1.AllocationState) {
+		go func() {
+			defer GinkgoRecover()
+			Eventually(func(g Gomega) {
+				list := &gameserverv1alpha1.GameServerAllocationList{}
+				g.Expect(c.List(context.Background(), list)).To(Succeed())
+				g.Expect(list.Items).NotTo(BeEmpty())
+				allocation := &list.Items[0]
+				allocation.Status.State = state
+				if state == gameserverv1alpha1.AllocationAllocated {
+					allocation.Status.Address = "10.0.0.12"
+					allocation.Status.Ports = []gameserverv1alpha1.GameServerAllocationPort{{Name: "game", Port: 2302, Protocol: "UDP"}}
+				}
+				g.Expect(c.Status().Update(context.Background(), allocation)).To(Succeed())
+			}).Should(Succeed())
+		}()
+	}
+	decode := func(recorder *httptest.ResponseRecorder) *gameserverv1alpha1.GameServerAllocation {
+		allocation := &gameserverv1alpha1.GameServerAllocation{}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), allocation)).To(Succeed())
+		return allocation
+	}
+	const path = "/v1/namespaces/games/gameserverallocations"
+
+	It("should require an allowed bearer token", func() {
+		Expect(request(http.MethodPost, path, "", "").Code).To(Equal(http.StatusUnauthorized))
+		Expect(request(http.MethodPost, path, "someone", "").Code).To(Equal(http.StatusForbidden))
+		Expect(request(http.MethodPost, "/v1/namespaces/other/gameserverallocations", "matchmaker", "").Code).To(Equal(http.StatusForbidden))
+	})
+
+	It("should create an allocation and answer with the allocated server", func() {
+		process(gameserverv1alpha1.AllocationAllocated)
+		recorder := request(http.MethodPost, path, "matchmaker", `{"spec":{"fleetRef":{"name":"event"},"selector":{"matchLabels":{"mode":"pvp"}}}}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusCreated))
+		allocation := decode(recorder)
+		Expect(allocation.Kind).To(Equal("GameServerAllocation"))
+		Expect(allocation.Name).To(HavePrefix("allocation-"))
+		Expect(allocation.Namespace).To(Equal("games"))
+		Expect(allocation.Spec.FleetRef.Name).To(Equal("event"))
+		Expect(allocation.Status.Address).To(Equal("10.0.0.12"))
+		Expect(allocation.Status.Ports[0].Port).To(Equal(int32(2302)))
+
+		recorder = request(http.MethodGet, path+"/"+allocation.Name, "matchmaker", "")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(decode(recorder).Status.State).To(Equal(gameserverv1alpha1.AllocationAllocated))
+
+		Expect(request(http.MethodDelete, path+"/"+allocation.Name, "matchmaker", "").Code).To(Equal(http.StatusNoContent))
+		Expect(request(http.MethodGet, path+"/"+allocation.Name, "matchmaker", "").Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("should answer with a conflict when no server matched", func() {
+		process(gameserverv1alpha1.AllocationUnAllocated)
+		recorder := request(http.MethodPost, path, "matchmaker", "")
+
+		Expect(recorder.Code).To(Equal(http.StatusConflict))
+		Expect(decode(recorder).Status.State).To(Equal(gameserverv1alpha1.AllocationUnAllocated))
+	})
+
+	It("should time out while the allocation is pending", func() {
+		recorder := request(http.MethodPost, path, "matchmaker", `{"metadata":{"name":"match-1"}}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusGatewayTimeout))
+		Expect(decode(recorder).Name).To(Equal("match-1"))
+	})
+
+	It("should reject an invalid body", func() {
+		Expect(request(http.MethodPost, path, "matchmaker", `{"spec":`).Code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should refuse to serve without a certificate unless insecure", func() {
+		server := &allocator.Server{Client: c, Addr: "127.0.0.1:0", CertDir: GinkgoT().TempDir()}
+		Expect(server.Start(context.Background())).To(MatchError(ContainSubstring("no serving certificate")))
+	})
+
+	It("should serve over TLS with the certificate of CertDir", func() {
+		certDir := GinkgoT().TempDir()
+		pool := writeCertificate(certDir)
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		addr := listener.Addr().String()
+		Expect(listener.Close()).To(Succeed())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		DeferCleanup(cancel)
+		server := &allocator.Server{Client: c, Addr: addr, CertDir: certDir}
+		go func() {
+			defer GinkgoRecover()
+			Expect(server.Start(ctx)).To(Succeed())
+		}()
+
+		httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+		Eventually(func(g Gomega) {
+			response, err := httpClient.Get("https://" + addr + path + "/match-1")
+			g.Expect(err).NotTo(HaveOccurred())
+			defer response.Body.Close()
+			g.Expect(response.StatusCode).To(Equal(http.StatusUnauthorized))
+		}).Should(Succeed())
+	})
+})
+
+// writeCertificate writes a self-signed certificate for 127.0.0.1 to dir and returns a pool trusting it
+func writeCertificate(dir string) *x509.CertPool {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "allocator"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(os.WriteFile(filepath.Join(dir, "tls.crt"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)).To(Succeed())
+	Expect(os.WriteFile(filepath.Join(dir, "tls.key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)).To(Succeed())
+
+	certificate, err := x509.ParseCertificate(der)
+	Expect(err).NotTo(HaveOccurred())
+	pool := x509.NewCertPool()
+	pool.AddCert(certificate)
+	return pool
+}
@@ -0,0 +1,14 @@
+package allocator_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestAllocator(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Allocator Suite")
+}
@@ -0,0 +1,227 @@
+// Package bercon implements the BattlEye RCon protocol (version 2) over UDP used by DayZ and Arma servers.
+// Protocol reference: https://www.battleye.com/downloads/BERConProtocol.txt
+package bercon
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Packet types of the protocol
+const (
+	PacketLogin   byte = 0x00
+	PacketCommand byte = 0x01
+	PacketMessage byte = 0x02
+
+	// DefaultTimeout bounds login and each command round trip
+	DefaultTimeout = 5 * time.Second
+
+	headerSize = 7
+)
+
+var (
+	// ErrLoginFailed is returned when the server rejects the RCon password
+	ErrLoginFailed = errors.New("bercon: login failed")
+	// ErrMalformedPacket is returned for packets with a bad header or checksum
+	ErrMalformedPacket = errors.New("bercon: malformed packet")
+)
+
+// Client is an authenticated RCon session. It is not safe for concurrent use.
+type Client struct {
+	conn    net.Conn
+	seq     byte
+	timeout time.Duration
+}
+
+// Dial connects to addr (host:port) and logs in with password
+func Dial(ctx context.Context, addr, password string, timeout time.Duration) (*Client, error) {
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{conn: conn, timeout: timeout}
+
+	if err := c.login(ctx, password); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Close ends the session
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Command sends an RCon command and returns the (reassembled) response
+func (c *Client) Command(ctx context.Context, command string) (string, error) {
+	if err := c.setDeadline(ctx); err != nil {
+		return "", err
+	}
+
+	seq := c.seq
+	c.seq++
+	if _, err := c.conn.Write(EncodePacket(PacketCommand, append([]byte{seq}, command...))); err != nil {
+		return "", err
+	}
+
+	parts := map[byte][]byte{}
+	for {
+		packetType, body, err := c.read()
+		if err != nil {
+			return "", err
+		}
+		if packetType != PacketCommand || len(body) == 0 || body[0] != seq {
+			continue
+		}
+		body = body[1:]
+
+		// Multi-packet header: 0x00, total, index
+		if len(body) < 3 || body[0] != 0x00 {
+			return string(body), nil
+		}
+		total, index := body[1], body[2]
+		if total == 0 || index >= total {
+			return "", fmt.Errorf("%w: invalid multi-packet header", ErrMalformedPacket)
+		}
+		parts[index] = body[3:]
+		if len(parts) == int(total) {
+			return joinParts(parts), nil
+		}
+	}
+}
+
+func (c *Client) login(ctx context.Context, password string) error {
+	if err := c.setDeadline(ctx); err != nil {
+		return err
+	}
+	if _, err := c.conn.Write(EncodePacket(PacketLogin, []byte(password))); err != nil {
+		return err
+	}
+
+	for {
+		packetType, body, err := c.read()
+		if err != nil {
+			return err
+		}
+		if packetType != PacketLogin {
+			continue
+		}
+		if len(body) != 1 || body[0] != 0x01 {
+			return ErrLoginFailed
+		}
+		return nil
+	}
+}
+
+// read returns the next command or login packet, acknowledging server messages on the way
+func (c *Client) read() (byte, []byte, error) {
+	buf := make([]byte, 65535)
+	for {
+		n, err := c.conn.Read(buf)
+		if err != nil {
+			return 0, nil, err
+		}
+		packetType, body, err := DecodePacket(buf[:n])
+		if err != nil {
+			return 0, nil, err
+		}
+		if packetType == PacketMessage {
+			if len(body) > 0 {
+				if _, err := c.conn.Write(EncodePacket(PacketMessage, body[:1])); err != nil {
+					return 0, nil, err
+				}
+			}
+			continue
+		}
+		return packetType, append([]byte(nil), body...), nil
+	}
+}
+
+func (c *Client) setDeadline(ctx context.Context) error {
+	deadline := time.Now().Add(c.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	return c.conn.SetDeadline(deadline)
+}
+
+func joinParts(parts map[byte][]byte) string {
+	indexes := make([]int, 0, len(parts))
+	for index := range parts {
+		indexes = append(indexes, int(index))
+	}
+	sort.Ints(indexes)
+
+	var b bytes.Buffer
+	for _, index := range indexes {
+		b.Write(parts[byte(index)])
+	}
+	return b.String()
+}
+
+// EncodePacket builds a packet: 'B' 'E', CRC32 of the rest, 0xFF, type, body
+func EncodePacket(packetType byte, body []byte) []byte {
+	payload := append([]byte{0xFF, packetType}, body...)
+	packet := make([]byte, 6, 6+len(payload))
+	packet[0], packet[1] = 'B', 'E'
+	binary.LittleEndian.PutUint32(packet[2:6], crc32.ChecksumIEEE(payload))
+	return append(packet, payload...)
+}
+
+// DecodePacket verifies the header and checksum of a packet and returns its type and body
+func DecodePacket(packet []byte) (byte, []byte, error) {
+	if len(packet) < headerSize+1 || packet[0] != 'B' || packet[1] != 'E' || packet[6] != 0xFF {
+		return 0, nil, fmt.Errorf("%w: bad header", ErrMalformedPacket)
+	}
+	if crc32.ChecksumIEEE(packet[6:]) != binary.LittleEndian.Uint32(packet[2:6]) {
+		return 0, nil, fmt.Errorf("%w: checksum mismatch", ErrMalformedPacket)
+	}
+	return packet[headerSize], packet[headerSize+1:], nil
+}
+
+// Player is one line of the "players" command output
+type Player struct {
+	Number  int
+	Address string
+	Ping    int
+	GUID    string
+	Name    string
+	Lobby   bool
+}
+
+var playerLine = regexp.MustCompile(`^(\d+)\s+(\S+)\s+(-?\d+)\s+(\S+?)(?:\((?:OK|\?)\))?\s+(.+)$`)
+
+// ParsePlayers parses the output of the "players" command
+func ParsePlayers(output string) []Player {
+	var players []Player
+	for _, line := range strings.Split(output, "\n") {
+		match := playerLine.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		number, _ := strconv.Atoi(match[1])
+		ping, _ := strconv.Atoi(match[3])
+		player := Player{Number: number, Address: match[2], Ping: ping, GUID: match[4], Name: match[5]}
+		if name, ok := strings.CutSuffix(player.Name, " (Lobby)"); ok {
+			player.Name, player.Lobby = name, true
+		}
+		players = append(players, player)
+	}
+	return players
+}
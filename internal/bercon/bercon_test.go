@@ -0,0 +1,115 @@
+package bercon_test
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/templarfelix/gameserver-operator/internal/bercon"
+	"github.com/templarfelix/gameserver-operator/internal/bercon/berconttest"
+)
+
+const playersOutput = `Players on server:
+[#] [IP Address]:[Port] [Ping] [GUID] [Name]
+--------------------------------------------------
+0   10.0.0.5:2304         32   0123456789abcdef0123456789abcdef(OK) Survivor
+1   10.0.0.6:2304         -1   -  New Player (Lobby)
+(2 players in total)`
+
+var _ = Describe("Client", func() {
+	var (
+		ctx    context.Context
+		server *berconttest.Server
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		server, err = berconttest.NewServer("secret", func(command string) string {
+			switch command {
+			case "players":
+				return playersOutput
+			case "#lock":
+				return ""
+			}
+			return "echo: " + command
+		})
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(server.Close)
+	})
+
+	It("should log in and execute commands", func() {
+		client, err := bercon.Dial(ctx, server.Addr(), "secret", time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		defer client.Close()
+
+		response, err := client.Command(ctx, "say -1 hello")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response).To(Equal("echo: say -1 hello"))
+
+		response, err = client.Command(ctx, "#lock")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response).To(BeEmpty())
+
+		Expect(server.Commands()).To(Equal([]string{"say -1 hello", "#lock"}))
+	})
+
+	It("should reject a wrong password", func() {
+		_, err := bercon.Dial(ctx, server.Addr(), "wrong", time.Second)
+		Expect(err).To(MatchError(bercon.ErrLoginFailed))
+	})
+
+	It("should reassemble multi-packet responses", func() {
+		server.SetSplitSize(40)
+		client, err := bercon.Dial(ctx, server.Addr(), "secret", time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		defer client.Close()
+
+		response, err := client.Command(ctx, "players")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response).To(Equal(playersOutput))
+	})
+
+	It("should acknowledge server messages received while waiting for a response", func() {
+		server.SetAnnouncement("(Global) Survivor: hello")
+		client, err := bercon.Dial(ctx, server.Addr(), "secret", time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		defer client.Close()
+
+		response, err := client.Command(ctx, "say -1 restart in 5 minutes")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response).To(HavePrefix("echo: "))
+		Eventually(server.Acks).Should(Equal(1))
+	})
+})
+
+var _ = Describe("Packets", func() {
+	It("should round trip and detect corrupted packets", func() {
+		packet := bercon.EncodePacket(bercon.PacketCommand, []byte{0x03, 'h', 'i'})
+		packetType, body, err := bercon.DecodePacket(packet)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(packetType).To(Equal(bercon.PacketCommand))
+		Expect(body).To(Equal([]byte{0x03, 'h', 'i'}))
+
+		packet[len(packet)-1] = 'o'
+		_, _, err = bercon.DecodePacket(packet)
+		Expect(err).To(MatchError(bercon.ErrMalformedPacket))
+	})
+})
+
+var _ = Describe("ParsePlayers", func() {
+	It("should parse the players command output", func() {
+		players := bercon.ParsePlayers(playersOutput)
+		Expect(players).To(HaveLen(2))
+		Expect(players[0]).To(Equal(bercon.Player{
+			Number: 0, Address: "10.0.0.5:2304", Ping: 32, GUID: "0123456789abcdef0123456789abcdef", Name: "Survivor",
+		}))
+		Expect(players[1].Name).To(Equal("New Player"))
+		Expect(players[1].Lobby).To(BeTrue())
+		Expect(strings.Contains(players[1].GUID, "(")).To(BeFalse())
+	})
+})
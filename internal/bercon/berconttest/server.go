@@ -0,0 +1,168 @@
+// Package berconttest provides a local BattlEye RCon server for testing clients and controllers
+// without a real game server.
+package berconttest
+
+import (
+	"net"
+	"sync"
+
+	"github.com/templarfelix/gameserver-operator/internal/bercon"
+)
+
+// Handler returns the response to an RCon command
+type Handler func(command string) string
+
+// Server answers BattlEye RCon logins and commands on a local UDP port
+type Server struct {
+	conn *net.UDPConn
+	wg   sync.WaitGroup
+
+	mu           sync.Mutex
+	password     string
+	handler      Handler
+	loggedIn     map[string]bool
+	commands     []string
+	splitSize    int
+	announcement string
+	messageSeq   byte
+	acks         int
+}
+
+// NewServer starts a server on 127.0.0.1 accepting password and answering commands with handler
+func NewServer(password string, handler Handler) (*Server, error) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		conn:     conn,
+		password: password,
+		handler:  handler,
+		loggedIn: map[string]bool{},
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Addr returns the host:port the server listens on
+func (s *Server) Addr() string {
+	return s.conn.LocalAddr().String()
+}
+
+// Port returns the UDP port the server listens on
+func (s *Server) Port() int32 {
+	return int32(s.conn.LocalAddr().(*net.UDPAddr).Port)
+}
+
+// Commands returns the commands received from logged in clients
+func (s *Server) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+// SetSplitSize splits command responses into multi-packet responses of at most size bytes, 0 disables splitting
+func (s *Server) SetSplitSize(size int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.splitSize = size
+}
+
+// SetAnnouncement makes the server send a server message before every command response,
+// like a server broadcasting chat while commands are issued
+func (s *Server) SetAnnouncement(message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.announcement = message
+}
+
+// Acks returns the number of server messages acknowledged by clients
+func (s *Server) Acks() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.acks
+}
+
+// Close stops the server
+func (s *Server) Close() error {
+	err := s.conn.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := s.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		packetType, body, err := bercon.DecodePacket(buf[:n])
+		if err != nil {
+			continue
+		}
+		for _, packet := range s.handle(addr.String(), packetType, body) {
+			_, _ = s.conn.WriteToUDP(packet, addr)
+		}
+	}
+}
+
+func (s *Server) handle(client string, packetType byte, body []byte) [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch packetType {
+	case bercon.PacketLogin:
+		result := byte(0x00)
+		if string(body) == s.password {
+			s.loggedIn[client] = true
+			result = 0x01
+		}
+		return [][]byte{bercon.EncodePacket(bercon.PacketLogin, []byte{result})}
+
+	case bercon.PacketMessage:
+		s.acks++
+		return nil
+
+	case bercon.PacketCommand:
+		if !s.loggedIn[client] || len(body) == 0 {
+			return nil
+		}
+		seq, command := body[0], string(body[1:])
+		s.commands = append(s.commands, command)
+
+		var packets [][]byte
+		if s.announcement != "" {
+			packets = append(packets, bercon.EncodePacket(bercon.PacketMessage, append([]byte{s.messageSeq}, s.announcement...)))
+			s.messageSeq++
+		}
+		response := ""
+		if s.handler != nil {
+			response = s.handler(command)
+		}
+		return append(packets, s.commandResponse(seq, response)...)
+	}
+	return nil
+}
+
+func (s *Server) commandResponse(seq byte, response string) [][]byte {
+	if s.splitSize <= 0 || len(response) <= s.splitSize {
+		return [][]byte{bercon.EncodePacket(bercon.PacketCommand, append([]byte{seq}, response...))}
+	}
+
+	total := (len(response) + s.splitSize - 1) / s.splitSize
+	packets := make([][]byte, 0, total)
+	// Send the parts in reverse order, clients must reassemble by index
+	for i := total - 1; i >= 0; i-- {
+		end := (i + 1) * s.splitSize
+		if end > len(response) {
+			end = len(response)
+		}
+		body := append([]byte{seq, 0x00, byte(total), byte(i)}, response[i*s.splitSize:end]...)
+		packets = append(packets, bercon.EncodePacket(bercon.PacketCommand, body))
+	}
+	return packets
+}
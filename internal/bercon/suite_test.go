@@ -0,0 +1,14 @@
+package bercon_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestBERCon(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "BattlEye RCon Suite")
+}
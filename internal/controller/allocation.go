@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+)
+
+const (
+	// ReasonAllocated means changes replacing the game pod wait until the allocation of the server is released
+	ReasonAllocated = "Allocated"
+)
+
+// IsAllocated reports whether a GameServerAllocation holds the game server obj
+func IsAllocated(obj client.Object) bool {
+	return obj.GetLabels()[gameserverv1alpha1.AllocatedLabel] == "true"
+}
+
+// SetAllocated marks the game server obj as held by the GameServerAllocation named allocation
+func SetAllocated(obj client.Object, allocation string) {
+	labels, annotations := obj.GetLabels(), obj.GetAnnotations()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	labels[gameserverv1alpha1.AllocatedLabel] = "true"
+	annotations[gameserverv1alpha1.AllocationAnnotation] = allocation
+	obj.SetLabels(labels)
+	obj.SetAnnotations(annotations)
+}
+
+// ClearAllocated hands the game server obj back to its fleet
+func ClearAllocated(obj client.Object) {
+	labels, annotations := obj.GetLabels(), obj.GetAnnotations()
+	delete(labels, gameserverv1alpha1.AllocatedLabel)
+	delete(annotations, gameserverv1alpha1.AllocationAnnotation)
+	obj.SetLabels(labels)
+	obj.SetAnnotations(annotations)
+}
+
+// AllocatedTo reports whether the GameServerAllocation named allocation holds the game server obj
+func AllocatedTo(obj client.Object, allocation string) bool {
+	return IsAllocated(obj) && obj.GetAnnotations()[gameserverv1alpha1.AllocationAnnotation] == allocation
+}
+
+// AllocationCandidates returns the servers which may be allocated in the order they are tried: ready,
+// empty, not allocated nor being deleted servers matching selector, lowest index first
+func AllocationCandidates(servers []FleetServer, selector labels.Selector) []FleetServer {
+	var candidates []FleetServer
+	for _, server := range servers {
+		if server.Object.GetDeletionTimestamp() != nil || server.Allocated() || !server.Ready() ||
+			server.Status.Players > 0 || !selector.Matches(labels.Set(server.Object.GetLabels())) {
+			continue
+		}
+		candidates = append(candidates, server)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Index < candidates[j].Index
+	})
+	return candidates
+}
+
+// GameServerAddress returns the address players connect to and the ports of the game Services of
+// server, see ReconcileServices. The address is empty until the LoadBalancer has one.
+func GameServerAddress(ctx context.Context, c client.Client, server client.Object) (string, []gameserverv1alpha1.GameServerAllocationPort, error) {
+	var address string
+	var ports []gameserverv1alpha1.GameServerAllocationPort
+	for _, suffix := range []string{"-udp", "-tcp"} {
+		service := &corev1.Service{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: server.GetNamespace(), Name: server.GetName() + suffix}, service); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return "", nil, err
+		}
+		if address == "" {
+			address = serviceAddress(service)
+		}
+		for _, port := range service.Spec.Ports {
+			ports = append(ports, gameserverv1alpha1.GameServerAllocationPort{Name: port.Name, Port: port.Port, Protocol: port.Protocol})
+		}
+	}
+	return address, ports, nil
+}
+
+// serviceAddress returns the LoadBalancer address of service, its requested IP until one is assigned
+func serviceAddress(service *corev1.Service) string {
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			return ingress.IP
+		}
+		if ingress.Hostname != "" {
+			return ingress.Hostname
+		}
+	}
+	return service.Spec.LoadBalancerIP
+}
+
+// AllocationRelease decides whether the game server of an allocation is released given its players
+// at now: once the players who joined left, or when nobody joined within spec.emptyTimeoutSeconds.
+// It records joined players in status and returns the reason of the release, or how long until the
+// empty timeout.
+func AllocationRelease(allocation *gameserverv1alpha1.GameServerAllocation, status *gameserverv1alpha1.GameServerAllocationStatus, players int32, now time.Time) (string, time.Duration) {
+	if players > 0 {
+		status.PlayersJoined = true
+		return "", 0
+	}
+	if status.PlayersJoined {
+		return "The players left the game server", 0
+	}
+
+	timeout := time.Duration(allocation.Spec.EmptyTimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 300 * time.Second
+	}
+	if status.AllocationTime == nil {
+		return "", timeout
+	}
+	if deadline := status.AllocationTime.Add(timeout); now.Before(deadline) {
+		return "", deadline.Sub(now)
+	}
+	return fmt.Sprintf("No player joined within %s", timeout), 0
+}
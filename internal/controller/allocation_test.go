@@ -0,0 +1,257 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+	gamev1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1/game"
+)
+
+var _ = Describe("GameServerAllocation", func() {
+	ctx := context.Background()
+
+	fleet := func(replicas int32) *gameserverv1alpha1.GameServerFleet {
+		return &gameserverv1alpha1.GameServerFleet{
+			ObjectMeta: metav1.ObjectMeta{Name: "event", Namespace: "games", UID: "fleet-uid"},
+			Spec: gameserverv1alpha1.GameServerFleetSpec{
+				Replicas: replicas,
+				Template: gameserverv1alpha1.GameServerFleetTemplate{
+					Kind:     "Dayz",
+					Metadata: gameserverv1alpha1.GameServerFleetMetadata{Labels: map[string]string{"mode": "pvp"}},
+					Spec:     apiextensionsv1.JSON{Raw: []byte(`{}`)},
+				},
+			},
+		}
+	}
+	// server returns the ready game server of owner with index and players
+	server := func(scheme *runtime.Scheme, owner *gameserverv1alpha1.GameServerFleet, index int, players int32) *gamev1alpha1.Dayz {
+		rendered, err := FleetKinds["Dayz"].Render(owner, index)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(controllerutil.SetControllerReference(owner, rendered, scheme)).To(Succeed())
+		dayz := rendered.(*gamev1alpha1.Dayz)
+		dayz.Status.Players = players
+		dayz.Status.Conditions = []metav1.Condition{{
+			Type: ConditionReady, Status: metav1.ConditionTrue, Reason: ReasonQueryResponded, LastTransitionTime: metav1.Now(),
+		}}
+		return dayz
+	}
+	service := func(name, ip string) *corev1.Service {
+		svc := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name + "-udp", Namespace: "games"},
+			Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{
+				{Name: "port-2302-udp", Port: 2302, Protocol: corev1.ProtocolUDP},
+				{Name: "port-27016-udp", Port: 27016, Protocol: corev1.ProtocolUDP},
+			}},
+		}
+		if ip != "" {
+			svc.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: ip}}
+		}
+		return svc
+	}
+
+	Describe("helpers", func() {
+		It("should only offer ready and empty servers which are not allocated", func() {
+			scheme := runtime.NewScheme()
+			Expect(gameserverv1alpha1.AddToScheme(scheme)).To(Succeed())
+			owner := fleet(5)
+			allocated := server(scheme, owner, 1, 0)
+			SetAllocated(allocated, "match-1")
+			notReady := server(scheme, owner, 2, 0)
+			notReady.Status.Conditions[0].Status = metav1.ConditionFalse
+			occupied := server(scheme, owner, 3, 4)
+			other := server(scheme, owner, 4, 0)
+			other.Labels["mode"] = "pve"
+
+			list := &gamev1alpha1.DayzList{Items: []gamev1alpha1.Dayz{*other, *server(scheme, owner, 0, 0), *allocated, *notReady, *occupied}}
+			candidates := AllocationCandidates(FleetKinds["Dayz"].Servers(list), labels.Everything())
+			Expect(candidates).To(HaveLen(2))
+			Expect(candidates[0].Object.GetName()).To(Equal("event-0"))
+			Expect(candidates[1].Object.GetName()).To(Equal("event-4"))
+
+			candidates = AllocationCandidates(FleetKinds["Dayz"].Servers(list), labels.SelectorFromSet(labels.Set{"mode": "pve"}))
+			Expect(candidates).To(HaveLen(1))
+			Expect(candidates[0].Object.GetName()).To(Equal("event-4"))
+		})
+
+		It("should release once the players left or nobody joined in time", func() {
+			allocation := &gameserverv1alpha1.GameServerAllocation{Spec: gameserverv1alpha1.GameServerAllocationSpec{EmptyTimeoutSeconds: 60}}
+			start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+			status := &gameserverv1alpha1.GameServerAllocationStatus{AllocationTime: &metav1.Time{Time: start}}
+
+			message, wait := AllocationRelease(allocation, status, 0, start.Add(20*time.Second))
+			Expect(message).To(BeEmpty())
+			Expect(wait).To(Equal(40 * time.Second))
+			message, _ = AllocationRelease(allocation, status, 0, start.Add(time.Minute))
+			Expect(message).To(Equal("No player joined within 1m0s"))
+
+			message, _ = AllocationRelease(allocation, status, 3, start.Add(2*time.Minute))
+			Expect(message).To(BeEmpty())
+			Expect(status.PlayersJoined).To(BeTrue())
+			message, _ = AllocationRelease(allocation, status, 0, start.Add(time.Hour))
+			Expect(message).To(Equal("The players left the game server"))
+		})
+	})
+
+	Describe("GameServerAllocationReconciler", func() {
+		var (
+			c          client.Client
+			scheme     *runtime.Scheme
+			recorder   *record.FakeRecorder
+			reconciler *GameServerAllocationReconciler
+			owner      *gameserverv1alpha1.GameServerFleet
+		)
+		key := types.NamespacedName{Name: "match-1", Namespace: "games"}
+
+		setup := func(objects ...client.Object) {
+			c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).
+				WithStatusSubresource(&gameserverv1alpha1.GameServerAllocation{}, &gameserverv1alpha1.GameServerFleet{}, &gamev1alpha1.Dayz{}).Build()
+			recorder = record.NewFakeRecorder(10)
+			reconciler = &GameServerAllocationReconciler{Client: c, Scheme: scheme, Recorder: recorder}
+		}
+		allocation := func(selector map[string]string) *gameserverv1alpha1.GameServerAllocation {
+			return &gameserverv1alpha1.GameServerAllocation{
+				ObjectMeta: metav1.ObjectMeta{Name: "match-1", Namespace: "games"},
+				Spec: gameserverv1alpha1.GameServerAllocationSpec{
+					FleetRef:            &corev1.LocalObjectReference{Name: "event"},
+					Selector:            &metav1.LabelSelector{MatchLabels: selector},
+					EmptyTimeoutSeconds: 300,
+				},
+			}
+		}
+		reconcileAllocation := func() reconcile.Result {
+			result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			return result
+		}
+		getAllocation := func() *gameserverv1alpha1.GameServerAllocation {
+			updated := &gameserverv1alpha1.GameServerAllocation{}
+			Expect(c.Get(ctx, key, updated)).To(Succeed())
+			return updated
+		}
+		getServer := func(name string) *gamev1alpha1.Dayz {
+			dayz := &gamev1alpha1.Dayz{}
+			Expect(c.Get(ctx, types.NamespacedName{Name: name, Namespace: "games"}, dayz)).To(Succeed())
+			return dayz
+		}
+		setPlayers := func(name string, players int32) {
+			dayz := getServer(name)
+			dayz.Status.Players = players
+			Expect(c.Status().Update(ctx, dayz)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			scheme = runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(gameserverv1alpha1.AddToScheme(scheme)).To(Succeed())
+			owner = fleet(3)
+		})
+
+		It("should allocate a ready and empty server and report its address", func() {
+			setup(allocation(map[string]string{"mode": "pvp"}), owner,
+				server(scheme, owner, 0, 6), server(scheme, owner, 1, 0), server(scheme, owner, 2, 0),
+				service("event-0", "10.0.0.10"), service("event-1", ""), service("event-2", "10.0.0.12"))
+
+			result := reconcileAllocation()
+			Expect(result.RequeueAfter).To(BeNumerically("~", 5*time.Minute, time.Second))
+
+			status := getAllocation().Status
+			Expect(status.State).To(Equal(gameserverv1alpha1.AllocationAllocated))
+			Expect(status.GameServerRef).To(Equal(&gameserverv1alpha1.GameServerReference{Kind: "Dayz", Name: "event-2"}), "event-1 has no address yet")
+			Expect(status.Fleet).To(Equal("event"))
+			Expect(status.Address).To(Equal("10.0.0.12"))
+			Expect(status.Ports).To(Equal([]gameserverv1alpha1.GameServerAllocationPort{
+				{Name: "port-2302-udp", Port: 2302, Protocol: corev1.ProtocolUDP},
+				{Name: "port-27016-udp", Port: 27016, Protocol: corev1.ProtocolUDP},
+			}))
+			Expect(getAllocation().Finalizers).To(ContainElement("gameserver.templarfelix.com/allocation"))
+			Expect(AllocatedTo(getServer("event-2"), "match-1")).To(BeTrue())
+			Expect(<-recorder.Events).To(Equal("Normal Allocated Allocated Dayz event-2 at 10.0.0.12"))
+
+			// Reconciling again keeps the allocation
+			reconcileAllocation()
+			Expect(getAllocation().Status.GameServerRef.Name).To(Equal("event-2"))
+		})
+
+		It("should keep allocated servers when the fleet scales down", func() {
+			setup(allocation(nil), owner, server(scheme, owner, 0, 0), server(scheme, owner, 1, 0), server(scheme, owner, 2, 0),
+				service("event-0", "10.0.0.10"), service("event-1", "10.0.0.11"), service("event-2", "10.0.0.12"))
+			reconcileAllocation()
+			Expect(getAllocation().Status.GameServerRef.Name).To(Equal("event-0"))
+
+			scaled := &gameserverv1alpha1.GameServerFleet{}
+			Expect(c.Get(ctx, types.NamespacedName{Name: "event", Namespace: "games"}, scaled)).To(Succeed())
+			scaled.Spec.Replicas = 0
+			Expect(c.Update(ctx, scaled)).To(Succeed())
+			fleetReconciler := &GameServerFleetReconciler{Client: c, Scheme: scheme}
+			_, err := fleetReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "event", Namespace: "games"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			dayzs := &gamev1alpha1.DayzList{}
+			Expect(c.List(ctx, dayzs)).To(Succeed())
+			Expect(dayzs.Items).To(HaveLen(1))
+			Expect(dayzs.Items[0].Name).To(Equal("event-0"))
+			Expect(c.Get(ctx, types.NamespacedName{Name: "event", Namespace: "games"}, scaled)).To(Succeed())
+			Expect(scaled.Status.AllocatedReplicas).To(Equal(int32(1)))
+		})
+
+		It("should release the server once the players left", func() {
+			setup(allocation(nil), owner, server(scheme, owner, 0, 0), service("event-0", "10.0.0.10"))
+			reconcileAllocation()
+			<-recorder.Events
+
+			setPlayers("event-0", 8)
+			reconcileAllocation()
+			Expect(getAllocation().Status.PlayersJoined).To(BeTrue())
+			Expect(IsAllocated(getServer("event-0"))).To(BeTrue())
+
+			setPlayers("event-0", 0)
+			reconcileAllocation()
+			released := getAllocation()
+			Expect(released.Status.State).To(Equal(gameserverv1alpha1.AllocationReleased))
+			Expect(released.Status.Message).To(Equal("The players left the game server"))
+			Expect(released.Status.ReleaseTime).NotTo(BeNil())
+			Expect(released.Finalizers).To(BeEmpty())
+			Expect(IsAllocated(getServer("event-0"))).To(BeFalse())
+			Expect(getServer("event-0").Annotations).NotTo(HaveKey(gameserverv1alpha1.AllocationAnnotation))
+			Expect(<-recorder.Events).To(Equal("Normal Released The players left the game server"))
+		})
+
+		It("should release the server when the allocation is deleted", func() {
+			setup(allocation(nil), owner, server(scheme, owner, 0, 0), service("event-0", "10.0.0.10"))
+			reconcileAllocation()
+
+			Expect(c.Delete(ctx, getAllocation())).To(Succeed())
+			reconcileAllocation()
+			Expect(IsAllocated(getServer("event-0"))).To(BeFalse())
+			Expect(c.Get(ctx, key, &gameserverv1alpha1.GameServerAllocation{})).NotTo(Succeed())
+		})
+
+		It("should report when no server matched", func() {
+			setup(allocation(map[string]string{"mode": "pve"}), owner, server(scheme, owner, 0, 0), service("event-0", "10.0.0.10"))
+
+			Expect(reconcileAllocation()).To(Equal(reconcile.Result{}))
+			status := getAllocation().Status
+			Expect(status.State).To(Equal(gameserverv1alpha1.AllocationUnAllocated))
+			Expect(status.Message).To(Equal("No ready and empty game server matched"))
+			Expect(IsAllocated(getServer("event-0"))).To(BeFalse())
+			Expect(<-recorder.Events).To(Equal("Warning UnAllocated No ready and empty game server matched"))
+		})
+	})
+})
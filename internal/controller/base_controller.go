@@ -1,9 +1,14 @@
 package controller
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"fmt"
+	"reflect"
+
 	"golang.org/x/net/context"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -21,18 +26,19 @@ import (
 func initializeDefaultPersistence(persistence *gameserverv1alpha1.Persistence, logger logr.Logger, ownerName string) {
 	// Ensure storageConfig is initialized with defaults
 	if persistence.StorageConfig.Size == "" {
-		logger.V(4).Info("Setting default storage size", "owner", ownerName, "size", "10G")
-		persistence.StorageConfig.Size = "10G"
+		logger.V(4).Info("Setting default storage size", "owner", ownerName, "size", DefaultStorageSize)
+		persistence.StorageConfig.Size = DefaultStorageSize
 	}
 
-	// Validate that the size can be parsed
+	// Validate that the size can be parsed, the admission webhook rejects such objects but
+	// resources stored before it was enabled may still carry an invalid size
 	if _, err := resource.ParseQuantity(persistence.StorageConfig.Size); err != nil {
 		logger.Error(err, "Invalid storage size, using default", "owner", ownerName, "size", persistence.StorageConfig.Size)
-		persistence.StorageConfig.Size = "10G"
+		persistence.StorageConfig.Size = DefaultStorageSize
 	}
 }
 
-// ReconcilePVC creates or updates a PersistentVolumeClaim for game data storage
+// ReconcilePVC creates the PersistentVolumeClaim for game data storage and expands it when the size grows
 func ReconcilePVC(ctx context.Context, k8sClient client.Client, owner metav1.Object, persistence *gameserverv1alpha1.Persistence) error {
 	logger := log.FromContext(ctx)
 	pvcName := owner.GetName() + "-pvc"
@@ -51,7 +57,7 @@ func ReconcilePVC(ctx context.Context, k8sClient client.Client, owner metav1.Obj
 	parsedSize, err := resource.ParseQuantity(storageSize)
 	if err != nil {
 		logger.Error(err, "Invalid storage size, using default", "size", storageSize)
-		parsedSize, _ = resource.ParseQuantity("10G") // This should not fail
+		parsedSize, _ = resource.ParseQuantity(DefaultStorageSize) // This should not fail
 	}
 
 	var storageClassName *string
@@ -89,30 +95,46 @@ func ReconcilePVC(ctx context.Context, k8sClient client.Client, owner metav1.Obj
 		return err
 	}
 
-	logger.V(4).Info("PVC already exists", "namespace", found.Namespace, "name", found.Name)
+	// Volumes are only grown, the admission webhook rejects shrinking them. The API server refuses the
+	// update when the storage class does not allow volume expansion.
+	current := found.Spec.Resources.Requests[corev1.ResourceStorage]
+	if parsedSize.Cmp(current) <= 0 {
+		logger.V(4).Info("PVC already exists", "namespace", found.Namespace, "name", found.Name)
+		return nil
+	}
+
+	logger.Info("Expanding PVC", "namespace", found.Namespace, "name", found.Name, "from", current.String(), "to", parsedSize.String())
+	if found.Spec.Resources.Requests == nil {
+		found.Spec.Resources.Requests = corev1.ResourceList{}
+	}
+	found.Spec.Resources.Requests[corev1.ResourceStorage] = parsedSize
+	if err := k8sClient.Update(ctx, found); err != nil {
+		return fmt.Errorf("expanding volume %s to %s: %w", pvcName, parsedSize.String(), err)
+	}
 	return nil
 }
 
-// ReconcileServices creates or updates Services for exposing the game server
-func ReconcileServices(ctx context.Context, k8sClient client.Client, owner metav1.Object, ports []corev1.ServicePort, loadBalancerIP string) error {
-	// Add code-server port to TCP service
+// ReconcileServices creates or updates Services for exposing the game server, selecting the pods
+// matching selector. The annotations are added to the Services, other annotations are kept.
+func ReconcileServices(ctx context.Context, k8sClient client.Client, owner metav1.Object, ports []corev1.ServicePort, loadBalancerIP string, annotations, selector map[string]string) error {
+	// The code-server editor is never published here, see ReconcileEditorExposure
 	tcpPorts, udpPorts := separatePortsByProtocol(ports)
 
-	// Create TCP service with code-server port
-	tcpPorts = append(tcpPorts, corev1.ServicePort{
-		Name:       "code-server",
-		Port:       8080,
-		TargetPort: intstr.FromInt32(8080),
-		Protocol:   corev1.ProtocolTCP,
-	})
-
-	// Create separate services for TCP and UDP
-	if err := reconcileService(ctx, owner.GetName()+"-tcp", k8sClient, owner, tcpPorts, loadBalancerIP); err != nil {
-		return err
-	}
-
-	if len(udpPorts) > 0 {
-		if err := reconcileService(ctx, owner.GetName()+"-udp", k8sClient, owner, udpPorts, loadBalancerIP); err != nil {
+	// Create separate services for TCP and UDP, a protocol without ports loses its Service
+	for _, service := range []struct {
+		name  string
+		ports []corev1.ServicePort
+	}{
+		{owner.GetName() + "-tcp", tcpPorts},
+		{owner.GetName() + "-udp", udpPorts},
+	} {
+		var err error
+		if len(service.ports) > 0 {
+			err = reconcileService(ctx, service.name, k8sClient, owner, service.ports, loadBalancerIP, annotations, selector)
+		} else {
+			err = deleteIfExists(ctx, k8sClient, owner, &corev1.Service{}, service.name)
+		}
+		if err != nil {
 			return err
 		}
 	}
@@ -120,7 +142,7 @@ func ReconcileServices(ctx context.Context, k8sClient client.Client, owner metav
 	return nil
 }
 
-func reconcileService(ctx context.Context, serviceName string, k8sClient client.Client, owner metav1.Object, ports []corev1.ServicePort, loadBalancerIP string) error {
+func reconcileService(ctx context.Context, serviceName string, k8sClient client.Client, owner metav1.Object, ports []corev1.ServicePort, loadBalancerIP string, annotations, selector map[string]string) error {
 	logger := log.FromContext(ctx)
 
 	desired := &corev1.Service{
@@ -130,11 +152,10 @@ func reconcileService(ctx context.Context, serviceName string, k8sClient client.
 			Labels: map[string]string{
 				"cloud.google.com/load-balancer-type": "External",
 			},
+			Annotations: annotations,
 		},
 		Spec: corev1.ServiceSpec{
-			Selector: map[string]string{
-				"app": owner.GetName(),
-			},
+			Selector:       selector,
 			Type:           corev1.ServiceTypeLoadBalancer,
 			LoadBalancerIP: loadBalancerIP,
 			Ports:          ports,
@@ -154,10 +175,61 @@ func reconcileService(ctx context.Context, serviceName string, k8sClient client.
 		return err
 	}
 
-	logger.Info("Skip reconcile: Service already exists", "Namespace", found.Namespace, "Name", found.Name)
+	if !CompareServicePorts(found.Spec.Ports, desired.Spec.Ports) || !reflect.DeepEqual(found.Spec.Selector, desired.Spec.Selector) ||
+		!hasAnnotations(found, annotations) {
+		logger.Info("Updating Service ports, selector and annotations", "Namespace", found.Namespace, "Name", found.Name)
+		found.Spec.Ports = preserveNodePorts(found.Spec.Ports, desired.Spec.Ports)
+		found.Spec.Selector = desired.Spec.Selector
+		for key, value := range annotations {
+			metav1.SetMetaDataAnnotation(&found.ObjectMeta, key, value)
+		}
+		return k8sClient.Update(ctx, found)
+	}
+
+	logger.V(4).Info("Service already exists and is up to date", "Namespace", found.Namespace, "Name", found.Name)
 	return nil
 }
 
+// hasAnnotations reports whether obj carries all annotations
+func hasAnnotations(obj metav1.Object, annotations map[string]string) bool {
+	for key, value := range annotations {
+		if current, ok := obj.GetAnnotations()[key]; !ok || current != value {
+			return false
+		}
+	}
+	return true
+}
+
+// CompareServicePorts checks if two port lists expose the same ports, ignoring server-assigned node ports
+func CompareServicePorts(a, b []corev1.ServicePort) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name || a[i].Port != b[i].Port || a[i].Protocol != b[i].Protocol ||
+			a[i].TargetPort.String() != b[i].TargetPort.String() {
+			return false
+		}
+	}
+	return true
+}
+
+// preserveNodePorts keeps the node ports already allocated for ports that are still desired
+func preserveNodePorts(current, desired []corev1.ServicePort) []corev1.ServicePort {
+	nodePorts := make(map[string]int32, len(current))
+	for _, port := range current {
+		nodePorts[port.Name] = port.NodePort
+	}
+	ports := make([]corev1.ServicePort, len(desired))
+	for i, port := range desired {
+		if port.NodePort == 0 {
+			port.NodePort = nodePorts[port.Name]
+		}
+		ports[i] = port
+	}
+	return ports
+}
+
 func separatePortsByProtocol(ports []corev1.ServicePort) (tcpPorts []corev1.ServicePort, udpPorts []corev1.ServicePort) {
 	for _, port := range ports {
 		switch port.Protocol {
@@ -169,3 +241,225 @@ func separatePortsByProtocol(ports []corev1.ServicePort) (tcpPorts []corev1.Serv
 	}
 	return tcpPorts, udpPorts
 }
+
+// ReconcileEditorSecret ensures the code-server password Secret exists and returns the key to reference it by,
+// with the resourceVersion of the Secret so that a password change restarts the editor.
+// An explicit EditorPasswordSecretRef is used as-is; otherwise a <name>-editor Secret is created holding
+// EditorPassword, or a random password when none is supplied. The generated Secret is removed when it is
+// no longer used, no key is returned when the editor is disabled.
+func ReconcileEditorSecret(ctx context.Context, k8sClient client.Client, owner metav1.Object, base *gameserverv1alpha1.Base) (*corev1.SecretKeySelector, string, error) {
+	logger := log.FromContext(ctx)
+	secretName := owner.GetName() + EditorSecretSuffix
+
+	if !IsEditorEnabled(&base.Editor) {
+		return nil, "", deleteIfExists(ctx, k8sClient, owner, &corev1.Secret{}, secretName)
+	}
+
+	if base.EditorPasswordSecretRef != nil {
+		ref := *base.EditorPasswordSecretRef
+		if ref.Key == "" {
+			ref.Key = EditorPasswordKey
+		}
+		if ref.Name != secretName {
+			if err := deleteIfExists(ctx, k8sClient, owner, &corev1.Secret{}, secretName); err != nil {
+				return nil, "", err
+			}
+		}
+
+		// A missing Secret keeps the editor from starting until it is created, which is reported on the pod
+		found := &corev1.Secret{}
+		err := k8sClient.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: owner.GetNamespace()}, found)
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, "", err
+		}
+		return &ref, found.ResourceVersion, nil
+	}
+
+	ref := &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+		Key:                  EditorPasswordKey,
+	}
+
+	found := &corev1.Secret{}
+	err := k8sClient.Get(ctx, types.NamespacedName{Name: secretName, Namespace: owner.GetNamespace()}, found)
+	if err != nil && errors.IsNotFound(err) {
+		password := base.EditorPassword
+		if password == "" {
+			if password, err = generatePassword(); err != nil {
+				return nil, "", err
+			}
+		}
+
+		desired := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      secretName,
+				Namespace: owner.GetNamespace(),
+			},
+			Type: corev1.SecretTypeOpaque,
+			Data: map[string][]byte{
+				EditorPasswordKey: []byte(password),
+			},
+		}
+		if err := controllerutil.SetControllerReference(owner, desired, k8sClient.Scheme()); err != nil {
+			return nil, "", err
+		}
+
+		logger.Info("Creating editor Secret", "namespace", owner.GetNamespace(), "name", secretName)
+		if err := k8sClient.Create(ctx, desired); err != nil {
+			return nil, "", err
+		}
+		return ref, desired.ResourceVersion, nil
+	} else if err != nil {
+		return nil, "", err
+	}
+
+	// Keep a previously generated password, only sync an explicitly supplied one
+	if base.EditorPassword != "" && string(found.Data[EditorPasswordKey]) != base.EditorPassword {
+		logger.Info("Updating editor Secret", "namespace", found.Namespace, "name", found.Name)
+		if found.Data == nil {
+			found.Data = map[string][]byte{}
+		}
+		found.Data[EditorPasswordKey] = []byte(base.EditorPassword)
+		if err := k8sClient.Update(ctx, found); err != nil {
+			return nil, "", err
+		}
+		return ref, found.ResourceVersion, nil
+	}
+
+	logger.V(4).Info("Editor Secret already exists", "namespace", found.Namespace, "name", found.Name)
+	return ref, found.ResourceVersion, nil
+}
+
+// generatePassword returns a random URL-safe password
+func generatePassword() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate editor password: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// ReconcileEditorExposure publishes the code-server editor through a ClusterIP Service and, when requested,
+// an Ingress. Both are removed when the editor is disabled.
+func ReconcileEditorExposure(ctx context.Context, k8sClient client.Client, owner metav1.Object, editor *gameserverv1alpha1.Editor) error {
+	name := owner.GetName() + EditorServiceSuffix
+
+	if !IsEditorEnabled(editor) {
+		if err := deleteIfExists(ctx, k8sClient, owner, &networkingv1.Ingress{}, name); err != nil {
+			return err
+		}
+		return deleteIfExists(ctx, k8sClient, owner, &corev1.Service{}, name)
+	}
+
+	if err := reconcileEditorService(ctx, k8sClient, owner, name); err != nil {
+		return err
+	}
+
+	if editor.Exposure != gameserverv1alpha1.EditorExposureIngress {
+		return deleteIfExists(ctx, k8sClient, owner, &networkingv1.Ingress{}, name)
+	}
+	if editor.Ingress == nil || editor.Ingress.Host == "" {
+		return fmt.Errorf("editor exposure is Ingress but no ingress host is configured")
+	}
+	return reconcileEditorIngress(ctx, k8sClient, owner, name, editor.Ingress)
+}
+
+func reconcileEditorService(ctx context.Context, k8sClient client.Client, owner metav1.Object, serviceName string) error {
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: serviceName, Namespace: owner.GetNamespace()}}
+	return createOrUpdateOwned(ctx, k8sClient, owner, service, func() {
+		service.Spec.Selector = map[string]string{
+			"app": owner.GetName(),
+		}
+		service.Spec.Type = corev1.ServiceTypeClusterIP
+		service.Spec.Ports = []corev1.ServicePort{{
+			Name:       CodeServerContainerName,
+			Port:       CodeServerPort,
+			TargetPort: intstr.FromInt32(CodeServerPort),
+			Protocol:   corev1.ProtocolTCP,
+		}}
+	})
+}
+
+func reconcileEditorIngress(ctx context.Context, k8sClient client.Client, owner metav1.Object, name string, config *gameserverv1alpha1.EditorIngress) error {
+	logger := log.FromContext(ctx)
+	pathType := networkingv1.PathTypePrefix
+
+	desired := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   owner.GetNamespace(),
+			Annotations: config.Annotations,
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: config.IngressClassName,
+			Rules: []networkingv1.IngressRule{{
+				Host: config.Host,
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path:     "/",
+							PathType: &pathType,
+							Backend: networkingv1.IngressBackend{
+								Service: &networkingv1.IngressServiceBackend{
+									Name: name,
+									Port: networkingv1.ServiceBackendPort{Number: CodeServerPort},
+								},
+							},
+						}},
+					},
+				},
+			}},
+		},
+	}
+	if config.TLSSecretName != "" {
+		desired.Spec.TLS = []networkingv1.IngressTLS{{
+			Hosts:      []string{config.Host},
+			SecretName: config.TLSSecretName,
+		}}
+	}
+
+	if err := controllerutil.SetControllerReference(owner, desired, k8sClient.Scheme()); err != nil {
+		return err
+	}
+
+	found := &networkingv1.Ingress{}
+	err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: owner.GetNamespace()}, found)
+	if err != nil && errors.IsNotFound(err) {
+		logger.Info("Creating editor Ingress", "namespace", owner.GetNamespace(), "name", name)
+		return k8sClient.Create(ctx, desired)
+	} else if err != nil {
+		return err
+	}
+
+	if !reflect.DeepEqual(found.Spec, desired.Spec) || !reflect.DeepEqual(found.Annotations, desired.Annotations) {
+		logger.Info("Updating editor Ingress", "namespace", found.Namespace, "name", found.Name)
+		found.Spec = desired.Spec
+		found.Annotations = desired.Annotations
+		return k8sClient.Update(ctx, found)
+	}
+
+	logger.V(4).Info("Editor Ingress already exists and is up to date", "namespace", found.Namespace, "name", found.Name)
+	return nil
+}
+
+// deleteIfExists deletes the named object in the namespace of owner, ignoring objects that are already
+// gone or not controlled by owner
+func deleteIfExists(ctx context.Context, k8sClient client.Client, owner metav1.Object, obj client.Object, name string) error {
+	namespace := owner.GetNamespace()
+	err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, obj)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !metav1.IsControlledBy(obj, owner) {
+		log.FromContext(ctx).Info("Keeping unused resource not controlled by the game server", "namespace", namespace, "name", name)
+		return nil
+	}
+
+	log.FromContext(ctx).Info("Deleting unused resource", "namespace", namespace, "name", name)
+	if err := k8sClient.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,373 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+)
+
+var _ = Describe("BaseController", func() {
+	Describe("ReconcileEditorSecret", func() {
+		var (
+			ctx        context.Context
+			fakeClient client.Client
+			owner      *corev1.ConfigMap
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			fakeClient = fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+			owner = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-server", Namespace: "default", UID: "test-uid"},
+			}
+		})
+
+		getSecret := func() *corev1.Secret {
+			secret := &corev1.Secret{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "test-server-editor", Namespace: "default"}, secret)).To(Succeed())
+			return secret
+		}
+
+		It("should generate a random password when none is supplied", func() {
+			ref, version, err := ReconcileEditorSecret(ctx, fakeClient, owner, &gameserverv1alpha1.Base{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).NotTo(BeEmpty())
+			Expect(ref.Name).To(Equal("test-server-editor"))
+			Expect(ref.Key).To(Equal(EditorPasswordKey))
+
+			secret := getSecret()
+			Expect(secret.Data[EditorPasswordKey]).NotTo(BeEmpty())
+			Expect(secret.OwnerReferences).To(HaveLen(1))
+		})
+
+		It("should keep a generated password across reconciles", func() {
+			_, version, err := ReconcileEditorSecret(ctx, fakeClient, owner, &gameserverv1alpha1.Base{})
+			Expect(err).NotTo(HaveOccurred())
+			generated := getSecret().Data[EditorPasswordKey]
+
+			_, unchanged, err := ReconcileEditorSecret(ctx, fakeClient, owner, &gameserverv1alpha1.Base{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getSecret().Data[EditorPasswordKey]).To(Equal(generated))
+			Expect(unchanged).To(Equal(version))
+		})
+
+		It("should store and sync an explicit editorPassword", func() {
+			_, first, err := ReconcileEditorSecret(ctx, fakeClient, owner, &gameserverv1alpha1.Base{EditorPassword: "first"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(getSecret().Data[EditorPasswordKey])).To(Equal("first"))
+
+			// The changed version restarts code-server with the new password
+			_, second, err := ReconcileEditorSecret(ctx, fakeClient, owner, &gameserverv1alpha1.Base{EditorPassword: "second"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(getSecret().Data[EditorPasswordKey])).To(Equal("second"))
+			Expect(second).NotTo(Equal(first))
+		})
+
+		It("should use editorPasswordSecretRef without creating a Secret", func() {
+			ref, _, err := ReconcileEditorSecret(ctx, fakeClient, owner, &gameserverv1alpha1.Base{
+				EditorPasswordSecretRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "my-secret"},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ref.Name).To(Equal("my-secret"))
+			Expect(ref.Key).To(Equal(EditorPasswordKey))
+
+			secrets := &corev1.SecretList{}
+			Expect(fakeClient.List(ctx, secrets)).To(Succeed())
+			Expect(secrets.Items).To(BeEmpty())
+		})
+
+		It("should remove the generated Secret once it is no longer used", func() {
+			_, _, err := ReconcileEditorSecret(ctx, fakeClient, owner, &gameserverv1alpha1.Base{})
+			Expect(err).NotTo(HaveOccurred())
+
+			external := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "my-secret", Namespace: "default"}}
+			Expect(fakeClient.Create(ctx, external)).To(Succeed())
+			ref, version, err := ReconcileEditorSecret(ctx, fakeClient, owner, &gameserverv1alpha1.Base{
+				EditorPasswordSecretRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "my-secret"},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ref.Name).To(Equal("my-secret"))
+			Expect(version).To(Equal(external.ResourceVersion))
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "test-server-editor", Namespace: "default"}, &corev1.Secret{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			_, _, err = ReconcileEditorSecret(ctx, fakeClient, owner, &gameserverv1alpha1.Base{})
+			Expect(err).NotTo(HaveOccurred())
+			disabled := false
+			ref, _, err = ReconcileEditorSecret(ctx, fakeClient, owner, &gameserverv1alpha1.Base{
+				Editor: gameserverv1alpha1.Editor{Enabled: &disabled},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ref).To(BeNil())
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "test-server-editor", Namespace: "default"}, &corev1.Secret{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Describe("ReconcilePVC", func() {
+		It("should expand the volume when the size grows and keep it when it shrinks", func() {
+			ctx := context.Background()
+			fakeClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+			owner := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-server", Namespace: "default", UID: "test-uid"},
+			}
+			persistence := &gameserverv1alpha1.Persistence{StorageConfig: gameserverv1alpha1.StorageConfig{Size: "10G"}}
+			storage := func() string {
+				pvc := &corev1.PersistentVolumeClaim{}
+				Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "test-server-pvc", Namespace: "default"}, pvc)).To(Succeed())
+				size := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+				return size.String()
+			}
+
+			Expect(ReconcilePVC(ctx, fakeClient, owner, persistence)).To(Succeed())
+			Expect(storage()).To(Equal("10G"))
+
+			persistence.StorageConfig.Size = "20G"
+			Expect(ReconcilePVC(ctx, fakeClient, owner, persistence)).To(Succeed())
+			Expect(storage()).To(Equal("20G"))
+
+			persistence.StorageConfig.Size = "15G"
+			Expect(ReconcilePVC(ctx, fakeClient, owner, persistence)).To(Succeed())
+			Expect(storage()).To(Equal("20G"))
+		})
+	})
+
+	Describe("ReconcileServices", func() {
+		It("should not publish the code-server port on the game LoadBalancer", func() {
+			ctx := context.Background()
+			fakeClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+			owner := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-server", Namespace: "default", UID: "test-uid"},
+			}
+			ports := []corev1.ServicePort{
+				{Name: "game-tcp", Port: 27016, TargetPort: intstr.FromInt32(27016), Protocol: corev1.ProtocolTCP},
+				{Name: "game-udp", Port: 2302, TargetPort: intstr.FromInt32(2302), Protocol: corev1.ProtocolUDP},
+			}
+
+			Expect(ReconcileServices(ctx, fakeClient, owner, ports, "", nil, map[string]string{"app": "test-server"})).To(Succeed())
+
+			tcp := &corev1.Service{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "test-server-tcp", Namespace: "default"}, tcp)).To(Succeed())
+			Expect(tcp.Spec.Ports).To(HaveLen(1))
+			Expect(tcp.Spec.Ports[0].Port).To(Equal(int32(27016)))
+		})
+
+		It("should remove ports that are no longer desired from existing Services", func() {
+			ctx := context.Background()
+			owner := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-server", Namespace: "default", UID: "test-uid"},
+			}
+			existing := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-server-tcp", Namespace: "default"},
+				Spec: corev1.ServiceSpec{
+					Type: corev1.ServiceTypeLoadBalancer,
+					Ports: []corev1.ServicePort{
+						{Name: "game-tcp", Port: 27016, TargetPort: intstr.FromInt32(27016), Protocol: corev1.ProtocolTCP, NodePort: 30001},
+						{Name: "code-server", Port: 8080, TargetPort: intstr.FromInt32(8080), Protocol: corev1.ProtocolTCP, NodePort: 30002},
+					},
+				},
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(existing).Build()
+			ports := []corev1.ServicePort{
+				{Name: "game-tcp", Port: 27016, TargetPort: intstr.FromInt32(27016), Protocol: corev1.ProtocolTCP},
+			}
+
+			Expect(ReconcileServices(ctx, fakeClient, owner, ports, "", nil, map[string]string{"app": "test-server"})).To(Succeed())
+
+			tcp := &corev1.Service{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "test-server-tcp", Namespace: "default"}, tcp)).To(Succeed())
+			Expect(tcp.Spec.Ports).To(HaveLen(1))
+			Expect(tcp.Spec.Ports[0].NodePort).To(Equal(int32(30001)))
+		})
+		It("should delete the owned TCP Service once no TCP port is left", func() {
+			ctx := context.Background()
+			owner := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-server", Namespace: "default", UID: "test-uid"},
+			}
+			existing := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-server-tcp", Namespace: "default"},
+				Spec: corev1.ServiceSpec{
+					Type:  corev1.ServiceTypeLoadBalancer,
+					Ports: []corev1.ServicePort{{Name: "code-server", Port: 8080, TargetPort: intstr.FromInt32(8080), Protocol: corev1.ProtocolTCP}},
+				},
+			}
+			Expect(controllerutil.SetControllerReference(owner, existing, clientgoscheme.Scheme)).To(Succeed())
+			fakeClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(existing).Build()
+			ports := []corev1.ServicePort{
+				{Name: "game-udp", Port: 2302, TargetPort: intstr.FromInt32(2302), Protocol: corev1.ProtocolUDP},
+			}
+
+			Expect(ReconcileServices(ctx, fakeClient, owner, ports, "", nil, map[string]string{"app": "test-server"})).To(Succeed())
+
+			err := fakeClient.Get(ctx, types.NamespacedName{Name: "test-server-tcp", Namespace: "default"}, &corev1.Service{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should keep a TCP Service it does not control", func() {
+			ctx := context.Background()
+			owner := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-server", Namespace: "default", UID: "test-uid"},
+			}
+			existing := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-server-tcp", Namespace: "default"},
+				Spec: corev1.ServiceSpec{
+					Type:  corev1.ServiceTypeLoadBalancer,
+					Ports: []corev1.ServicePort{{Name: "web", Port: 80, TargetPort: intstr.FromInt32(80), Protocol: corev1.ProtocolTCP}},
+				},
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(existing).Build()
+			ports := []corev1.ServicePort{
+				{Name: "game-udp", Port: 2302, TargetPort: intstr.FromInt32(2302), Protocol: corev1.ProtocolUDP},
+			}
+
+			Expect(ReconcileServices(ctx, fakeClient, owner, ports, "", nil, map[string]string{"app": "test-server"})).To(Succeed())
+
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "test-server-tcp", Namespace: "default"}, &corev1.Service{})).To(Succeed())
+		})
+
+		It("should switch the Services to a new pod selector", func() {
+			ctx := context.Background()
+			fakeClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+			owner := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-server", Namespace: "default", UID: "test-uid"},
+			}
+			ports := []corev1.ServicePort{
+				{Name: "game-udp", Port: 2302, TargetPort: intstr.FromInt32(2302), Protocol: corev1.ProtocolUDP},
+			}
+
+			Expect(ReconcileServices(ctx, fakeClient, owner, ports, "", nil, map[string]string{"app": "test-server"})).To(Succeed())
+			Expect(ReconcileServices(ctx, fakeClient, owner, ports, "", nil, map[string]string{"app": "test-server-wake-proxy"})).To(Succeed())
+
+			udp := &corev1.Service{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "test-server-udp", Namespace: "default"}, udp)).To(Succeed())
+			Expect(udp.Spec.Selector).To(Equal(map[string]string{"app": "test-server-wake-proxy"}))
+		})
+
+		It("should add the annotations and keep the annotations of others", func() {
+			ctx := context.Background()
+			existing := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-server-udp", Namespace: "default",
+					Annotations: map[string]string{"cloud.example.com/assigned": "true"},
+				},
+				Spec: corev1.ServiceSpec{
+					Type:     corev1.ServiceTypeLoadBalancer,
+					Selector: map[string]string{"app": "test-server"},
+					Ports:    []corev1.ServicePort{{Name: "game-udp", Port: 2302, TargetPort: intstr.FromInt32(2302), Protocol: corev1.ProtocolUDP}},
+				},
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(existing).Build()
+			owner := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-server", Namespace: "default", UID: "test-uid"},
+			}
+
+			Expect(ReconcileServices(ctx, fakeClient, owner, existing.Spec.Ports, "",
+				map[string]string{"metallb.universe.tf/address-pool": "games"}, map[string]string{"app": "test-server"})).To(Succeed())
+
+			udp := &corev1.Service{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "test-server-udp", Namespace: "default"}, udp)).To(Succeed())
+			Expect(udp.Annotations).To(Equal(map[string]string{
+				"cloud.example.com/assigned":       "true",
+				"metallb.universe.tf/address-pool": "games",
+			}))
+		})
+	})
+
+	Describe("ReconcileEditorExposure", func() {
+		var (
+			ctx        context.Context
+			fakeClient client.Client
+			owner      *corev1.ConfigMap
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			fakeClient = fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+			owner = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-server", Namespace: "default", UID: "test-uid"},
+			}
+		})
+
+		editorKey := types.NamespacedName{Name: "test-server-editor", Namespace: "default"}
+
+		It("should expose the editor through a ClusterIP Service by default", func() {
+			Expect(ReconcileEditorExposure(ctx, fakeClient, owner, &gameserverv1alpha1.Editor{})).To(Succeed())
+
+			service := &corev1.Service{}
+			Expect(fakeClient.Get(ctx, editorKey, service)).To(Succeed())
+			Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+			Expect(service.Spec.Ports[0].Port).To(Equal(int32(CodeServerPort)))
+
+			Expect(fakeClient.Get(ctx, editorKey, &networkingv1.Ingress{})).NotTo(Succeed())
+		})
+
+		It("should restore an edited editor Service", func() {
+			Expect(ReconcileEditorExposure(ctx, fakeClient, owner, &gameserverv1alpha1.Editor{})).To(Succeed())
+			service := &corev1.Service{}
+			Expect(fakeClient.Get(ctx, editorKey, service)).To(Succeed())
+			service.Spec.Selector = map[string]string{"app": "other"}
+			service.Spec.Ports[0].TargetPort = intstr.FromInt32(9090)
+			Expect(fakeClient.Update(ctx, service)).To(Succeed())
+
+			Expect(ReconcileEditorExposure(ctx, fakeClient, owner, &gameserverv1alpha1.Editor{})).To(Succeed())
+			Expect(fakeClient.Get(ctx, editorKey, service)).To(Succeed())
+			Expect(service.Spec.Selector).To(Equal(map[string]string{"app": "test-server"}))
+			Expect(service.Spec.Ports[0].TargetPort.IntValue()).To(Equal(CodeServerPort))
+		})
+
+		It("should create an Ingress with TLS when requested", func() {
+			editor := &gameserverv1alpha1.Editor{
+				Exposure: gameserverv1alpha1.EditorExposureIngress,
+				Ingress: &gameserverv1alpha1.EditorIngress{
+					Host:          "editor.example.com",
+					TLSSecretName: "editor-tls",
+				},
+			}
+			Expect(ReconcileEditorExposure(ctx, fakeClient, owner, editor)).To(Succeed())
+
+			ingress := &networkingv1.Ingress{}
+			Expect(fakeClient.Get(ctx, editorKey, ingress)).To(Succeed())
+			Expect(ingress.Spec.Rules[0].Host).To(Equal("editor.example.com"))
+			Expect(ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name).To(Equal("test-server-editor"))
+			Expect(ingress.Spec.TLS[0].SecretName).To(Equal("editor-tls"))
+		})
+
+		It("should reject Ingress exposure without a host", func() {
+			editor := &gameserverv1alpha1.Editor{Exposure: gameserverv1alpha1.EditorExposureIngress}
+			Expect(ReconcileEditorExposure(ctx, fakeClient, owner, editor)).NotTo(Succeed())
+		})
+
+		It("should remove the Service and Ingress when the editor is disabled", func() {
+			editor := &gameserverv1alpha1.Editor{
+				Exposure: gameserverv1alpha1.EditorExposureIngress,
+				Ingress:  &gameserverv1alpha1.EditorIngress{Host: "editor.example.com"},
+			}
+			Expect(ReconcileEditorExposure(ctx, fakeClient, owner, editor)).To(Succeed())
+
+			disabled := false
+			editor.Enabled = &disabled
+			Expect(ReconcileEditorExposure(ctx, fakeClient, owner, editor)).To(Succeed())
+
+			Expect(fakeClient.Get(ctx, editorKey, &corev1.Service{})).NotTo(Succeed())
+			Expect(fakeClient.Get(ctx, editorKey, &networkingv1.Ingress{})).NotTo(Succeed())
+		})
+	})
+})
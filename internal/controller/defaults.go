@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+)
+
+const (
+	// ConditionDefaultsApplied is true when the GameServerTemplate and GameServerClass of the game server
+	// are merged into its effective spec
+	ConditionDefaultsApplied = "DefaultsApplied"
+
+	// Reasons of the DefaultsApplied condition
+	ReasonDefaultsApplied  = "Applied"
+	ReasonTemplateNotFound = "TemplateNotFound"
+	ReasonClassNotFound    = "ClassNotFound"
+)
+
+// ResolveBase returns the effective Base of a game server in the namespace of owner: the fields of
+// the GameServerTemplate of base, overridden by the fields of the GameServerClass, overridden by the
+// fields set in base. A field counts as set when it is not its zero value, struct fields are merged
+// field by field. A missing template or class is reported in the returned DefaultsApplied condition
+// and leaves out its layer.
+func ResolveBase(ctx context.Context, c client.Client, owner client.Object, base *gameserverv1alpha1.Base) (*gameserverv1alpha1.Base, metav1.Condition, error) {
+	effective := base.DeepCopy()
+	condition := metav1.Condition{
+		Type:               ConditionDefaultsApplied,
+		Status:             metav1.ConditionTrue,
+		Reason:             ReasonDefaultsApplied,
+		ObservedGeneration: owner.GetGeneration(),
+	}
+	var applied []string
+
+	var template *gameserverv1alpha1.GameServerTemplate
+	if base.TemplateRef != nil {
+		template = &gameserverv1alpha1.GameServerTemplate{}
+		err := c.Get(ctx, types.NamespacedName{Namespace: owner.GetNamespace(), Name: base.TemplateRef.Name}, template)
+		if errors.IsNotFound(err) {
+			condition.Status, condition.Reason = metav1.ConditionFalse, ReasonTemplateNotFound
+			condition.Message = fmt.Sprintf("GameServerTemplate %s not found", base.TemplateRef.Name)
+			template = nil
+		} else if err != nil {
+			return nil, condition, err
+		} else {
+			applied = append(applied, "GameServerTemplate "+template.Name)
+		}
+	}
+
+	if effective.ClassName == "" && template != nil {
+		effective.ClassName = template.Spec.ClassName
+	}
+	if effective.ClassName != "" {
+		class := &gameserverv1alpha1.GameServerClass{}
+		err := c.Get(ctx, types.NamespacedName{Name: effective.ClassName}, class)
+		if errors.IsNotFound(err) {
+			if condition.Status == metav1.ConditionTrue {
+				condition.Status, condition.Reason = metav1.ConditionFalse, ReasonClassNotFound
+				condition.Message = fmt.Sprintf("GameServerClass %s not found", effective.ClassName)
+			}
+		} else if err != nil {
+			return nil, condition, err
+		} else {
+			mergeUnset(reflect.ValueOf(effective).Elem(), reflect.ValueOf(classLayer(class)).Elem())
+			applied = append(applied, "GameServerClass "+class.Name)
+		}
+	}
+
+	if template != nil {
+		layer := template.Spec.Base.DeepCopy()
+		layer.TemplateRef = nil
+		mergeUnset(reflect.ValueOf(effective).Elem(), reflect.ValueOf(layer).Elem())
+	}
+
+	if condition.Status == metav1.ConditionTrue {
+		condition.Message = "The spec is used as is"
+		if len(applied) > 0 {
+			condition.Message = "Merged the defaults of " + strings.Join(applied, " and ")
+		}
+	}
+	return effective, condition, nil
+}
+
+// UpdateDefaultsStatus records the effective spec and the DefaultsApplied condition returned by
+// ResolveBase in status
+func UpdateDefaultsStatus(status *gameserverv1alpha1.BaseStatus, effective *gameserverv1alpha1.Base, condition metav1.Condition) error {
+	raw, err := json.Marshal(effective)
+	if err != nil {
+		return err
+	}
+	status.EffectiveSpec = &apiextensionsv1.JSON{Raw: raw}
+	meta.SetStatusCondition(&status.Conditions, condition)
+	return nil
+}
+
+// classLayer returns the fields of class as a Base to merge
+func classLayer(class *gameserverv1alpha1.GameServerClass) *gameserverv1alpha1.Base {
+	spec := class.Spec.DeepCopy()
+	layer := &gameserverv1alpha1.Base{
+		NodeSelector:       spec.Scheduling.NodeSelector,
+		Tolerations:        spec.Scheduling.Tolerations,
+		Affinity:           spec.Scheduling.Affinity,
+		Annotations:        spec.Scheduling.PodAnnotations,
+		ServiceAnnotations: spec.Exposure.ServiceAnnotations,
+		Editor: gameserverv1alpha1.Editor{
+			Enabled:  spec.Editor.Enabled,
+			Exposure: spec.Editor.Exposure,
+		},
+	}
+	layer.Persistence.StorageConfig.StorageClassName = spec.StorageClassName
+	return layer
+}
+
+// mergeUnset sets the fields of dst left at their zero value to the fields of src. Structs, also behind
+// pointers set in both, are merged field by field, maps and lists are replaced as a whole.
+func mergeUnset(dst, src reflect.Value) {
+	switch dst.Kind() {
+	case reflect.Struct:
+		for i := 0; i < dst.NumField(); i++ {
+			if dst.Field(i).CanSet() {
+				mergeUnset(dst.Field(i), src.Field(i))
+			}
+		}
+	case reflect.Pointer:
+		if dst.IsNil() {
+			dst.Set(src)
+		} else if !src.IsNil() && dst.Elem().Kind() == reflect.Struct {
+			mergeUnset(dst.Elem(), src.Elem())
+		}
+	default:
+		if dst.IsZero() {
+			dst.Set(src)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+)
+
+var _ = Describe("Defaults", func() {
+	ctx := context.Background()
+	owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "templated", Namespace: "games", Generation: 2}}
+	enabled, preserve := false, true
+
+	template := &gameserverv1alpha1.GameServerTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "dayz-event", Namespace: "games"},
+		Spec: gameserverv1alpha1.GameServerTemplateSpec{Base: gameserverv1alpha1.Base{
+			ClassName: "dedicated",
+			Ports:     []corev1.ServicePort{{Name: "game-udp", Port: 2302, Protocol: corev1.ProtocolUDP}},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("8Gi")},
+				Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("16Gi")},
+			},
+			Tolerations: []corev1.Toleration{{Key: "template"}},
+			Shutdown:    gameserverv1alpha1.Shutdown{GracePeriodSeconds: 300, WarningSeconds: []int32{60}},
+			Persistence: gameserverv1alpha1.Persistence{PreserveOnDelete: &preserve},
+		}},
+	}
+	class := &gameserverv1alpha1.GameServerClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "dedicated"},
+		Spec: gameserverv1alpha1.GameServerClassSpec{
+			Scheduling:       gameserverv1alpha1.GameServerClassScheduling{Tolerations: []corev1.Toleration{{Key: "dedicated"}}},
+			StorageClassName: "fast-ssd",
+			Exposure:         gameserverv1alpha1.GameServerClassExposure{ServiceAnnotations: map[string]string{"metallb.universe.tf/address-pool": "games"}},
+			Editor:           gameserverv1alpha1.GameServerClassEditor{Enabled: &enabled},
+		},
+	}
+	resolve := func(base *gameserverv1alpha1.Base, objects ...client.Object) (*gameserverv1alpha1.Base, metav1.Condition) {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(gameserverv1alpha1.AddToScheme(scheme)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+		effective, condition, err := ResolveBase(ctx, c, owner, base)
+		Expect(err).NotTo(HaveOccurred())
+		return effective, condition
+	}
+
+	It("should merge the template, then the class, then the spec", func() {
+		base := &gameserverv1alpha1.Base{
+			TemplateRef: &corev1.LocalObjectReference{Name: "dayz-event"},
+			Resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("24Gi")},
+			},
+			Shutdown: gameserverv1alpha1.Shutdown{GracePeriodSeconds: 120},
+		}
+
+		effective, condition := resolve(base, template, class)
+
+		Expect(effective.TemplateRef).To(Equal(base.TemplateRef))
+		Expect(effective.ClassName).To(Equal("dedicated"))
+		Expect(effective.Ports).To(Equal(template.Spec.Ports))
+		Expect(effective.Tolerations).To(Equal([]corev1.Toleration{{Key: "dedicated"}}))
+		Expect(effective.Persistence.StorageConfig.StorageClassName).To(Equal("fast-ssd"))
+		Expect(*effective.Persistence.PreserveOnDelete).To(BeTrue())
+		Expect(effective.ServiceAnnotations).To(HaveKeyWithValue("metallb.universe.tf/address-pool", "games"))
+		Expect(*effective.Editor.Enabled).To(BeFalse())
+		Expect(effective.Resources.Limits.Memory().String()).To(Equal("24Gi"))
+		Expect(effective.Resources.Requests.Memory().String()).To(Equal("8Gi"))
+		Expect(effective.Shutdown.GracePeriodSeconds).To(Equal(int32(120)))
+		Expect(effective.Shutdown.WarningSeconds).To(Equal([]int32{60}))
+
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Message).To(Equal("Merged the defaults of GameServerTemplate dayz-event and GameServerClass dedicated"))
+		Expect(condition.ObservedGeneration).To(Equal(int64(2)))
+		Expect(base.Ports).To(BeEmpty(), "the spec is not modified")
+	})
+
+	It("should prefer the class of the spec over the class of the template", func() {
+		other := class.DeepCopy()
+		other.Name = "shared"
+		other.Spec.StorageClassName = "standard"
+
+		effective, _ := resolve(&gameserverv1alpha1.Base{
+			TemplateRef: &corev1.LocalObjectReference{Name: "dayz-event"},
+			ClassName:   "shared",
+		}, template, class, other)
+
+		Expect(effective.ClassName).To(Equal("shared"))
+		Expect(effective.Persistence.StorageConfig.StorageClassName).To(Equal("standard"))
+	})
+
+	It("should let the spec turn off a flag set by the template", func() {
+		effective, _ := resolve(&gameserverv1alpha1.Base{
+			TemplateRef: &corev1.LocalObjectReference{Name: "dayz-event"},
+			Persistence: gameserverv1alpha1.Persistence{PreserveOnDelete: &enabled},
+		}, template, class)
+		Expect(*effective.Persistence.PreserveOnDelete).To(BeFalse())
+	})
+
+	It("should report a missing template and class", func() {
+		effective, condition := resolve(&gameserverv1alpha1.Base{
+			TemplateRef: &corev1.LocalObjectReference{Name: "dayz-event"},
+			ClassName:   "dedicated",
+		}, class)
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(ReasonTemplateNotFound))
+		Expect(effective.Tolerations).To(Equal(class.Spec.Scheduling.Tolerations), "the class still applies")
+
+		_, condition = resolve(&gameserverv1alpha1.Base{ClassName: "dedicated"})
+		Expect(condition.Reason).To(Equal(ReasonClassNotFound))
+		Expect(condition.Message).To(Equal("GameServerClass dedicated not found"))
+	})
+
+	It("should record the effective spec in status", func() {
+		effective, condition := resolve(&gameserverv1alpha1.Base{Paused: &preserve})
+		Expect(condition.Message).To(Equal("The spec is used as is"))
+
+		status := &gameserverv1alpha1.BaseStatus{}
+		Expect(UpdateDefaultsStatus(status, effective, condition)).To(Succeed())
+		recorded := &gameserverv1alpha1.Base{}
+		Expect(json.Unmarshal(status.EffectiveSpec.Raw, recorded)).To(Succeed())
+		Expect(*recorded.Paused).To(BeTrue())
+		Expect(meta.IsStatusConditionTrue(status.Conditions, ConditionDefaultsApplied)).To(BeTrue())
+	})
+})
@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+)
+
+//+kubebuilder:webhook:path=/validate-gameserver-templarfelix-com-v1alpha1-gameservertemplate,mutating=false,failurePolicy=fail,sideEffects=None,groups=gameserver.templarfelix.com,resources=gameservertemplates,verbs=create;update,versions=v1alpha1,name=vgameservertemplate.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-gameserver-templarfelix-com-v1alpha1-gameserverclass,mutating=false,failurePolicy=fail,sideEffects=None,groups=gameserver.templarfelix.com,resources=gameserverclasses,verbs=create;update,versions=v1alpha1,name=vgameserverclass.kb.io,admissionReviewVersions=v1
+
+// GameServerTemplateValidator rejects templates with fields no game server could use. Fields depending
+// on each other are checked on the servers, whose spec and class may complete them.
+type GameServerTemplateValidator struct{}
+
+// GameServerClassValidator rejects classes with invalid labels or annotations
+type GameServerClassValidator struct{}
+
+var _ admission.CustomValidator = &GameServerTemplateValidator{}
+var _ admission.CustomValidator = &GameServerClassValidator{}
+
+// SetupWebhookWithManager registers the validating webhook for GameServerTemplates
+func (v *GameServerTemplateValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&gameserverv1alpha1.GameServerTemplate{}).
+		WithValidator(v).
+		Complete()
+}
+
+// ValidateCreate implements admission.CustomValidator
+func (v *GameServerTemplateValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validate(obj)
+}
+
+// ValidateUpdate implements admission.CustomValidator
+func (v *GameServerTemplateValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validate(newObj)
+}
+
+// ValidateDelete implements admission.CustomValidator
+func (v *GameServerTemplateValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *GameServerTemplateValidator) validate(obj runtime.Object) error {
+	template, ok := obj.(*gameserverv1alpha1.GameServerTemplate)
+	if !ok {
+		return fmt.Errorf("expected a GameServerTemplate object but got %T", obj)
+	}
+	allErrs := ValidateBaseFields(&template.Spec.Base, field.NewPath("spec"))
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(gameserverv1alpha1.GroupVersion.WithKind("GameServerTemplate").GroupKind(), template.Name, allErrs)
+}
+
+// SetupWebhookWithManager registers the validating webhook for GameServerClasses
+func (v *GameServerClassValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&gameserverv1alpha1.GameServerClass{}).
+		WithValidator(v).
+		Complete()
+}
+
+// ValidateCreate implements admission.CustomValidator
+func (v *GameServerClassValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validate(obj)
+}
+
+// ValidateUpdate implements admission.CustomValidator
+func (v *GameServerClassValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validate(newObj)
+}
+
+// ValidateDelete implements admission.CustomValidator
+func (v *GameServerClassValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *GameServerClassValidator) validate(obj runtime.Object) error {
+	class, ok := obj.(*gameserverv1alpha1.GameServerClass)
+	if !ok {
+		return fmt.Errorf("expected a GameServerClass object but got %T", obj)
+	}
+	specPath := field.NewPath("spec")
+	schedulingPath := specPath.Child("scheduling")
+	allErrs := metav1validation.ValidateLabels(class.Spec.Scheduling.NodeSelector, schedulingPath.Child("nodeSelector"))
+	allErrs = append(allErrs, apivalidation.ValidateAnnotations(class.Spec.Scheduling.PodAnnotations, schedulingPath.Child("podAnnotations"))...)
+	allErrs = append(allErrs, apivalidation.ValidateAnnotations(class.Spec.Exposure.ServiceAnnotations, specPath.Child("exposure", "serviceAnnotations"))...)
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(gameserverv1alpha1.GroupVersion.WithKind("GameServerClass").GroupKind(), class.Name, allErrs)
+}
@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+	"github.com/templarfelix/gameserver-operator/internal/economy"
+)
+
+const (
+	// EconomyContainerName is the init container merging the economy patches into the mission
+	EconomyContainerName = "economy"
+
+	// ConditionEconomyMerged is true when the economy and gameplay patches were applied at the last start of
+	// the game pod
+	ConditionEconomyMerged = "EconomyMerged"
+
+	// ReasonEconomyPending means the economy init container did not run yet
+	ReasonEconomyPending = "Pending"
+)
+
+// EconomyPatches are the patches the economy init container applies to the files of a mission, a
+// file without patches is left alone
+type EconomyPatches struct {
+	// TypesFile is the types.xml file Types are merged into
+	TypesFile string
+	Types     []economy.TypePatch
+
+	// GameplayFile is the cfggameplay.json file the JSON patch Gameplay is applied to
+	GameplayFile string
+	Gameplay     json.RawMessage
+}
+
+// GetEconomyInitContainer returns the init container applying patches to the files of a mission. It
+// fails the pod when a file is malformed, see UpdateEconomyCondition.
+func GetEconomyInitContainer(image string, patches EconomyPatches) (corev1.Container, error) {
+	if image == "" {
+		image = DefaultOperatorImage
+	}
+	var args []string
+	if len(patches.Types) > 0 {
+		types, err := json.Marshal(patches.Types)
+		if err != nil {
+			return corev1.Container{}, err
+		}
+		args = append(args, "--types-file="+patches.TypesFile, "--types="+string(types))
+	}
+	if len(patches.Gameplay) > 0 {
+		args = append(args, "--gameplay-file="+patches.GameplayFile, "--gameplay="+string(patches.Gameplay))
+	}
+	return corev1.Container{
+		Name:                     EconomyContainerName,
+		Image:                    image,
+		Command:                  []string{"/economy"},
+		Args:                     args,
+		TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+		SecurityContext: &corev1.SecurityContext{
+			RunAsUser:                func(i int64) *int64 { return &i }(1000),
+			RunAsGroup:               func(i int64) *int64 { return &i }(1000),
+			RunAsNonRoot:             func(b bool) *bool { return &b }(true),
+			AllowPrivilegeEscalation: func(b bool) *bool { return &b }(false),
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: DataVolumeName, MountPath: DataMountPath},
+		},
+	}, nil
+}
+
+// UpdateEconomyCondition sets the EconomyMerged condition from the economy init container of the
+// newest game pod of owner. The condition is removed when the game server has no economy patches and
+// kept while no pod reports a merge.
+func UpdateEconomyCondition(ctx context.Context, c client.Client, owner client.Object, status *gameserverv1alpha1.BaseStatus, enabled bool) error {
+	if !enabled {
+		meta.RemoveStatusCondition(&status.Conditions, ConditionEconomyMerged)
+		return nil
+	}
+
+	pods := &corev1.PodList{}
+	if err := c.List(ctx, pods, client.InNamespace(owner.GetNamespace()), client.MatchingLabels{"app": owner.GetName()}); err != nil {
+		return err
+	}
+	var newest *corev1.Pod
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp == nil && (newest == nil || newest.CreationTimestamp.Before(&pod.CreationTimestamp)) {
+			newest = pod
+		}
+	}
+
+	var terminated *corev1.ContainerStateTerminated
+	if newest != nil {
+		for _, containerStatus := range newest.Status.InitContainerStatuses {
+			if containerStatus.Name != EconomyContainerName {
+				continue
+			}
+			// A failed container waits for its restart, its failure is the last termination
+			terminated = containerStatus.State.Terminated
+			if terminated == nil {
+				terminated = containerStatus.LastTerminationState.Terminated
+			}
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:               ConditionEconomyMerged,
+		Status:             metav1.ConditionUnknown,
+		Reason:             ReasonEconomyPending,
+		Message:            "The economy patches are merged when the game pod starts",
+		ObservedGeneration: owner.GetGeneration(),
+	}
+	if terminated == nil {
+		if meta.FindStatusCondition(status.Conditions, ConditionEconomyMerged) == nil {
+			meta.SetStatusCondition(&status.Conditions, condition)
+		}
+		return nil
+	}
+
+	condition.Reason, condition.Message = economy.ParseReport(terminated.Message)
+	condition.Status = metav1.ConditionFalse
+	if terminated.ExitCode == 0 && condition.Reason == economy.ReasonMerged {
+		condition.Status = metav1.ConditionTrue
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+	return nil
+}
@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+	"github.com/templarfelix/gameserver-operator/internal/economy"
+)
+
+var _ = Describe("Economy", func() {
+	ctx := context.Background()
+	owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "looted", Namespace: "default", Generation: 3}}
+
+	economyPod := func(name string, created time.Time, status corev1.ContainerStatus) *corev1.Pod {
+		status.Name = EconomyContainerName
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name, Namespace: "default", Labels: map[string]string{"app": "looted"},
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Status: corev1.PodStatus{InitContainerStatuses: []corev1.ContainerStatus{status}},
+		}
+	}
+	terminated := func(exitCode int32, message string) corev1.ContainerState {
+		return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode, Message: message}}
+	}
+	update := func(status *gameserverv1alpha1.BaseStatus, enabled bool, objects ...client.Object) *metav1.Condition {
+		c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(objects...).Build()
+		Expect(UpdateEconomyCondition(ctx, c, owner, status, enabled)).To(Succeed())
+		return meta.FindStatusCondition(status.Conditions, ConditionEconomyMerged)
+	}
+
+	It("should merge the patches with the economy binary", func() {
+		nominal := int32(2)
+		container, err := GetEconomyInitContainer("", EconomyPatches{
+			TypesFile: "/data/serverfiles/mpmissions/dayzOffline.enoch/db/types.xml",
+			Types:     []economy.TypePatch{{Name: "AKM", Nominal: &nominal}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(container.Image).To(Equal(DefaultOperatorImage))
+		Expect(container.Command).To(Equal([]string{"/economy"}))
+		Expect(container.Args).To(Equal([]string{
+			"--types-file=/data/serverfiles/mpmissions/dayzOffline.enoch/db/types.xml",
+			`--types=[{"name":"AKM","nominal":2}]`,
+		}))
+		Expect(*container.SecurityContext.RunAsUser).To(Equal(int64(1000)))
+	})
+
+	It("should only pass the files with patches", func() {
+		container, err := GetEconomyInitContainer("registry.example.com/operator:v1", EconomyPatches{
+			TypesFile:    "/data/serverfiles/mpmissions/dayzOffline.enoch/db/types.xml",
+			GameplayFile: "/data/serverfiles/mpmissions/dayzOffline.enoch/cfggameplay.json",
+			Gameplay:     []byte(`[{"op":"replace","path":"/GeneralData/disableBaseDamage","value":true}]`),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(container.Image).To(Equal("registry.example.com/operator:v1"))
+		Expect(container.Args).To(Equal([]string{
+			"--gameplay-file=/data/serverfiles/mpmissions/dayzOffline.enoch/cfggameplay.json",
+			`--gameplay=[{"op":"replace","path":"/GeneralData/disableBaseDamage","value":true}]`,
+		}))
+	})
+
+	It("should report the merge of the newest pod", func() {
+		now := time.Now()
+		status := &gameserverv1alpha1.BaseStatus{}
+
+		condition := update(status, true,
+			economyPod("old", now.Add(-time.Hour), corev1.ContainerStatus{State: terminated(1, "MalformedXML: types.xml: line 3: broken")}),
+			economyPod("new", now, corev1.ContainerStatus{State: terminated(0, "Merged: types.xml: 1 types updated, 0 added")}),
+		)
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(economy.ReasonMerged))
+		Expect(condition.Message).To(Equal("types.xml: 1 types updated, 0 added"))
+		Expect(condition.ObservedGeneration).To(Equal(int64(3)))
+	})
+
+	It("should report malformed XML while the init container restarts", func() {
+		status := &gameserverv1alpha1.BaseStatus{}
+
+		condition := update(status, true, economyPod("crashing", time.Now(), corev1.ContainerStatus{
+			State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+			LastTerminationState: terminated(1, "MalformedXML: types.xml: line 3: element <type> closed by </types>"),
+		}))
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(economy.ReasonMalformedXML))
+		Expect(condition.Message).To(ContainSubstring("line 3"))
+	})
+
+	It("should not report a mission which is not installed yet as merged", func() {
+		status := &gameserverv1alpha1.BaseStatus{}
+
+		condition := update(status, true, economyPod("first", time.Now(), corev1.ContainerStatus{
+			State: terminated(0, "MissionNotInstalled: types.xml does not exist yet, it is patched on the next start"),
+		}))
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(economy.ReasonMissionNotInstalled))
+	})
+
+	It("should keep the last report without pods and remove it without patches", func() {
+		status := &gameserverv1alpha1.BaseStatus{}
+		Expect(update(status, true).Reason).To(Equal(ReasonEconomyPending))
+
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{Type: ConditionEconomyMerged, Status: metav1.ConditionTrue, Reason: economy.ReasonMerged})
+		Expect(update(status, true).Reason).To(Equal(economy.ReasonMerged))
+
+		Expect(update(status, false)).To(BeNil())
+	})
+})
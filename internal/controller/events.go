@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+)
+
+// Reasons of the Events recorded on game servers
+const (
+	// EventReasonCreated means the Deployment of the game server was created
+	EventReasonCreated = "Created"
+	// EventReasonConfigApplied means a changed pod template was applied to the Deployment
+	EventReasonConfigApplied = "ConfigApplied"
+	// EventReasonRestarting means the game pod is replaced by a scheduled restart
+	EventReasonRestarting = "Restarting"
+	// EventReasonPVCPreserved means the volume is kept after the game server was deleted
+	EventReasonPVCPreserved = "PVCPreserved"
+	// EventReasonReconcileFailed means a reconcile step failed, it is retried
+	EventReasonReconcileFailed = "ReconcileFailed"
+	// EventReasonPhaseChanged means status.phase changed
+	EventReasonPhaseChanged = "PhaseChanged"
+	// EventReasonUpdateDeferred means a change replacing the game pod waits for a maintenance window
+	EventReasonUpdateDeferred = "UpdateDeferred"
+	// EventReasonShutdownCountdown means the players are warned before the game pod is replaced
+	EventReasonShutdownCountdown = "ShutdownCountdown"
+	// EventReasonUpdateAvailable means a newer build of the game was released
+	EventReasonUpdateAvailable = "UpdateAvailable"
+	// EventReasonCrashed means the game container exited with an error
+	EventReasonCrashed = "Crashed"
+	// EventReasonScaled means a FleetAutoscaler changed the replicas of its fleet
+	EventReasonScaled = "Scaled"
+	// EventReasonAllocated means a GameServerAllocation claimed a game server
+	EventReasonAllocated = "Allocated"
+	// EventReasonUnAllocated means no game server matched a GameServerAllocation
+	EventReasonUnAllocated = "UnAllocated"
+	// EventReasonReleased means the game server of a GameServerAllocation was handed back to its fleet
+	EventReasonReleased = "Released"
+)
+
+// RecordEvent records an Event on obj, nothing is recorded with a nil recorder
+func RecordEvent(recorder record.EventRecorder, obj runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	if recorder == nil {
+		return
+	}
+	recorder.Eventf(obj, eventType, reason, messageFmt, args...)
+}
+
+// RecordStatusEvents records Events on obj for the transitions from the status old to new of a game
+// server: phase changes, rollouts waiting for a maintenance window, shutdown countdowns, available
+// updates, and Warnings for crashes and conditions turning false
+func RecordStatusEvents(recorder record.EventRecorder, obj runtime.Object, old, new *gameserverv1alpha1.BaseStatus) {
+	if new.Phase != "" && new.Phase != old.Phase {
+		from := old.Phase
+		if from == "" {
+			from = "None"
+		}
+		RecordEvent(recorder, obj, corev1.EventTypeNormal, EventReasonPhaseChanged, "Phase changed from %s to %s", from, new.Phase)
+	}
+
+	if pending := meta.FindStatusCondition(new.Conditions, ConditionUpdatePending); pending != nil &&
+		!meta.IsStatusConditionTrue(old.Conditions, ConditionUpdatePending) {
+		RecordEvent(recorder, obj, corev1.EventTypeNormal, EventReasonUpdateDeferred, "%s", pending.Message)
+	}
+
+	if old.Shutdown == nil && new.Shutdown != nil {
+		RecordEvent(recorder, obj, corev1.EventTypeNormal, EventReasonShutdownCountdown, "Warning the players before the game pod is replaced")
+	}
+
+	if available := availableBuild(new); available != "" && available != availableBuild(old) && available != new.Updates.InstalledBuildID {
+		RecordEvent(recorder, obj, corev1.EventTypeNormal, EventReasonUpdateAvailable,
+			"Build %s is available, build %s is installed", available, new.Updates.InstalledBuildID)
+	}
+
+	if new.LastCrashTime != nil && (old.LastCrashTime == nil || old.LastCrashTime.Before(new.LastCrashTime)) {
+		RecordEvent(recorder, obj, corev1.EventTypeWarning, EventReasonCrashed, "The game container exited with an error at %s", new.LastCrashTime.UTC().Format(time.RFC3339))
+	}
+
+	for _, condition := range new.Conditions {
+		if condition.Type == ConditionReady || condition.Type == ConditionUpdatePending || condition.Status != metav1.ConditionFalse {
+			continue
+		}
+		previous := meta.FindStatusCondition(old.Conditions, condition.Type)
+		if previous == nil || previous.Status != metav1.ConditionFalse || previous.Reason != condition.Reason {
+			RecordEvent(recorder, obj, corev1.EventTypeWarning, condition.Reason, "%s: %s", condition.Type, condition.Message)
+		}
+	}
+}
+
+// availableBuild returns the latest build recorded in status, "" when unknown
+func availableBuild(status *gameserverv1alpha1.BaseStatus) string {
+	if status.Updates == nil {
+		return ""
+	}
+	return status.Updates.AvailableBuildID
+}
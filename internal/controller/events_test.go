@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+)
+
+var _ = Describe("Events", func() {
+	owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "eventful", Namespace: "default"}}
+
+	recorded := func(old, new *gameserverv1alpha1.BaseStatus) []string {
+		recorder := record.NewFakeRecorder(10)
+		RecordStatusEvents(recorder, owner, old, new)
+		close(recorder.Events)
+		var events []string
+		for event := range recorder.Events {
+			events = append(events, event)
+		}
+		return events
+	}
+
+	It("should record the transitions of the status", func() {
+		old := &gameserverv1alpha1.BaseStatus{
+			Phase:   gameserverv1alpha1.GameServerPending,
+			Updates: &gameserverv1alpha1.UpdateStatus{AvailableBuildID: "100", InstalledBuildID: "100"},
+		}
+		new := &gameserverv1alpha1.BaseStatus{
+			Phase:         gameserverv1alpha1.GameServerRunning,
+			Shutdown:      &gameserverv1alpha1.ShutdownStatus{},
+			Updates:       &gameserverv1alpha1.UpdateStatus{AvailableBuildID: "101", InstalledBuildID: "100"},
+			LastCrashTime: &metav1.Time{Time: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+			Conditions: []metav1.Condition{
+				{Type: ConditionUpdatePending, Status: metav1.ConditionTrue, Reason: ReasonWaitingForMaintenanceWindow, Message: "Changes are rolled out at 03:00"},
+				{Type: ConditionEconomyMerged, Status: metav1.ConditionFalse, Reason: "MalformedXML", Message: "types.xml: line 3: broken"},
+				{Type: ConditionReady, Status: metav1.ConditionFalse, Reason: "QueryFailed"},
+			},
+		}
+
+		Expect(recorded(old, new)).To(Equal([]string{
+			"Normal PhaseChanged Phase changed from Pending to Running",
+			"Normal UpdateDeferred Changes are rolled out at 03:00",
+			"Normal ShutdownCountdown Warning the players before the game pod is replaced",
+			"Normal UpdateAvailable Build 101 is available, build 100 is installed",
+			"Warning Crashed The game container exited with an error at 2024-05-01T12:00:00Z",
+			"Warning MalformedXML EconomyMerged: types.xml: line 3: broken",
+		}))
+	})
+
+	It("should not repeat Events for an unchanged status", func() {
+		status := &gameserverv1alpha1.BaseStatus{
+			Phase: gameserverv1alpha1.GameServerPaused,
+			Conditions: []metav1.Condition{
+				{Type: ConditionUpdatePending, Status: metav1.ConditionTrue, Reason: ReasonWaitingForMaintenanceWindow},
+				{Type: ConditionPlayerListsApplied, Status: metav1.ConditionFalse, Reason: ReasonPlayerListNotFound},
+			},
+		}
+
+		Expect(recorded(status, status.DeepCopy())).To(BeEmpty())
+	})
+
+	It("should ignore a nil recorder", func() {
+		Expect(func() {
+			RecordEvent(nil, owner, corev1.EventTypeNormal, EventReasonCreated, "Created Deployment %s", "eventful")
+		}).NotTo(Panic())
+	})
+})
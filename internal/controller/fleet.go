@@ -0,0 +1,261 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+	gamev1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1/game"
+)
+
+// FleetServer is a game server of a GameServerFleet
+type FleetServer struct {
+	Object client.Object
+	Index  int
+	Status *gameserverv1alpha1.BaseStatus
+}
+
+// Ready reports whether the server answers its query port and caught up with its spec
+func (s *FleetServer) Ready() bool {
+	ready := meta.FindStatusCondition(s.Status.Conditions, ConditionReady)
+	return ready != nil && ready.Status == metav1.ConditionTrue && ready.ObservedGeneration >= s.Object.GetGeneration() &&
+		!meta.IsStatusConditionTrue(s.Status.Conditions, ConditionUpdatePending)
+}
+
+// Allocated reports whether a GameServerAllocation holds the server
+func (s *FleetServer) Allocated() bool {
+	return IsAllocated(s.Object)
+}
+
+// Updated reports whether the server was last updated to the fleet template with hash
+func (s *FleetServer) Updated(hash string) bool {
+	return s.Object.GetAnnotations()[gameserverv1alpha1.FleetTemplateHashAnnotation] == hash
+}
+
+// FleetKind creates and reads the game servers of a kind for fleets
+type FleetKind interface {
+	// NewObject returns an empty game server of the kind
+	NewObject() client.Object
+
+	// NewList returns an empty list of the game servers of the kind
+	NewList() client.ObjectList
+
+	// Server returns the FleetServer of obj, a game server of the kind
+	Server(obj client.Object) FleetServer
+
+	// Servers returns the game servers of list
+	Servers(list client.ObjectList) []FleetServer
+
+	// Render returns the game server of fleet with index, with its name, labels and spec set
+	Render(fleet *gameserverv1alpha1.GameServerFleet, index int) (client.Object, error)
+
+	// ApplySpec copies the spec, labels and annotations of the rendered src into the existing dst
+	ApplySpec(dst, src client.Object)
+}
+
+// FleetKinds are the game server kinds fleets can be made of
+var FleetKinds = map[string]FleetKind{
+	"Dayz": dayzFleetKind{},
+}
+
+// LookupFleetKind returns the FleetKind of the template of fleet
+func LookupFleetKind(fleet *gameserverv1alpha1.GameServerFleet) (FleetKind, error) {
+	kind := fleet.Spec.Template.Kind
+	if kind == "" {
+		kind = "Dayz"
+	}
+	fleetKind, ok := FleetKinds[kind]
+	if !ok {
+		return nil, fmt.Errorf("unsupported game server kind %q", kind)
+	}
+	return fleetKind, nil
+}
+
+// ListFleetServers returns the game servers controlled by fleet, including the ones being deleted
+func ListFleetServers(ctx context.Context, c client.Client, fleet *gameserverv1alpha1.GameServerFleet, kind FleetKind) ([]FleetServer, error) {
+	list := kind.NewList()
+	if err := c.List(ctx, list, client.InNamespace(fleet.Namespace), client.MatchingLabels{gameserverv1alpha1.FleetLabel: fleet.Name}); err != nil {
+		return nil, err
+	}
+	var servers []FleetServer
+	for _, server := range kind.Servers(list) {
+		if metav1.IsControlledBy(server.Object, fleet) {
+			servers = append(servers, server)
+		}
+	}
+	return servers, nil
+}
+
+// FleetServerName returns the name of the game server of fleet with index
+func FleetServerName(fleet *gameserverv1alpha1.GameServerFleet, index int) string {
+	return fmt.Sprintf("%s-%d", fleet.Name, index)
+}
+
+// FleetTemplateHash hashes the fields of fleet the game servers are rendered from, servers are
+// updated when it changes. The template spec is hashed decoded so the order of its keys does not
+// matter, the API server does not keep it.
+func FleetTemplateHash(fleet *gameserverv1alpha1.GameServerFleet) string {
+	var spec interface{}
+	if err := json.Unmarshal(fleet.Spec.Template.Spec.Raw, &spec); err != nil {
+		spec = string(fleet.Spec.Template.Spec.Raw)
+	}
+	data, _ := json.Marshal(struct {
+		Kind       string
+		Metadata   gameserverv1alpha1.GameServerFleetMetadata
+		Spec       interface{}
+		Hostname   string
+		PortStride int32
+	}{fleet.Spec.Template.Kind, fleet.Spec.Template.Metadata, spec, fleet.Spec.Hostname, fleet.Spec.PortStride})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// FleetHostname returns the hostname of the game server of fleet with name and index, templateHostname
+// is the hostname set in the template
+func FleetHostname(fleet *gameserverv1alpha1.GameServerFleet, templateHostname, name string, index int) string {
+	hostname := fleet.Spec.Hostname
+	if hostname == "" {
+		hostname = "{name}"
+		if templateHostname != "" {
+			hostname = templateHostname + " #{index}"
+		}
+	}
+	return strings.NewReplacer("{index}", strconv.Itoa(index), "{name}", name).Replace(hostname)
+}
+
+// FleetScaleDownOrder sorts servers in the order they are removed when the fleet scales down: servers
+// which are not ready first, then by players, then the highest index first. Allocated servers come
+// last, they are not removed.
+func FleetScaleDownOrder(servers []FleetServer) {
+	sort.SliceStable(servers, func(i, j int) bool {
+		if allocated := servers[i].Allocated(); allocated != servers[j].Allocated() {
+			return !allocated
+		}
+		if ready := servers[i].Ready(); ready != servers[j].Ready() {
+			return !ready
+		}
+		if servers[i].Status.Players != servers[j].Status.Players {
+			return servers[i].Status.Players < servers[j].Status.Players
+		}
+		return servers[i].Index > servers[j].Index
+	})
+}
+
+// fleetObjectMeta returns the metadata of the game server of fleet with index
+func fleetObjectMeta(fleet *gameserverv1alpha1.GameServerFleet, index int) metav1.ObjectMeta {
+	labels := map[string]string{}
+	for key, value := range fleet.Spec.Template.Metadata.Labels {
+		labels[key] = value
+	}
+	labels[gameserverv1alpha1.FleetLabel] = fleet.Name
+	labels[gameserverv1alpha1.FleetIndexLabel] = strconv.Itoa(index)
+
+	annotations := map[string]string{}
+	for key, value := range fleet.Spec.Template.Metadata.Annotations {
+		annotations[key] = value
+	}
+	annotations[gameserverv1alpha1.FleetTemplateHashAnnotation] = FleetTemplateHash(fleet)
+
+	return metav1.ObjectMeta{
+		Name:        FleetServerName(fleet, index),
+		Namespace:   fleet.Namespace,
+		Labels:      labels,
+		Annotations: annotations,
+	}
+}
+
+// fleetServerIndex reads the index label of a game server, -1 when it has none
+func fleetServerIndex(obj client.Object) int {
+	index, err := strconv.Atoi(obj.GetLabels()[gameserverv1alpha1.FleetIndexLabel])
+	if err != nil {
+		return -1
+	}
+	return index
+}
+
+// applyFleetMetadata sets the labels and annotations of the rendered src on dst, keeping others
+func applyFleetMetadata(dst, src client.Object) {
+	labels, annotations := dst.GetLabels(), dst.GetAnnotations()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	for key, value := range src.GetLabels() {
+		labels[key] = value
+	}
+	for key, value := range src.GetAnnotations() {
+		annotations[key] = value
+	}
+	dst.SetLabels(labels)
+	dst.SetAnnotations(annotations)
+}
+
+// dayzFleetKind makes fleets of Dayz game servers
+type dayzFleetKind struct{}
+
+func (dayzFleetKind) NewObject() client.Object {
+	return &gamev1alpha1.Dayz{}
+}
+
+func (dayzFleetKind) NewList() client.ObjectList {
+	return &gamev1alpha1.DayzList{}
+}
+
+func (dayzFleetKind) Server(obj client.Object) FleetServer {
+	dayz := obj.(*gamev1alpha1.Dayz)
+	return FleetServer{Object: dayz, Index: fleetServerIndex(dayz), Status: &dayz.Status.BaseStatus}
+}
+
+func (k dayzFleetKind) Servers(list client.ObjectList) []FleetServer {
+	dayzs := list.(*gamev1alpha1.DayzList)
+	servers := make([]FleetServer, 0, len(dayzs.Items))
+	for i := range dayzs.Items {
+		servers = append(servers, k.Server(&dayzs.Items[i]))
+	}
+	return servers
+}
+
+func (dayzFleetKind) Render(fleet *gameserverv1alpha1.GameServerFleet, index int) (client.Object, error) {
+	dayz := &gamev1alpha1.Dayz{ObjectMeta: fleetObjectMeta(fleet, index)}
+	decoder := json.NewDecoder(bytes.NewReader(fleet.Spec.Template.Spec.Raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&dayz.Spec); err != nil {
+		return nil, fmt.Errorf("invalid Dayz spec in template: %w", err)
+	}
+
+	if fleet.Spec.PortStride > 0 && len(dayz.Spec.Ports) == 0 {
+		return nil, fmt.Errorf("portStride needs the ports of the game server in the template")
+	}
+	for i := range dayz.Spec.Ports {
+		port := &dayz.Spec.Ports[i]
+		// The container keeps listening on the port of the template
+		if port.TargetPort.IntValue() == 0 && port.TargetPort.StrVal == "" {
+			port.TargetPort = intstr.FromInt32(port.Port)
+		}
+		port.Port += fleet.Spec.PortStride * int32(index)
+	}
+	if dayz.Spec.Settings == nil {
+		dayz.Spec.Settings = &gamev1alpha1.DayzServerSettings{}
+	}
+	dayz.Spec.Settings.Hostname = FleetHostname(fleet, dayz.Spec.Settings.Hostname, dayz.Name, index)
+	return dayz, nil
+}
+
+func (dayzFleetKind) ApplySpec(dst, src client.Object) {
+	dst.(*gamev1alpha1.Dayz).Spec = src.(*gamev1alpha1.Dayz).Spec
+	applyFleetMetadata(dst, src)
+}
@@ -0,0 +1,221 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+	gamev1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1/game"
+)
+
+var _ = Describe("GameServerFleet", func() {
+	ctx := context.Background()
+
+	newFleet := func(replicas int32, spec string) *gameserverv1alpha1.GameServerFleet {
+		return &gameserverv1alpha1.GameServerFleet{
+			ObjectMeta: metav1.ObjectMeta{Name: "event", Namespace: "games", UID: "fleet-uid"},
+			Spec: gameserverv1alpha1.GameServerFleetSpec{
+				Replicas: replicas,
+				Template: gameserverv1alpha1.GameServerFleetTemplate{
+					Kind:     "Dayz",
+					Metadata: gameserverv1alpha1.GameServerFleetMetadata{Labels: map[string]string{"event": "summer"}},
+					Spec:     apiextensionsv1.JSON{Raw: []byte(spec)},
+				},
+				PortStride: 10,
+				Strategy:   gameserverv1alpha1.GameServerFleetStrategy{MaxUnavailable: 1},
+			},
+		}
+	}
+	const spec = `{"image":"dayz:1","ports":[{"name":"game","port":2302,"targetPort":2302,"protocol":"UDP"}],"settings":{"hostname":"Summer Event"}}`
+
+	Describe("helpers", func() {
+		It("should render unique names, ports and hostnames", func() {
+			server, err := FleetKinds["Dayz"].Render(newFleet(3, spec), 2)
+			Expect(err).NotTo(HaveOccurred())
+			dayz := server.(*gamev1alpha1.Dayz)
+			Expect(dayz.Name).To(Equal("event-2"))
+			Expect(dayz.Labels).To(Equal(map[string]string{
+				"event":                            "summer",
+				gameserverv1alpha1.FleetLabel:      "event",
+				gameserverv1alpha1.FleetIndexLabel: "2",
+			}))
+			Expect(dayz.Spec.Ports[0].Port).To(Equal(int32(2322)))
+			Expect(dayz.Spec.Ports[0].TargetPort.IntValue()).To(Equal(2302))
+			Expect(dayz.Spec.Settings.Hostname).To(Equal("Summer Event #2"))
+		})
+
+		It("should keep the target port of ports without one", func() {
+			server, err := FleetKinds["Dayz"].Render(newFleet(3, `{"ports":[{"name":"game","port":2302,"protocol":"UDP"}]}`), 1)
+			Expect(err).NotTo(HaveOccurred())
+			dayz := server.(*gamev1alpha1.Dayz)
+			Expect(dayz.Spec.Ports[0].Port).To(Equal(int32(2312)))
+			Expect(dayz.Spec.Ports[0].TargetPort.IntValue()).To(Equal(2302))
+		})
+
+		It("should reject a port stride without ports in the template", func() {
+			_, err := FleetKinds["Dayz"].Render(newFleet(1, `{"image":"dayz:1"}`), 0)
+			Expect(err).To(MatchError(ContainSubstring("portStride")))
+		})
+
+		It("should fill the hostname pattern", func() {
+			fleet := newFleet(1, `{}`)
+			Expect(FleetHostname(fleet, "", "event-0", 0)).To(Equal("event-0"))
+			fleet.Spec.Hostname = "Event {index} ({name})"
+			Expect(FleetHostname(fleet, "ignored", "event-4", 4)).To(Equal("Event 4 (event-4)"))
+		})
+
+		It("should reject unknown fields in the template", func() {
+			_, err := FleetKinds["Dayz"].Render(newFleet(1, `{"image":"dayz","hostname":"misplaced"}`), 0)
+			Expect(err).To(MatchError(ContainSubstring("unknown field")))
+		})
+
+		It("should change the template hash with the template only", func() {
+			fleet := newFleet(1, spec)
+			hash := FleetTemplateHash(fleet)
+			fleet.Spec.Replicas = 5
+			Expect(FleetTemplateHash(fleet)).To(Equal(hash))
+			fleet.Spec.Template.Spec.Raw = []byte(`{"settings":{"hostname":"Summer Event"},"image":"dayz:1","ports":[{"targetPort":2302,"protocol":"UDP","port":2302,"name":"game"}]}`)
+			Expect(FleetTemplateHash(fleet)).To(Equal(hash), "the order of the keys does not matter")
+			fleet.Spec.Hostname = "Event {index}"
+			Expect(FleetTemplateHash(fleet)).NotTo(Equal(hash))
+		})
+	})
+
+	Describe("GameServerFleetReconciler", func() {
+		var (
+			c          client.Client
+			reconciler *GameServerFleetReconciler
+			fleet      *gameserverv1alpha1.GameServerFleet
+		)
+		key := types.NamespacedName{Name: "event", Namespace: "games"}
+
+		setup := func(objects ...client.Object) {
+			scheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(gameserverv1alpha1.AddToScheme(scheme)).To(Succeed())
+			c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).
+				WithStatusSubresource(&gameserverv1alpha1.GameServerFleet{}, &gamev1alpha1.Dayz{}).Build()
+			reconciler = &GameServerFleetReconciler{Client: c, Scheme: scheme}
+		}
+		reconcileFleet := func() {
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		servers := func() map[string]*gamev1alpha1.Dayz {
+			list := &gamev1alpha1.DayzList{}
+			Expect(c.List(ctx, list, client.InNamespace("games"))).To(Succeed())
+			byName := map[string]*gamev1alpha1.Dayz{}
+			for i := range list.Items {
+				byName[list.Items[i].Name] = &list.Items[i]
+			}
+			return byName
+		}
+		// serve marks a server ready with players
+		serve := func(name string, players int32) {
+			dayz := servers()[name]
+			dayz.Status.Players = players
+			dayz.Status.Conditions = []metav1.Condition{{
+				Type: ConditionReady, Status: metav1.ConditionTrue, Reason: ReasonQueryResponded,
+				ObservedGeneration: dayz.Generation, LastTransitionTime: metav1.Now(),
+			}}
+			Expect(c.Status().Update(ctx, dayz)).To(Succeed())
+		}
+		getFleet := func() *gameserverv1alpha1.GameServerFleet {
+			updated := &gameserverv1alpha1.GameServerFleet{}
+			Expect(c.Get(ctx, key, updated)).To(Succeed())
+			return updated
+		}
+
+		BeforeEach(func() {
+			fleet = newFleet(3, spec)
+			setup(fleet)
+		})
+
+		It("should create the servers and report the fleet status", func() {
+			reconcileFleet()
+			Expect(servers()).To(HaveLen(3))
+			Expect(servers()).To(HaveKey("event-0"))
+			Expect(metav1.IsControlledBy(servers()["event-1"], getFleet())).To(BeTrue())
+
+			serve("event-0", 4)
+			serve("event-1", 0)
+			reconcileFleet()
+			Expect(getFleet().Status).To(Equal(gameserverv1alpha1.GameServerFleetStatus{
+				Replicas: 3, ReadyReplicas: 2, UpdatedReplicas: 3, Players: 4,
+				Selector: gameserverv1alpha1.FleetLabel + "=event",
+			}))
+		})
+
+		It("should remove the servers which are not ready, then the emptiest ones", func() {
+			reconcileFleet()
+			serve("event-0", 0)
+			serve("event-1", 5)
+			serve("event-2", 2)
+
+			current := getFleet()
+			current.Spec.Replicas = 2
+			Expect(c.Update(ctx, current)).To(Succeed())
+			reconcileFleet()
+			Expect(servers()).To(HaveLen(2))
+			Expect(servers()).NotTo(HaveKey("event-0"))
+
+			current = getFleet()
+			current.Spec.Replicas = 1
+			Expect(c.Update(ctx, current)).To(Succeed())
+			reconcileFleet()
+			Expect(servers()).To(HaveLen(1))
+			Expect(servers()).To(HaveKey("event-1"))
+
+			current = getFleet()
+			current.Spec.Replicas = 2
+			Expect(c.Update(ctx, current)).To(Succeed())
+			reconcileFleet()
+			Expect(servers()).To(HaveKey("event-0"), "the lowest free index is reused")
+		})
+
+		It("should roll template changes out to one ready server at a time", func() {
+			reconcileFleet()
+			serve("event-0", 3)
+			serve("event-1", 0)
+			serve("event-2", 1)
+
+			current := getFleet()
+			current.Spec.Template.Spec.Raw = []byte(`{"image":"dayz:2","ports":[{"name":"game","port":2302,"targetPort":2302,"protocol":"UDP"}]}`)
+			Expect(c.Update(ctx, current)).To(Succeed())
+			reconcileFleet()
+
+			images := func() map[string]string {
+				result := map[string]string{}
+				for name, dayz := range servers() {
+					result[name] = dayz.Spec.Image
+				}
+				return result
+			}
+			Expect(images()).To(Equal(map[string]string{"event-0": "dayz:1", "event-1": "dayz:2", "event-2": "dayz:1"}))
+			Expect(servers()["event-1"].Spec.Settings.Hostname).To(Equal("event-1"))
+
+			// The updated server is unavailable until it answers again
+			dayz := servers()["event-1"]
+			dayz.Status.Conditions[0].Status = metav1.ConditionFalse
+			Expect(c.Status().Update(ctx, dayz)).To(Succeed())
+			reconcileFleet()
+			Expect(images()).To(Equal(map[string]string{"event-0": "dayz:1", "event-1": "dayz:2", "event-2": "dayz:1"}))
+
+			serve("event-1", 0)
+			reconcileFleet()
+			Expect(images()).To(Equal(map[string]string{"event-0": "dayz:1", "event-1": "dayz:2", "event-2": "dayz:2"}))
+			Expect(getFleet().Status.UpdatedReplicas).To(Equal(int32(2)))
+		})
+	})
+})
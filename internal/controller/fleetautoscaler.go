@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+)
+
+const (
+	// ConditionAbleToScale reports whether a FleetAutoscaler can scale its fleet now
+	ConditionAbleToScale = "AbleToScale"
+	// ConditionScalingLimited reports whether minReplicas or maxReplicas bound the desired replicas
+	ConditionScalingLimited = "ScalingLimited"
+
+	// ReasonReadyForNewScale means the fleet is scaled as soon as the desired replicas change
+	ReasonReadyForNewScale = "ReadyForNewScale"
+	// ReasonFleetNotFound means the referenced GameServerFleet does not exist
+	ReasonFleetNotFound = "FleetNotFound"
+	// ReasonScaleUpCooldown means scaling up waits for scaleUpCooldownSeconds after the last scale
+	ReasonScaleUpCooldown = "ScaleUpCooldown"
+	// ReasonScaleDownCooldown means scaling down waits for scaleDownCooldownSeconds after the last scale
+	ReasonScaleDownCooldown = "ScaleDownCooldown"
+	// ReasonTooFewReplicas means the desired replicas were raised to minReplicas
+	ReasonTooFewReplicas = "TooFewReplicas"
+	// ReasonTooManyReplicas means the desired replicas were lowered to maxReplicas
+	ReasonTooManyReplicas = "TooManyReplicas"
+	// ReasonDesiredWithinRange means the desired replicas are between minReplicas and maxReplicas
+	ReasonDesiredWithinRange = "DesiredWithinRange"
+)
+
+// FleetOccupiedReplicas counts the servers with players or allocated, servers being deleted are left out
+func FleetOccupiedReplicas(servers []FleetServer) int32 {
+	var occupied int32
+	for _, server := range servers {
+		if server.Object.GetDeletionTimestamp() == nil && (server.Status.Players > 0 || server.Allocated()) {
+			occupied++
+		}
+	}
+	return occupied
+}
+
+// FleetAutoscalerReplicas returns the replicas keeping the buffer of empty servers of spec next to the
+// occupied ones, bounded by minReplicas and maxReplicas, and the ScalingLimited condition explaining it.
+// A percentage buffer is a share of the replicas, so 20% keeps one empty server per four occupied ones.
+func FleetAutoscalerReplicas(spec *gameserverv1alpha1.FleetAutoscalerSpec, occupied int32) (int32, metav1.Condition, error) {
+	var desired int32
+	switch spec.BufferSize.Type {
+	case intstr.String:
+		percent, err := strconv.Atoi(strings.TrimSuffix(spec.BufferSize.StrVal, "%"))
+		if err != nil || !strings.HasSuffix(spec.BufferSize.StrVal, "%") || percent < 0 || percent >= 100 {
+			return 0, metav1.Condition{}, fmt.Errorf("invalid bufferSize %q", spec.BufferSize.StrVal)
+		}
+		// ceil(occupied / (1 - percent/100))
+		desired = int32((int(occupied)*100 + 99 - percent) / (100 - percent))
+	default:
+		desired = occupied + spec.BufferSize.IntVal
+	}
+
+	switch {
+	case desired < spec.MinReplicas:
+		return spec.MinReplicas, metav1.Condition{
+			Type:    ConditionScalingLimited,
+			Status:  metav1.ConditionTrue,
+			Reason:  ReasonTooFewReplicas,
+			Message: fmt.Sprintf("%d occupied servers with a buffer of %s need %d replicas, raised to minReplicas", occupied, spec.BufferSize.String(), desired),
+		}, nil
+	case desired > spec.MaxReplicas:
+		return spec.MaxReplicas, metav1.Condition{
+			Type:    ConditionScalingLimited,
+			Status:  metav1.ConditionTrue,
+			Reason:  ReasonTooManyReplicas,
+			Message: fmt.Sprintf("%d occupied servers with a buffer of %s need %d replicas, lowered to maxReplicas", occupied, spec.BufferSize.String(), desired),
+		}, nil
+	}
+	return desired, metav1.Condition{
+		Type:    ConditionScalingLimited,
+		Status:  metav1.ConditionFalse,
+		Reason:  ReasonDesiredWithinRange,
+		Message: fmt.Sprintf("%d occupied servers with a buffer of %s need %d replicas", occupied, spec.BufferSize.String(), desired),
+	}, nil
+}
+
+// FleetAutoscalerCooldown returns until when scaling from current to desired replicas waits after the
+// last scale, and the reason, zero when the fleet can be scaled at now. Fleets outside minReplicas and
+// maxReplicas are scaled right away.
+func FleetAutoscalerCooldown(spec *gameserverv1alpha1.FleetAutoscalerSpec, lastScale *metav1.Time, current, desired int32, now time.Time) (time.Time, string) {
+	if lastScale == nil || current < spec.MinReplicas || current > spec.MaxReplicas {
+		return time.Time{}, ""
+	}
+	cooldown, reason := time.Duration(spec.ScaleUpCooldownSeconds)*time.Second, ReasonScaleUpCooldown
+	if desired < current {
+		cooldown, reason = time.Duration(spec.ScaleDownCooldownSeconds)*time.Second, ReasonScaleDownCooldown
+	}
+	if until := lastScale.Add(cooldown); until.After(now) {
+		return until, reason
+	}
+	return time.Time{}, ""
+}
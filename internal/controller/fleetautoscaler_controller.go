@@ -0,0 +1,195 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+	gamev1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1/game"
+)
+
+// FleetAutoscalerReconciler sets the replicas of GameServerFleets from the player counts of their
+// game servers
+type FleetAutoscalerReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Recorder records the scaling decisions, none are recorded when nil
+	Recorder record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=gameserver.templarfelix.com,resources=fleetautoscalers,verbs=get;list;watch
+//+kubebuilder:rbac:groups=gameserver.templarfelix.com,resources=fleetautoscalers/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=gameserver.templarfelix.com,resources=gameserverfleets,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=gameserver.templarfelix.com,resources=dayzs,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Reconcile scales the fleet of a FleetAutoscaler to keep spec.bufferSize empty game servers, waiting
+// for the cooldowns after the last scale
+func (r *FleetAutoscalerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("fleetautoscaler", req.Name)
+
+	autoscaler := &gameserverv1alpha1.FleetAutoscaler{}
+	if err := r.Get(ctx, req.NamespacedName, autoscaler); err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	now := time.Now()
+	status := autoscaler.Status.DeepCopy()
+	status.ObservedGeneration = autoscaler.Generation
+	var result reconcile.Result
+
+	fleet := &gameserverv1alpha1.GameServerFleet{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: autoscaler.Namespace, Name: autoscaler.Spec.FleetRef.Name}, fleet)
+	switch {
+	case errors.IsNotFound(err):
+		setFleetAutoscalerCondition(status, autoscaler, metav1.ConditionFalse, ReasonFleetNotFound,
+			fmt.Sprintf("GameServerFleet %s not found", autoscaler.Spec.FleetRef.Name))
+		meta.RemoveStatusCondition(&status.Conditions, ConditionScalingLimited)
+	case err != nil:
+		return reconcile.Result{}, err
+	default:
+		kind, err := LookupFleetKind(fleet)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		servers, err := ListFleetServers(ctx, r.Client, fleet, kind)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		status.OccupiedReplicas = FleetOccupiedReplicas(servers)
+		status.CurrentReplicas = fleet.Spec.Replicas
+
+		desired, limited, err := FleetAutoscalerReplicas(&autoscaler.Spec, status.OccupiedReplicas)
+		if err != nil {
+			RecordEvent(r.Recorder, autoscaler, corev1.EventTypeWarning, EventReasonReconcileFailed, "%v", err)
+			return reconcile.Result{}, err
+		}
+		status.DesiredReplicas = desired
+		limited.ObservedGeneration = autoscaler.Generation
+		meta.SetStatusCondition(&status.Conditions, limited)
+
+		until, reason := FleetAutoscalerCooldown(&autoscaler.Spec, status.LastScaleTime, fleet.Spec.Replicas, desired, now)
+		switch {
+		case desired == fleet.Spec.Replicas:
+			setFleetAutoscalerCondition(status, autoscaler, metav1.ConditionTrue, ReasonReadyForNewScale,
+				fmt.Sprintf("The fleet has the desired %d replicas", desired))
+		case !until.IsZero():
+			setFleetAutoscalerCondition(status, autoscaler, metav1.ConditionFalse, reason,
+				fmt.Sprintf("Scaling from %d to %d replicas waits until %s", fleet.Spec.Replicas, desired, until.UTC().Format(time.RFC3339)))
+			result.RequeueAfter = until.Sub(now)
+		default:
+			from := fleet.Spec.Replicas
+			fleet.Spec.Replicas = desired
+			if err := r.Update(ctx, fleet); err != nil {
+				if errors.IsConflict(err) {
+					return reconcile.Result{Requeue: true}, nil
+				}
+				RecordEvent(r.Recorder, autoscaler, corev1.EventTypeWarning, EventReasonReconcileFailed, "%v", err)
+				return reconcile.Result{}, err
+			}
+			logger.Info("Scaled fleet", "fleet", fleet.Name, "from", from, "to", desired, "occupied", status.OccupiedReplicas)
+			RecordEvent(r.Recorder, autoscaler, corev1.EventTypeNormal, EventReasonScaled, "Scaled fleet %s from %d to %d replicas: %s",
+				fleet.Name, from, desired, limited.Message)
+			status.CurrentReplicas = desired
+			status.LastScaleTime = &metav1.Time{Time: now}
+			setFleetAutoscalerCondition(status, autoscaler, metav1.ConditionTrue, ReasonReadyForNewScale,
+				fmt.Sprintf("Scaled from %d to %d replicas", from, desired))
+		}
+	}
+
+	if !equality.Semantic.DeepEqual(&autoscaler.Status, status) {
+		autoscaler.Status = *status
+		if err := r.Status().Update(ctx, autoscaler); err != nil {
+			if errors.IsConflict(err) {
+				return reconcile.Result{Requeue: true}, nil
+			}
+			return reconcile.Result{}, err
+		}
+	}
+	return result, nil
+}
+
+// setFleetAutoscalerCondition sets the AbleToScale condition of status
+func setFleetAutoscalerCondition(status *gameserverv1alpha1.FleetAutoscalerStatus, autoscaler *gameserverv1alpha1.FleetAutoscaler, conditionStatus metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               ConditionAbleToScale,
+		Status:             conditionStatus,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: autoscaler.Generation,
+	})
+}
+
+// autoscalersForFleet maps a GameServerFleet to the FleetAutoscalers referencing it
+func (r *FleetAutoscalerReconciler) autoscalersForFleet(ctx context.Context, fleet client.Object) []reconcile.Request {
+	return r.autoscalersFor(ctx, fleet.GetNamespace(), fleet.GetName())
+}
+
+// autoscalersForServer maps a game server of a fleet to the FleetAutoscalers of the fleet
+func (r *FleetAutoscalerReconciler) autoscalersForServer(ctx context.Context, server client.Object) []reconcile.Request {
+	fleet := server.GetLabels()[gameserverv1alpha1.FleetLabel]
+	if fleet == "" {
+		return nil
+	}
+	return r.autoscalersFor(ctx, server.GetNamespace(), fleet)
+}
+
+// autoscalersFor returns requests for the FleetAutoscalers of namespace referencing the fleet
+func (r *FleetAutoscalerReconciler) autoscalersFor(ctx context.Context, namespace, fleet string) []reconcile.Request {
+	autoscalers := &gameserverv1alpha1.FleetAutoscalerList{}
+	if err := r.List(ctx, autoscalers, client.InNamespace(namespace)); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list FleetAutoscalers")
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range autoscalers.Items {
+		if autoscalers.Items[i].Spec.FleetRef.Name == fleet {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&autoscalers.Items[i])})
+		}
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *FleetAutoscalerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&gameserverv1alpha1.FleetAutoscaler{}).
+		Watches(&gameserverv1alpha1.GameServerFleet{}, handler.EnqueueRequestsFromMapFunc(r.autoscalersForFleet)).
+		Watches(&gamev1alpha1.Dayz{}, handler.EnqueueRequestsFromMapFunc(r.autoscalersForServer)).
+		Complete(r)
+}
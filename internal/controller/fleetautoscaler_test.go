@@ -0,0 +1,211 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+	gamev1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1/game"
+)
+
+var _ = Describe("FleetAutoscaler", func() {
+	ctx := context.Background()
+
+	newSpec := func(buffer intstr.IntOrString) *gameserverv1alpha1.FleetAutoscalerSpec {
+		return &gameserverv1alpha1.FleetAutoscalerSpec{
+			BufferSize:               buffer,
+			MinReplicas:              1,
+			MaxReplicas:              10,
+			ScaleUpCooldownSeconds:   30,
+			ScaleDownCooldownSeconds: 300,
+		}
+	}
+
+	Describe("FleetAutoscalerReplicas", func() {
+		It("should keep an absolute buffer of empty servers", func() {
+			replicas, limited, err := FleetAutoscalerReplicas(newSpec(intstr.FromInt(2)), 3)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(replicas).To(Equal(int32(5)))
+			Expect(limited.Status).To(Equal(metav1.ConditionFalse))
+			Expect(limited.Reason).To(Equal(ReasonDesiredWithinRange))
+			Expect(limited.Message).To(Equal("3 occupied servers with a buffer of 2 need 5 replicas"))
+		})
+
+		It("should keep a percentage of the replicas empty", func() {
+			for occupied, expected := range map[int32]int32{1: 2, 4: 5, 5: 7, 8: 10} {
+				replicas, _, err := FleetAutoscalerReplicas(newSpec(intstr.FromString("20%")), occupied)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(replicas).To(Equal(expected), "%d occupied servers", occupied)
+			}
+		})
+
+		It("should bound the replicas by minReplicas and maxReplicas", func() {
+			replicas, limited, err := FleetAutoscalerReplicas(newSpec(intstr.FromString("50%")), 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(replicas).To(Equal(int32(1)))
+			Expect(limited.Reason).To(Equal(ReasonTooFewReplicas))
+
+			replicas, limited, err = FleetAutoscalerReplicas(newSpec(intstr.FromInt(2)), 9)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(replicas).To(Equal(int32(10)))
+			Expect(limited.Status).To(Equal(metav1.ConditionTrue))
+			Expect(limited.Reason).To(Equal(ReasonTooManyReplicas))
+			Expect(limited.Message).To(Equal("9 occupied servers with a buffer of 2 need 11 replicas, lowered to maxReplicas"))
+		})
+
+		It("should reject an invalid buffer", func() {
+			for _, buffer := range []string{"20", "100%", "x%"} {
+				_, _, err := FleetAutoscalerReplicas(newSpec(intstr.FromString(buffer)), 1)
+				Expect(err).To(HaveOccurred(), buffer)
+			}
+		})
+	})
+
+	Describe("FleetAutoscalerCooldown", func() {
+		now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		lastScale := &metav1.Time{Time: now.Add(-time.Minute)}
+
+		It("should wait for the cooldown of the direction", func() {
+			spec := newSpec(intstr.FromInt(1))
+			until, reason := FleetAutoscalerCooldown(spec, lastScale, 3, 4, now)
+			Expect(until.IsZero()).To(BeTrue())
+			Expect(reason).To(BeEmpty())
+
+			until, reason = FleetAutoscalerCooldown(spec, lastScale, 4, 3, now)
+			Expect(until).To(Equal(now.Add(4 * time.Minute)))
+			Expect(reason).To(Equal(ReasonScaleDownCooldown))
+
+			spec.ScaleUpCooldownSeconds = 120
+			until, reason = FleetAutoscalerCooldown(spec, lastScale, 3, 4, now)
+			Expect(until).To(Equal(now.Add(time.Minute)))
+			Expect(reason).To(Equal(ReasonScaleUpCooldown))
+		})
+
+		It("should scale fleets outside the bounds right away", func() {
+			until, _ := FleetAutoscalerCooldown(newSpec(intstr.FromInt(1)), lastScale, 12, 10, now)
+			Expect(until.IsZero()).To(BeTrue())
+			until, _ = FleetAutoscalerCooldown(newSpec(intstr.FromInt(1)), nil, 4, 3, now)
+			Expect(until.IsZero()).To(BeTrue())
+		})
+	})
+
+	Describe("FleetAutoscalerReconciler", func() {
+		var (
+			c          client.Client
+			scheme     *runtime.Scheme
+			recorder   *record.FakeRecorder
+			reconciler *FleetAutoscalerReconciler
+		)
+		key := types.NamespacedName{Name: "event", Namespace: "games"}
+
+		newFleet := func(replicas int32) *gameserverv1alpha1.GameServerFleet {
+			return &gameserverv1alpha1.GameServerFleet{
+				ObjectMeta: metav1.ObjectMeta{Name: "event", Namespace: "games", UID: "fleet-uid"},
+				Spec: gameserverv1alpha1.GameServerFleetSpec{
+					Replicas: replicas,
+					Template: gameserverv1alpha1.GameServerFleetTemplate{Kind: "Dayz", Spec: apiextensionsv1.JSON{Raw: []byte(`{}`)}},
+				},
+			}
+		}
+		// server returns the game server of fleet with index and players
+		server := func(fleet *gameserverv1alpha1.GameServerFleet, index int, players int32) client.Object {
+			rendered, err := FleetKinds["Dayz"].Render(fleet, index)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(controllerutil.SetControllerReference(fleet, rendered, scheme)).To(Succeed())
+			rendered.(*gamev1alpha1.Dayz).Status.Players = players
+			return rendered
+		}
+		setup := func(autoscaler *gameserverv1alpha1.FleetAutoscaler, objects ...client.Object) {
+			c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(objects, autoscaler)...).
+				WithStatusSubresource(&gameserverv1alpha1.FleetAutoscaler{}, &gameserverv1alpha1.GameServerFleet{}, &gamev1alpha1.Dayz{}).Build()
+			recorder = record.NewFakeRecorder(10)
+			reconciler = &FleetAutoscalerReconciler{Client: c, Scheme: scheme, Recorder: recorder}
+		}
+		newAutoscaler := func(lastScale *metav1.Time) *gameserverv1alpha1.FleetAutoscaler {
+			spec := newSpec(intstr.FromInt(1))
+			spec.FleetRef.Name = "event"
+			return &gameserverv1alpha1.FleetAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{Name: "event", Namespace: "games"},
+				Spec:       *spec,
+				Status:     gameserverv1alpha1.FleetAutoscalerStatus{LastScaleTime: lastScale},
+			}
+		}
+		reconcileAutoscaler := func() reconcile.Result {
+			result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			return result
+		}
+		getStatus := func() gameserverv1alpha1.FleetAutoscalerStatus {
+			autoscaler := &gameserverv1alpha1.FleetAutoscaler{}
+			Expect(c.Get(ctx, key, autoscaler)).To(Succeed())
+			return autoscaler.Status
+		}
+		fleetReplicas := func() int32 {
+			fleet := &gameserverv1alpha1.GameServerFleet{}
+			Expect(c.Get(ctx, key, fleet)).To(Succeed())
+			return fleet.Spec.Replicas
+		}
+
+		BeforeEach(func() {
+			scheme = runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(gameserverv1alpha1.AddToScheme(scheme)).To(Succeed())
+		})
+
+		It("should scale the fleet up to keep the buffer", func() {
+			fleet := newFleet(2)
+			setup(newAutoscaler(nil), fleet, server(fleet, 0, 3), server(fleet, 1, 5))
+
+			Expect(reconcileAutoscaler()).To(Equal(reconcile.Result{}))
+			Expect(fleetReplicas()).To(Equal(int32(3)))
+			status := getStatus()
+			Expect(status.CurrentReplicas).To(Equal(int32(3)))
+			Expect(status.OccupiedReplicas).To(Equal(int32(2)))
+			Expect(status.DesiredReplicas).To(Equal(int32(3)))
+			Expect(status.LastScaleTime).NotTo(BeNil())
+			Expect(meta.IsStatusConditionTrue(status.Conditions, ConditionAbleToScale)).To(BeTrue())
+			Expect(<-recorder.Events).To(Equal("Normal Scaled Scaled fleet event from 2 to 3 replicas: 2 occupied servers with a buffer of 1 need 3 replicas"))
+		})
+
+		It("should wait for the cooldown before scaling down", func() {
+			fleet := newFleet(3)
+			setup(newAutoscaler(&metav1.Time{Time: time.Now().Add(-time.Minute)}),
+				fleet, server(fleet, 0, 0), server(fleet, 1, 0), server(fleet, 2, 4))
+
+			result := reconcileAutoscaler()
+			Expect(result.RequeueAfter).To(BeNumerically("~", 4*time.Minute, 5*time.Second))
+			Expect(fleetReplicas()).To(Equal(int32(3)))
+			able := meta.FindStatusCondition(getStatus().Conditions, ConditionAbleToScale)
+			Expect(able.Status).To(Equal(metav1.ConditionFalse))
+			Expect(able.Reason).To(Equal(ReasonScaleDownCooldown))
+			Expect(able.Message).To(HavePrefix("Scaling from 3 to 2 replicas waits until "))
+			Expect(getStatus().DesiredReplicas).To(Equal(int32(2)))
+			Expect(recorder.Events).To(BeEmpty())
+		})
+
+		It("should report a missing fleet", func() {
+			setup(newAutoscaler(nil))
+
+			reconcileAutoscaler()
+			able := meta.FindStatusCondition(getStatus().Conditions, ConditionAbleToScale)
+			Expect(able.Status).To(Equal(metav1.ConditionFalse))
+			Expect(able.Reason).To(Equal(ReasonFleetNotFound))
+		})
+	})
+})
@@ -19,10 +19,17 @@ package controller
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"k8s.io/apimachinery/pkg/runtime"
@@ -34,24 +41,89 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	apiv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
 	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1/game"
+	"github.com/templarfelix/gameserver-operator/internal/a2s"
 	"github.com/templarfelix/gameserver-operator/internal/controller"
+	"github.com/templarfelix/gameserver-operator/internal/steam"
 )
 
+// DefaultDayzQueryPort is the Steam query port of a LinuxGSM DayZ server
+const DefaultDayzQueryPort int32 = 27016
+
+// dayzServerAppID is the Steam app of the DayZ dedicated server
+const dayzServerAppID = 223350
+
+// dayzKind labels the metrics exported for Dayz servers
+const dayzKind = "Dayz"
+
+// dayzLogFiles are the logs LinuxGSM and the game write, the game starts new RPT and ADM files in its
+// profiles directory at each start
+var dayzLogFiles = controller.LogFiles{
+	apiv1alpha1.LogStreamConsole: "/data/log/console/dayzserver-console.log",
+	apiv1alpha1.LogStreamRPT:     "/data/serverfiles/profiles/*.RPT",
+	apiv1alpha1.LogStreamADM:     "/data/serverfiles/profiles/*.ADM",
+}
+
+// dayzBattlEyeConfigPath is the BattlEye server config LinuxGSM starts DayZ with
+const dayzBattlEyeConfigPath = "/data/serverfiles/battleye/beserver_x64.cfg"
+
+// dayzWorkshopAppID is the Steam app the DayZ Workshop items belong to
+const dayzWorkshopAppID = 221100
+
+// dayzMissionContainerName is the init container downloading spec.mission.source
+const dayzMissionContainerName = "mission"
+
+// dayzLinuxGSMConfigPath is the LinuxGSM instance config holding the mods parameters
+const dayzLinuxGSMConfigPath = "/data/config-lgsm/dayzserver/dayzserver.cfg"
+
+// dayzModsMarker ends the LinuxGSM config lines managed from spec.mods
+const dayzModsMarker = "# managed by gameserver-operator: spec.mods"
+
 // DayzReconciler reconciles a Dayz object
 type DayzReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// Querier reads readiness and player counts from the game server, an A2S client is used when nil
+	Querier controller.ServerQuerier
+
+	// BuildChecker looks up the latest DayZ server build, the SteamCMD info API is used when nil
+	BuildChecker controller.BuildChecker
+
+	// WakeProxyImage runs the wake proxy of servers with spec.idle.wakeOnConnect, DefaultOperatorImage when empty
+	WakeProxyImage string
+
+	// EconomyImage merges spec.economy into the mission, DefaultOperatorImage when empty
+	EconomyImage string
+
+	// LogsImage streams the logs of spec.logs, DefaultOperatorImage when empty
+	LogsImage string
+
+	// Recorder records the lifecycle Events of the Dayz resources, none are recorded when nil
+	Recorder record.EventRecorder
+
+	// Notifier delivers spec.notifications, none are sent when nil
+	Notifier controller.Notifier
 }
 
 //+kubebuilder:rbac:groups=gameserver.templarfelix.com,resources=dayzs,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=gameserver.templarfelix.com,resources=dayzs/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=gameserver.templarfelix.com,resources=dayzs/finalizers,verbs=update
+//+kubebuilder:rbac:groups=gameserver.templarfelix.com,resources=playerlists,verbs=get;list;watch
+//+kubebuilder:rbac:groups=gameserver.templarfelix.com,resources=gameservertemplates,verbs=get;list;watch
+//+kubebuilder:rbac:groups=gameserver.templarfelix.com,resources=gameserverclasses,verbs=get;list;watch
 
 //+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
 
 // Add RBAC for networking resources to fix permission warnings
 //+kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch
@@ -73,6 +145,7 @@ func (r *DayzReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 	err := r.Get(ctx, req.NamespacedName, instance)
 	if err != nil {
 		if errors.IsNotFound(err) {
+			controller.DeleteGameServerMetrics(dayzKind, req.Namespace, req.Name)
 			return reconcile.Result{}, nil
 		}
 		return reconcile.Result{}, err
@@ -85,13 +158,19 @@ func (r *DayzReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 			// Perform cleanup
 			pvcName := instance.Name + "-pvc"
 			pvc := &corev1.PersistentVolumeClaim{}
-			err := r.Get(ctx, types.NamespacedName{Name: pvcName, Namespace: instance.Namespace}, pvc)
-			if err != nil && !errors.IsNotFound(err) {
-				logger.Error(err, "Failed to get PVC")
-				return reconcile.Result{}, err
+			pvcErr := r.Get(ctx, types.NamespacedName{Name: pvcName, Namespace: instance.Namespace}, pvc)
+			if pvcErr != nil && !errors.IsNotFound(pvcErr) {
+				logger.Error(pvcErr, "Failed to get PVC")
+				return reconcile.Result{}, pvcErr
 			}
-			if err == nil { // PVC exists
-				if instance.Spec.Persistence.PreserveOnDelete {
+			// The template or class may preserve the volume, the spec decides when they are gone
+			preserve := controller.IsTrue(instance.Spec.Persistence.PreserveOnDelete)
+			effective, _, resolveErr := controller.ResolveBase(ctx, r.Client, instance, &instance.Spec.Base)
+			if resolveErr == nil {
+				preserve = controller.IsTrue(effective.Persistence.PreserveOnDelete)
+			}
+			if pvcErr == nil { // PVC exists
+				if preserve {
 					// Remove owner reference to preserve PVC
 					pvc.OwnerReferences = nil // Remove all owner refs
 					if err := r.Update(ctx, pvc); err != nil {
@@ -99,9 +178,13 @@ func (r *DayzReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 						return reconcile.Result{}, err
 					}
 					logger.Info("Preserved PVC by removing owner reference")
+					controller.RecordEvent(r.Recorder, instance, corev1.EventTypeNormal, controller.EventReasonPVCPreserved,
+						"Preserved PVC %s, it is not deleted with the game server", pvcName)
 				} // else let GC delete it
 			}
 
+			controller.DeleteGameServerMetrics(dayzKind, instance.Namespace, instance.Name)
+
 			// Remove finalizer
 			controllerutil.RemoveFinalizer(instance, finalizer)
 			if err := r.Update(ctx, instance); err != nil {
@@ -132,20 +215,178 @@ func (r *DayzReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 		return reconcile.Result{Requeue: true}, nil
 	}
 
-	// Normal reconciliation
+	// Normal reconciliation runs with the effective spec, it is never written back
+	effective, defaultsCondition, err := controller.ResolveBase(ctx, r.Client, instance, &instance.Spec.Base)
+	if err != nil {
+		return r.reconcileFailed(instance, controller.StepDefaults, err)
+	}
+	defaultDayzBase(effective)
+	instance.Spec.Base = *effective
+
 	if err := r.reconcilePVC(ctx, instance); err != nil {
-		return reconcile.Result{}, err
+		return r.reconcileFailed(instance, controller.StepPVC, err)
 	}
 
-	if err := r.reconcileDeployment(ctx, instance); err != nil {
-		return reconcile.Result{}, err
+	editorPasswordRef, editorSecretVersion, err := r.reconcileEditorSecret(ctx, instance)
+	if err != nil {
+		return r.reconcileFailed(instance, controller.StepEditorSecret, err)
+	}
+
+	status := instance.Status.DeepCopy()
+	if err := controller.UpdateDefaultsStatus(&status.BaseStatus, effective, defaultsCondition); err != nil {
+		return r.reconcileFailed(instance, controller.StepDefaults, err)
+	}
+	idleWait := controller.UpdateIdleStatus(instance, &instance.Spec.Base, &status.BaseStatus, time.Now())
+	updateWait := r.checkForUpdate(ctx, instance, &status.BaseStatus)
+	playerListsWait, err := r.reconcilePlayerLists(ctx, instance, &status.BaseStatus)
+	if err != nil {
+		return r.reconcileFailed(instance, controller.StepPlayerLists, err)
+	}
+	if err := controller.ReconcileLogsServiceAccount(ctx, r.Client, instance, &instance.Spec.Logs, dayzLogFiles); err != nil {
+		return r.reconcileFailed(instance, controller.StepLogs, err)
+	}
+	rolloutWait, err := r.reconcileDeployment(ctx, instance, editorPasswordRef, editorSecretVersion, &status.BaseStatus)
+	if err != nil {
+		return r.reconcileFailed(instance, controller.StepDeployment, err)
+	}
+
+	// The wake proxy is up before the LoadBalancer Services select it
+	port := controller.QueryPort(&instance.Spec.Query, DefaultDayzQueryPort)
+	if err := controller.ReconcileWakeProxy(ctx, r.Client, instance, &instance.Spec.Base, apiv1alpha1.GroupVersion.WithResource("dayzs"), port, r.WakeProxyImage); err != nil {
+		return r.reconcileFailed(instance, controller.StepWakeProxy, err)
 	}
 
 	if err := r.reconcileServices(ctx, instance); err != nil {
-		return reconcile.Result{}, err
+		return r.reconcileFailed(instance, controller.StepService, err)
+	}
+
+	if err := r.reconcileEditorExposure(ctx, instance); err != nil {
+		return r.reconcileFailed(instance, controller.StepEditorExposure, err)
+	}
+
+	status.EditorSecretName = ""
+	if editorPasswordRef != nil {
+		status.EditorSecretName = editorPasswordRef.Name
+	}
+	if err := r.updateQueryStatus(ctx, instance, &status.BaseStatus); err != nil {
+		return r.reconcileFailed(instance, controller.StepStatus, err)
+	}
+	if err := r.updateModStatus(ctx, instance, status); err != nil {
+		return r.reconcileFailed(instance, controller.StepStatus, err)
+	}
+	if err := controller.UpdateEconomyCondition(ctx, r.Client, instance, &status.BaseStatus, dayzPatchesMission(instance)); err != nil {
+		return r.reconcileFailed(instance, controller.StepStatus, err)
+	}
+	if err := controller.UpdateCrashStatus(ctx, r.Client, instance, &status.BaseStatus); err != nil {
+		return r.reconcileFailed(instance, controller.StepStatus, err)
+	}
+	if err := controller.RecordGameServerMetrics(ctx, r.Client, dayzKind, instance, &status.BaseStatus); err != nil {
+		logger.Error(err, "Failed to record game server metrics")
+	}
+
+	if !equality.Semantic.DeepEqual(&instance.Status, status) {
+		old := instance.Status.BaseStatus
+		instance.Status = *status
+		if err := r.Status().Update(ctx, instance); err != nil {
+			if errors.IsConflict(err) {
+				logger.Info("Conflict updating status, requeueing")
+				return reconcile.Result{Requeue: true}, nil
+			}
+			logger.Error(err, "Failed to update status")
+			return r.reconcileFailed(instance, controller.StepStatus, err)
+		}
+		controller.RecordStatusEvents(r.Recorder, instance, &old, &status.BaseStatus)
+		controller.NotifyStatusChanges(r.Notifier, instance, dayzKind, instance.Spec.Notifications, &old, &status.BaseStatus, time.Now())
+	}
+
+	// Requeue periodically to keep readiness and player counts current, sooner for a pending rollout step
+	// or an expiring player list entry
+	requeueAfter := controller.QueryPeriod(&instance.Spec.Query)
+	for _, wait := range []time.Duration{rolloutWait, updateWait, idleWait, playerListsWait} {
+		if wait > 0 && wait < requeueAfter {
+			requeueAfter = wait
+		}
+	}
+	return reconcile.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// reconcileFailed records the failure of a reconcile step in the metrics and as a Warning Event, and
+// returns err to retry the reconcile. Conflicts are retried without an Event.
+func (r *DayzReconciler) reconcileFailed(instance *gameserverv1alpha1.Dayz, step string, err error) (ctrl.Result, error) {
+	controller.RecordReconcileError(dayzKind, step)
+	if !errors.IsConflict(err) {
+		controller.RecordEvent(r.Recorder, instance, corev1.EventTypeWarning, controller.EventReasonReconcileFailed, "Reconcile step %s failed: %v", step, err)
+	}
+	return reconcile.Result{}, err
+}
+
+// updateQueryStatus queries the game server with the configured or default A2S querier
+func (r *DayzReconciler) updateQueryStatus(ctx context.Context, instance *gameserverv1alpha1.Dayz, status *apiv1alpha1.BaseStatus) error {
+	if controller.IsPaused(&instance.Spec.Base, status) {
+		controller.SetPausedStatus(instance, status)
+		return nil
 	}
 
-	return reconcile.Result{}, nil
+	querier := r.Querier
+	if querier == nil {
+		querier = &a2s.Client{}
+	}
+	port := controller.QueryPort(&instance.Spec.Query, DefaultDayzQueryPort)
+	return controller.UpdateQueryStatus(ctx, r.Client, querier, instance, port, status)
+}
+
+// updateModStatus lists the mods of spec.mods with the version installed in the running game pod
+func (r *DayzReconciler) updateModStatus(ctx context.Context, instance *gameserverv1alpha1.Dayz, status *gameserverv1alpha1.DayzStatus) error {
+	if len(instance.Spec.Mods) == 0 {
+		status.Mods = nil
+		return nil
+	}
+	versions, err := controller.InstalledWorkshopVersions(ctx, r.Client, instance)
+	if err != nil {
+		return err
+	}
+
+	previous := map[string]*metav1.Time{}
+	for _, mod := range status.Mods {
+		previous[mod.WorkshopID] = mod.Updated
+	}
+	mods := make([]gameserverv1alpha1.DayzModStatus, 0, len(instance.Spec.Mods))
+	for _, mod := range instance.Spec.Mods {
+		modStatus := gameserverv1alpha1.DayzModStatus{WorkshopID: mod.WorkshopID, Name: dayzModName(mod)}
+		if updated, ok := versions[mod.WorkshopID]; ok {
+			t := metav1.NewTime(updated)
+			modStatus.Updated = &t
+		} else if versions == nil {
+			// Keep the last known version while no pod is running
+			modStatus.Updated = previous[mod.WorkshopID]
+		}
+		mods = append(mods, modStatus)
+	}
+	status.Mods = mods
+	return nil
+}
+
+// checkForUpdate looks up the latest DayZ server build with the configured or default build checker
+func (r *DayzReconciler) checkForUpdate(ctx context.Context, instance *gameserverv1alpha1.Dayz, status *apiv1alpha1.BaseStatus) time.Duration {
+	checker := r.BuildChecker
+	if checker == nil {
+		checker = &steam.Client{}
+	}
+	return controller.CheckForUpdate(ctx, checker, &instance.Spec.Updates, dayzServerAppID, status, time.Now())
+}
+
+// reconcileEditorSecret wraps ReconcileEditorSecret with logging for concurrency conflicts
+func (r *DayzReconciler) reconcileEditorSecret(ctx context.Context, instance *gameserverv1alpha1.Dayz) (*corev1.SecretKeySelector, string, error) {
+	logger := log.FromContext(ctx)
+	ref, version, err := controller.ReconcileEditorSecret(ctx, r.Client, instance, &instance.Spec.Base)
+	if err != nil {
+		// Log concurrent modification conflicts
+		if errors.IsConflict(err) {
+			logger.Info("Editor Secret conflict detected, will retry")
+		}
+		return nil, "", err
+	}
+	return ref, version, nil
 }
 
 // reconcilePVC wraps ReconcilePVC with logging for concurrency conflicts
@@ -164,7 +405,8 @@ func (r *DayzReconciler) reconcilePVC(ctx context.Context, instance *gameserverv
 // reconcileServices wraps ReconcileServices with logging for concurrency conflicts
 func (r *DayzReconciler) reconcileServices(ctx context.Context, instance *gameserverv1alpha1.Dayz) error {
 	logger := log.FromContext(ctx)
-	if err := controller.ReconcileServices(ctx, r.Client, instance, instance.Spec.Ports, instance.Spec.LoadBalancerIP); err != nil {
+	if err := controller.ReconcileServices(ctx, r.Client, instance, instance.Spec.Ports, instance.Spec.LoadBalancerIP,
+		instance.Spec.ServiceAnnotations, controller.ServiceSelector(instance, &instance.Spec.Base)); err != nil {
 		// Log concurrent modification conflicts
 		if errors.IsConflict(err) {
 			logger.Info("Services conflict detected, will retry")
@@ -174,9 +416,30 @@ func (r *DayzReconciler) reconcileServices(ctx context.Context, instance *gamese
 	return nil
 }
 
-func (r *DayzReconciler) reconcileDeployment(ctx context.Context, instance *gameserverv1alpha1.Dayz) error {
+// reconcileEditorExposure wraps ReconcileEditorExposure with logging for concurrency conflicts
+func (r *DayzReconciler) reconcileEditorExposure(ctx context.Context, instance *gameserverv1alpha1.Dayz) error {
+	logger := log.FromContext(ctx)
+	if err := controller.ReconcileEditorExposure(ctx, r.Client, instance, &instance.Spec.Editor); err != nil {
+		// Log concurrent modification conflicts
+		if errors.IsConflict(err) {
+			logger.Info("Editor exposure conflict detected, will retry")
+		}
+		return err
+	}
+	return nil
+}
+
+// reconcileDeployment creates or updates the game Deployment. Updates replacing the game pod wait for the
+// maintenance window and the shutdown countdown, the returned duration is the time left until the next
+// countdown step, maintenance window or scheduled restart.
+func (r *DayzReconciler) reconcileDeployment(ctx context.Context, instance *gameserverv1alpha1.Dayz, editorPasswordRef *corev1.SecretKeySelector, editorSecretVersion string, status *apiv1alpha1.BaseStatus) (time.Duration, error) {
 	logger := log.FromContext(ctx)
 
+	configScript, err := r.generateDayzConfigSetupScript(instance)
+	if err != nil {
+		return 0, err
+	}
+
 	// Generate container ports dynamically from CRD ports
 	var containerPorts []corev1.ContainerPort
 	for _, port := range instance.Spec.Ports {
@@ -194,7 +457,7 @@ func (r *DayzReconciler) reconcileDeployment(ctx context.Context, instance *game
 			Namespace: instance.Namespace,
 		},
 		Spec: appsv1.DeploymentSpec{
-			Replicas: func() *int32 { r := int32(1); return &r }(),
+			Replicas: controller.GameServerReplicas(&instance.Spec.Base, status),
 			Selector: &metav1.LabelSelector{
 				MatchLabels: map[string]string{"app": instance.Name},
 			},
@@ -203,9 +466,10 @@ func (r *DayzReconciler) reconcileDeployment(ctx context.Context, instance *game
 					Labels: map[string]string{"app": instance.Name},
 				},
 				Spec: corev1.PodSpec{
-					NodeSelector: instance.Spec.NodeSelector,
-					Tolerations:  instance.Spec.Tolerations,
-					Affinity:     instance.Spec.Affinity,
+					NodeSelector:                  instance.Spec.NodeSelector,
+					Tolerations:                   instance.Spec.Tolerations,
+					Affinity:                      instance.Spec.Affinity,
+					TerminationGracePeriodSeconds: controller.ShutdownGracePeriod(&instance.Spec.Shutdown),
 					SecurityContext: &corev1.PodSecurityContext{
 						FSGroup: func(i int64) *int64 { return &i }(1000),
 					},
@@ -215,7 +479,8 @@ func (r *DayzReconciler) reconcileDeployment(ctx context.Context, instance *game
 							Name:    "config-writer",
 							Image:   controller.SetupContainerImage,
 							Command: []string{"sh", "-c"},
-							Args:    []string{r.generateDayzConfigSetupScript(instance)},
+							Args:    []string{configScript},
+							Env:     dayzConfigWriterEnv(instance),
 							VolumeMounts: []corev1.VolumeMount{
 								{Name: "tmp-configs", MountPath: "/tmp/configs"},
 							},
@@ -253,22 +518,21 @@ func (r *DayzReconciler) reconcileDeployment(ctx context.Context, instance *game
 								chown -R 1000:1000 /data/config-lgsm/dayzserver /data/serverfiles/cfg
 
 								echo "DayZ config setup completed successfully"
-							`},
+							` + dayzModsConfigScript(instance) + dayzPlayerListsLinkScript(instance)},
 							SecurityContext: &corev1.SecurityContext{
 								RunAsUser:  func(i int64) *int64 { return &i }(1000),
 								RunAsGroup: func(i int64) *int64 { return &i }(1000),
 							},
-							VolumeMounts: []corev1.VolumeMount{
+							VolumeMounts: append([]corev1.VolumeMount{
 								{Name: controller.DataVolumeName, MountPath: "/data"},
 								{Name: "tmp-configs", MountPath: "/tmp/configs"},
-							},
+							}, dayzPlayerListsMounts(instance)...),
 						},
 					},
 					Containers: []corev1.Container{
-						controller.GetSecureGameServerContainer("server", instance.Spec.Image, instance.Spec.Resources, containerPorts),
-						controller.GetSecureCodeServerContainer(instance.Spec.EditorPassword),
+						dayzServerContainer(instance, containerPorts),
 					},
-					Volumes: []corev1.Volume{
+					Volumes: append([]corev1.Volume{
 						{
 							Name: "tmp-configs", // Direct file path volume
 							VolumeSource: corev1.VolumeSource{
@@ -283,74 +547,250 @@ func (r *DayzReconciler) reconcileDeployment(ctx context.Context, instance *game
 								},
 							},
 						},
-					},
+					}, dayzPlayerListsVolumes(instance)...),
 				},
 			},
 		},
 	}
 
-	if err := controllerutil.SetControllerReference(instance, k8sResource, r.Scheme); err != nil {
-		return err
+	if len(instance.Spec.Mods) > 0 && instance.Spec.SteamCredentialsSecretRef != nil {
+		k8sResource.Spec.Template.Spec.InitContainers = append(k8sResource.Spec.Template.Spec.InitContainers,
+			controller.GetWorkshopInitContainer(dayzWorkshopAppID, dayzWorkshopItems(instance), "/data/serverfiles/keys", *instance.Spec.SteamCredentialsSecretRef))
 	}
 
+	if source := dayzMissionSource(instance); source != nil {
+		k8sResource.Spec.Template.Spec.InitContainers = append(k8sResource.Spec.Template.Spec.InitContainers,
+			controller.GetSourceInitContainer(dayzMissionContainerName, *source, dayzMissionDir(instance)))
+	}
+
+	// The economy is merged last, into the mission files of the installed server
+	if dayzPatchesMission(instance) {
+		patches, err := dayzEconomyPatches(instance)
+		if err != nil {
+			return 0, err
+		}
+		economyContainer, err := controller.GetEconomyInitContainer(r.EconomyImage, patches)
+		if err != nil {
+			return 0, err
+		}
+		k8sResource.Spec.Template.Spec.InitContainers = append(k8sResource.Spec.Template.Spec.InitContainers, economyContainer)
+	}
+
+	if editorPasswordRef != nil {
+		k8sResource.Spec.Template.Spec.Containers = append(k8sResource.Spec.Template.Spec.Containers,
+			controller.GetSecureCodeServerContainer(&instance.Spec.Editor, *editorPasswordRef))
+		controller.SetEditorSecretVersion(k8sResource, editorSecretVersion)
+	}
+
+	controller.AddLogContainers(&k8sResource.Spec.Template.Spec, r.LogsImage, instance,
+		apiv1alpha1.GroupVersion.WithKind(dayzKind), &instance.Spec.Logs, dayzLogFiles)
+
+	if err := controllerutil.SetControllerReference(instance, k8sResource, r.Scheme); err != nil {
+		return 0, err
+	}
 	found := &appsv1.Deployment{}
-	err := r.Get(ctx, client.ObjectKey{Name: k8sResource.Name, Namespace: k8sResource.Namespace}, found)
-	if err != nil && errors.IsNotFound(err) {
+	err = r.Get(ctx, client.ObjectKey{Name: k8sResource.Name, Namespace: k8sResource.Namespace}, found)
+	if err != nil && !errors.IsNotFound(err) {
+		return 0, err
+	}
+	exists := err == nil
+
+	// Scheduled restarts bump the pod template, other changes may have to wait for a maintenance window
+	now := time.Now()
+	var existing *appsv1.Deployment
+	if exists {
+		existing = found
+	}
+	plan, err := controller.PlanRollout(&instance.Spec.Schedule, existing, status.NextRestartTime, now)
+	if err != nil {
+		return 0, err
+	}
+	plan.Allocated = controller.IsAllocated(instance)
+	controller.SetRestartedAt(k8sResource, plan)
+	if err := controller.SetBuildID(existing, k8sResource, &instance.Spec.Updates, status); err != nil {
+		return 0, err
+	}
+
+	if !exists {
+		controller.DeferRollout(plan, false, instance.Generation, status)
 		logger.Info("Creating a new Deployment", "Namespace", k8sResource.Namespace, "Name", k8sResource.Name)
 		err = r.Create(ctx, k8sResource)
 		if err != nil {
-			return err
+			return 0, err
+		}
+		controller.RecordEvent(r.Recorder, instance, corev1.EventTypeNormal, controller.EventReasonCreated, "Created Deployment %s", k8sResource.Name)
+		return plan.Wait(now), nil // Don't update immediately after creation
+	}
+
+	// A paused server has no pod to disrupt, changes are applied right away
+	podTemplateChanged := controller.PodTemplateChanged(found, k8sResource) && !controller.IsPaused(&instance.Spec.Base, status)
+	if controller.DeferRollout(plan, podTemplateChanged, instance.Generation, status) {
+		logger.Info("Deployment update waits for the next maintenance window")
+		status.Shutdown = nil
+		return plan.Wait(now), nil
+	}
+
+	if !podTemplateChanged {
+		status.Shutdown = nil
+	} else {
+		// Warn the players before the update replaces the game pod
+		wait, err := controller.RunShutdownCountdown(ctx, r.Client, instance, &instance.Spec.Base, controller.BattlEyeProfile, status, now)
+		if err != nil {
+			return 0, err
+		}
+		if wait > 0 {
+			logger.Info("Deployment update waits for the shutdown countdown", "remaining", wait)
+			return wait, nil
+		}
+		if plan.RestartDue {
+			logger.Info("Restarting the game server as scheduled", "restartedAt", plan.RestartedAt)
 		}
-		return nil // Don't update immediately after creation
-	} else if err != nil {
-		return err
 	}
 
 	// Check if the Deployment needs update
-	if !controller.CompareDeployments(found, k8sResource) {
+	templateChanged := controller.PodTemplateChanged(found, k8sResource)
+	if !controller.CompareDeployments(found, k8sResource) || templateChanged {
 		logger.Info("Updating Deployment", "Namespace", found.Namespace, "Name", found.Name)
 		found.Spec = k8sResource.Spec
+		if found.Annotations == nil {
+			found.Annotations = map[string]string{}
+		}
+		found.Annotations[controller.PodTemplateHashAnnotation] = k8sResource.Annotations[controller.PodTemplateHashAnnotation]
 		if err := r.Update(ctx, found); err != nil {
 			if errors.IsConflict(err) {
 				logger.Info("Conflict updating deployment, will retry")
 			}
-			return err
+			return 0, err
+		}
+		switch {
+		case templateChanged && plan.RestartDue:
+			controller.RecordEvent(r.Recorder, instance, corev1.EventTypeNormal, controller.EventReasonRestarting, "Restarting the game server as scheduled")
+		case templateChanged:
+			controller.RecordEvent(r.Recorder, instance, corev1.EventTypeNormal, controller.EventReasonConfigApplied, "Applied the changed configuration to Deployment %s", found.Name)
 		}
 	}
 
 	logger.V(4).Info("Deployment already exists and is up to date", "namespace", found.Namespace, "name", found.Name)
 
-	return nil
+	return plan.Wait(now), nil
+}
+
+// dayzServerContainer returns the game container, stopped through LinuxGSM before the pod terminates
+func dayzServerContainer(instance *gameserverv1alpha1.Dayz, ports []corev1.ContainerPort) corev1.Container {
+	container := controller.GetSecureGameServerContainer(controller.GameContainerName, instance.Spec.Image, instance.Spec.Resources, ports)
+	container.Lifecycle = controller.GetLinuxGSMPreStopHook("dayzserver")
+	container.Env = append(container.Env, controller.LinuxGSMUpdateCheckEnv)
+	container.VolumeMounts = append(container.VolumeMounts, dayzPlayerListsMounts(instance)...)
+	return container
 }
 
 // generateDayzConfigSetupScript creates a shell script that writes config files to the tmp-configs volume
-func (r *DayzReconciler) generateDayzConfigSetupScript(instance *gameserverv1alpha1.Dayz) string {
+func (r *DayzReconciler) generateDayzConfigSetupScript(instance *gameserverv1alpha1.Dayz) (string, error) {
 	script := `set -eu
 mkdir -p /tmp/configs
 
 # Write config files directly to tmp-configs volume
 `
 
-	// Add all configuration files
+	// Add all configuration files, the server.cfg is rendered with spec.settings below
 	if instance.Spec.Config != nil {
 		for filepath, content := range instance.Spec.Config {
+			if filepath == dayzServerConfigPath && dayzRendersServerConfig(instance) {
+				continue
+			}
 			script += fmt.Sprintf("mkdir -p $(dirname '/tmp/configs%s')\n", filepath)
 			script += fmt.Sprintf("cat > '/tmp/configs%s' << 'EOF'\n%s\nEOF\n", filepath, content)
 		}
 	}
 
+	if dayzRendersServerConfig(instance) {
+		content, err := renderDayzServerConfig(instance)
+		if err != nil {
+			return "", err
+		}
+		script += fmt.Sprintf("mkdir -p $(dirname '/tmp/configs%s')\n", dayzServerConfigPath)
+		script += fmt.Sprintf("cat > '/tmp/configs%s' << 'EOF'\n%sEOF\n", dayzServerConfigPath, content)
+		if settings := instance.Spec.Settings; settings != nil {
+			if settings.PasswordSecretRef != nil {
+				script += dayzPasswordScript("password", dayzPasswordEnv)
+			}
+			if settings.AdminPasswordSecretRef != nil {
+				script += dayzPasswordScript("passwordAdmin", dayzAdminPasswordEnv)
+			}
+		}
+	}
+
+	// Configure BattlEye RCon from the password Secret unless the config is managed by hand
+	if _, ok := instance.Spec.Config[dayzBattlEyeConfigPath]; !ok && instance.Spec.RCon.PasswordSecretRef != nil {
+		port := controller.RConPort(&instance.Spec.RCon, controller.DefaultBattlEyeRConPort)
+		script += fmt.Sprintf("mkdir -p $(dirname '/tmp/configs%s')\n", dayzBattlEyeConfigPath)
+		script += fmt.Sprintf("printf 'RConPassword %%s\\nRConPort %d\\nRestrictRCon 0\\n' \"$%s\" > '/tmp/configs%s'\n",
+			port, controller.RConPasswordEnv, dayzBattlEyeConfigPath)
+	}
+
 	script += "echo 'Config files written to tmp-configs volume successfully'\n"
+	return script, nil
+}
+
+// dayzModName returns the @Mod folder of mod
+func dayzModName(mod gameserverv1alpha1.DayzMod) string {
+	if mod.Name != "" {
+		return mod.Name
+	}
+	return "@" + mod.WorkshopID
+}
+
+// dayzWorkshopItems links each mod of spec.mods to its @Mod folder in serverfiles
+func dayzWorkshopItems(instance *gameserverv1alpha1.Dayz) []controller.WorkshopItem {
+	var items []controller.WorkshopItem
+	for _, mod := range instance.Spec.Mods {
+		items = append(items, controller.WorkshopItem{ID: mod.WorkshopID, Link: "/data/serverfiles/" + dayzModName(mod)})
+	}
+	return items
+}
+
+// dayzModsConfigScript renders the LinuxGSM mods and servermods parameters of spec.mods in load order.
+// Lines written for a previous spec are removed, other settings of the config are kept.
+func dayzModsConfigScript(instance *gameserverv1alpha1.Dayz) string {
+	script := fmt.Sprintf("\ntouch '%s'\nsed -i '/%s$/d' '%s'\n", dayzLinuxGSMConfigPath, dayzModsMarker, dayzLinuxGSMConfigPath)
+	if len(instance.Spec.Mods) == 0 {
+		return script
+	}
+
+	var mods, serverMods []string
+	for _, mod := range instance.Spec.Mods {
+		if mod.ServerSide {
+			serverMods = append(serverMods, dayzModName(mod))
+		} else {
+			mods = append(mods, dayzModName(mod))
+		}
+	}
+	script += fmt.Sprintf("cat >> '%s' << 'EOF'\nmods=\"%s\" %s\nservermods=\"%s\" %s\nEOF\n", dayzLinuxGSMConfigPath,
+		strings.Join(mods, ";"), dayzModsMarker, strings.Join(serverMods, ";"), dayzModsMarker)
 	return script
 }
 
+// dayzConfigWriterEnv exposes the RCon and server passwords to the config writer without placing them in the pod spec
+func dayzConfigWriterEnv(instance *gameserverv1alpha1.Dayz) []corev1.EnvVar {
+	env := dayzPasswordEnvVars(instance)
+	if instance.Spec.RCon.PasswordSecretRef != nil {
+		env = append(env, controller.RConPasswordEnvVar(&instance.Spec.RCon))
+	}
+	return env
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *DayzReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	// Temporarily disabled webhooks due to certificate issues
-	// if err := (&DayzValidator{}).SetupWebhookWithManager(mgr); err != nil {
-	//	return err
-	// }
-
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&gameserverv1alpha1.Dayz{}).
+		// Status updates are not reconciled, the query requeue refreshes the status on its own period.
+		// Labels and annotations carry the allocation and the wake request.
+		For(&gameserverv1alpha1.Dayz{}, builder.WithPredicates(predicate.Or(
+			predicate.GenerationChangedPredicate{},
+			predicate.LabelChangedPredicate{},
+			predicate.AnnotationChangedPredicate{},
+		))).
+		Watches(&apiv1alpha1.PlayerList{}, handler.EnqueueRequestsFromMapFunc(r.dayzsForPlayerList)).
+		Watches(&apiv1alpha1.GameServerTemplate{}, handler.EnqueueRequestsFromMapFunc(r.dayzsForTemplate)).
+		Watches(&apiv1alpha1.GameServerClass{}, handler.EnqueueRequestsFromMapFunc(r.dayzsForClass)).
 		Complete(r)
 }
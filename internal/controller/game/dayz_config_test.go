@@ -0,0 +1,324 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1/game"
+	"github.com/templarfelix/gameserver-operator/internal/controller"
+)
+
+var _ = Describe("Dayz config setup", func() {
+	reconciler := &DayzReconciler{}
+	rconDayz := func() *gameserverv1alpha1.Dayz {
+		return &gameserverv1alpha1.Dayz{Spec: gameserverv1alpha1.DayzSpec{Base: apiv1alpha1.Base{
+			RCon: apiv1alpha1.RCon{PasswordSecretRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "dayz-rcon"},
+				Key:                  "password",
+			}},
+		}}}
+	}
+
+	It("should write the BattlEye config from the RCon password Secret", func() {
+		dayz := rconDayz()
+
+		script, err := reconciler.generateDayzConfigSetupScript(dayz)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(script).To(ContainSubstring(`RConPort 2306`))
+		Expect(script).To(ContainSubstring(`"$RCON_PASSWORD" > '/tmp/configs/data/serverfiles/battleye/beserver_x64.cfg'`))
+		Expect(script).NotTo(ContainSubstring("dayz-rcon"))
+
+		env := dayzConfigWriterEnv(dayz)
+		Expect(env).To(HaveLen(1))
+		Expect(env[0].Name).To(Equal(controller.RConPasswordEnv))
+		Expect(env[0].ValueFrom.SecretKeyRef.Name).To(Equal("dayz-rcon"))
+	})
+
+	It("should keep a hand written BattlEye config", func() {
+		dayz := rconDayz()
+		dayz.Spec.Config = gameserverv1alpha1.DayzConfig{dayzBattlEyeConfigPath: "RConPassword manual"}
+
+		Expect(reconciler.generateDayzConfigSetupScript(dayz)).NotTo(ContainSubstring("RCON_PASSWORD"))
+	})
+
+	It("should not configure RCon without a password Secret", func() {
+		dayz := &gameserverv1alpha1.Dayz{}
+
+		Expect(reconciler.generateDayzConfigSetupScript(dayz)).NotTo(ContainSubstring("beserver_x64.cfg"))
+		Expect(dayzConfigWriterEnv(dayz)).To(BeEmpty())
+	})
+
+	It("should render the LinuxGSM mods parameters in load order", func() {
+		dayz := &gameserverv1alpha1.Dayz{Spec: gameserverv1alpha1.DayzSpec{Mods: []gameserverv1alpha1.DayzMod{
+			{WorkshopID: "1559212036", Name: "@CF"},
+			{WorkshopID: "1564026768", Name: "@COT"},
+			{WorkshopID: "2116151222", ServerSide: true},
+		}}}
+
+		script := dayzModsConfigScript(dayz)
+		Expect(script).To(ContainSubstring(`sed -i '/` + dayzModsMarker + `$/d' '/data/config-lgsm/dayzserver/dayzserver.cfg'`))
+		Expect(script).To(ContainSubstring(`mods="@CF;@COT" ` + dayzModsMarker))
+		Expect(script).To(ContainSubstring(`servermods="@2116151222" ` + dayzModsMarker))
+
+		items := dayzWorkshopItems(dayz)
+		Expect(items).To(HaveLen(3))
+		Expect(items[2]).To(Equal(controller.WorkshopItem{ID: "2116151222", Link: "/data/serverfiles/@2116151222"}))
+	})
+
+	It("should render the settings over the server.cfg of the config", func() {
+		dayz := &gameserverv1alpha1.Dayz{Spec: gameserverv1alpha1.DayzSpec{
+			Config: gameserverv1alpha1.DayzConfig{dayzServerConfigPath: `// Server name
+hostname = "old";
+passwordAdmin = "plain";
+maxPlayers = 60;
+motdInterval = 60;
+
+class Missions
+{
+    class DayZ
+    {
+        template = "dayzOffline.chernarusplus";
+    };
+};`},
+			Settings: &gameserverv1alpha1.DayzServerSettings{
+				Hostname:               "gameserver-operator",
+				AdminPasswordSecretRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "dayz-passwords"}, Key: "admin"},
+				MaxPlayers:             40,
+				Mission:                "dayzOffline.enoch",
+				ServerTimeAcceleration: "0.5",
+				Disable3rdPerson:       func(b bool) *bool { return &b }(true),
+				Motd:                   []string{"Welcome", `Say "hi"`},
+			},
+		}}
+		dayz.Spec.Query.Port = 27020
+
+		content, err := renderDayzServerConfig(dayz)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content).To(Equal(`// Server name
+hostname = "gameserver-operator";
+maxPlayers = 40;
+motdInterval = 60;
+
+class Missions
+{
+    class DayZ
+    {
+        template = "dayzOffline.enoch";
+    };
+};
+serverTimeAcceleration = 0.5;
+disable3rdPerson = 1;
+motd[] = {"Welcome", "Say ""hi"""};
+steamQueryPort = 27020;
+`))
+
+		script, err := reconciler.generateDayzConfigSetupScript(dayz)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(script).NotTo(ContainSubstring(`"old"`))
+		Expect(script).NotTo(ContainSubstring("plain"))
+		Expect(script).To(ContainSubstring(`"$DAYZ_ADMIN_PASSWORD" | sed 's/"/""/g')" >> '/tmp/configs` + dayzServerConfigPath + "'"))
+		Expect(script).NotTo(ContainSubstring("DAYZ_PASSWORD\""))
+
+		env := dayzConfigWriterEnv(dayz)
+		Expect(env).To(HaveLen(1))
+		Expect(env[0].Name).To(Equal(dayzAdminPasswordEnv))
+		Expect(env[0].ValueFrom.SecretKeyRef.Key).To(Equal("admin"))
+	})
+
+	It("should render the settings without a server.cfg in the config", func() {
+		dayz := &gameserverv1alpha1.Dayz{Spec: gameserverv1alpha1.DayzSpec{
+			Settings: &gameserverv1alpha1.DayzServerSettings{InstanceID: 2},
+		}}
+
+		Expect(renderDayzServerConfig(dayz)).To(Equal("instanceId = 2;\nsteamQueryPort = 27016;\n"))
+	})
+
+	It("should fail on a server.cfg the game cannot read", func() {
+		dayz := &gameserverv1alpha1.Dayz{Spec: gameserverv1alpha1.DayzSpec{
+			Config:   gameserverv1alpha1.DayzConfig{dayzServerConfigPath: "maxPlayers = 60"},
+			Settings: &gameserverv1alpha1.DayzServerSettings{},
+		}}
+
+		_, err := reconciler.generateDayzConfigSetupScript(dayz)
+		Expect(err).To(MatchError(ContainSubstring("line 1: expected ';' after the value of maxPlayers")))
+	})
+
+	It("should merge the economy into the mission of the server", func() {
+		dayz := &gameserverv1alpha1.Dayz{}
+		Expect(dayzMissionDir(dayz)).To(Equal("/data/serverfiles/mpmissions/dayzOffline.chernarusplus"))
+		Expect(dayzTypePatches(dayz)).To(BeEmpty())
+
+		dayz.Spec.Config = gameserverv1alpha1.DayzConfig{dayzServerConfigPath: "class Missions { class DayZ { template = \"dayzOffline.enoch\"; }; };"}
+		Expect(dayzMission(dayz)).To(Equal("dayzOffline.enoch"))
+
+		dayz.Spec.Settings = &gameserverv1alpha1.DayzServerSettings{Mission: "custom.sakhal"}
+		Expect(dayzMission(dayz)).To(Equal("custom.sakhal"))
+
+		lifetime := int32(3600)
+		dayz.Spec.Economy = &gameserverv1alpha1.DayzEconomy{Types: []gameserverv1alpha1.DayzEconomyType{
+			{Name: "AKM", LifetimeSeconds: &lifetime, Usages: []string{"Military"}},
+		}}
+		patches := dayzTypePatches(dayz)
+		Expect(patches).To(HaveLen(1))
+		Expect(*patches[0].Lifetime).To(Equal(int32(3600)))
+		Expect(patches[0].Usages).To(Equal([]string{"Military"}))
+	})
+
+	It("should patch cfggameplay.json of a downloaded mission", func() {
+		dayz := &gameserverv1alpha1.Dayz{Spec: gameserverv1alpha1.DayzSpec{
+			Settings: &gameserverv1alpha1.DayzServerSettings{Mission: "pvp.chernarusplus"},
+			Mission: &gameserverv1alpha1.DayzMission{
+				Source: &gameserverv1alpha1.DayzMissionSource{Git: &gameserverv1alpha1.DayzGitSource{
+					URL:  "https://github.com/example/missions.git",
+					Ref:  "main",
+					Path: "pvp.chernarusplus",
+				}},
+				Gameplay: &gameserverv1alpha1.DayzGameplay{Patches: []gameserverv1alpha1.DayzJSONPatch{
+					{Op: "replace", Path: "/GeneralData/disableBaseDamage", Value: &apiextensionsv1.JSON{Raw: []byte("true")}},
+				}},
+			},
+		}}
+
+		source := dayzMissionSource(dayz)
+		Expect(source).NotTo(BeNil())
+		Expect(source.GitURL).To(Equal("https://github.com/example/missions.git"))
+		Expect(source.Path).To(Equal("pvp.chernarusplus"))
+
+		Expect(dayzPatchesMission(dayz)).To(BeTrue())
+		patches, err := dayzEconomyPatches(dayz)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(patches.Types).To(BeEmpty())
+		Expect(patches.GameplayFile).To(Equal("/data/serverfiles/mpmissions/pvp.chernarusplus/cfggameplay.json"))
+		Expect(string(patches.Gameplay)).To(Equal(`[{"op":"replace","path":"/GeneralData/disableBaseDamage","value":true}]`))
+
+		Expect(renderDayzServerConfig(dayz)).To(ContainSubstring("enableCfgGameplayFile = 1;\n"))
+		disabled := false
+		dayz.Spec.Settings.EnableCfgGameplayFile = &disabled
+		Expect(renderDayzServerConfig(dayz)).To(ContainSubstring("enableCfgGameplayFile = 0;\n"))
+	})
+
+	It("should render the server.cfg for gameplay patches without settings", func() {
+		dayz := &gameserverv1alpha1.Dayz{Spec: gameserverv1alpha1.DayzSpec{
+			Config: gameserverv1alpha1.DayzConfig{dayzServerConfigPath: "hostname = \"Sample\";"},
+			Mission: &gameserverv1alpha1.DayzMission{Gameplay: &gameserverv1alpha1.DayzGameplay{Patches: []gameserverv1alpha1.DayzJSONPatch{
+				{Op: "remove", Path: "/PlayerData/spawnGearPresetFiles/0"},
+			}}},
+		}}
+		Expect(dayzMissionSource(dayz)).To(BeNil())
+
+		script, err := reconciler.generateDayzConfigSetupScript(dayz)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(script).To(ContainSubstring("hostname = \"Sample\";\nenableCfgGameplayFile = 1;\nsteamQueryPort = 27016;\nEOF\n"))
+		Expect(script).NotTo(ContainSubstring("hostname = \"Sample\";\nEOF"))
+	})
+
+	It("should render the active player list entries into the DayZ list files", func() {
+		now := time.Now()
+		expired := metav1.NewTime(now.Add(-time.Minute))
+		list := func(name string, listType apiv1alpha1.PlayerListType, entries ...apiv1alpha1.PlayerListEntry) apiv1alpha1.PlayerList {
+			return apiv1alpha1.PlayerList{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Spec:       apiv1alpha1.PlayerListSpec{Type: listType, Entries: entries},
+			}
+		}
+
+		files, unsupported := renderDayzPlayerLists([]apiv1alpha1.PlayerList{
+			list("bans", apiv1alpha1.PlayerListBan,
+				apiv1alpha1.PlayerListEntry{ID: "76561197960287930", Name: "Cheater", Reason: "Aimbot\nsecond line"},
+				apiv1alpha1.PlayerListEntry{ID: "76561197960287931", Expires: &expired},
+			),
+			list("more-bans", apiv1alpha1.PlayerListBan,
+				apiv1alpha1.PlayerListEntry{ID: "76561197960287930", Reason: "Duplicate"},
+				apiv1alpha1.PlayerListEntry{ID: "0123456789abcdef0123456789abcdef"},
+			),
+			list("vip", apiv1alpha1.PlayerListPriority,
+				apiv1alpha1.PlayerListEntry{ID: "76561197960287932"},
+				apiv1alpha1.PlayerListEntry{ID: "76561197960287933"},
+			),
+			list("whitelist", apiv1alpha1.PlayerListWhitelist),
+			list("admins", apiv1alpha1.PlayerListAdmin, apiv1alpha1.PlayerListEntry{ID: "76561197960287934"}),
+		}, now)
+		Expect(files).To(Equal(map[string]string{
+			"ban.txt":       "76561197960287930 // Cheater: Aimbot second line\n0123456789abcdef0123456789abcdef\n",
+			"priority.txt":  "76561197960287932;76561197960287933;",
+			"whitelist.txt": "",
+		}))
+		Expect(unsupported).To(Equal([]string{"admins"}))
+	})
+
+	It("should only push bans over RCon when they changed", func() {
+		ctx := context.Background()
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(apiv1alpha1.AddToScheme(scheme)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		kicker := &DayzReconciler{Client: c}
+		dayz := rconDayz()
+		dayz.ObjectMeta = metav1.ObjectMeta{Name: "dayz", Namespace: "games"}
+		now := time.Now()
+		bans := []apiv1alpha1.PlayerList{{Spec: apiv1alpha1.PlayerListSpec{
+			Type:    apiv1alpha1.PlayerListBan,
+			Entries: []apiv1alpha1.PlayerListEntry{{ID: "0123456789abcdef0123456789abcdef"}},
+		}}}
+		status := &apiv1alpha1.BaseStatus{}
+
+		// The RCon password Secret is missing, the bans are pushed again on the next reconcile
+		kicker.kickBannedPlayers(ctx, dayz, status, bans, now)
+		Expect(status.BanListHash).To(BeEmpty())
+
+		Expect(c.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "dayz-rcon", Namespace: "games"},
+			Data:       map[string][]byte{"password": []byte("secret")},
+		})).To(Succeed())
+		kicker.kickBannedPlayers(ctx, dayz, status, bans, now)
+		Expect(status.BanListHash).To(Equal(dayzBanListHash(map[string]string{"0123456789abcdef0123456789abcdef": ""})))
+
+		kicker.kickBannedPlayers(ctx, dayz, status, nil, now)
+		Expect(status.BanListHash).To(BeEmpty())
+	})
+
+	It("should mount and link the player lists only when referenced", func() {
+		dayz := &gameserverv1alpha1.Dayz{ObjectMeta: metav1.ObjectMeta{Name: "dayz"}}
+		Expect(dayzPlayerListsMounts(dayz)).To(BeEmpty())
+		Expect(dayzPlayerListsVolumes(dayz)).To(BeEmpty())
+		Expect(dayzPlayerListsLinkScript(dayz)).To(BeEmpty())
+
+		dayz.Spec.PlayerLists = []corev1.LocalObjectReference{{Name: "bans"}}
+		Expect(dayzPlayerListsMounts(dayz)).To(Equal([]corev1.VolumeMount{{Name: controller.PlayerListsVolumeName, MountPath: "/player-lists", ReadOnly: true}}))
+		Expect(dayzPlayerListsVolumes(dayz)[0].ConfigMap.Name).To(Equal("dayz-player-lists"))
+		Expect(dayzPlayerListsLinkScript(dayz)).To(ContainSubstring("'/data/serverfiles'"))
+		Expect(dayzServerContainer(dayz, nil).VolumeMounts).To(ContainElement(dayzPlayerListsMounts(dayz)[0]))
+	})
+
+	It("should remove the mods parameters once all mods are removed", func() {
+		script := dayzModsConfigScript(&gameserverv1alpha1.Dayz{})
+		Expect(script).To(ContainSubstring("sed -i"))
+		Expect(script).NotTo(ContainSubstring("mods="))
+	})
+})
@@ -27,6 +27,7 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	apiv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
 	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1/game"
 )
 
@@ -52,7 +53,7 @@ var _ = Describe("Dayz Controller", func() {
 						Namespace: "default",
 					},
 					Spec: gameserverv1alpha1.DayzSpec{
-						Image: "gameservermanagers/gameserver:dayz",
+						Base: apiv1alpha1.Base{Image: "gameservermanagers/gameserver:dayz"},
 					},
 				}
 				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
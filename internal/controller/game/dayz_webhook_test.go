@@ -0,0 +1,254 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1/game"
+	"github.com/templarfelix/gameserver-operator/internal/controller"
+)
+
+var _ = Describe("Dayz Webhook", func() {
+	ctx := context.Background()
+
+	Context("When defaulting a resource", func() {
+		It("should fill in the image, storage size and DayZ ports", func() {
+			dayz := &gameserverv1alpha1.Dayz{}
+
+			Expect((&DayzDefaulter{}).Default(ctx, dayz)).To(Succeed())
+			Expect(dayz.Spec.Image).To(Equal(DefaultDayzImage))
+			Expect(dayz.Spec.Persistence.StorageConfig.Size).To(Equal("10G"))
+			Expect(dayz.Spec.Ports).To(HaveLen(7))
+			Expect(dayz.Spec.Ports[2].Name).To(Equal("port-2302-udp"))
+		})
+
+		It("should keep user supplied ports", func() {
+			dayz := &gameserverv1alpha1.Dayz{}
+			dayz.Spec.Ports = []corev1.ServicePort{{Name: "game", Port: 2402}}
+
+			Expect((&DayzDefaulter{}).Default(ctx, dayz)).To(Succeed())
+			Expect(dayz.Spec.Ports).To(HaveLen(1))
+			Expect(dayz.Spec.Ports[0].Protocol).To(Equal(corev1.ProtocolTCP))
+			Expect(dayz.Spec.Ports[0].TargetPort.IntValue()).To(Equal(2402))
+		})
+
+		It("should leave the image, storage size and ports to the template or class", func() {
+			dayz := &gameserverv1alpha1.Dayz{}
+			dayz.Spec.TemplateRef = &corev1.LocalObjectReference{Name: "dayz-event"}
+
+			Expect((&DayzDefaulter{}).Default(ctx, dayz)).To(Succeed())
+			Expect(dayz.Spec.Image).To(BeEmpty())
+			Expect(dayz.Spec.Persistence.StorageConfig.Size).To(BeEmpty())
+			Expect(dayz.Spec.Ports).To(BeEmpty())
+
+			dayz = &gameserverv1alpha1.Dayz{}
+			dayz.Spec.ClassName = "dedicated"
+			dayz.Spec.Ports = []corev1.ServicePort{{Name: "game", Port: 2402}}
+			Expect((&DayzDefaulter{}).Default(ctx, dayz)).To(Succeed())
+			Expect(dayz.Spec.Image).To(BeEmpty())
+			Expect(dayz.Spec.Ports[0].TargetPort.IntValue()).To(Equal(2402))
+		})
+
+		It("should default the effective spec after the template", func() {
+			scheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(apiv1alpha1.AddToScheme(scheme)).To(Succeed())
+			template := &apiv1alpha1.GameServerTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: "dayz-event", Namespace: "games"},
+				Spec: apiv1alpha1.GameServerTemplateSpec{Base: apiv1alpha1.Base{
+					Image: "registry.example.com/dayz:event",
+					Ports: []corev1.ServicePort{{Name: "game", Port: 2402, Protocol: corev1.ProtocolUDP}},
+				}},
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(template).Build()
+			dayz := &gameserverv1alpha1.Dayz{ObjectMeta: metav1.ObjectMeta{Name: "event", Namespace: "games"}}
+			dayz.Spec.TemplateRef = &corev1.LocalObjectReference{Name: "dayz-event"}
+			Expect((&DayzDefaulter{}).Default(ctx, dayz)).To(Succeed())
+
+			effective, _, err := controller.ResolveBase(ctx, c, dayz, &dayz.Spec.Base)
+			Expect(err).NotTo(HaveOccurred())
+			defaultDayzBase(effective)
+
+			Expect(effective.Image).To(Equal("registry.example.com/dayz:event"))
+			Expect(effective.Ports).To(HaveLen(1))
+			Expect(effective.Ports[0].Port).To(Equal(int32(2402)))
+			Expect(effective.Ports[0].TargetPort.IntValue()).To(Equal(2402))
+			Expect(effective.Persistence.StorageConfig.Size).To(Equal(controller.DefaultStorageSize))
+		})
+	})
+
+	Context("When validating a resource", func() {
+		validator := &DayzValidator{}
+
+		It("should accept a defaulted resource", func() {
+			dayz := &gameserverv1alpha1.Dayz{}
+			Expect((&DayzDefaulter{}).Default(ctx, dayz)).To(Succeed())
+
+			_, err := validator.ValidateCreate(ctx, dayz)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should reject invalid storage sizes and config paths", func() {
+			dayz := &gameserverv1alpha1.Dayz{}
+			dayz.Spec.Persistence.StorageConfig.Size = "lots"
+			dayz.Spec.Config = gameserverv1alpha1.DayzConfig{"/etc/dayz.cfg": "hostname = \"x\";"}
+
+			_, err := validator.ValidateCreate(ctx, dayz)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.persistence.storageConfig.size"))
+			Expect(err.Error()).To(ContainSubstring("spec.config[/etc/dayz.cfg]"))
+		})
+
+		It("should require Steam credentials and unique mods", func() {
+			dayz := &gameserverv1alpha1.Dayz{}
+			Expect((&DayzDefaulter{}).Default(ctx, dayz)).To(Succeed())
+			dayz.Spec.Mods = []gameserverv1alpha1.DayzMod{
+				{WorkshopID: "1559212036", Name: "@CF"},
+				{WorkshopID: "1559212036"},
+				{WorkshopID: "1564026768", Name: "@CF"},
+			}
+
+			_, err := validator.ValidateCreate(ctx, dayz)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.steamCredentialsSecretRef"))
+			Expect(err.Error()).To(ContainSubstring("spec.mods[1].workshopID"))
+			Expect(err.Error()).To(ContainSubstring("spec.mods[2].name"))
+		})
+
+		It("should reject a server.cfg the game cannot read and invalid settings", func() {
+			dayz := &gameserverv1alpha1.Dayz{}
+			Expect((&DayzDefaulter{}).Default(ctx, dayz)).To(Succeed())
+			dayz.Spec.Config = gameserverv1alpha1.DayzConfig{dayzServerConfigPath: "hostname = \"x\";\nmaxPlayers = 60\ninstanceId = 1;\n"}
+			dayz.Spec.Settings = &gameserverv1alpha1.DayzServerSettings{
+				Hostname:               "two\nlines",
+				ServerTimeAcceleration: "100",
+			}
+
+			_, err := validator.ValidateCreate(ctx, dayz)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring(`spec.config[` + dayzServerConfigPath + `]: Invalid value: "maxPlayers = 60": line 2: expected ';'`))
+			Expect(err.Error()).To(ContainSubstring("spec.settings.hostname"))
+			Expect(err.Error()).To(ContainSubstring("spec.settings.serverTimeAcceleration"))
+
+			dayz.Spec.Config[dayzServerConfigPath] = "hostname = \"x\";\nmaxPlayers = 60;\n"
+			dayz.Spec.Settings = &gameserverv1alpha1.DayzServerSettings{Hostname: "x", ServerTimeAcceleration: "0.5"}
+			_, err = validator.ValidateCreate(ctx, dayz)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should reject types patched twice and a min above the nominal", func() {
+			dayz := &gameserverv1alpha1.Dayz{}
+			Expect((&DayzDefaulter{}).Default(ctx, dayz)).To(Succeed())
+			nominal, min := int32(2), int32(5)
+			dayz.Spec.Economy = &gameserverv1alpha1.DayzEconomy{Types: []gameserverv1alpha1.DayzEconomyType{
+				{Name: "AKM", Nominal: &nominal},
+				{Name: "akm", Min: &min},
+				{Name: "Apple", Nominal: &nominal, Min: &min},
+			}}
+
+			_, err := validator.ValidateCreate(ctx, dayz)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.economy.types[1].name"))
+			Expect(err.Error()).To(ContainSubstring("spec.economy.types[2].min"))
+			Expect(err.Error()).NotTo(ContainSubstring("spec.economy.types[1].min"))
+		})
+
+		It("should reject downloads over vanilla missions and incomplete gameplay patches", func() {
+			dayz := &gameserverv1alpha1.Dayz{}
+			Expect((&DayzDefaulter{}).Default(ctx, dayz)).To(Succeed())
+			disabled := false
+			dayz.Spec.Settings = &gameserverv1alpha1.DayzServerSettings{EnableCfgGameplayFile: &disabled}
+			dayz.Spec.Mission = &gameserverv1alpha1.DayzMission{
+				Source: &gameserverv1alpha1.DayzMissionSource{Archive: &gameserverv1alpha1.DayzArchiveSource{
+					URL:  "https://example.com/missions.zip",
+					Path: "missions/../..",
+				}},
+				Gameplay: &gameserverv1alpha1.DayzGameplay{Patches: []gameserverv1alpha1.DayzJSONPatch{
+					{Op: "replace", Path: "/GeneralData/disableBaseDamage"},
+					{Op: "copy", Path: "/PlayerData/spawnGearPresetFiles/1"},
+					{Op: "remove", Path: "/WorldsData"},
+				}},
+			}
+
+			_, err := validator.ValidateCreate(ctx, dayz)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.settings.mission"))
+			Expect(err.Error()).To(ContainSubstring("spec.mission.source.archive.path"))
+			Expect(err.Error()).To(ContainSubstring("spec.mission.gameplay.patches[0].value"))
+			Expect(err.Error()).To(ContainSubstring("spec.mission.gameplay.patches[1].from"))
+			Expect(err.Error()).NotTo(ContainSubstring("spec.mission.gameplay.patches[2]"))
+			Expect(err.Error()).To(ContainSubstring("spec.settings.enableCfgGameplayFile"))
+
+			dayz.Spec.Settings = &gameserverv1alpha1.DayzServerSettings{Mission: "pvp.chernarusplus"}
+			dayz.Spec.Mission.Source.Archive.Path = "missions/pvp.chernarusplus"
+			dayz.Spec.Mission.Gameplay.Patches = dayz.Spec.Mission.Gameplay.Patches[2:]
+			_, err = validator.ValidateCreate(ctx, dayz)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should validate the spec merged from the template", func() {
+			scheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(apiv1alpha1.AddToScheme(scheme)).To(Succeed())
+			template := &apiv1alpha1.GameServerTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: "dayz-event", Namespace: "games"},
+				Spec: apiv1alpha1.GameServerTemplateSpec{Base: apiv1alpha1.Base{
+					RCon:     apiv1alpha1.RCon{PasswordSecretRef: &corev1.SecretKeySelector{Key: "password"}},
+					Schedule: apiv1alpha1.Schedule{Restarts: "0 6 * * *"},
+				}},
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(template).Build()
+			merged := &DayzValidator{Client: c}
+			dayz := &gameserverv1alpha1.Dayz{ObjectMeta: metav1.ObjectMeta{Name: "event", Namespace: "games"}}
+			dayz.Spec.TemplateRef = &corev1.LocalObjectReference{Name: "dayz-event"}
+			dayz.Spec.Shutdown.WarningSeconds = []int32{60}
+			Expect((&DayzDefaulter{}).Default(ctx, dayz)).To(Succeed())
+
+			_, err := merged.ValidateCreate(ctx, dayz)
+			Expect(err).NotTo(HaveOccurred())
+
+			template.Spec.RCon.PasswordSecretRef = nil
+			template.Spec.Schedule.Restarts = "every morning"
+			Expect(c.Update(ctx, template)).To(Succeed())
+			_, err = merged.ValidateCreate(ctx, dayz)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.rcon.passwordSecretRef"))
+			Expect(err.Error()).To(ContainSubstring("spec.schedule.restarts"))
+		})
+
+		It("should reject storage class changes", func() {
+			oldDayz := &gameserverv1alpha1.Dayz{}
+			oldDayz.Spec.Persistence.StorageConfig.StorageClassName = "standard"
+			dayz := oldDayz.DeepCopy()
+			dayz.Spec.Persistence.StorageConfig.StorageClassName = "premium-rwo"
+
+			_, err := validator.ValidateUpdate(ctx, oldDayz, dayz)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,58 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1/game"
+)
+
+// dayzsForTemplate maps a GameServerTemplate to the Dayz game servers referencing it
+func (r *DayzReconciler) dayzsForTemplate(ctx context.Context, template client.Object) []reconcile.Request {
+	return r.dayzsMatching(ctx, []client.ListOption{client.InNamespace(template.GetNamespace())}, func(dayz *gameserverv1alpha1.Dayz) bool {
+		return dayz.Spec.TemplateRef != nil && dayz.Spec.TemplateRef.Name == template.GetName()
+	})
+}
+
+// dayzsForClass maps a GameServerClass to the Dayz game servers naming it and to the servers with a
+// template, which may name it
+func (r *DayzReconciler) dayzsForClass(ctx context.Context, class client.Object) []reconcile.Request {
+	return r.dayzsMatching(ctx, nil, func(dayz *gameserverv1alpha1.Dayz) bool {
+		return dayz.Spec.ClassName == class.GetName() || (dayz.Spec.ClassName == "" && dayz.Spec.TemplateRef != nil)
+	})
+}
+
+// dayzsMatching lists the Dayz game servers and returns requests for those matching
+func (r *DayzReconciler) dayzsMatching(ctx context.Context, opts []client.ListOption, matches func(*gameserverv1alpha1.Dayz) bool) []reconcile.Request {
+	dayzs := &gameserverv1alpha1.DayzList{}
+	if err := r.List(ctx, dayzs, opts...); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list Dayz game servers")
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range dayzs.Items {
+		if matches(&dayzs.Items[i]) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&dayzs.Items[i])})
+		}
+	}
+	return requests
+}
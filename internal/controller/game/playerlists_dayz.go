@@ -0,0 +1,251 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	apiv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1/game"
+	"github.com/templarfelix/gameserver-operator/internal/controller"
+)
+
+// dayzPlayerListsDir is the server root DayZ reads the player list files from
+const dayzPlayerListsDir = "/data/serverfiles"
+
+// dayzPlayerListFiles are the files DayZ reads each type of player list from, DayZ has no admin list
+var dayzPlayerListFiles = map[apiv1alpha1.PlayerListType]string{
+	apiv1alpha1.PlayerListBan:       "ban.txt",
+	apiv1alpha1.PlayerListWhitelist: "whitelist.txt",
+	apiv1alpha1.PlayerListPriority:  "priority.txt",
+}
+
+// renderDayzPlayerLists renders the active entries of lists into the DayZ list files, lists of the same
+// type are merged and the first entry of an id wins. It returns the names of the lists of a type DayZ
+// has no file for.
+func renderDayzPlayerLists(lists []apiv1alpha1.PlayerList, now time.Time) (map[string]string, []string) {
+	files := map[string]string{}
+	var unsupported []string
+	ids := map[string]bool{}
+	for i := range lists {
+		list := &lists[i]
+		file, ok := dayzPlayerListFiles[list.Spec.Type]
+		if !ok {
+			unsupported = append(unsupported, list.Name)
+			continue
+		}
+		if _, ok := files[file]; !ok {
+			files[file] = ""
+		}
+		for _, entry := range controller.ActivePlayerEntries(list, now) {
+			key := file + "/" + strings.ToLower(entry.ID)
+			if ids[key] {
+				continue
+			}
+			ids[key] = true
+			if list.Spec.Type == apiv1alpha1.PlayerListPriority {
+				// The priority queue is a single line of ids separated by semicolons
+				files[file] += entry.ID + ";"
+				continue
+			}
+			files[file] += entry.ID + dayzPlayerListComment(entry) + "\n"
+		}
+	}
+	return files, unsupported
+}
+
+// dayzPlayerListComment returns the name and the reason of entry as a trailing comment
+func dayzPlayerListComment(entry apiv1alpha1.PlayerListEntry) string {
+	var parts []string
+	for _, part := range []string{entry.Name, entry.Reason} {
+		if part = strings.Join(strings.Fields(part), " "); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " // " + strings.Join(parts, ": ")
+}
+
+// dayzPlayerListsMounts mounts the player list files of spec.playerLists
+func dayzPlayerListsMounts(instance *gameserverv1alpha1.Dayz) []corev1.VolumeMount {
+	if len(instance.Spec.PlayerLists) == 0 {
+		return nil
+	}
+	return []corev1.VolumeMount{{Name: controller.PlayerListsVolumeName, MountPath: controller.PlayerListsMountPath, ReadOnly: true}}
+}
+
+// dayzPlayerListsVolumes returns the volume of the player list files of spec.playerLists
+func dayzPlayerListsVolumes(instance *gameserverv1alpha1.Dayz) []corev1.Volume {
+	if len(instance.Spec.PlayerLists) == 0 {
+		return nil
+	}
+	return []corev1.Volume{controller.PlayerListsVolume(instance)}
+}
+
+// dayzPlayerListsLinkScript links the player list files into the server root from the setup container
+func dayzPlayerListsLinkScript(instance *gameserverv1alpha1.Dayz) string {
+	if len(instance.Spec.PlayerLists) == 0 {
+		return ""
+	}
+	return "\n" + controller.PlayerListsLinkScript(dayzPlayerListsDir)
+}
+
+// reconcilePlayerLists renders spec.playerLists into the player lists ConfigMap and kicks the connected
+// players the ban lists ban. It returns the time left until the next entry expires.
+func (r *DayzReconciler) reconcilePlayerLists(ctx context.Context, instance *gameserverv1alpha1.Dayz, status *apiv1alpha1.BaseStatus) (time.Duration, error) {
+	if len(instance.Spec.PlayerLists) == 0 {
+		if meta.FindStatusCondition(status.Conditions, controller.ConditionPlayerListsApplied) == nil {
+			return 0, nil
+		}
+		meta.RemoveStatusCondition(&status.Conditions, controller.ConditionPlayerListsApplied)
+		return 0, controller.DeletePlayerListsConfigMap(ctx, r.Client, instance)
+	}
+
+	now := time.Now()
+	lists, missing, err := controller.GetPlayerLists(ctx, r.Client, instance.Namespace, instance.Spec.PlayerLists)
+	if err != nil {
+		return 0, err
+	}
+	files, unsupported := renderDayzPlayerLists(lists, now)
+	if err := controller.ReconcilePlayerListsConfigMap(ctx, r.Client, instance, files); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case len(missing) > 0:
+		controller.SetPlayerListsCondition(instance, status, controller.ReasonPlayerListNotFound,
+			"PlayerLists not found: "+strings.Join(missing, ", "))
+	case len(unsupported) > 0:
+		controller.SetPlayerListsCondition(instance, status, controller.ReasonPlayerListNotSupported,
+			"DayZ has no admin list, not applied: "+strings.Join(unsupported, ", "))
+	default:
+		controller.SetPlayerListsCondition(instance, status, controller.ReasonPlayerListsApplied,
+			fmt.Sprintf("%d PlayerLists applied", len(lists)))
+	}
+
+	r.kickBannedPlayers(ctx, instance, status, lists, now)
+
+	if next := controller.NextPlayerListExpiry(lists, now); next != nil {
+		return next.Sub(now), nil
+	}
+	return 0, nil
+}
+
+// kickBannedPlayers kicks the connected players of the ban lists over RCon, the game only reads ban.txt
+// when a player joins. The bans are only pushed when they changed since status.banListHash, failures are
+// logged and the next reconcile tries again.
+func (r *DayzReconciler) kickBannedPlayers(ctx context.Context, instance *gameserverv1alpha1.Dayz, status *apiv1alpha1.BaseStatus, lists []apiv1alpha1.PlayerList, now time.Time) {
+	reasons := map[string]string{}
+	for i := range lists {
+		if lists[i].Spec.Type != apiv1alpha1.PlayerListBan {
+			continue
+		}
+		for _, entry := range controller.ActivePlayerEntries(&lists[i], now) {
+			guid := strings.ToLower(entry.ID)
+			if len(entry.ID) == 17 {
+				guid, _ = controller.BattlEyeGUID(entry.ID)
+			}
+			if _, ok := reasons[guid]; !ok {
+				reasons[guid] = strings.Join(strings.Fields(entry.Reason), " ")
+			}
+		}
+	}
+	hash := dayzBanListHash(reasons)
+	if hash == status.BanListHash {
+		return
+	}
+	// Without a game pod no player is connected, the next one reads ban.txt when joining
+	if len(reasons) == 0 || controller.IsPaused(&instance.Spec.Base, status) {
+		status.BanListHash = hash
+		return
+	}
+	if instance.Spec.RCon.PasswordSecretRef == nil {
+		return
+	}
+
+	logger := log.FromContext(ctx)
+	session, err := controller.OpenRConSession(ctx, r.Client, instance, &instance.Spec.Base, controller.BattlEyeProfile, 0)
+	if goerrors.Is(err, controller.ErrNoRunningPod) {
+		status.BanListHash = hash
+		return
+	}
+	if err != nil {
+		logger.Error(err, "Failed to open an RCon session to kick banned players")
+		return
+	}
+	defer session.Close()
+
+	kicked, err := controller.KickBattlEyePlayers(ctx, session, reasons)
+	if len(kicked) > 0 {
+		logger.Info("Kicked banned players", "players", kicked)
+	}
+	if err != nil {
+		logger.Error(err, "Failed to kick banned players")
+		return
+	}
+	status.BanListHash = hash
+}
+
+// dayzBanListHash returns the hash of the banned GUIDs, empty without bans
+func dayzBanListHash(reasons map[string]string) string {
+	if len(reasons) == 0 {
+		return ""
+	}
+	guids := make([]string, 0, len(reasons))
+	for guid := range reasons {
+		guids = append(guids, guid)
+	}
+	sort.Strings(guids)
+	hash := fnv.New32a()
+	for _, guid := range guids {
+		_, _ = hash.Write([]byte(guid + "\n"))
+	}
+	return fmt.Sprintf("%08x", hash.Sum32())
+}
+
+// dayzsForPlayerList enqueues the Dayz game servers of the namespace of a PlayerList which reference it
+func (r *DayzReconciler) dayzsForPlayerList(ctx context.Context, list client.Object) []reconcile.Request {
+	dayzs := &gameserverv1alpha1.DayzList{}
+	if err := r.List(ctx, dayzs, client.InNamespace(list.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list Dayz game servers for a PlayerList")
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, dayz := range dayzs.Items {
+		for _, ref := range dayz.Spec.PlayerLists {
+			if ref.Name == list.GetName() {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&dayz)})
+				break
+			}
+		}
+	}
+	return requests
+}
@@ -0,0 +1,251 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1/game"
+	"github.com/templarfelix/gameserver-operator/internal/controller"
+	"github.com/templarfelix/gameserver-operator/internal/dayzcfg"
+	"github.com/templarfelix/gameserver-operator/internal/economy"
+)
+
+// dayzServerConfigPath is the server.cfg LinuxGSM starts DayZ with
+const dayzServerConfigPath = "/data/serverfiles/cfg/dayzserver.server.cfg"
+
+// dayzDefaultMission is the mission template of the DayZ server.cfg
+const dayzDefaultMission = "dayzOffline.chernarusplus"
+
+const (
+	// dayzPasswordEnv and dayzAdminPasswordEnv expose the server passwords to the config writer
+	dayzPasswordEnv      = "DAYZ_PASSWORD"
+	dayzAdminPasswordEnv = "DAYZ_ADMIN_PASSWORD"
+)
+
+// dayzRendersServerConfig reports whether the server.cfg is rendered by renderDayzServerConfig rather
+// than written from spec.config as it is
+func dayzRendersServerConfig(instance *gameserverv1alpha1.Dayz) bool {
+	return instance.Spec.Settings != nil || len(dayzGameplayPatches(instance)) > 0
+}
+
+// renderDayzServerConfig renders spec.settings over the server.cfg of spec.config, settings win
+// over the same properties of the config. The passwords are left out, the config writer appends
+// them from their Secrets, see dayzPasswordScript.
+func renderDayzServerConfig(instance *gameserverv1alpha1.Dayz) (string, error) {
+	config := &dayzcfg.Config{}
+	if raw, ok := instance.Spec.Config[dayzServerConfigPath]; ok {
+		var err error
+		if config, err = dayzcfg.Parse(raw); err != nil {
+			return "", fmt.Errorf("invalid %s: %w", dayzServerConfigPath, err)
+		}
+	}
+
+	settings := instance.Spec.Settings
+	if settings == nil {
+		settings = &gameserverv1alpha1.DayzServerSettings{}
+	}
+	setString := func(path, s string) {
+		if s != "" {
+			config.Set(path, dayzcfg.String(s))
+		}
+	}
+	setInt := func(path string, n int32) {
+		if n != 0 {
+			config.Set(path, dayzcfg.Int(int64(n)))
+		}
+	}
+	setBool := func(path string, b *bool) {
+		if b != nil {
+			config.Set(path, dayzcfg.Bool(*b))
+		}
+	}
+	setNumber := func(path, literal string) error {
+		if literal == "" {
+			return nil
+		}
+		value, err := dayzcfg.Number(literal)
+		if err != nil {
+			return fmt.Errorf("settings.%s: %w", path, err)
+		}
+		config.Set(path, value)
+		return nil
+	}
+
+	setString("hostname", settings.Hostname)
+	setInt("maxPlayers", settings.MaxPlayers)
+	setString("Missions.DayZ.template", settings.Mission)
+	setInt("instanceId", settings.InstanceID)
+	setString("serverTime", settings.ServerTime)
+	if err := setNumber("serverTimeAcceleration", settings.ServerTimeAcceleration); err != nil {
+		return "", err
+	}
+	if err := setNumber("serverNightTimeAcceleration", settings.ServerNightTimeAcceleration); err != nil {
+		return "", err
+	}
+	setBool("serverTimePersistent", settings.ServerTimePersistent)
+	setBool("disable3rdPerson", settings.Disable3rdPerson)
+	setBool("disableCrosshair", settings.DisableCrosshair)
+	setBool("disableVoN", settings.DisableVoN)
+	setBool("enableWhitelist", settings.EnableWhitelist)
+	setInt("maxPing", settings.MaxPing)
+	if len(settings.Motd) > 0 {
+		motd := dayzcfg.Array()
+		for _, message := range settings.Motd {
+			motd.Items = append(motd.Items, dayzcfg.String(message))
+		}
+		config.Set("motd", motd)
+	}
+	setInt("motdInterval", settings.MotdIntervalSeconds)
+	if enabled := settings.EnableCfgGameplayFile; enabled != nil {
+		setBool("enableCfgGameplayFile", enabled)
+	} else if len(dayzGameplayPatches(instance)) > 0 {
+		config.Set("enableCfgGameplayFile", dayzcfg.Bool(true))
+	}
+
+	// The Ready condition and the player counts are read from the query port
+	config.Set("steamQueryPort", dayzcfg.Int(int64(controller.QueryPort(&instance.Spec.Query, DefaultDayzQueryPort))))
+
+	if settings.PasswordSecretRef != nil {
+		config.Delete("password")
+	}
+	if settings.AdminPasswordSecretRef != nil {
+		config.Delete("passwordAdmin")
+	}
+	return config.String(), nil
+}
+
+// dayzMission returns the mission template the server loads, from spec.settings or the server.cfg of
+// spec.config
+func dayzMission(instance *gameserverv1alpha1.Dayz) string {
+	if settings := instance.Spec.Settings; settings != nil && settings.Mission != "" {
+		return settings.Mission
+	}
+	if raw, ok := instance.Spec.Config[dayzServerConfigPath]; ok {
+		if config, err := dayzcfg.Parse(raw); err == nil {
+			if template, ok := config.Get("Missions.DayZ.template"); ok && template.Kind == dayzcfg.KindString && template.Text != "" {
+				return template.Text
+			}
+		}
+	}
+	return dayzDefaultMission
+}
+
+// dayzMissionDir is the folder of the mission the server loads
+func dayzMissionDir(instance *gameserverv1alpha1.Dayz) string {
+	return "/data/serverfiles/mpmissions/" + dayzMission(instance)
+}
+
+// dayzTypePatches converts spec.economy.types into the patches of the economy init container
+func dayzTypePatches(instance *gameserverv1alpha1.Dayz) []economy.TypePatch {
+	if instance.Spec.Economy == nil {
+		return nil
+	}
+	var patches []economy.TypePatch
+	for _, t := range instance.Spec.Economy.Types {
+		patches = append(patches, economy.TypePatch{
+			Name:     t.Name,
+			Nominal:  t.Nominal,
+			Min:      t.Min,
+			Lifetime: t.LifetimeSeconds,
+			Restock:  t.RestockSeconds,
+			Category: t.Category,
+			Usages:   t.Usages,
+			Values:   t.Values,
+		})
+	}
+	return patches
+}
+
+// dayzGameplayPatches returns the operations of spec.mission.gameplay
+func dayzGameplayPatches(instance *gameserverv1alpha1.Dayz) []gameserverv1alpha1.DayzJSONPatch {
+	if mission := instance.Spec.Mission; mission != nil && mission.Gameplay != nil {
+		return mission.Gameplay.Patches
+	}
+	return nil
+}
+
+// dayzPatchesMission reports whether the economy init container has patches to apply to the mission
+func dayzPatchesMission(instance *gameserverv1alpha1.Dayz) bool {
+	return len(dayzTypePatches(instance)) > 0 || len(dayzGameplayPatches(instance)) > 0
+}
+
+// dayzEconomyPatches returns the patches the economy init container applies to the mission, the JSON
+// patch of spec.mission.gameplay is marshalled as the list of its operations
+func dayzEconomyPatches(instance *gameserverv1alpha1.Dayz) (controller.EconomyPatches, error) {
+	patches := controller.EconomyPatches{
+		TypesFile:    dayzMissionDir(instance) + "/db/types.xml",
+		Types:        dayzTypePatches(instance),
+		GameplayFile: dayzMissionDir(instance) + "/" + economy.GameplayFile,
+	}
+	if operations := dayzGameplayPatches(instance); len(operations) > 0 {
+		gameplay, err := json.Marshal(operations)
+		if err != nil {
+			return patches, err
+		}
+		patches.Gameplay = gameplay
+	}
+	return patches, nil
+}
+
+// dayzMissionSource returns where spec.mission.source downloads the mission from, nil without a source
+func dayzMissionSource(instance *gameserverv1alpha1.Dayz) *controller.Source {
+	if instance.Spec.Mission == nil || instance.Spec.Mission.Source == nil {
+		return nil
+	}
+	source := instance.Spec.Mission.Source
+	switch {
+	case source.Git != nil:
+		return &controller.Source{
+			GitURL:         source.Git.URL,
+			GitRef:         source.Git.Ref,
+			GitCredentials: source.Git.CredentialsSecretRef,
+			Path:           source.Git.Path,
+		}
+	case source.Archive != nil:
+		return &controller.Source{
+			ArchiveURL:    source.Archive.URL,
+			ArchiveSHA256: source.Archive.SHA256,
+			Path:          source.Archive.Path,
+		}
+	}
+	return nil
+}
+
+// dayzPasswordScript appends property with the password of env to the server.cfg written by the
+// config writer, quotes in the password are doubled like in any config string
+func dayzPasswordScript(property, env string) string {
+	return fmt.Sprintf("printf '%s = \"%%s\";\\n' \"$(printf '%%s' \"$%s\" | sed 's/\"/\"\"/g')\" >> '/tmp/configs%s'\n",
+		property, env, dayzServerConfigPath)
+}
+
+// dayzPasswordEnvVars exposes the passwords of spec.settings to the config writer
+func dayzPasswordEnvVars(instance *gameserverv1alpha1.Dayz) []corev1.EnvVar {
+	var env []corev1.EnvVar
+	if settings := instance.Spec.Settings; settings != nil {
+		if settings.PasswordSecretRef != nil {
+			env = append(env, corev1.EnvVar{Name: dayzPasswordEnv, ValueFrom: &corev1.EnvVarSource{SecretKeyRef: settings.PasswordSecretRef}})
+		}
+		if settings.AdminPasswordSecretRef != nil {
+			env = append(env, corev1.EnvVar{Name: dayzAdminPasswordEnv, ValueFrom: &corev1.EnvVarSource{SecretKeyRef: settings.AdminPasswordSecretRef}})
+		}
+	}
+	return env
+}
@@ -0,0 +1,350 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	apiv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1/game"
+	"github.com/templarfelix/gameserver-operator/internal/controller"
+	"github.com/templarfelix/gameserver-operator/internal/dayzcfg"
+)
+
+// DefaultDayzImage is the LinuxGSM image used when spec.image is empty
+const DefaultDayzImage = "gameservermanagers/gameserver:dayz"
+
+//+kubebuilder:webhook:path=/mutate-gameserver-templarfelix-com-v1alpha1-dayz,mutating=true,failurePolicy=fail,sideEffects=None,groups=gameserver.templarfelix.com,resources=dayzs,verbs=create;update,versions=v1alpha1,name=mdayz.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-gameserver-templarfelix-com-v1alpha1-dayz,mutating=false,failurePolicy=fail,sideEffects=None,groups=gameserver.templarfelix.com,resources=dayzs,verbs=create;update,versions=v1alpha1,name=vdayz.kb.io,admissionReviewVersions=v1
+
+// DayzDefaulter fills in the image, storage size and the standard DayZ ports of servers without a
+// template or class
+type DayzDefaulter struct{}
+
+// DayzValidator rejects Dayz objects the reconciler cannot turn into a working server. The common
+// fields are validated after merging the GameServerTemplate and GameServerClass of the server.
+type DayzValidator struct {
+	// Client reads the template and class, the spec is validated on its own when nil
+	Client client.Client
+}
+
+var _ admission.CustomDefaulter = &DayzDefaulter{}
+var _ admission.CustomValidator = &DayzValidator{}
+
+// SetupWebhookWithManager registers the defaulting and validating webhooks for Dayz. The /convert
+// endpoint is registered alongside them once the v1beta1 hub is part of the manager scheme.
+func (v *DayzValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&gameserverv1alpha1.Dayz{}).
+		WithDefaulter(&DayzDefaulter{}).
+		WithValidator(v).
+		Complete()
+}
+
+// DefaultDayzPorts returns the ports a LinuxGSM DayZ server listens on
+func DefaultDayzPorts() []corev1.ServicePort {
+	port := func(number int32, protocol corev1.Protocol) corev1.ServicePort {
+		return corev1.ServicePort{
+			Name:       fmt.Sprintf("port-%d-%s", number, strings.ToLower(string(protocol))),
+			Port:       number,
+			TargetPort: intstr.FromInt32(number),
+			Protocol:   protocol,
+		}
+	}
+	return []corev1.ServicePort{
+		port(27015, corev1.ProtocolTCP),
+		port(27016, corev1.ProtocolTCP),
+		port(2302, corev1.ProtocolUDP),
+		port(2304, corev1.ProtocolUDP),
+		port(2306, corev1.ProtocolUDP),
+		port(27015, corev1.ProtocolUDP),
+		port(27016, corev1.ProtocolUDP),
+	}
+}
+
+// Default implements admission.CustomDefaulter
+func (d *DayzDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	dayz, ok := obj.(*gameserverv1alpha1.Dayz)
+	if !ok {
+		return fmt.Errorf("expected a Dayz object but got %T", obj)
+	}
+
+	// The template or class may supply the image, size and ports, the reconciler fills them in after
+	// merging them
+	if dayz.Spec.TemplateRef != nil || dayz.Spec.ClassName != "" {
+		completeDayzPorts(dayz.Spec.Ports)
+		return nil
+	}
+	defaultDayzBase(&dayz.Spec.Base)
+	return nil
+}
+
+// defaultDayzBase fills in the image, storage size and ports left empty in base, after the fields of
+// the spec, class and template
+func defaultDayzBase(base *apiv1alpha1.Base) {
+	if base.Image == "" {
+		base.Image = DefaultDayzImage
+	}
+	if base.Persistence.StorageConfig.Size == "" {
+		base.Persistence.StorageConfig.Size = controller.DefaultStorageSize
+	}
+	if len(base.Ports) == 0 {
+		base.Ports = DefaultDayzPorts()
+	}
+	completeDayzPorts(base.Ports)
+}
+
+// completeDayzPorts defaults the protocol of ports to TCP and their target port to the port
+func completeDayzPorts(ports []corev1.ServicePort) {
+	for i := range ports {
+		if ports[i].Protocol == "" {
+			ports[i].Protocol = corev1.ProtocolTCP
+		}
+		if ports[i].TargetPort.IntValue() == 0 && ports[i].TargetPort.StrVal == "" {
+			ports[i].TargetPort = intstr.FromInt32(ports[i].Port)
+		}
+	}
+}
+
+// ValidateCreate implements admission.CustomValidator
+func (v *DayzValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	dayz, ok := obj.(*gameserverv1alpha1.Dayz)
+	if !ok {
+		return nil, fmt.Errorf("expected a Dayz object but got %T", obj)
+	}
+	allErrs, err := v.validateDayz(ctx, dayz)
+	if err != nil {
+		return nil, err
+	}
+	return nil, toInvalidError(dayz, allErrs)
+}
+
+// ValidateUpdate implements admission.CustomValidator
+func (v *DayzValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldDayz, ok := oldObj.(*gameserverv1alpha1.Dayz)
+	if !ok {
+		return nil, fmt.Errorf("expected a Dayz object but got %T", oldObj)
+	}
+	dayz, ok := newObj.(*gameserverv1alpha1.Dayz)
+	if !ok {
+		return nil, fmt.Errorf("expected a Dayz object but got %T", newObj)
+	}
+
+	allErrs, err := v.validateDayz(ctx, dayz)
+	if err != nil {
+		return nil, err
+	}
+	allErrs = append(allErrs, controller.ValidateBaseUpdate(&oldDayz.Spec.Base, &dayz.Spec.Base, field.NewPath("spec"))...)
+	return nil, toInvalidError(dayz, allErrs)
+}
+
+// ValidateDelete implements admission.CustomValidator
+func (v *DayzValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateDayz validates dayz with the fields its template and class supply. While one of them is
+// missing, fields depending on each other are not checked as it may supply the rest.
+func (v *DayzValidator) validateDayz(ctx context.Context, dayz *gameserverv1alpha1.Dayz) (field.ErrorList, error) {
+	specPath := field.NewPath("spec")
+	validateBase := controller.ValidateBase
+	base := &dayz.Spec.Base
+	if v.Client != nil {
+		effective, condition, err := controller.ResolveBase(ctx, v.Client, dayz, base)
+		if err != nil {
+			return nil, err
+		}
+		if condition.Status != metav1.ConditionTrue {
+			validateBase = controller.ValidateBaseFields
+		}
+		base = effective
+	}
+
+	allErrs := validateBase(base, specPath)
+	allErrs = append(allErrs, controller.ValidateConfigPaths(dayz.Spec.Config, specPath.Child("config"))...)
+	allErrs = append(allErrs, validateDayzMods(dayz, specPath)...)
+	allErrs = append(allErrs, validateDayzServerConfig(dayz, specPath)...)
+	allErrs = append(allErrs, validateDayzEconomy(dayz, specPath)...)
+	allErrs = append(allErrs, validateDayzMission(dayz, specPath)...)
+	return allErrs, nil
+}
+
+// dayzVanillaMissions are the missions installed with the server, game updates restore their files
+var dayzVanillaMissions = []string{"dayzOffline.chernarusplus", "dayzOffline.enoch", "dayzOffline.sakhal"}
+
+// validateDayzMission rejects a source downloaded over a vanilla mission or outside of its repository or
+// archive, incomplete gameplay patch operations and gameplay patches with cfggameplay.json disabled
+func validateDayzMission(dayz *gameserverv1alpha1.Dayz, specPath *field.Path) field.ErrorList {
+	mission := dayz.Spec.Mission
+	if mission == nil {
+		return nil
+	}
+	var allErrs field.ErrorList
+	missionPath := specPath.Child("mission")
+
+	if source := dayzMissionSource(dayz); source != nil {
+		for _, vanilla := range dayzVanillaMissions {
+			if strings.EqualFold(dayzMission(dayz), vanilla) {
+				allErrs = append(allErrs, field.Invalid(specPath.Child("settings", "mission"), dayzMission(dayz),
+					"a downloaded mission needs a name of its own, game updates restore the files of the vanilla missions"))
+			}
+		}
+		sourcePath := missionPath.Child("source", "git", "path")
+		if mission.Source.Git == nil {
+			sourcePath = missionPath.Child("source", "archive", "path")
+		}
+		for _, segment := range strings.Split(source.Path, "/") {
+			if segment == ".." {
+				allErrs = append(allErrs, field.Invalid(sourcePath, source.Path, "must not contain '..'"))
+				break
+			}
+		}
+	}
+
+	operations := dayzGameplayPatches(dayz)
+	patchesPath := missionPath.Child("gameplay", "patches")
+	for i, operation := range operations {
+		switch operation.Op {
+		case "move", "copy":
+			if operation.From == "" {
+				allErrs = append(allErrs, field.Required(patchesPath.Index(i).Child("from"), "from is required for "+operation.Op))
+			}
+		case "add", "replace", "test":
+			if operation.Value == nil {
+				allErrs = append(allErrs, field.Required(patchesPath.Index(i).Child("value"), "value is required for "+operation.Op))
+			}
+		}
+	}
+	if settings := dayz.Spec.Settings; len(operations) > 0 && settings != nil &&
+		settings.EnableCfgGameplayFile != nil && !*settings.EnableCfgGameplayFile {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("settings", "enableCfgGameplayFile"), false,
+			"the server does not read cfggameplay.json, its patches would have no effect"))
+	}
+	return allErrs
+}
+
+// validateDayzEconomy rejects types patched twice, the game matches class names case insensitively,
+// and a min above the nominal
+func validateDayzEconomy(dayz *gameserverv1alpha1.Dayz, specPath *field.Path) field.ErrorList {
+	if dayz.Spec.Economy == nil {
+		return nil
+	}
+	var allErrs field.ErrorList
+	typesPath := specPath.Child("economy", "types")
+	names := map[string]bool{}
+	for i, t := range dayz.Spec.Economy.Types {
+		if key := strings.ToLower(t.Name); names[key] {
+			allErrs = append(allErrs, field.Duplicate(typesPath.Index(i).Child("name"), t.Name))
+		} else {
+			names[key] = true
+		}
+		if t.Min != nil && t.Nominal != nil && *t.Min > *t.Nominal {
+			allErrs = append(allErrs, field.Invalid(typesPath.Index(i).Child("min"), *t.Min, "must not be greater than nominal"))
+		}
+	}
+	return allErrs
+}
+
+// validateDayzServerConfig rejects a server.cfg the game cannot read and settings out of the ranges
+// the game accepts
+func validateDayzServerConfig(dayz *gameserverv1alpha1.Dayz, specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if raw, ok := dayz.Spec.Config[dayzServerConfigPath]; ok {
+		if _, err := dayzcfg.Parse(raw); err != nil {
+			line := ""
+			var syntaxErr *dayzcfg.SyntaxError
+			if errors.As(err, &syntaxErr) && syntaxErr.Line <= strings.Count(raw, "\n")+1 {
+				line = strings.TrimSpace(strings.Split(raw, "\n")[syntaxErr.Line-1])
+			}
+			allErrs = append(allErrs, field.Invalid(specPath.Child("config").Key(dayzServerConfigPath), line, err.Error()))
+		}
+	}
+
+	settings := dayz.Spec.Settings
+	if settings == nil {
+		return allErrs
+	}
+	settingsPath := specPath.Child("settings")
+	if strings.ContainsAny(settings.Hostname, "\r\n") {
+		allErrs = append(allErrs, field.Invalid(settingsPath.Child("hostname"), settings.Hostname, "must be a single line"))
+	}
+	for i, message := range settings.Motd {
+		if strings.ContainsAny(message, "\r\n") {
+			allErrs = append(allErrs, field.Invalid(settingsPath.Child("motd").Index(i), message, "must be a single line"))
+		}
+	}
+	for _, acceleration := range []struct {
+		name  string
+		value string
+	}{
+		{"serverTimeAcceleration", settings.ServerTimeAcceleration},
+		{"serverNightTimeAcceleration", settings.ServerNightTimeAcceleration},
+	} {
+		if acceleration.value == "" {
+			continue
+		}
+		if multiplier, err := strconv.ParseFloat(acceleration.value, 64); err != nil || multiplier < 0.1 || multiplier > 64 {
+			allErrs = append(allErrs, field.Invalid(settingsPath.Child(acceleration.name), acceleration.value, "must be a number from 0.1 to 64"))
+		}
+	}
+	return allErrs
+}
+
+// validateDayzMods requires Steam credentials for mods and rejects mods loaded twice
+func validateDayzMods(dayz *gameserverv1alpha1.Dayz, specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	modsPath := specPath.Child("mods")
+	if len(dayz.Spec.Mods) > 0 && dayz.Spec.SteamCredentialsSecretRef == nil {
+		allErrs = append(allErrs, field.Required(specPath.Child("steamCredentialsSecretRef"), "Workshop mods can only be downloaded with a Steam account owning DayZ"))
+	}
+
+	ids := map[string]bool{}
+	names := map[string]bool{}
+	for i, mod := range dayz.Spec.Mods {
+		if ids[mod.WorkshopID] {
+			allErrs = append(allErrs, field.Duplicate(modsPath.Index(i).Child("workshopID"), mod.WorkshopID))
+		}
+		ids[mod.WorkshopID] = true
+		if name := dayzModName(mod); names[name] {
+			allErrs = append(allErrs, field.Duplicate(modsPath.Index(i).Child("name"), name))
+		} else {
+			names[name] = true
+		}
+	}
+	return allErrs
+}
+
+func toInvalidError(dayz *gameserverv1alpha1.Dayz, allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(apiv1alpha1.GroupVersion.WithKind("Dayz").GroupKind(), dayz.Name, allErrs)
+}
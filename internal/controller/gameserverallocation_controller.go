@@ -0,0 +1,318 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+	gamev1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1/game"
+)
+
+const (
+	// allocationFinalizer releases the game server of an allocation before it is deleted
+	allocationFinalizer = "gameserver.templarfelix.com/allocation"
+)
+
+// GameServerAllocationReconciler allocates game servers of fleets to GameServerAllocations and
+// releases them once they are empty again
+type GameServerAllocationReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// APIReader reads new allocations around the cache before allocating, so a stale cache never
+	// allocates twice. The client is used when nil
+	APIReader client.Reader
+
+	// Recorder records allocations and releases, none are recorded when nil
+	Recorder record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=gameserver.templarfelix.com,resources=gameserverallocations,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=gameserver.templarfelix.com,resources=gameserverallocations/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=gameserver.templarfelix.com,resources=gameserverallocations/finalizers,verbs=update
+//+kubebuilder:rbac:groups=gameserver.templarfelix.com,resources=gameserverfleets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=gameserver.templarfelix.com,resources=dayzs,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Reconcile allocates a game server to a new GameServerAllocation once, and releases it when it is
+// empty again or the allocation is deleted
+func (r *GameServerAllocationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	allocation := &gameserverv1alpha1.GameServerAllocation{}
+	if err := r.Get(ctx, req.NamespacedName, allocation); err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	if allocation.DeletionTimestamp != nil {
+		if !controllerutil.ContainsFinalizer(allocation, allocationFinalizer) {
+			return reconcile.Result{}, nil
+		}
+		if allocation.Status.State == gameserverv1alpha1.AllocationAllocated {
+			if err := r.releaseServer(ctx, allocation); err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+		return reconcile.Result{}, r.removeFinalizer(ctx, allocation)
+	}
+
+	switch allocation.Status.State {
+	case "":
+		reader := r.APIReader
+		if reader == nil {
+			reader = r.Client
+		}
+		if err := reader.Get(ctx, req.NamespacedName, allocation); err != nil {
+			return reconcile.Result{}, client.IgnoreNotFound(err)
+		}
+		if allocation.Status.State != "" {
+			return reconcile.Result{Requeue: true}, nil
+		}
+		return r.allocate(ctx, allocation)
+	case gameserverv1alpha1.AllocationAllocated:
+		return r.track(ctx, allocation)
+	default:
+		return reconcile.Result{}, r.removeFinalizer(ctx, allocation)
+	}
+}
+
+// allocate claims the first candidate server of the fleets of allocation with an address
+func (r *GameServerAllocationReconciler) allocate(ctx context.Context, allocation *gameserverv1alpha1.GameServerAllocation) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("gameserverallocation", allocation.Name)
+
+	selector := labels.Everything()
+	if allocation.Spec.Selector != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(allocation.Spec.Selector); err != nil {
+			return r.unallocated(ctx, allocation, fmt.Sprintf("Invalid selector: %v", err))
+		}
+	}
+
+	fleets := &gameserverv1alpha1.GameServerFleetList{}
+	if err := r.List(ctx, fleets, client.InNamespace(allocation.Namespace)); err != nil {
+		return reconcile.Result{}, err
+	}
+	sort.Slice(fleets.Items, func(i, j int) bool { return fleets.Items[i].Name < fleets.Items[j].Name })
+
+	for i := range fleets.Items {
+		fleet := &fleets.Items[i]
+		if allocation.Spec.FleetRef != nil && allocation.Spec.FleetRef.Name != fleet.Name {
+			continue
+		}
+		kind, err := LookupFleetKind(fleet)
+		if err != nil {
+			continue
+		}
+		servers, err := ListFleetServers(ctx, r.Client, fleet, kind)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+
+		// A server marked before the status of the allocation could be written is taken again
+		candidates := AllocationCandidates(servers, selector)
+		for _, server := range servers {
+			if AllocatedTo(server.Object, allocation.Name) {
+				candidates = []FleetServer{server}
+				break
+			}
+		}
+
+		for _, server := range candidates {
+			address, ports, err := GameServerAddress(ctx, r.Client, server.Object)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+			if address == "" {
+				continue
+			}
+			if !AllocatedTo(server.Object, allocation.Name) {
+				SetAllocated(server.Object, allocation.Name)
+				if err := r.Update(ctx, server.Object); err != nil {
+					if errors.IsConflict(err) || errors.IsNotFound(err) {
+						continue
+					}
+					return reconcile.Result{}, err
+				}
+			}
+
+			if !controllerutil.ContainsFinalizer(allocation, allocationFinalizer) {
+				controllerutil.AddFinalizer(allocation, allocationFinalizer)
+				if err := r.Update(ctx, allocation); err != nil {
+					return reconcile.Result{}, err
+				}
+			}
+			gvk, err := r.GroupVersionKindFor(server.Object)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+			now := metav1.Now()
+			allocation.Status = gameserverv1alpha1.GameServerAllocationStatus{
+				State:          gameserverv1alpha1.AllocationAllocated,
+				GameServerRef:  &gameserverv1alpha1.GameServerReference{Kind: gvk.Kind, Name: server.Object.GetName()},
+				Fleet:          fleet.Name,
+				Address:        address,
+				Ports:          ports,
+				AllocationTime: &now,
+				Message:        fmt.Sprintf("Allocated %s %s of fleet %s", gvk.Kind, server.Object.GetName(), fleet.Name),
+			}
+			if err := r.Status().Update(ctx, allocation); err != nil {
+				return reconcile.Result{}, err
+			}
+			logger.Info("Allocated game server", "name", server.Object.GetName(), "address", address)
+			RecordEvent(r.Recorder, allocation, corev1.EventTypeNormal, EventReasonAllocated, "Allocated %s %s at %s", gvk.Kind, server.Object.GetName(), address)
+			_, wait := AllocationRelease(allocation, &allocation.Status, 0, now.Time)
+			return reconcile.Result{RequeueAfter: wait}, nil
+		}
+	}
+
+	return r.unallocated(ctx, allocation, "No ready and empty game server matched")
+}
+
+// unallocated records that no game server could be allocated, the allocation is not retried
+func (r *GameServerAllocationReconciler) unallocated(ctx context.Context, allocation *gameserverv1alpha1.GameServerAllocation, message string) (ctrl.Result, error) {
+	allocation.Status = gameserverv1alpha1.GameServerAllocationStatus{State: gameserverv1alpha1.AllocationUnAllocated, Message: message}
+	if err := r.Status().Update(ctx, allocation); err != nil {
+		return reconcile.Result{}, err
+	}
+	RecordEvent(r.Recorder, allocation, corev1.EventTypeWarning, EventReasonUnAllocated, "%s", message)
+	return reconcile.Result{}, nil
+}
+
+// track follows the players of the allocated game server and releases it once it is empty again
+func (r *GameServerAllocationReconciler) track(ctx context.Context, allocation *gameserverv1alpha1.GameServerAllocation) (ctrl.Result, error) {
+	status := allocation.Status.DeepCopy()
+	message, wait := "The game server was deleted or released", time.Duration(0)
+
+	server, err := r.getServer(ctx, allocation)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if server != nil && AllocatedTo(server.Object, allocation.Name) {
+		message, wait = AllocationRelease(allocation, status, server.Status.Players, time.Now())
+		if message == "" {
+			if status.PlayersJoined != allocation.Status.PlayersJoined {
+				allocation.Status = *status
+				if err := r.Status().Update(ctx, allocation); err != nil {
+					if errors.IsConflict(err) {
+						return reconcile.Result{Requeue: true}, nil
+					}
+					return reconcile.Result{}, err
+				}
+			}
+			return reconcile.Result{RequeueAfter: wait}, nil
+		}
+		ClearAllocated(server.Object)
+		if err := r.Update(ctx, server.Object); err != nil {
+			if errors.IsConflict(err) {
+				return reconcile.Result{Requeue: true}, nil
+			}
+			return reconcile.Result{}, err
+		}
+	}
+
+	now := metav1.Now()
+	status.State = gameserverv1alpha1.AllocationReleased
+	status.ReleaseTime = &now
+	status.Message = message
+	allocation.Status = *status
+	if err := r.Status().Update(ctx, allocation); err != nil {
+		if errors.IsConflict(err) {
+			return reconcile.Result{Requeue: true}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	log.FromContext(ctx).Info("Released game server", "gameserverallocation", allocation.Name, "reason", message)
+	RecordEvent(r.Recorder, allocation, corev1.EventTypeNormal, EventReasonReleased, "%s", message)
+	return reconcile.Result{}, r.removeFinalizer(ctx, allocation)
+}
+
+// releaseServer hands the game server of a deleted allocation back to its fleet
+func (r *GameServerAllocationReconciler) releaseServer(ctx context.Context, allocation *gameserverv1alpha1.GameServerAllocation) error {
+	server, err := r.getServer(ctx, allocation)
+	if err != nil || server == nil || !AllocatedTo(server.Object, allocation.Name) {
+		return err
+	}
+	ClearAllocated(server.Object)
+	if err := r.Update(ctx, server.Object); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	RecordEvent(r.Recorder, allocation, corev1.EventTypeNormal, EventReasonReleased, "Released %s as the allocation was deleted", server.Object.GetName())
+	return nil
+}
+
+// getServer returns the game server of allocation, nil when it is gone
+func (r *GameServerAllocationReconciler) getServer(ctx context.Context, allocation *gameserverv1alpha1.GameServerAllocation) (*FleetServer, error) {
+	ref := allocation.Status.GameServerRef
+	if ref == nil {
+		return nil, nil
+	}
+	kind, ok := FleetKinds[ref.Kind]
+	if !ok {
+		return nil, fmt.Errorf("unsupported game server kind %q", ref.Kind)
+	}
+	obj := kind.NewObject()
+	if err := r.Get(ctx, types.NamespacedName{Namespace: allocation.Namespace, Name: ref.Name}, obj); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	server := kind.Server(obj)
+	return &server, nil
+}
+
+// removeFinalizer removes the finalizer of allocation once its game server was released
+func (r *GameServerAllocationReconciler) removeFinalizer(ctx context.Context, allocation *gameserverv1alpha1.GameServerAllocation) error {
+	if !controllerutil.RemoveFinalizer(allocation, allocationFinalizer) {
+		return nil
+	}
+	return client.IgnoreNotFound(r.Update(ctx, allocation))
+}
+
+// allocationForServer maps a game server to the GameServerAllocation holding it
+func (r *GameServerAllocationReconciler) allocationForServer(_ context.Context, server client.Object) []reconcile.Request {
+	name := server.GetAnnotations()[gameserverv1alpha1.AllocationAnnotation]
+	if name == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: server.GetNamespace(), Name: name}}}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GameServerAllocationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&gameserverv1alpha1.GameServerAllocation{}).
+		Watches(&gamev1alpha1.Dayz{}, handler.EnqueueRequestsFromMapFunc(r.allocationForServer)).
+		Complete(r)
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+	gamev1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1/game"
+	"github.com/templarfelix/gameserver-operator/internal/rcon"
+)
+
+const (
+	// commandPendingRequeue is how often a command waiting for a running game server pod is retried
+	commandPendingRequeue = 10 * time.Second
+
+	// maxCommandResponseLength bounds the response stored in status
+	maxCommandResponseLength = 16384
+)
+
+// GameServerCommandReconciler runs GameServerCommands against their game server over RCON
+type GameServerCommandReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// RConTimeout bounds the RCON login and each command, rcon.DefaultTimeout is used when zero
+	RConTimeout time.Duration
+}
+
+//+kubebuilder:rbac:groups=gameserver.templarfelix.com,resources=gameservercommands,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=gameserver.templarfelix.com,resources=gameservercommands/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=gameserver.templarfelix.com,resources=dayzs,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+
+// Reconcile executes a pending GameServerCommand once and records the outcome in its status
+func (r *GameServerCommandReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("gameservercommand", req.Name)
+
+	command := &gameserverv1alpha1.GameServerCommand{}
+	if err := r.Get(ctx, req.NamespacedName, command); err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	// Commands run at most once
+	if command.Status.Phase == gameserverv1alpha1.CommandSucceeded || command.Status.Phase == gameserverv1alpha1.CommandFailed {
+		return reconcile.Result{}, nil
+	}
+
+	server, base, profile, err := r.getGameServer(ctx, command)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return r.complete(ctx, command, gameserverv1alpha1.CommandFailed, "",
+				fmt.Sprintf("%s %q not found", command.Spec.GameServerRef.Kind, command.Spec.GameServerRef.Name))
+		}
+		return reconcile.Result{}, err
+	}
+
+	session, err := OpenRConSession(ctx, r.Client, server, base, profile, r.RConTimeout)
+	if err != nil {
+		var apiStatus errors.APIStatus
+		switch {
+		case goerrors.Is(err, ErrNoRunningPod):
+			return r.pending(ctx, command, "Waiting for a running game server pod")
+		case goerrors.As(err, &apiStatus) && !errors.IsNotFound(err):
+			return reconcile.Result{}, err
+		}
+		return r.complete(ctx, command, gameserverv1alpha1.CommandFailed, "", err.Error())
+	}
+	defer session.Close()
+
+	response, err := r.execute(ctx, session, profile, &command.Spec)
+	if err != nil {
+		logger.Info("Command failed", "command", command.Spec.Command, "error", err.Error())
+		return r.complete(ctx, command, gameserverv1alpha1.CommandFailed, "", err.Error())
+	}
+
+	logger.Info("Command executed", "command", command.Spec.Command, "server", server.GetName())
+	return r.complete(ctx, command, gameserverv1alpha1.CommandSucceeded, response, "")
+}
+
+// getGameServer returns the referenced game server, its common spec and how it is administered
+func (r *GameServerCommandReconciler) getGameServer(ctx context.Context, command *gameserverv1alpha1.GameServerCommand) (client.Object, *gameserverv1alpha1.Base, RConProfile, error) {
+	key := types.NamespacedName{Name: command.Spec.GameServerRef.Name, Namespace: command.Namespace}
+
+	switch command.Spec.GameServerRef.Kind {
+	case "", "Dayz":
+		dayz := &gamev1alpha1.Dayz{}
+		if err := r.Get(ctx, key, dayz); err != nil {
+			return nil, nil, RConProfile{}, err
+		}
+		return dayz, &dayz.Spec.Base, BattlEyeProfile, nil
+	default:
+		return nil, nil, RConProfile{}, fmt.Errorf("unsupported game server kind %q", command.Spec.GameServerRef.Kind)
+	}
+}
+
+// execute resolves the targeted player and runs the command in session
+func (r *GameServerCommandReconciler) execute(ctx context.Context, session rcon.Session, profile RConProfile, spec *gameserverv1alpha1.GameServerCommandSpec) (string, error) {
+	player := spec.Player
+	if player != "" && profile.ResolvePlayer != nil {
+		var err error
+		if player, err = profile.ResolvePlayer(ctx, session, player); err != nil {
+			return "", err
+		}
+	}
+
+	line, err := profile.RenderCommand(spec, player)
+	if err != nil {
+		return "", err
+	}
+	response, err := session.Command(ctx, line)
+	if err != nil {
+		return "", err
+	}
+	if len(response) > maxCommandResponseLength {
+		response = response[:maxCommandResponseLength]
+	}
+	return response, nil
+}
+
+// pending records why the command is waiting and retries it later
+func (r *GameServerCommandReconciler) pending(ctx context.Context, command *gameserverv1alpha1.GameServerCommand, message string) (ctrl.Result, error) {
+	if command.Status.Phase != gameserverv1alpha1.CommandPending || command.Status.Message != message {
+		command.Status.Phase = gameserverv1alpha1.CommandPending
+		command.Status.Message = message
+		if err := r.Status().Update(ctx, command); err != nil {
+			if errors.IsConflict(err) {
+				return reconcile.Result{Requeue: true}, nil
+			}
+			return reconcile.Result{}, err
+		}
+	}
+	return reconcile.Result{RequeueAfter: commandPendingRequeue}, nil
+}
+
+// complete records the final phase of the command. Status conflicts are retried here instead of
+// requeueing, so an executed command is never sent twice.
+func (r *GameServerCommandReconciler) complete(ctx context.Context, command *gameserverv1alpha1.GameServerCommand, phase gameserverv1alpha1.GameServerCommandPhase, response, message string) (ctrl.Result, error) {
+	now := metav1.Now()
+	status := gameserverv1alpha1.GameServerCommandStatus{
+		Phase:          phase,
+		Response:       response,
+		Message:        message,
+		CompletionTime: &now,
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := &gameserverv1alpha1.GameServerCommand{}
+		if err := r.Get(ctx, client.ObjectKeyFromObject(command), latest); err != nil {
+			return err
+		}
+		latest.Status = status
+		return r.Status().Update(ctx, latest)
+	})
+	if err != nil && !errors.IsNotFound(err) {
+		log.FromContext(ctx).Error(err, "Failed to update command status")
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GameServerCommandReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&gameserverv1alpha1.GameServerCommand{}).
+		Complete(r)
+}
@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+	gamev1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1/game"
+	"github.com/templarfelix/gameserver-operator/internal/bercon/berconttest"
+)
+
+var _ = Describe("GameServerCommandReconciler", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+		server *berconttest.Server
+		dayz   *gamev1alpha1.Dayz
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(gameserverv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		var err error
+		server, err = berconttest.NewServer("rcon-secret", func(command string) string {
+			if command == "players" {
+				return "0   10.0.0.5:2304   32   0123456789abcdef0123456789abcdef(OK) Survivor\n(1 players in total)"
+			}
+			return ""
+		})
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(server.Close)
+
+		dayz = &gamev1alpha1.Dayz{
+			ObjectMeta: metav1.ObjectMeta{Name: "dayz", Namespace: "default"},
+			Spec: gamev1alpha1.DayzSpec{Base: gameserverv1alpha1.Base{RCon: gameserverv1alpha1.RCon{
+				Port: server.Port(),
+				PasswordSecretRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "dayz-rcon"},
+					Key:                  "password",
+				},
+			}}},
+		}
+	})
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "dayz-rcon", Namespace: "default"},
+		Data:       map[string][]byte{"password": []byte("rcon-secret")},
+	}
+	runningPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "dayz-abc", Namespace: "default", Labels: map[string]string{"app": "dayz"}},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "127.0.0.1"},
+	}
+
+	newCommand := func(spec gameserverv1alpha1.GameServerCommandSpec) *gameserverv1alpha1.GameServerCommand {
+		spec.GameServerRef = gameserverv1alpha1.GameServerReference{Kind: "Dayz", Name: "dayz"}
+		return &gameserverv1alpha1.GameServerCommand{
+			ObjectMeta: metav1.ObjectMeta{Name: "command", Namespace: "default"},
+			Spec:       spec,
+		}
+	}
+
+	run := func(command *gameserverv1alpha1.GameServerCommand, objects ...client.Object) (reconcile.Result, *gameserverv1alpha1.GameServerCommand) {
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(append(objects, command)...).
+			WithStatusSubresource(&gameserverv1alpha1.GameServerCommand{}).
+			Build()
+		reconciler := &GameServerCommandReconciler{Client: fakeClient, Scheme: scheme, RConTimeout: time.Second}
+
+		key := types.NamespacedName{Name: command.Name, Namespace: command.Namespace}
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		updated := &gameserverv1alpha1.GameServerCommand{}
+		Expect(fakeClient.Get(ctx, key, updated)).To(Succeed())
+		return result, updated
+	}
+
+	It("should broadcast a message and record the outcome", func() {
+		command := newCommand(gameserverv1alpha1.GameServerCommandSpec{Command: gameserverv1alpha1.CommandSay, Message: "Restart in 5 minutes"})
+
+		_, updated := run(command, dayz, secret, runningPod)
+
+		Expect(updated.Status.Phase).To(Equal(gameserverv1alpha1.CommandSucceeded))
+		Expect(updated.Status.CompletionTime).NotTo(BeNil())
+		Expect(server.Commands()).To(Equal([]string{"say -1 Restart in 5 minutes"}))
+	})
+
+	It("should resolve player names before kicking", func() {
+		command := newCommand(gameserverv1alpha1.GameServerCommandSpec{Command: gameserverv1alpha1.CommandKick, Player: "Survivor", Reason: "AFK"})
+
+		_, updated := run(command, dayz, secret, runningPod)
+
+		Expect(updated.Status.Phase).To(Equal(gameserverv1alpha1.CommandSucceeded))
+		Expect(server.Commands()).To(Equal([]string{"players", "kick 0 AFK"}))
+	})
+
+	It("should fail when the player is not connected", func() {
+		command := newCommand(gameserverv1alpha1.GameServerCommandSpec{Command: gameserverv1alpha1.CommandBan, Player: "Nobody"})
+
+		_, updated := run(command, dayz, secret, runningPod)
+
+		Expect(updated.Status.Phase).To(Equal(gameserverv1alpha1.CommandFailed))
+		Expect(updated.Status.Message).To(ContainSubstring(`"Nobody" is not connected`))
+	})
+
+	It("should fail when the RCon password is wrong", func() {
+		wrong := secret.DeepCopy()
+		wrong.Data["password"] = []byte("wrong")
+		command := newCommand(gameserverv1alpha1.GameServerCommandSpec{Command: gameserverv1alpha1.CommandLock})
+
+		_, updated := run(command, dayz, wrong, runningPod)
+
+		Expect(updated.Status.Phase).To(Equal(gameserverv1alpha1.CommandFailed))
+		Expect(updated.Status.Message).To(ContainSubstring("login failed"))
+		Expect(server.Commands()).To(BeEmpty())
+	})
+
+	It("should fail when RCon is not configured", func() {
+		dayz.Spec.RCon.PasswordSecretRef = nil
+		command := newCommand(gameserverv1alpha1.GameServerCommandSpec{Command: gameserverv1alpha1.CommandShutdown})
+
+		_, updated := run(command, dayz, runningPod)
+
+		Expect(updated.Status.Phase).To(Equal(gameserverv1alpha1.CommandFailed))
+		Expect(updated.Status.Message).To(ContainSubstring("passwordSecretRef is not set"))
+	})
+
+	It("should wait for a running game server pod", func() {
+		command := newCommand(gameserverv1alpha1.GameServerCommandSpec{Command: gameserverv1alpha1.CommandShutdown})
+
+		result, updated := run(command, dayz, secret)
+
+		Expect(updated.Status.Phase).To(Equal(gameserverv1alpha1.CommandPending))
+		Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+	})
+
+	It("should send raw console commands", func() {
+		command := newCommand(gameserverv1alpha1.GameServerCommandSpec{Command: gameserverv1alpha1.CommandRaw, Raw: "#monitor 5"})
+
+		_, updated := run(command, dayz, secret, runningPod)
+
+		Expect(updated.Status.Phase).To(Equal(gameserverv1alpha1.CommandSucceeded))
+		Expect(server.Commands()).To(Equal([]string{"#monitor 5"}))
+	})
+
+	It("should fail commands DayZ has no console command for", func() {
+		command := newCommand(gameserverv1alpha1.GameServerCommandSpec{Command: gameserverv1alpha1.CommandSave})
+
+		_, updated := run(command, dayz, secret, runningPod)
+
+		Expect(updated.Status.Phase).To(Equal(gameserverv1alpha1.CommandFailed))
+		Expect(updated.Status.Message).To(ContainSubstring("not supported"))
+	})
+
+	It("should not run completed commands again", func() {
+		command := newCommand(gameserverv1alpha1.GameServerCommandSpec{Command: gameserverv1alpha1.CommandShutdown})
+		command.Status.Phase = gameserverv1alpha1.CommandSucceeded
+
+		run(command, dayz, secret, runningPod)
+
+		Expect(server.Commands()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("BuildBattlEyeCommand", func() {
+	It("should render the BattlEye command lines", func() {
+		Expect(BuildBattlEyeCommand(&gameserverv1alpha1.GameServerCommandSpec{Command: gameserverv1alpha1.CommandSay, Message: "hi"}, "")).To(Equal("say -1 hi"))
+		Expect(BuildBattlEyeCommand(&gameserverv1alpha1.GameServerCommandSpec{Command: gameserverv1alpha1.CommandSay, Message: "hi"}, "3")).To(Equal("say 3 hi"))
+		Expect(BuildBattlEyeCommand(&gameserverv1alpha1.GameServerCommandSpec{Command: gameserverv1alpha1.CommandKick}, "3")).To(Equal("kick 3"))
+		Expect(BuildBattlEyeCommand(&gameserverv1alpha1.GameServerCommandSpec{Command: gameserverv1alpha1.CommandBan, BanMinutes: 60, Reason: "cheating"}, "3")).To(Equal("ban 3 60 cheating"))
+		Expect(BuildBattlEyeCommand(&gameserverv1alpha1.GameServerCommandSpec{Command: gameserverv1alpha1.CommandLock}, "")).To(Equal("#lock"))
+		Expect(BuildBattlEyeCommand(&gameserverv1alpha1.GameServerCommandSpec{Command: gameserverv1alpha1.CommandShutdown}, "")).To(Equal("#shutdown"))
+	})
+})
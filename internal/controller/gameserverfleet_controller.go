@@ -0,0 +1,216 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+	gamev1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1/game"
+)
+
+// GameServerFleetReconciler creates, removes and updates the game servers of GameServerFleets
+type GameServerFleetReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Recorder records failures to render or create the game servers, none are recorded when nil
+	Recorder record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=gameserver.templarfelix.com,resources=gameserverfleets,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=gameserver.templarfelix.com,resources=gameserverfleets/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=gameserver.templarfelix.com,resources=dayzs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Reconcile scales the game servers of a fleet to spec.replicas, removing the emptiest servers first,
+// and rolls template changes out to at most spec.strategy.maxUnavailable servers at a time
+func (r *GameServerFleetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("gameserverfleet", req.Name)
+
+	fleet := &gameserverv1alpha1.GameServerFleet{}
+	if err := r.Get(ctx, req.NamespacedName, fleet); err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	if fleet.DeletionTimestamp != nil {
+		return reconcile.Result{}, nil
+	}
+
+	kind, err := LookupFleetKind(fleet)
+	if err != nil {
+		return r.failed(fleet, err)
+	}
+	all, err := ListFleetServers(ctx, r.Client, fleet, kind)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Indexes of servers being deleted stay taken until they are gone
+	var servers []FleetServer
+	taken := map[int]bool{}
+	for _, server := range all {
+		taken[server.Index] = true
+		if server.Object.GetDeletionTimestamp() == nil {
+			servers = append(servers, server)
+		}
+	}
+
+	replicas := len(servers)
+	for index := 0; replicas < int(fleet.Spec.Replicas); index++ {
+		if taken[index] {
+			continue
+		}
+		server, err := kind.Render(fleet, index)
+		if err != nil {
+			return r.failed(fleet, err)
+		}
+		if err := controllerutil.SetControllerReference(fleet, server, r.Scheme); err != nil {
+			return reconcile.Result{}, err
+		}
+		if err := r.Create(ctx, server); err != nil {
+			if errors.IsAlreadyExists(err) {
+				return reconcile.Result{Requeue: true}, nil
+			}
+			return r.failed(fleet, err)
+		}
+		logger.Info("Created game server", "name", server.GetName())
+		replicas++
+	}
+
+	// Allocated servers are kept until they are released, they sort last
+	if excess := len(servers) - int(fleet.Spec.Replicas); excess > 0 {
+		FleetScaleDownOrder(servers)
+		removed := 0
+		for _, server := range servers[:excess] {
+			if server.Allocated() {
+				break
+			}
+			if err := r.Delete(ctx, server.Object); err != nil && !errors.IsNotFound(err) {
+				return reconcile.Result{}, err
+			}
+			logger.Info("Removed game server", "name", server.Object.GetName(), "players", server.Status.Players)
+			removed++
+		}
+		servers = servers[removed:]
+		replicas = len(servers)
+	}
+
+	hash := FleetTemplateHash(fleet)
+	if err := r.rollOut(ctx, fleet, kind, servers, hash); err != nil {
+		if errors.IsConflict(err) {
+			return reconcile.Result{Requeue: true}, nil
+		}
+		return r.failed(fleet, err)
+	}
+
+	status := gameserverv1alpha1.GameServerFleetStatus{
+		Replicas:           int32(replicas),
+		Selector:           labels.SelectorFromSet(labels.Set{gameserverv1alpha1.FleetLabel: fleet.Name}).String(),
+		ObservedGeneration: fleet.Generation,
+	}
+	for i := range servers {
+		if servers[i].Ready() {
+			status.ReadyReplicas++
+		}
+		if servers[i].Updated(hash) {
+			status.UpdatedReplicas++
+		}
+		if servers[i].Allocated() {
+			status.AllocatedReplicas++
+		}
+		status.Players += servers[i].Status.Players
+	}
+	if !equality.Semantic.DeepEqual(fleet.Status, status) {
+		fleet.Status = status
+		if err := r.Status().Update(ctx, fleet); err != nil {
+			if errors.IsConflict(err) {
+				return reconcile.Result{Requeue: true}, nil
+			}
+			return reconcile.Result{}, err
+		}
+	}
+	return reconcile.Result{}, nil
+}
+
+// rollOut updates the servers not running the template with hash. Servers which are not ready are
+// updated right away, ready ones while fewer than maxUnavailable servers are not ready, emptiest first.
+// Allocated servers are updated once they are released.
+func (r *GameServerFleetReconciler) rollOut(ctx context.Context, fleet *gameserverv1alpha1.GameServerFleet, kind FleetKind, servers []FleetServer, hash string) error {
+	maxUnavailable := fleet.Spec.Strategy.MaxUnavailable
+	if maxUnavailable <= 0 {
+		maxUnavailable = 1
+	}
+	budget := int(maxUnavailable)
+	var outdated []FleetServer
+	for _, server := range servers {
+		if !server.Ready() {
+			budget--
+		}
+		if !server.Updated(hash) && !server.Allocated() {
+			outdated = append(outdated, server)
+		}
+	}
+
+	FleetScaleDownOrder(outdated)
+	for _, server := range outdated {
+		if server.Ready() {
+			if budget <= 0 {
+				return nil
+			}
+			budget--
+		}
+		rendered, err := kind.Render(fleet, server.Index)
+		if err != nil {
+			return err
+		}
+		kind.ApplySpec(server.Object, rendered)
+		if err := r.Update(ctx, server.Object); err != nil {
+			return err
+		}
+		log.FromContext(ctx).Info("Updated game server to the fleet template", "name", server.Object.GetName())
+	}
+	return nil
+}
+
+// failed records err as a Warning Event on fleet and returns it to retry the reconcile
+func (r *GameServerFleetReconciler) failed(fleet *gameserverv1alpha1.GameServerFleet, err error) (ctrl.Result, error) {
+	RecordEvent(r.Recorder, fleet, corev1.EventTypeWarning, EventReasonReconcileFailed, "%v", err)
+	return reconcile.Result{}, err
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GameServerFleetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&gameserverv1alpha1.GameServerFleet{}).
+		Owns(&gamev1alpha1.Dayz{}).
+		Complete(r)
+}
@@ -0,0 +1,229 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+	"github.com/templarfelix/gameserver-operator/internal/wakeproxy"
+)
+
+const (
+	// ReasonIdle means the game server is paused after running without players
+	ReasonIdle = "Idle"
+
+	// WakeProxySuffix names the wake proxy Deployment, its ServiceAccount, Role and RoleBinding
+	WakeProxySuffix = "-wake-proxy"
+
+	// GameServiceSuffix names the ClusterIP Service of the game pod the wake proxy forwards to
+	GameServiceSuffix = "-game"
+)
+
+// IsPaused reports whether the game server is scaled to zero by spec.paused or after being idle
+func IsPaused(base *gameserverv1alpha1.Base, status *gameserverv1alpha1.BaseStatus) bool {
+	return IsTrue(base.Paused) || status.Sleeping
+}
+
+// UpdateIdleStatus pauses a game server once it ran without players for spec.idle.shutdownAfterMinutes
+// and resumes it when the wake annotation of owner is newer than the pause. Allocated servers are not
+// idle. It reads the Ready condition and the player count of the previous query and returns how long
+// until the server is paused, zero when no pause is pending.
+func UpdateIdleStatus(owner client.Object, base *gameserverv1alpha1.Base, status *gameserverv1alpha1.BaseStatus, now time.Time) time.Duration {
+	period := time.Duration(base.Idle.ShutdownAfterMinutes) * time.Minute
+	if period == 0 || IsTrue(base.Paused) {
+		status.IdleSince = nil
+		status.Sleeping = false
+		return 0
+	}
+
+	if status.Sleeping {
+		// The server went to sleep at the end of its idle period
+		if status.IdleSince == nil || wokenAfter(owner, status.IdleSince.Add(period)) {
+			status.Sleeping = false
+			status.IdleSince = nil
+		}
+		return 0
+	}
+
+	if !meta.IsStatusConditionTrue(status.Conditions, ConditionReady) || status.Players > 0 || IsAllocated(owner) {
+		status.IdleSince = nil
+		return 0
+	}
+	if status.IdleSince == nil {
+		idleSince := metav1.NewTime(now)
+		status.IdleSince = &idleSince
+	}
+	if deadline := status.IdleSince.Add(period); now.Before(deadline) {
+		return deadline.Sub(now)
+	}
+	status.Sleeping = true
+	return 0
+}
+
+// wokenAfter reports whether the wake annotation of owner is later than t
+func wokenAfter(owner client.Object, t time.Time) bool {
+	woken, err := time.Parse(time.RFC3339, owner.GetAnnotations()[wakeproxy.WakeAnnotation])
+	return err == nil && woken.After(t)
+}
+
+// ServiceSelector returns the pod selector of the game LoadBalancer Services, the wake proxy
+// receives the traffic when spec.idle.wakeOnConnect is set
+func ServiceSelector(owner metav1.Object, base *gameserverv1alpha1.Base) map[string]string {
+	if IsTrue(base.Idle.WakeOnConnect) {
+		return map[string]string{"app": owner.GetName() + WakeProxySuffix}
+	}
+	return map[string]string{"app": owner.GetName()}
+}
+
+// ReconcileWakeProxy runs the wake proxy in front of the game pod when spec.idle.wakeOnConnect is set
+// and removes it otherwise. The proxy forwards to a ClusterIP Service of the game pod and may only
+// annotate the game server resource of owner, which is resource in the API.
+func ReconcileWakeProxy(ctx context.Context, c client.Client, owner client.Object, base *gameserverv1alpha1.Base, gameResource schema.GroupVersionResource, queryPort int32, image string) error {
+	name := owner.GetName() + WakeProxySuffix
+	namespace := owner.GetNamespace()
+	gameService := owner.GetName() + GameServiceSuffix
+
+	if !IsTrue(base.Idle.WakeOnConnect) {
+		for _, obj := range []struct {
+			obj  client.Object
+			name string
+		}{
+			{&appsv1.Deployment{}, name},
+			{&rbacv1.RoleBinding{}, name},
+			{&rbacv1.Role{}, name},
+			{&corev1.ServiceAccount{}, name},
+			{&corev1.Service{}, gameService},
+		} {
+			if err := deleteIfExists(ctx, c, owner, obj.obj, obj.name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if image == "" {
+		image = DefaultOperatorImage
+	}
+
+	var servicePorts []corev1.ServicePort
+	var containerPorts []corev1.ContainerPort
+	args := []string{
+		"--upstream=" + gameService + "." + namespace + ".svc",
+		"--query-port=" + strconv.Itoa(int(queryPort)),
+		"--resource=" + gameResource.Resource,
+		"--group=" + gameResource.Group,
+		"--version=" + gameResource.Version,
+		"--namespace=" + namespace,
+		"--name=" + owner.GetName(),
+	}
+	for _, port := range base.Ports {
+		// The proxy takes the place of the game pod, it listens on the ports the game pod listens on
+		target := port.TargetPort.IntVal
+		if target == 0 {
+			target = port.Port
+		}
+		servicePorts = append(servicePorts, corev1.ServicePort{
+			Name:     port.Name,
+			Protocol: port.Protocol,
+			Port:     target,
+		})
+		containerPorts = append(containerPorts, corev1.ContainerPort{
+			Name:          port.Name,
+			Protocol:      port.Protocol,
+			ContainerPort: target,
+		})
+		args = append(args, fmt.Sprintf("--port=%s/%d", port.Protocol, target))
+	}
+
+	serviceAccount := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	if err := createOrUpdateOwned(ctx, c, owner, serviceAccount, func() {}); err != nil {
+		return err
+	}
+
+	role := &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	if err := createOrUpdateOwned(ctx, c, owner, role, func() {
+		role.Rules = []rbacv1.PolicyRule{{
+			APIGroups:     []string{gameResource.Group},
+			Resources:     []string{gameResource.Resource},
+			ResourceNames: []string{owner.GetName()},
+			Verbs:         []string{"get", "patch"},
+		}}
+	}); err != nil {
+		return err
+	}
+
+	roleBinding := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	if err := createOrUpdateOwned(ctx, c, owner, roleBinding, func() {
+		roleBinding.RoleRef = rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: name}
+		roleBinding.Subjects = []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: name, Namespace: namespace}}
+	}); err != nil {
+		return err
+	}
+
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: gameService, Namespace: namespace}}
+	if err := createOrUpdateOwned(ctx, c, owner, service, func() {
+		service.Spec.Type = corev1.ServiceTypeClusterIP
+		service.Spec.Selector = map[string]string{"app": owner.GetName()}
+		service.Spec.Ports = servicePorts
+	}); err != nil {
+		return err
+	}
+
+	labels := map[string]string{"app": name}
+	replicas := int32(1)
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	return createOrUpdateOwned(ctx, c, owner, deployment, func() {
+		deployment.Spec.Replicas = &replicas
+		deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: labels}
+		deployment.Spec.Template.Labels = labels
+		deployment.Spec.Template.Spec.ServiceAccountName = name
+		deployment.Spec.Template.Spec.Containers = []corev1.Container{{
+			Name:    "wake-proxy",
+			Image:   image,
+			Command: []string{"/wakeproxy"},
+			Args:    args,
+			Ports:   containerPorts,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("10m"),
+					corev1.ResourceMemory: resource.MustParse("32Mi"),
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceMemory: resource.MustParse("128Mi"),
+				},
+			},
+			SecurityContext: &corev1.SecurityContext{
+				RunAsNonRoot:             func(b bool) *bool { return &b }(true),
+				AllowPrivilegeEscalation: func(b bool) *bool { return &b }(false),
+			},
+		}}
+	})
+}
+
+// createOrUpdateOwned creates obj or updates it with mutate, owned by owner
+func createOrUpdateOwned(ctx context.Context, c client.Client, owner metav1.Object, obj client.Object, mutate func()) error {
+	result, err := controllerutil.CreateOrUpdate(ctx, c, obj, func() error {
+		mutate()
+		return controllerutil.SetControllerReference(owner, obj, c.Scheme())
+	})
+	if err != nil {
+		return err
+	}
+	if result != controllerutil.OperationResultNone {
+		log.FromContext(ctx).Info("Reconciled owned resource", "namespace", obj.GetNamespace(), "name", obj.GetName(), "operation", result)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+	"github.com/templarfelix/gameserver-operator/internal/wakeproxy"
+)
+
+var _ = Describe("Idle", func() {
+	var (
+		owner  *corev1.ConfigMap
+		base   *gameserverv1alpha1.Base
+		status *gameserverv1alpha1.BaseStatus
+		start  time.Time
+	)
+
+	BeforeEach(func() {
+		owner = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "idle-server", Namespace: "default", UID: "idle-uid"}}
+		base = &gameserverv1alpha1.Base{Idle: gameserverv1alpha1.Idle{ShutdownAfterMinutes: 30}}
+		status = &gameserverv1alpha1.BaseStatus{}
+		start = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+		setReadyCondition(owner, status, metav1.ConditionTrue, ReasonQueryResponded, "up")
+	})
+
+	Describe("UpdateIdleStatus", func() {
+		It("should pause the server once it ran without players for the idle period", func() {
+			Expect(UpdateIdleStatus(owner, base, status, start)).To(Equal(30 * time.Minute))
+			Expect(status.IdleSince.Time).To(Equal(start))
+
+			Expect(UpdateIdleStatus(owner, base, status, start.Add(20*time.Minute))).To(Equal(10 * time.Minute))
+			Expect(status.Sleeping).To(BeFalse())
+
+			Expect(UpdateIdleStatus(owner, base, status, start.Add(30*time.Minute))).To(BeZero())
+			Expect(status.Sleeping).To(BeTrue())
+			Expect(IsPaused(base, status)).To(BeTrue())
+			Expect(*GameServerReplicas(base, status)).To(BeZero())
+
+			SetPausedStatus(owner, status)
+			Expect(meta.FindStatusCondition(status.Conditions, ConditionReady).Reason).To(Equal(ReasonIdle))
+		})
+
+		It("should restart the idle period when players join", func() {
+			UpdateIdleStatus(owner, base, status, start)
+			status.Players = 2
+			Expect(UpdateIdleStatus(owner, base, status, start.Add(20*time.Minute))).To(BeZero())
+			Expect(status.IdleSince).To(BeNil())
+		})
+
+		It("should not pause an allocated server", func() {
+			SetAllocated(owner, "match-1")
+			Expect(UpdateIdleStatus(owner, base, status, start)).To(BeZero())
+			Expect(status.IdleSince).To(BeNil())
+		})
+
+		It("should not count the time the server is starting", func() {
+			setReadyCondition(owner, status, metav1.ConditionFalse, ReasonQueryFailed, "starting")
+			Expect(UpdateIdleStatus(owner, base, status, start)).To(BeZero())
+			Expect(status.IdleSince).To(BeNil())
+		})
+
+		It("should wake the server on a connection after the pause", func() {
+			idleSince := metav1.NewTime(start)
+			status.IdleSince = &idleSince
+			status.Sleeping = true
+
+			// A connection seen before the pause does not wake the server
+			owner.Annotations = map[string]string{wakeproxy.WakeAnnotation: "2024-06-01T12:10:00Z"}
+			UpdateIdleStatus(owner, base, status, start.Add(time.Hour))
+			Expect(status.Sleeping).To(BeTrue())
+
+			owner.Annotations[wakeproxy.WakeAnnotation] = "2024-06-01T12:45:00Z"
+			UpdateIdleStatus(owner, base, status, start.Add(time.Hour))
+			Expect(status.Sleeping).To(BeFalse())
+			Expect(status.IdleSince).To(BeNil())
+		})
+
+		It("should leave the server running without idle shutdown", func() {
+			status.Sleeping = true
+			Expect(UpdateIdleStatus(owner, &gameserverv1alpha1.Base{}, status, start)).To(BeZero())
+			Expect(status.Sleeping).To(BeFalse())
+			Expect(status.IdleSince).To(BeNil())
+		})
+	})
+
+	Describe("ReconcileWakeProxy", func() {
+		dayzs := gameserverv1alpha1.GroupVersion.WithResource("dayzs")
+
+		BeforeEach(func() {
+			wake := true
+			base.Idle.WakeOnConnect = &wake
+			base.Ports = []corev1.ServicePort{
+				{Name: "game", Port: 2302, TargetPort: intstr.FromInt32(2302), Protocol: corev1.ProtocolUDP},
+				{Name: "query", Port: 27016, TargetPort: intstr.FromInt32(27016), Protocol: corev1.ProtocolUDP},
+			}
+		})
+
+		It("should run the proxy in front of the game pod", func() {
+			ctx := context.Background()
+			c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+
+			Expect(ReconcileWakeProxy(ctx, c, owner, base, dayzs, 27016, "operator:v1")).To(Succeed())
+
+			deployment := &appsv1.Deployment{}
+			Expect(c.Get(ctx, types.NamespacedName{Name: "idle-server-wake-proxy", Namespace: "default"}, deployment)).To(Succeed())
+			container := deployment.Spec.Template.Spec.Containers[0]
+			Expect(container.Image).To(Equal("operator:v1"))
+			Expect(container.Args).To(ContainElements(
+				"--upstream=idle-server-game.default.svc",
+				"--query-port=27016",
+				"--resource=dayzs",
+				"--name=idle-server",
+				"--port=UDP/2302",
+				"--port=UDP/27016",
+			))
+			Expect(deployment.Spec.Template.Spec.ServiceAccountName).To(Equal("idle-server-wake-proxy"))
+
+			role := &rbacv1.Role{}
+			Expect(c.Get(ctx, types.NamespacedName{Name: "idle-server-wake-proxy", Namespace: "default"}, role)).To(Succeed())
+			Expect(role.Rules).To(ConsistOf(rbacv1.PolicyRule{
+				APIGroups:     []string{"gameserver.templarfelix.com"},
+				Resources:     []string{"dayzs"},
+				ResourceNames: []string{"idle-server"},
+				Verbs:         []string{"get", "patch"},
+			}))
+
+			game := &corev1.Service{}
+			Expect(c.Get(ctx, types.NamespacedName{Name: "idle-server-game", Namespace: "default"}, game)).To(Succeed())
+			Expect(game.Spec.Selector).To(Equal(map[string]string{"app": "idle-server"}))
+			Expect(game.Spec.Ports).To(HaveLen(2))
+
+			Expect(ServiceSelector(owner, base)).To(Equal(map[string]string{"app": "idle-server-wake-proxy"}))
+		})
+
+		It("should remove the proxy when wake on connect is disabled", func() {
+			ctx := context.Background()
+			c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+			Expect(ReconcileWakeProxy(ctx, c, owner, base, dayzs, 27016, "")).To(Succeed())
+
+			base.Idle.WakeOnConnect = nil
+			Expect(ReconcileWakeProxy(ctx, c, owner, base, dayzs, 27016, "")).To(Succeed())
+
+			err := c.Get(ctx, types.NamespacedName{Name: "idle-server-wake-proxy", Namespace: "default"}, &appsv1.Deployment{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+			err = c.Get(ctx, types.NamespacedName{Name: "idle-server-game", Namespace: "default"}, &corev1.Service{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+			Expect(ServiceSelector(owner, base)).To(Equal(map[string]string{"app": "idle-server"}))
+		})
+	})
+})
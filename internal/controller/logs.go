@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+)
+
+const (
+	// LogContainerPrefix names the sidecar streaming a log, log-<stream>
+	LogContainerPrefix = "log-"
+
+	// LogsSuffix names the ServiceAccount, Role and RoleBinding recording player Events
+	LogsSuffix = "-logs"
+
+	// LogsTokenVolumeName is the ServiceAccount token of the sidecar recording player Events, the other
+	// containers of the game pod get no token
+	LogsTokenVolumeName = "logs-token"
+
+	serviceAccountMountPath = "/var/run/secrets/kubernetes.io/serviceaccount"
+)
+
+// LogFiles are the globs of the files of each log a game writes, the most recently modified file of a
+// glob is the current log
+type LogFiles map[gameserverv1alpha1.LogStream]string
+
+// logStreams returns the streams of logs the game writes, in order, with the admin log for Events
+func logStreams(logs *gameserverv1alpha1.Logs, files LogFiles) []gameserverv1alpha1.LogStream {
+	streams := logs.Streams
+	if IsTrue(logs.Events) {
+		streams = append(append([]gameserverv1alpha1.LogStream{}, streams...), gameserverv1alpha1.LogStreamADM)
+	}
+	var result []gameserverv1alpha1.LogStream
+	seen := map[gameserverv1alpha1.LogStream]bool{}
+	for _, stream := range streams {
+		if _, ok := files[stream]; ok && !seen[stream] {
+			seen[stream] = true
+			result = append(result, stream)
+		}
+	}
+	return result
+}
+
+// recordsLogEvents reports whether the admin log sidecar records player Events
+func recordsLogEvents(logs *gameserverv1alpha1.Logs, files LogFiles) bool {
+	_, ok := files[gameserverv1alpha1.LogStreamADM]
+	return IsTrue(logs.Events) && ok
+}
+
+// AddLogContainers adds a sidecar streaming each log of logs the game writes to podSpec. With
+// logs.events the admin log sidecar records player Events on owner, of kind gvk, with the token of
+// the ServiceAccount of ReconcileLogsServiceAccount.
+func AddLogContainers(podSpec *corev1.PodSpec, image string, owner client.Object, gvk schema.GroupVersionKind, logs *gameserverv1alpha1.Logs, files LogFiles) {
+	if image == "" {
+		image = DefaultOperatorImage
+	}
+	events := recordsLogEvents(logs, files)
+	for _, stream := range logStreams(logs, files) {
+		container := corev1.Container{
+			Name:    LogContainerPrefix + string(stream),
+			Image:   image,
+			Command: []string{"/logs"},
+			Args:    []string{"--file=" + files[stream]},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("10m"),
+					corev1.ResourceMemory: resource.MustParse("16Mi"),
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceMemory: resource.MustParse("64Mi"),
+				},
+			},
+			SecurityContext: &corev1.SecurityContext{
+				RunAsUser:                func(i int64) *int64 { return &i }(1000),
+				RunAsGroup:               func(i int64) *int64 { return &i }(1000),
+				RunAsNonRoot:             func(b bool) *bool { return &b }(true),
+				AllowPrivilegeEscalation: func(b bool) *bool { return &b }(false),
+			},
+			VolumeMounts: []corev1.VolumeMount{
+				{Name: DataVolumeName, MountPath: DataMountPath, ReadOnly: true},
+			},
+		}
+		if events && stream == gameserverv1alpha1.LogStreamADM {
+			container.Args = append(container.Args,
+				"--events",
+				"--api-version="+gvk.GroupVersion().String(),
+				"--kind="+gvk.Kind,
+				"--namespace="+owner.GetNamespace(),
+				"--name="+owner.GetName(),
+				"--uid="+string(owner.GetUID()),
+			)
+			container.VolumeMounts = append(container.VolumeMounts,
+				corev1.VolumeMount{Name: LogsTokenVolumeName, MountPath: serviceAccountMountPath, ReadOnly: true})
+		}
+		podSpec.Containers = append(podSpec.Containers, container)
+	}
+
+	if !events {
+		return
+	}
+	podSpec.ServiceAccountName = owner.GetName() + LogsSuffix
+	podSpec.AutomountServiceAccountToken = func(b bool) *bool { return &b }(false)
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: LogsTokenVolumeName,
+		VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{
+			{ServiceAccountToken: &corev1.ServiceAccountTokenProjection{Path: "token"}},
+			{ConfigMap: &corev1.ConfigMapProjection{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "kube-root-ca.crt"},
+				Items:                []corev1.KeyToPath{{Key: "ca.crt", Path: "ca.crt"}},
+			}},
+			{DownwardAPI: &corev1.DownwardAPIProjection{Items: []corev1.DownwardAPIVolumeFile{{
+				Path:     "namespace",
+				FieldRef: &corev1.ObjectFieldSelector{APIVersion: "v1", FieldPath: "metadata.namespace"},
+			}}}},
+		}}},
+	})
+}
+
+// ReconcileLogsServiceAccount creates the ServiceAccount the admin log sidecar records player Events
+// with when logs.events is set and removes it otherwise
+func ReconcileLogsServiceAccount(ctx context.Context, c client.Client, owner client.Object, logs *gameserverv1alpha1.Logs, files LogFiles) error {
+	name := owner.GetName() + LogsSuffix
+	namespace := owner.GetNamespace()
+
+	if !recordsLogEvents(logs, files) {
+		for _, obj := range []client.Object{&rbacv1.RoleBinding{}, &rbacv1.Role{}, &corev1.ServiceAccount{}} {
+			if err := deleteIfExists(ctx, c, owner, obj, name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	serviceAccount := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	if err := createOrUpdateOwned(ctx, c, owner, serviceAccount, func() {}); err != nil {
+		return err
+	}
+
+	role := &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	if err := createOrUpdateOwned(ctx, c, owner, role, func() {
+		role.Rules = []rbacv1.PolicyRule{{
+			APIGroups: []string{""},
+			Resources: []string{"events"},
+			Verbs:     []string{"create"},
+		}}
+	}); err != nil {
+		return err
+	}
+
+	roleBinding := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	return createOrUpdateOwned(ctx, c, owner, roleBinding, func() {
+		roleBinding.RoleRef = rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: name}
+		roleBinding.Subjects = []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: name, Namespace: namespace}}
+	})
+}
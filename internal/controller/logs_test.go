@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+)
+
+var _ = Describe("Logs", func() {
+	owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "logged", Namespace: "default", UID: "1234"}}
+	dayz := gameserverv1alpha1.GroupVersion.WithKind("Dayz")
+	events := true
+	files := LogFiles{
+		gameserverv1alpha1.LogStreamConsole: "/data/log/console/dayzserver-console.log",
+		gameserverv1alpha1.LogStreamADM:     "/data/serverfiles/profiles/*.ADM",
+	}
+
+	It("should stream each log the game writes from a sidecar", func() {
+		podSpec := &corev1.PodSpec{}
+		AddLogContainers(podSpec, "", owner, dayz, &gameserverv1alpha1.Logs{Streams: []gameserverv1alpha1.LogStream{
+			gameserverv1alpha1.LogStreamConsole, gameserverv1alpha1.LogStreamRPT, gameserverv1alpha1.LogStreamConsole,
+		}}, files)
+
+		Expect(podSpec.Containers).To(HaveLen(1))
+		container := podSpec.Containers[0]
+		Expect(container.Name).To(Equal("log-console"))
+		Expect(container.Image).To(Equal(DefaultOperatorImage))
+		Expect(container.Args).To(Equal([]string{"--file=/data/log/console/dayzserver-console.log"}))
+		Expect(container.VolumeMounts).To(Equal([]corev1.VolumeMount{{Name: DataVolumeName, MountPath: "/data", ReadOnly: true}}))
+		Expect(podSpec.ServiceAccountName).To(BeEmpty())
+		Expect(podSpec.Volumes).To(BeEmpty())
+	})
+
+	It("should only give the admin log sidecar a token to record Events", func() {
+		podSpec := &corev1.PodSpec{Containers: []corev1.Container{{Name: "server"}}}
+		AddLogContainers(podSpec, "operator:v1", owner, dayz, &gameserverv1alpha1.Logs{Events: &events}, files)
+
+		Expect(podSpec.Containers).To(HaveLen(2))
+		container := podSpec.Containers[1]
+		Expect(container.Name).To(Equal("log-adm"))
+		Expect(container.Args).To(Equal([]string{
+			"--file=/data/serverfiles/profiles/*.ADM",
+			"--events",
+			"--api-version=gameserver.templarfelix.com/v1alpha1",
+			"--kind=Dayz",
+			"--namespace=default",
+			"--name=logged",
+			"--uid=1234",
+		}))
+		Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name: LogsTokenVolumeName, MountPath: "/var/run/secrets/kubernetes.io/serviceaccount", ReadOnly: true,
+		}))
+		Expect(podSpec.Containers[0].VolumeMounts).To(BeEmpty())
+		Expect(podSpec.ServiceAccountName).To(Equal("logged-logs"))
+		Expect(*podSpec.AutomountServiceAccountToken).To(BeFalse())
+		Expect(podSpec.Volumes).To(HaveLen(1))
+		Expect(podSpec.Volumes[0].Projected.Sources).To(HaveLen(3))
+	})
+
+	It("should grant the Events ServiceAccount only while Events are recorded", func() {
+		ctx := context.Background()
+		c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+		key := types.NamespacedName{Name: "logged-logs", Namespace: "default"}
+
+		Expect(ReconcileLogsServiceAccount(ctx, c, owner, &gameserverv1alpha1.Logs{Events: &events}, files)).To(Succeed())
+		role := &rbacv1.Role{}
+		Expect(c.Get(ctx, key, role)).To(Succeed())
+		Expect(role.Rules).To(ConsistOf(rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"events"}, Verbs: []string{"create"}}))
+		roleBinding := &rbacv1.RoleBinding{}
+		Expect(c.Get(ctx, key, roleBinding)).To(Succeed())
+		Expect(roleBinding.Subjects[0].Name).To(Equal("logged-logs"))
+
+		Expect(ReconcileLogsServiceAccount(ctx, c, owner, &gameserverv1alpha1.Logs{}, files)).To(Succeed())
+		Expect(apierrors.IsNotFound(c.Get(ctx, key, &corev1.ServiceAccount{}))).To(BeTrue())
+		Expect(apierrors.IsNotFound(c.Get(ctx, key, &rbacv1.Role{}))).To(BeTrue())
+	})
+})
@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+)
+
+// Reconcile steps reported by the gameserver_reconcile_errors_total metric
+const (
+	StepDefaults       = "defaults"
+	StepPVC            = "pvc"
+	StepEditorSecret   = "editor_secret"
+	StepDeployment     = "deployment"
+	StepService        = "service"
+	StepEditorExposure = "editor_exposure"
+	StepWakeProxy      = "wake_proxy"
+	StepPlayerLists    = "player_lists"
+	StepLogs           = "logs"
+	StepStatus         = "status"
+)
+
+// LastBackupAnnotation is set on a game server by the backup tooling to the RFC 3339 time its last backup
+// of the game data completed, the operator does not take backups itself
+const LastBackupAnnotation = "gameserver.templarfelix.com/last-backup"
+
+// NoBackupAge is the value of gameserver_backup_age_seconds for servers without a recorded backup
+const NoBackupAge = -1
+
+var gameServerLabels = []string{"kind", "namespace", "name"}
+
+var (
+	// GameServerPlayers is the number of connected players reported by the query port
+	GameServerPlayers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "gameserver_players",
+		Help: "Number of players connected to the game server",
+	}, gameServerLabels)
+
+	// GameServerMaxPlayers is the number of player slots reported by the query port
+	GameServerMaxPlayers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "gameserver_max_players",
+		Help: "Number of player slots of the game server",
+	}, gameServerLabels)
+
+	// GameServerUp is 1 while the game server answers on its query port
+	GameServerUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "gameserver_up",
+		Help: "Whether the game server answers on its query port (1) or not (0)",
+	}, gameServerLabels)
+
+	// GameServerRestarts counts the container restarts of the game server pods. Unlike the restart count
+	// of a pod it does not drop when the pod is replaced.
+	GameServerRestarts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gameserver_restarts_total",
+		Help: "Container restarts of the game server pods",
+	}, gameServerLabels)
+
+	// GameServerBackupAge is the time since the last backup recorded in LastBackupAnnotation
+	GameServerBackupAge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "gameserver_backup_age_seconds",
+		Help: "Seconds since the last backup of the game server data, -1 when no backup is recorded",
+	}, gameServerLabels)
+
+	// QueryLatency is the duration of successful query port round trips
+	QueryLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gameserver_query_latency_seconds",
+		Help:    "Latency of game server query port requests",
+		Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5},
+	}, []string{"kind"})
+
+	// ReconcileErrors counts failed reconcile steps
+	ReconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gameserver_reconcile_errors_total",
+		Help: "Number of failed reconcile steps per game server kind",
+	}, []string{"kind", "step"})
+)
+
+func init() {
+	// Served on the controller-runtime metrics endpoint next to the default controller metrics
+	metrics.Registry.MustRegister(
+		GameServerPlayers,
+		GameServerMaxPlayers,
+		GameServerUp,
+		GameServerRestarts,
+		GameServerBackupAge,
+		QueryLatency,
+		ReconcileErrors,
+	)
+}
+
+// operatorStart is when the operator started, restarts of pods created before are not counted again.
+// Creation timestamps have a precision of seconds.
+var operatorStart = time.Now().Truncate(time.Second)
+
+// observedRestarts is the restart count of each game server pod when the metrics were last recorded,
+// by game server
+var observedRestarts = struct {
+	sync.Mutex
+	servers map[string]map[types.UID]int32
+}{servers: map[string]map[types.UID]int32{}}
+
+// RecordReconcileError counts a failed reconcile step
+func RecordReconcileError(kind, step string) {
+	ReconcileErrors.WithLabelValues(kind, step).Inc()
+}
+
+// RecordGameServerMetrics exports the observed state of a game server and the restarts of its pods
+func RecordGameServerMetrics(ctx context.Context, c client.Client, kind string, owner client.Object, status *gameserverv1alpha1.BaseStatus) error {
+	labels := prometheus.Labels{"kind": kind, "namespace": owner.GetNamespace(), "name": owner.GetName()}
+
+	up := 0.0
+	if meta.IsStatusConditionTrue(status.Conditions, ConditionReady) {
+		up = 1
+	}
+	GameServerUp.With(labels).Set(up)
+	GameServerPlayers.With(labels).Set(float64(status.Players))
+	GameServerMaxPlayers.With(labels).Set(float64(status.MaxPlayers))
+
+	GameServerBackupAge.With(labels).Set(backupAge(owner, time.Now()))
+
+	pods := &corev1.PodList{}
+	if err := c.List(ctx, pods, client.InNamespace(owner.GetNamespace()), client.MatchingLabels{"app": owner.GetName()}); err != nil {
+		return err
+	}
+	GameServerRestarts.With(labels).Add(float64(countRestarts(kind+"/"+owner.GetNamespace()+"/"+owner.GetName(), pods.Items)))
+	return nil
+}
+
+// backupAge returns the seconds since the LastBackupAnnotation of owner, NoBackupAge without a valid one
+func backupAge(owner client.Object, now time.Time) float64 {
+	lastBackup, err := time.Parse(time.RFC3339, owner.GetAnnotations()[LastBackupAnnotation])
+	if err != nil {
+		return NoBackupAge
+	}
+	return now.Sub(lastBackup).Seconds()
+}
+
+// countRestarts returns the container restarts of pods since they were last counted for server. The
+// restarts a pod had before the operator started are counted only if the pod was created since.
+func countRestarts(server string, pods []corev1.Pod) int32 {
+	observedRestarts.Lock()
+	defer observedRestarts.Unlock()
+
+	previous := observedRestarts.servers[server]
+	current := make(map[types.UID]int32, len(pods))
+	var restarts int32
+	for _, pod := range pods {
+		var count int32
+		for _, containerStatus := range pod.Status.ContainerStatuses {
+			count += containerStatus.RestartCount
+		}
+		current[pod.UID] = count
+
+		last, seen := previous[pod.UID]
+		if !seen && pod.CreationTimestamp.Time.Before(operatorStart) {
+			continue
+		}
+		if count > last {
+			restarts += count - last
+		}
+	}
+	observedRestarts.servers[server] = current
+	return restarts
+}
+
+// DeleteGameServerMetrics removes the series of a deleted game server
+func DeleteGameServerMetrics(kind, namespace, name string) {
+	labels := prometheus.Labels{"kind": kind, "namespace": namespace, "name": name}
+	GameServerPlayers.Delete(labels)
+	GameServerMaxPlayers.Delete(labels)
+	GameServerUp.Delete(labels)
+	GameServerRestarts.Delete(labels)
+	GameServerBackupAge.Delete(labels)
+
+	observedRestarts.Lock()
+	delete(observedRestarts.servers, kind+"/"+namespace+"/"+name)
+	observedRestarts.Unlock()
+}
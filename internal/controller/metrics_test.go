@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+)
+
+var _ = Describe("Metrics", func() {
+	var owner *corev1.ConfigMap
+
+	BeforeEach(func() {
+		owner = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "metrics-server", Namespace: "default", UID: "test-uid"},
+		}
+		DeferCleanup(DeleteGameServerMetrics, "Test", "default", "metrics-server")
+	})
+
+	It("should export players, up and pod restarts of a game server", func() {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "metrics-server-abc", Namespace: "default", UID: "pod-1",
+				Labels: map[string]string{"app": "metrics-server"}, CreationTimestamp: metav1.Now()},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "server", RestartCount: 2},
+				{Name: CodeServerContainerName, RestartCount: 1},
+			}},
+		}
+		fakeClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(pod).Build()
+		status := &gameserverv1alpha1.BaseStatus{
+			Players:    5,
+			MaxPlayers: 60,
+			Conditions: []metav1.Condition{{Type: ConditionReady, Status: metav1.ConditionTrue}},
+		}
+
+		Expect(RecordGameServerMetrics(context.Background(), fakeClient, "Test", owner, status)).To(Succeed())
+
+		Expect(testutil.ToFloat64(GameServerPlayers.WithLabelValues("Test", "default", "metrics-server"))).To(Equal(5.0))
+		Expect(testutil.ToFloat64(GameServerMaxPlayers.WithLabelValues("Test", "default", "metrics-server"))).To(Equal(60.0))
+		Expect(testutil.ToFloat64(GameServerUp.WithLabelValues("Test", "default", "metrics-server"))).To(Equal(1.0))
+		Expect(testutil.ToFloat64(GameServerRestarts.WithLabelValues("Test", "default", "metrics-server"))).To(Equal(3.0))
+		Expect(testutil.ToFloat64(GameServerBackupAge.WithLabelValues("Test", "default", "metrics-server"))).To(Equal(float64(NoBackupAge)))
+	})
+
+	It("should keep counting restarts when the pod is replaced", func() {
+		ctx := context.Background()
+		pod := func(uid string, created metav1.Time, restarts int32) *corev1.Pod {
+			return &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "metrics-server-" + uid, Namespace: "default", UID: types.UID(uid),
+					Labels: map[string]string{"app": "metrics-server"}, CreationTimestamp: created},
+				Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Name: "server", RestartCount: restarts}}},
+			}
+		}
+		restarts := func() float64 {
+			return testutil.ToFloat64(GameServerRestarts.WithLabelValues("Test", "default", "metrics-server"))
+		}
+
+		// Restarts of a pod created before the operator started were counted by the previous operator
+		old := pod("old", metav1.NewTime(operatorStart.Add(-time.Hour)), 7)
+		fakeClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(old).Build()
+		Expect(RecordGameServerMetrics(ctx, fakeClient, "Test", owner, &gameserverv1alpha1.BaseStatus{})).To(Succeed())
+		Expect(restarts()).To(BeZero())
+
+		old.Status.ContainerStatuses[0].RestartCount = 9
+		Expect(fakeClient.Status().Update(ctx, old)).To(Succeed())
+		Expect(RecordGameServerMetrics(ctx, fakeClient, "Test", owner, &gameserverv1alpha1.BaseStatus{})).To(Succeed())
+		Expect(restarts()).To(Equal(2.0))
+
+		Expect(fakeClient.Delete(ctx, old)).To(Succeed())
+		Expect(fakeClient.Create(ctx, pod("new", metav1.Now(), 1))).To(Succeed())
+		Expect(RecordGameServerMetrics(ctx, fakeClient, "Test", owner, &gameserverv1alpha1.BaseStatus{})).To(Succeed())
+		Expect(restarts()).To(Equal(3.0))
+	})
+
+	It("should export the age of the last recorded backup", func() {
+		fakeClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+		owner.Annotations = map[string]string{LastBackupAnnotation: time.Now().Add(-time.Hour).Format(time.RFC3339)}
+
+		Expect(RecordGameServerMetrics(context.Background(), fakeClient, "Test", owner, &gameserverv1alpha1.BaseStatus{})).To(Succeed())
+		Expect(testutil.ToFloat64(GameServerBackupAge.WithLabelValues("Test", "default", "metrics-server"))).To(BeNumerically("~", 3600, 5))
+
+		owner.Annotations[LastBackupAnnotation] = "yesterday"
+		Expect(RecordGameServerMetrics(context.Background(), fakeClient, "Test", owner, &gameserverv1alpha1.BaseStatus{})).To(Succeed())
+		Expect(testutil.ToFloat64(GameServerBackupAge.WithLabelValues("Test", "default", "metrics-server"))).To(Equal(float64(NoBackupAge)))
+	})
+
+	It("should report a server without Ready condition as down", func() {
+		fakeClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+
+		Expect(RecordGameServerMetrics(context.Background(), fakeClient, "Test", owner, &gameserverv1alpha1.BaseStatus{})).To(Succeed())
+
+		Expect(testutil.ToFloat64(GameServerUp.WithLabelValues("Test", "default", "metrics-server"))).To(BeZero())
+	})
+
+	It("should drop the series of deleted game servers", func() {
+		fakeClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+		Expect(RecordGameServerMetrics(context.Background(), fakeClient, "Test", owner, &gameserverv1alpha1.BaseStatus{})).To(Succeed())
+		Expect(testutil.CollectAndCount(GameServerUp, "gameserver_up")).To(Equal(1))
+
+		DeleteGameServerMetrics("Test", "default", "metrics-server")
+		Expect(testutil.CollectAndCount(GameServerUp, "gameserver_up")).To(BeZero())
+	})
+
+	It("should count failed reconcile steps", func() {
+		before := testutil.ToFloat64(ReconcileErrors.WithLabelValues("Test", StepDeployment))
+		RecordReconcileError("Test", StepDeployment)
+		Expect(testutil.ToFloat64(ReconcileErrors.WithLabelValues("Test", StepDeployment))).To(Equal(before + 1))
+	})
+})
@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+	"github.com/templarfelix/gameserver-operator/internal/notify"
+)
+
+// GameContainerName is the name of the game container in the pods of game servers
+const GameContainerName = "server"
+
+// Notifier delivers notifications to the NotificationChannels of game servers
+type Notifier interface {
+	Enqueue(namespace, channel string, notification notify.Notification)
+}
+
+// UpdateCrashStatus sets status.LastCrashTime to the newest failed exit of the game container in the
+// pods of owner. Pods being deleted are skipped, their game container is stopped on purpose.
+func UpdateCrashStatus(ctx context.Context, c client.Client, owner client.Object, status *gameserverv1alpha1.BaseStatus) error {
+	pods := &corev1.PodList{}
+	if err := c.List(ctx, pods, client.InNamespace(owner.GetNamespace()), client.MatchingLabels{"app": owner.GetName()}); err != nil {
+		return err
+	}
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		for _, containerStatus := range pod.Status.ContainerStatuses {
+			if containerStatus.Name != GameContainerName {
+				continue
+			}
+			for _, terminated := range []*corev1.ContainerStateTerminated{containerStatus.State.Terminated, containerStatus.LastTerminationState.Terminated} {
+				if terminated == nil || terminated.ExitCode == 0 {
+					continue
+				}
+				finished := terminated.FinishedAt
+				if status.LastCrashTime == nil || status.LastCrashTime.Before(&finished) {
+					status.LastCrashTime = &metav1.Time{Time: finished.Time}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// NotifyStatusChanges enqueues a notification for every subscribed event between the status old and
+// new of a game server of kind: started when the phase becomes Running, stopped when it becomes
+// Paused, crashed for a new status.lastCrashTime, updated when another build is installed, and
+// players when the player count rises to a threshold. Nothing is sent with a nil notifier.
+func NotifyStatusChanges(notifier Notifier, owner client.Object, kind string, notifications []gameserverv1alpha1.Notification, old, new *gameserverv1alpha1.BaseStatus, now time.Time) {
+	if notifier == nil || len(notifications) == 0 {
+		return
+	}
+	server := fmt.Sprintf("%s %s/%s", kind, owner.GetNamespace(), owner.GetName())
+
+	events := map[gameserverv1alpha1.NotificationEvent]string{}
+	if new.Phase != old.Phase && new.Phase == gameserverv1alpha1.GameServerRunning {
+		events[gameserverv1alpha1.NotificationStarted] = fmt.Sprintf("%s started", server)
+	}
+	if new.Phase != old.Phase && new.Phase == gameserverv1alpha1.GameServerPaused {
+		events[gameserverv1alpha1.NotificationStopped] = fmt.Sprintf("%s stopped", server)
+	}
+	if new.LastCrashTime != nil && (old.LastCrashTime == nil || old.LastCrashTime.Before(new.LastCrashTime)) {
+		events[gameserverv1alpha1.NotificationCrashed] = fmt.Sprintf("%s crashed", server)
+	}
+	if installed, previous := installedBuild(new), installedBuild(old); previous != "" && installed != "" && installed != previous {
+		events[gameserverv1alpha1.NotificationUpdated] = fmt.Sprintf("%s was updated from build %s to %s", server, previous, installed)
+	}
+
+	for _, subscription := range notifications {
+		for _, event := range subscription.Events {
+			message, ok := events[event]
+			if event == gameserverv1alpha1.NotificationPlayers {
+				message, ok = playersMessage(server, subscription.PlayerThresholds, old.Players, new.Players)
+			}
+			if !ok {
+				continue
+			}
+			notifier.Enqueue(owner.GetNamespace(), subscription.ChannelRef.Name, notify.Notification{
+				Event:     string(event),
+				Kind:      kind,
+				Namespace: owner.GetNamespace(),
+				Name:      owner.GetName(),
+				Message:   message,
+				Players:   new.Players,
+				Time:      now,
+			})
+		}
+	}
+}
+
+// playersMessage describes the highest threshold the player count rose to from old to new
+func playersMessage(server string, thresholds []int32, old, new int32) (string, bool) {
+	reached := int32(0)
+	for _, threshold := range thresholds {
+		if old < threshold && threshold <= new {
+			reached = max(reached, threshold)
+		}
+	}
+	if reached == 0 {
+		return "", false
+	}
+	return fmt.Sprintf("%s reached %d players", server, reached), true
+}
+
+// installedBuild returns the installed build recorded in status, "" when unknown
+func installedBuild(status *gameserverv1alpha1.BaseStatus) string {
+	if status.Updates == nil {
+		return ""
+	}
+	return status.Updates.InstalledBuildID
+}
@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+	"github.com/templarfelix/gameserver-operator/internal/notify"
+)
+
+// queuedNotification is a notification enqueued on the fakeNotifier
+type queuedNotification struct {
+	namespace, channel string
+	notification       notify.Notification
+}
+
+type fakeNotifier struct {
+	queued []queuedNotification
+}
+
+func (n *fakeNotifier) Enqueue(namespace, channel string, notification notify.Notification) {
+	n.queued = append(n.queued, queuedNotification{namespace: namespace, channel: channel, notification: notification})
+}
+
+var _ = Describe("Notifications", func() {
+	owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "noisy", Namespace: "games"}}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	messages := func(notifications []gameserverv1alpha1.Notification, old, new *gameserverv1alpha1.BaseStatus) map[string][]string {
+		notifier := &fakeNotifier{}
+		NotifyStatusChanges(notifier, owner, "Dayz", notifications, old, new, now)
+		sent := map[string][]string{}
+		for _, queued := range notifier.queued {
+			Expect(queued.namespace).To(Equal("games"))
+			Expect(queued.notification.Name).To(Equal("noisy"))
+			Expect(queued.notification.Time).To(Equal(now))
+			sent[queued.channel] = append(sent[queued.channel], queued.notification.Event+": "+queued.notification.Message)
+		}
+		return sent
+	}
+	subscribe := func(channel string, thresholds []int32, events ...gameserverv1alpha1.NotificationEvent) gameserverv1alpha1.Notification {
+		return gameserverv1alpha1.Notification{
+			ChannelRef:       corev1.LocalObjectReference{Name: channel},
+			Events:           events,
+			PlayerThresholds: thresholds,
+		}
+	}
+
+	It("should notify the subscribed channels of lifecycle transitions", func() {
+		notifications := []gameserverv1alpha1.Notification{
+			subscribe("discord", nil, gameserverv1alpha1.NotificationStarted, gameserverv1alpha1.NotificationCrashed, gameserverv1alpha1.NotificationUpdated),
+			subscribe("slack", nil, gameserverv1alpha1.NotificationStopped),
+		}
+		old := &gameserverv1alpha1.BaseStatus{
+			Phase:   gameserverv1alpha1.GameServerPending,
+			Updates: &gameserverv1alpha1.UpdateStatus{InstalledBuildID: "100"},
+		}
+		new := &gameserverv1alpha1.BaseStatus{
+			Phase:         gameserverv1alpha1.GameServerRunning,
+			Updates:       &gameserverv1alpha1.UpdateStatus{InstalledBuildID: "101"},
+			LastCrashTime: &metav1.Time{Time: now},
+		}
+
+		Expect(messages(notifications, old, new)).To(Equal(map[string][]string{
+			"discord": {
+				"started: Dayz games/noisy started",
+				"crashed: Dayz games/noisy crashed",
+				"updated: Dayz games/noisy was updated from build 100 to 101",
+			},
+		}))
+
+		paused := &gameserverv1alpha1.BaseStatus{Phase: gameserverv1alpha1.GameServerPaused, Updates: new.Updates, LastCrashTime: new.LastCrashTime}
+		Expect(messages(notifications, new, paused)).To(Equal(map[string][]string{
+			"slack": {"stopped: Dayz games/noisy stopped"},
+		}))
+	})
+
+	It("should notify once when the player count rises to a threshold", func() {
+		notifications := []gameserverv1alpha1.Notification{
+			subscribe("discord", []int32{10, 20, 50}, gameserverv1alpha1.NotificationPlayers),
+		}
+		players := func(old, new int32) map[string][]string {
+			return messages(notifications, &gameserverv1alpha1.BaseStatus{Players: old}, &gameserverv1alpha1.BaseStatus{Players: new})
+		}
+
+		Expect(players(9, 10)).To(Equal(map[string][]string{"discord": {"players: Dayz games/noisy reached 10 players"}}))
+		Expect(players(5, 25)).To(Equal(map[string][]string{"discord": {"players: Dayz games/noisy reached 20 players"}}))
+		Expect(players(10, 12)).To(BeEmpty())
+		Expect(players(25, 9)).To(BeEmpty())
+	})
+
+	It("should not notify of the first installed build or without a notifier", func() {
+		notifications := []gameserverv1alpha1.Notification{subscribe("discord", nil, gameserverv1alpha1.NotificationUpdated)}
+		Expect(messages(notifications, &gameserverv1alpha1.BaseStatus{},
+			&gameserverv1alpha1.BaseStatus{Updates: &gameserverv1alpha1.UpdateStatus{InstalledBuildID: "100"}})).To(BeEmpty())
+
+		Expect(func() {
+			NotifyStatusChanges(nil, owner, "Dayz", notifications, &gameserverv1alpha1.BaseStatus{}, &gameserverv1alpha1.BaseStatus{}, now)
+		}).NotTo(Panic())
+	})
+
+	It("should record the newest failed exit of the game container", func() {
+		crashedAt := metav1.NewTime(now.Add(-time.Minute))
+		pod := func(name string, deleting bool, statuses ...corev1.ContainerStatus) *corev1.Pod {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "games", Labels: map[string]string{"app": "noisy"}},
+				Status:     corev1.PodStatus{ContainerStatuses: statuses},
+			}
+			if deleting {
+				pod.DeletionTimestamp = &metav1.Time{Time: now}
+				pod.Finalizers = []string{"test"}
+			}
+			return pod
+		}
+		exited := func(container string, exitCode int32, at metav1.Time) corev1.ContainerStatus {
+			return corev1.ContainerStatus{
+				Name:                 container,
+				LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode, FinishedAt: at}},
+			}
+		}
+		c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(
+			pod("crashed", false, exited(GameContainerName, 139, crashedAt), exited("log-adm", 1, metav1.NewTime(now))),
+			pod("stopped", false, exited(GameContainerName, 0, metav1.NewTime(now))),
+			pod("terminating", true, exited(GameContainerName, 143, metav1.NewTime(now))),
+		).Build()
+
+		status := &gameserverv1alpha1.BaseStatus{}
+		Expect(UpdateCrashStatus(context.Background(), c, owner, status)).To(Succeed())
+		Expect(status.LastCrashTime).NotTo(BeNil())
+		Expect(status.LastCrashTime.Time).To(BeTemporally("==", crashedAt.Time))
+	})
+})
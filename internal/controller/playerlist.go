@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+	"github.com/templarfelix/gameserver-operator/internal/bercon"
+	"github.com/templarfelix/gameserver-operator/internal/rcon"
+)
+
+const (
+	// PlayerListsVolumeName is the volume of the ConfigMap holding the rendered player list files
+	PlayerListsVolumeName = "player-lists"
+
+	// PlayerListsMountPath is where the player list files are mounted in the game pod, the game reads
+	// them through links, see PlayerListsLinkScript
+	PlayerListsMountPath = "/player-lists"
+
+	// ConditionPlayerListsApplied is true when all PlayerLists of spec.playerLists are rendered for the game
+	ConditionPlayerListsApplied = "PlayerListsApplied"
+
+	// Reasons of the PlayerListsApplied condition
+	ReasonPlayerListsApplied     = "Applied"
+	ReasonPlayerListNotFound     = "NotFound"
+	ReasonPlayerListNotSupported = "NotSupported"
+)
+
+// ActivePlayerEntries returns the entries of list which did not expire at now
+func ActivePlayerEntries(list *gameserverv1alpha1.PlayerList, now time.Time) []gameserverv1alpha1.PlayerListEntry {
+	var entries []gameserverv1alpha1.PlayerListEntry
+	for _, entry := range list.Spec.Entries {
+		if entry.Expires == nil || entry.Expires.Time.After(now) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// NextPlayerListExpiry returns when the first active entry of lists expires, nil when none expires
+func NextPlayerListExpiry(lists []gameserverv1alpha1.PlayerList, now time.Time) *time.Time {
+	var next *time.Time
+	for i := range lists {
+		for _, entry := range ActivePlayerEntries(&lists[i], now) {
+			if entry.Expires != nil && (next == nil || entry.Expires.Time.Before(*next)) {
+				expires := entry.Expires.Time
+				next = &expires
+			}
+		}
+	}
+	return next
+}
+
+// GetPlayerLists returns the PlayerLists of refs in namespace, and the names of the missing ones
+func GetPlayerLists(ctx context.Context, c client.Client, namespace string, refs []corev1.LocalObjectReference) ([]gameserverv1alpha1.PlayerList, []string, error) {
+	var lists []gameserverv1alpha1.PlayerList
+	var missing []string
+	for _, ref := range refs {
+		list := gameserverv1alpha1.PlayerList{}
+		err := c.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, &list)
+		if apierrors.IsNotFound(err) {
+			missing = append(missing, ref.Name)
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		lists = append(lists, list)
+	}
+	return lists, missing, nil
+}
+
+// PlayerListsConfigMapName is the ConfigMap holding the player list files of owner
+func PlayerListsConfigMapName(owner client.Object) string {
+	return owner.GetName() + "-player-lists"
+}
+
+// ReconcilePlayerListsConfigMap writes files, file names to content, into the player lists ConfigMap
+// of owner. The kubelet updates the mounted files in the running pod, without a restart.
+func ReconcilePlayerListsConfigMap(ctx context.Context, c client.Client, owner client.Object, files map[string]string) error {
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: PlayerListsConfigMapName(owner), Namespace: owner.GetNamespace()}}
+	return createOrUpdateOwned(ctx, c, owner, configMap, func() {
+		configMap.Data = files
+	})
+}
+
+// DeletePlayerListsConfigMap removes the player lists ConfigMap of owner, if any
+func DeletePlayerListsConfigMap(ctx context.Context, c client.Client, owner client.Object) error {
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: PlayerListsConfigMapName(owner), Namespace: owner.GetNamespace()}}
+	return client.IgnoreNotFound(c.Delete(ctx, configMap))
+}
+
+// PlayerListsVolume returns the volume of the player lists ConfigMap of owner. It is optional, the pod
+// starts before the first reconcile writes the ConfigMap.
+func PlayerListsVolume(owner client.Object) corev1.Volume {
+	return corev1.Volume{
+		Name: PlayerListsVolumeName,
+		VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: PlayerListsConfigMapName(owner)},
+			Optional:             func(b bool) *bool { return &b }(true),
+		}},
+	}
+}
+
+// PlayerListsLinkScript links each file of the player lists volume into dir, where the game reads it.
+// Links to files no longer rendered are removed, files written by hand are only replaced by a list of
+// the same type.
+func PlayerListsLinkScript(dir string) string {
+	script := fmt.Sprintf("find '%s' -maxdepth 1 -type l -lname '%s/*' -delete\n", dir, PlayerListsMountPath)
+	script += fmt.Sprintf("for file in %s/*; do\n", PlayerListsMountPath)
+	script += "  [ -f \"$file\" ] || continue\n"
+	script += fmt.Sprintf("  ln -sfn \"$file\" '%s'/\"$(basename \"$file\")\"\n", dir)
+	script += "done\n"
+	return script
+}
+
+// SetPlayerListsCondition sets the PlayerListsApplied condition of a game server, true for ReasonPlayerListsApplied
+func SetPlayerListsCondition(owner client.Object, status *gameserverv1alpha1.BaseStatus, reason, message string) {
+	conditionStatus := metav1.ConditionFalse
+	if reason == ReasonPlayerListsApplied {
+		conditionStatus = metav1.ConditionTrue
+	}
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               ConditionPlayerListsApplied,
+		Status:             conditionStatus,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: owner.GetGeneration(),
+	})
+}
+
+// BattlEyeGUID returns the BattlEye GUID of a player from the Steam64 id, the MD5 of "BE" followed by
+// the id in little endian bytes
+func BattlEyeGUID(steamID string) (string, error) {
+	id, err := strconv.ParseUint(steamID, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid Steam64 id %q", steamID)
+	}
+	data := make([]byte, 10)
+	copy(data, "BE")
+	binary.LittleEndian.PutUint64(data[2:], id)
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// KickBattlEyePlayers kicks the connected players of a BattlEye server whose GUID is a key of reasons,
+// with its reason, and returns the names of the kicked players
+func KickBattlEyePlayers(ctx context.Context, session rcon.Session, reasons map[string]string) ([]string, error) {
+	output, err := session.Command(ctx, "players")
+	if err != nil {
+		return nil, fmt.Errorf("listing players: %w", err)
+	}
+	var kicked []string
+	for _, player := range bercon.ParsePlayers(output) {
+		reason, ok := reasons[strings.ToLower(player.GUID)]
+		if !ok {
+			continue
+		}
+		command := strings.TrimSpace(fmt.Sprintf("kick %d %s", player.Number, reason))
+		if _, err := session.Command(ctx, command); err != nil {
+			return kicked, fmt.Errorf("kicking %s: %w", player.Name, err)
+		}
+		kicked = append(kicked, player.Name)
+	}
+	return kicked, nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+)
+
+// PlayerListReconciler counts the active entries of PlayerLists. The game server controllers render
+// the lists, they watch PlayerLists themselves.
+type PlayerListReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=gameserver.templarfelix.com,resources=playerlists,verbs=get;list;watch
+//+kubebuilder:rbac:groups=gameserver.templarfelix.com,resources=playerlists/status,verbs=get;update;patch
+
+// Reconcile updates the status of a PlayerList and requeues it when its next entry expires
+func (r *PlayerListReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	list := &gameserverv1alpha1.PlayerList{}
+	if err := r.Get(ctx, req.NamespacedName, list); err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	now := time.Now()
+	status := gameserverv1alpha1.PlayerListStatus{ActiveEntries: int32(len(ActivePlayerEntries(list, now)))}
+	next := NextPlayerListExpiry([]gameserverv1alpha1.PlayerList{*list}, now)
+	if next != nil {
+		status.NextExpiry = &metav1.Time{Time: *next}
+	}
+
+	if !equality.Semantic.DeepEqual(list.Status, status) {
+		list.Status = status
+		if err := r.Status().Update(ctx, list); err != nil {
+			if errors.IsConflict(err) {
+				return reconcile.Result{Requeue: true}, nil
+			}
+			return reconcile.Result{}, err
+		}
+	}
+
+	if next != nil {
+		return reconcile.Result{RequeueAfter: next.Sub(now)}, nil
+	}
+	return reconcile.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PlayerListReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&gameserverv1alpha1.PlayerList{}).
+		Complete(r)
+}
@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+	"github.com/templarfelix/gameserver-operator/internal/bercon/berconttest"
+	"github.com/templarfelix/gameserver-operator/internal/rcon"
+)
+
+var _ = Describe("PlayerList", func() {
+	ctx := context.Background()
+	now := time.Now()
+	expires := func(d time.Duration) *metav1.Time {
+		t := metav1.NewTime(now.Add(d).Truncate(time.Second))
+		return &t
+	}
+	banList := func() *gameserverv1alpha1.PlayerList {
+		return &gameserverv1alpha1.PlayerList{
+			ObjectMeta: metav1.ObjectMeta{Name: "bans", Namespace: "default"},
+			Spec: gameserverv1alpha1.PlayerListSpec{Type: gameserverv1alpha1.PlayerListBan, Entries: []gameserverv1alpha1.PlayerListEntry{
+				{ID: "76561197960287930", Reason: "Cheating"},
+				{ID: "76561197960287931", Expires: expires(time.Hour)},
+				{ID: "76561197960287932", Expires: expires(-time.Hour)},
+				{ID: "0123456789abcdef0123456789abcdef", Expires: expires(2 * time.Hour)},
+			}},
+		}
+	}
+
+	It("should only keep the entries which did not expire", func() {
+		list := banList()
+
+		entries := ActivePlayerEntries(list, now)
+		Expect(entries).To(HaveLen(3))
+		Expect(entries[0].ID).To(Equal("76561197960287930"))
+
+		Expect(*NextPlayerListExpiry([]gameserverv1alpha1.PlayerList{*list}, now)).To(Equal(expires(time.Hour).Time))
+		Expect(NextPlayerListExpiry(nil, now)).To(BeNil())
+	})
+
+	It("should derive the BattlEye GUID from the Steam64 id", func() {
+		Expect(BattlEyeGUID("76561197960287930")).To(Equal("a357f31c8335a5263e0d816e64445b6a"))
+
+		_, err := BattlEyeGUID("0123456789abcdef0123456789abcdef")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should link the rendered files into the game directory", func() {
+		script := PlayerListsLinkScript("/data/serverfiles")
+		Expect(script).To(ContainSubstring("find '/data/serverfiles' -maxdepth 1 -type l -lname '/player-lists/*' -delete"))
+		Expect(script).To(ContainSubstring(`ln -sfn "$file" '/data/serverfiles'/"$(basename "$file")"`))
+	})
+
+	It("should kick the banned players which are connected", func() {
+		server, err := berconttest.NewServer("secret", func(command string) string {
+			if command == "players" {
+				return "0   10.0.0.5:2304   32   0123456789ABCDEF0123456789ABCDEF(OK) Survivor\n" +
+					"1   10.0.0.6:2304   40   fedcba9876543210fedcba9876543210(OK) Friendly\n(2 players in total)"
+			}
+			return ""
+		})
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(server.Close)
+
+		session, err := rcon.Dial(ctx, rcon.ProtocolBattlEye, server.Addr(), "secret", time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		defer session.Close()
+
+		kicked, err := KickBattlEyePlayers(ctx, session, map[string]string{"0123456789abcdef0123456789abcdef": "Cheating"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kicked).To(Equal([]string{"Survivor"}))
+		Expect(server.Commands()).To(Equal([]string{"players", "kick 0 Cheating"}))
+	})
+
+	Describe("PlayerListReconciler", func() {
+		It("should count the active entries and requeue at the next expiry", func() {
+			scheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(gameserverv1alpha1.AddToScheme(scheme)).To(Succeed())
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(banList()).
+				WithStatusSubresource(&gameserverv1alpha1.PlayerList{}).
+				Build()
+			reconciler := &PlayerListReconciler{Client: fakeClient, Scheme: scheme}
+
+			key := types.NamespacedName{Name: "bans", Namespace: "default"}
+			result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically("~", time.Hour, time.Minute))
+
+			updated := &gameserverv1alpha1.PlayerList{}
+			Expect(fakeClient.Get(ctx, key, updated)).To(Succeed())
+			Expect(updated.Status.ActiveEntries).To(Equal(int32(3)))
+			Expect(updated.Status.NextExpiry.Time).To(BeTemporally("==", expires(time.Hour).Time))
+		})
+	})
+
+	It("should mount the optional player lists ConfigMap", func() {
+		owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "dayz", Namespace: "default"}}
+
+		volume := PlayerListsVolume(owner)
+		Expect(volume.ConfigMap.Name).To(Equal("dayz-player-lists"))
+		Expect(*volume.ConfigMap.Optional).To(BeTrue())
+	})
+})
@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+	"github.com/templarfelix/gameserver-operator/internal/a2s"
+)
+
+const (
+	// ConditionReady is true while the game server answers on its query port
+	ConditionReady = "Ready"
+
+	// ReasonQueryResponded means the query port answered
+	ReasonQueryResponded = "QueryResponded"
+	// ReasonQueryFailed means the pod is running but the query port did not answer
+	ReasonQueryFailed = "QueryFailed"
+	// ReasonPodNotRunning means there is no running game pod to query
+	ReasonPodNotRunning = "PodNotRunning"
+	// ReasonPaused means the game server is scaled to zero by spec.paused, see ReasonIdle for idle servers
+	ReasonPaused = "Paused"
+
+	// DefaultQueryPeriod is the interval between two queries when spec.query.periodSeconds is unset
+	DefaultQueryPeriod = 30 * time.Second
+)
+
+// ServerQuerier reads the live state of a game server from its query port
+type ServerQuerier interface {
+	QueryInfo(ctx context.Context, addr string) (*a2s.Info, error)
+}
+
+// QueryPeriod returns how often the game server should be queried
+func QueryPeriod(query *gameserverv1alpha1.Query) time.Duration {
+	if query.PeriodSeconds > 0 {
+		return time.Duration(query.PeriodSeconds) * time.Second
+	}
+	return DefaultQueryPeriod
+}
+
+// QueryPort returns the configured query port or the game default
+func QueryPort(query *gameserverv1alpha1.Query, defaultPort int32) int32 {
+	if query.Port > 0 {
+		return query.Port
+	}
+	return defaultPort
+}
+
+// UpdateQueryStatus queries the running game pod of owner on port and records the Ready condition,
+// player counts, map and version in status. Only listing the pods can fail, an unreachable server
+// is reported through the Ready condition.
+func UpdateQueryStatus(ctx context.Context, c client.Client, querier ServerQuerier, owner client.Object, port int32, status *gameserverv1alpha1.BaseStatus) error {
+	pod, err := findRunningPod(ctx, c, owner)
+	if err != nil {
+		return err
+	}
+
+	if pod == nil {
+		status.Players = 0
+		setReadyCondition(owner, status, metav1.ConditionFalse, ReasonPodNotRunning, "No running game server pod")
+		return nil
+	}
+
+	addr := net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(int(port)))
+	start := time.Now()
+	info, err := querier.QueryInfo(ctx, addr)
+	if err != nil {
+		status.Players = 0
+		setReadyCondition(owner, status, metav1.ConditionFalse, ReasonQueryFailed, queryFailedMessage(port, err))
+		return nil
+	}
+
+	if gvk, err := apiutil.GVKForObject(owner, c.Scheme()); err == nil {
+		QueryLatency.WithLabelValues(gvk.Kind).Observe(time.Since(start).Seconds())
+	}
+
+	status.Players = int32(info.Players)
+	status.MaxPlayers = int32(info.MaxPlayers)
+	status.Map = info.Map
+	status.Version = info.Version
+	setReadyCondition(owner, status, metav1.ConditionTrue, ReasonQueryResponded, fmt.Sprintf("Server %q answered on query port %d", info.Name, port))
+	return nil
+}
+
+// SetPausedStatus records a paused game server in status without querying it
+func SetPausedStatus(owner client.Object, status *gameserverv1alpha1.BaseStatus) {
+	status.Players = 0
+	if status.Sleeping {
+		setReadyCondition(owner, status, metav1.ConditionFalse, ReasonIdle, "Game server is paused after running without players")
+	} else {
+		setReadyCondition(owner, status, metav1.ConditionFalse, ReasonPaused, "Game server is paused")
+	}
+	status.Phase = gameserverv1alpha1.GameServerPaused
+}
+
+// GameServerReplicas returns the replicas of the game Deployment, zero while paused
+func GameServerReplicas(base *gameserverv1alpha1.Base, status *gameserverv1alpha1.BaseStatus) *int32 {
+	replicas := int32(1)
+	if IsPaused(base, status) {
+		replicas = 0
+	}
+	return &replicas
+}
+
+// queryFailedMessage describes a failed query without the addresses of the error, which change with
+// every query and would rewrite the Ready condition each time
+func queryFailedMessage(port int32, err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, a2s.ErrMalformedResponse):
+		return fmt.Sprintf("Query port %d returned a malformed response", port)
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return fmt.Sprintf("Query port %d timed out", port)
+	default:
+		return fmt.Sprintf("Query port %d did not answer", port)
+	}
+}
+
+// findRunningPod returns a running pod of the owner's Deployment, or nil when there is none
+func findRunningPod(ctx context.Context, c client.Client, owner client.Object) (*corev1.Pod, error) {
+	pods := &corev1.PodList{}
+	if err := c.List(ctx, pods, client.InNamespace(owner.GetNamespace()), client.MatchingLabels{"app": owner.GetName()}); err != nil {
+		return nil, err
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp == nil && pod.Status.Phase == corev1.PodRunning && pod.Status.PodIP != "" {
+			return pod, nil
+		}
+	}
+	return nil, nil
+}
+
+// setReadyCondition records the Ready condition and the matching phase
+func setReadyCondition(owner client.Object, status *gameserverv1alpha1.BaseStatus, conditionStatus metav1.ConditionStatus, reason, message string) {
+	status.Phase = gameserverv1alpha1.GameServerPending
+	if conditionStatus == metav1.ConditionTrue {
+		status.Phase = gameserverv1alpha1.GameServerRunning
+	}
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               ConditionReady,
+		Status:             conditionStatus,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: owner.GetGeneration(),
+	})
+}
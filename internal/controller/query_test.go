@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"syscall"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+	"github.com/templarfelix/gameserver-operator/internal/a2s"
+	"github.com/templarfelix/gameserver-operator/internal/a2s/a2stest"
+)
+
+var _ = Describe("UpdateQueryStatus", func() {
+	var (
+		ctx     context.Context
+		owner   *corev1.ConfigMap
+		server  *a2stest.Server
+		querier *a2s.Client
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		owner = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-server", Namespace: "default", UID: "test-uid", Generation: 2},
+		}
+		querier = &a2s.Client{Timeout: 200 * time.Millisecond}
+
+		var err error
+		server, err = a2stest.NewServer(a2s.Info{
+			Name:       "Test DayZ Server",
+			Map:        "chernarusplus",
+			Players:    7,
+			MaxPlayers: 60,
+			Version:    "1.26.159040",
+		}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(server.Close)
+	})
+
+	pod := func(phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-server-abc", Namespace: "default", Labels: map[string]string{"app": "test-server"}},
+			Status:     corev1.PodStatus{Phase: phase, PodIP: "127.0.0.1"},
+		}
+	}
+
+	It("should mark the server ready and record players, map and version", func() {
+		fakeClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(pod(corev1.PodRunning)).Build()
+		status := &gameserverv1alpha1.BaseStatus{}
+
+		Expect(UpdateQueryStatus(ctx, fakeClient, querier, owner, server.Port(), status)).To(Succeed())
+
+		ready := meta.FindStatusCondition(status.Conditions, ConditionReady)
+		Expect(ready).NotTo(BeNil())
+		Expect(ready.Status).To(Equal(metav1.ConditionTrue))
+		Expect(ready.Reason).To(Equal(ReasonQueryResponded))
+		Expect(ready.ObservedGeneration).To(Equal(int64(2)))
+		Expect(status.Players).To(Equal(int32(7)))
+		Expect(status.MaxPlayers).To(Equal(int32(60)))
+		Expect(status.Map).To(Equal("chernarusplus"))
+		Expect(status.Version).To(Equal("1.26.159040"))
+		Expect(status.Phase).To(Equal(gameserverv1alpha1.GameServerRunning))
+	})
+
+	It("should report PodNotRunning when the game pod is not running", func() {
+		fakeClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(pod(corev1.PodPending)).Build()
+		status := &gameserverv1alpha1.BaseStatus{Players: 3}
+
+		Expect(UpdateQueryStatus(ctx, fakeClient, querier, owner, server.Port(), status)).To(Succeed())
+
+		Expect(meta.IsStatusConditionFalse(status.Conditions, ConditionReady)).To(BeTrue())
+		Expect(meta.FindStatusCondition(status.Conditions, ConditionReady).Reason).To(Equal(ReasonPodNotRunning))
+		Expect(status.Players).To(BeZero())
+		Expect(status.Phase).To(Equal(gameserverv1alpha1.GameServerPending))
+	})
+
+	It("should report paused servers without querying them", func() {
+		status := &gameserverv1alpha1.BaseStatus{Players: 3, Map: "chernarusplus"}
+
+		SetPausedStatus(owner, status)
+
+		Expect(status.Phase).To(Equal(gameserverv1alpha1.GameServerPaused))
+		Expect(meta.FindStatusCondition(status.Conditions, ConditionReady).Reason).To(Equal(ReasonPaused))
+		Expect(status.Players).To(BeZero())
+		Expect(status.Map).To(Equal("chernarusplus"))
+	})
+
+	It("should report QueryFailed when the query port does not answer", func() {
+		fakeClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(pod(corev1.PodRunning)).Build()
+		status := &gameserverv1alpha1.BaseStatus{}
+		Expect(UpdateQueryStatus(ctx, fakeClient, querier, owner, server.Port(), status)).To(Succeed())
+		Expect(meta.IsStatusConditionTrue(status.Conditions, ConditionReady)).To(BeTrue())
+
+		server.SetSilent(true)
+		Expect(UpdateQueryStatus(ctx, fakeClient, querier, owner, server.Port(), status)).To(Succeed())
+
+		ready := meta.FindStatusCondition(status.Conditions, ConditionReady)
+		Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+		Expect(ready.Reason).To(Equal(ReasonQueryFailed))
+		Expect(ready.Message).To(Equal(fmt.Sprintf("Query port %d timed out", server.Port())))
+		Expect(status.Players).To(BeZero())
+		Expect(status.Map).To(Equal("chernarusplus"))
+	})
+})
+
+var _ = Describe("Query settings", func() {
+	It("should describe failed queries without addresses", func() {
+		malformed := fmt.Errorf("%w: unexpected info response type", a2s.ErrMalformedResponse)
+		Expect(queryFailedMessage(2303, malformed)).To(Equal("Query port 2303 returned a malformed response"))
+
+		refused := &net.OpError{Op: "read", Net: "udp", Err: syscall.ECONNREFUSED,
+			Source: &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 51234}}
+		Expect(queryFailedMessage(2303, refused)).To(Equal("Query port 2303 did not answer"))
+	})
+
+	It("should fall back to the game default port and period", func() {
+		Expect(QueryPort(&gameserverv1alpha1.Query{}, 27016)).To(Equal(int32(27016)))
+		Expect(QueryPort(&gameserverv1alpha1.Query{Port: 27020}, 27016)).To(Equal(int32(27020)))
+		Expect(QueryPeriod(&gameserverv1alpha1.Query{})).To(Equal(DefaultQueryPeriod))
+		Expect(QueryPeriod(&gameserverv1alpha1.Query{PeriodSeconds: 10})).To(Equal(10 * time.Second))
+	})
+
+	It("should scale paused servers to zero", func() {
+		status := &gameserverv1alpha1.BaseStatus{}
+		paused := true
+		Expect(*GameServerReplicas(&gameserverv1alpha1.Base{}, status)).To(Equal(int32(1)))
+		Expect(*GameServerReplicas(&gameserverv1alpha1.Base{Paused: &paused}, status)).To(BeZero())
+		Expect(*GameServerReplicas(&gameserverv1alpha1.Base{}, &gameserverv1alpha1.BaseStatus{Sleeping: true})).To(BeZero())
+	})
+})
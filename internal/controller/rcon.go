@@ -0,0 +1,215 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+	"github.com/templarfelix/gameserver-operator/internal/bercon"
+	"github.com/templarfelix/gameserver-operator/internal/rcon"
+)
+
+const (
+	// DefaultBattlEyeRConPort is the BattlEye RCon port configured for DayZ servers when spec.rcon.port is unset
+	DefaultBattlEyeRConPort int32 = 2306
+
+	// RConPasswordEnv is the environment variable the RCon password is exposed in to config setup containers
+	RConPasswordEnv = "RCON_PASSWORD"
+)
+
+var (
+	// ErrRConNotConfigured is returned when the RCon password Secret reference is missing or incomplete
+	ErrRConNotConfigured = errors.New("RCon is not configured")
+	// ErrNoRunningPod is returned when the game server has no running pod to connect to
+	ErrNoRunningPod = errors.New("no running game server pod")
+	// ErrCommandNotSupported is returned for commands the game has no console command for
+	ErrCommandNotSupported = errors.New("command is not supported by this game")
+)
+
+// RConProfile describes how the servers of a game kind are administered over RCON
+type RConProfile struct {
+	// Protocol spoken on the RCON port
+	Protocol rcon.Protocol
+
+	// DefaultPort is used when spec.rcon.port is unset
+	DefaultPort int32
+
+	// Render returns the console command for a GameServerCommand, player is the resolved player
+	Render func(spec *gameserverv1alpha1.GameServerCommandSpec, player string) (string, error)
+
+	// ResolvePlayer maps a player name to the identifier the console expects, names are used as-is when nil
+	ResolvePlayer func(ctx context.Context, session rcon.Session, name string) (string, error)
+}
+
+// BattlEyeProfile administers DayZ and other BattlEye protected servers
+var BattlEyeProfile = RConProfile{
+	Protocol:    rcon.ProtocolBattlEye,
+	DefaultPort: DefaultBattlEyeRConPort,
+	Render: func(spec *gameserverv1alpha1.GameServerCommandSpec, player string) (string, error) {
+		if spec.Command == gameserverv1alpha1.CommandSave {
+			return "", ErrCommandNotSupported
+		}
+		return BuildBattlEyeCommand(spec, player), nil
+	},
+	ResolvePlayer: resolveBattlEyePlayer,
+}
+
+// SourceCommands maps command types to console commands of a Source RCON game. The placeholders
+// {player}, {message}, {reason} and {minutes} are replaced with the GameServerCommand fields.
+type SourceCommands map[gameserverv1alpha1.GameServerCommandType]string
+
+// DefaultSourceCommands are the console commands of Source dedicated servers (srcds)
+var DefaultSourceCommands = SourceCommands{
+	gameserverv1alpha1.CommandSay:      "say {message}",
+	gameserverv1alpha1.CommandKick:     "kick {player}",
+	gameserverv1alpha1.CommandBan:      "banid {minutes} {player} kick",
+	gameserverv1alpha1.CommandShutdown: "quit",
+}
+
+// NewSourceProfile returns the profile of a Source RCON game listening on defaultPort
+func NewSourceProfile(defaultPort int32, commands SourceCommands) RConProfile {
+	return RConProfile{
+		Protocol:    rcon.ProtocolSource,
+		DefaultPort: defaultPort,
+		Render: func(spec *gameserverv1alpha1.GameServerCommandSpec, player string) (string, error) {
+			template, ok := commands[spec.Command]
+			if !ok {
+				return "", ErrCommandNotSupported
+			}
+			return strings.TrimSpace(strings.NewReplacer(
+				"{player}", player,
+				"{message}", spec.Message,
+				"{reason}", spec.Reason,
+				"{minutes}", strconv.Itoa(int(spec.BanMinutes)),
+			).Replace(template)), nil
+		},
+	}
+}
+
+// RenderCommand returns the console command for spec, raw commands are sent as-is
+func (p RConProfile) RenderCommand(spec *gameserverv1alpha1.GameServerCommandSpec, player string) (string, error) {
+	if spec.Command == gameserverv1alpha1.CommandRaw {
+		return spec.Raw, nil
+	}
+	command, err := p.Render(spec, player)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", spec.Command, err)
+	}
+	return command, nil
+}
+
+// RConPort returns the configured RCon port or the game default
+func RConPort(rcon *gameserverv1alpha1.RCon, defaultPort int32) int32 {
+	if rcon.Port > 0 {
+		return rcon.Port
+	}
+	return defaultPort
+}
+
+// RConPasswordEnvVar returns the environment variable exposing the RCon password from its Secret
+func RConPasswordEnvVar(rcon *gameserverv1alpha1.RCon) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: RConPasswordEnv,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: rcon.PasswordSecretRef,
+		},
+	}
+}
+
+// GetRConPassword reads the RCon password from the Secret referenced by rcon
+func GetRConPassword(ctx context.Context, c client.Client, namespace string, rcon *gameserverv1alpha1.RCon) (string, error) {
+	if rcon.PasswordSecretRef == nil {
+		return "", fmt.Errorf("%w: rcon.passwordSecretRef is not set", ErrRConNotConfigured)
+	}
+
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Name: rcon.PasswordSecretRef.Name, Namespace: namespace}, secret); err != nil {
+		return "", err
+	}
+	password, ok := secret.Data[rcon.PasswordSecretRef.Key]
+	if !ok {
+		return "", fmt.Errorf("%w: secret %s has no key %q", ErrRConNotConfigured, rcon.PasswordSecretRef.Name, rcon.PasswordSecretRef.Key)
+	}
+	return string(password), nil
+}
+
+// OpenRConSession logs in to the RCON console of the running pod of server. It returns
+// ErrNoRunningPod when there is no pod to connect to yet.
+func OpenRConSession(ctx context.Context, c client.Client, server client.Object, base *gameserverv1alpha1.Base, profile RConProfile, timeout time.Duration) (rcon.Session, error) {
+	password, err := GetRConPassword(ctx, c, server.GetNamespace(), &base.RCon)
+	if err != nil {
+		return nil, err
+	}
+
+	pod, err := findRunningPod(ctx, c, server)
+	if err != nil {
+		return nil, err
+	}
+	if pod == nil {
+		return nil, ErrNoRunningPod
+	}
+
+	addr := net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(int(RConPort(&base.RCon, profile.DefaultPort))))
+	session, err := rcon.Dial(ctx, profile.Protocol, addr, password, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("RCON login to %s: %w", addr, err)
+	}
+	return session, nil
+}
+
+// BuildBattlEyeCommand renders the BattlEye RCon command line for spec, player is the resolved player number
+func BuildBattlEyeCommand(spec *gameserverv1alpha1.GameServerCommandSpec, player string) string {
+	withReason := func(command string) string {
+		if spec.Reason == "" {
+			return command
+		}
+		return command + " " + spec.Reason
+	}
+
+	switch spec.Command {
+	case gameserverv1alpha1.CommandSay:
+		target := "-1"
+		if player != "" {
+			target = player
+		}
+		return fmt.Sprintf("say %s %s", target, spec.Message)
+	case gameserverv1alpha1.CommandKick:
+		return withReason("kick " + player)
+	case gameserverv1alpha1.CommandBan:
+		return withReason(fmt.Sprintf("ban %s %d", player, spec.BanMinutes))
+	case gameserverv1alpha1.CommandLock:
+		return "#lock"
+	case gameserverv1alpha1.CommandUnlock:
+		return "#unlock"
+	case gameserverv1alpha1.CommandShutdown:
+		return "#shutdown"
+	}
+	return string(spec.Command)
+}
+
+// resolveBattlEyePlayer looks up the player number of name in the players list, numbers are used as-is
+func resolveBattlEyePlayer(ctx context.Context, session rcon.Session, name string) (string, error) {
+	if _, err := strconv.Atoi(name); err == nil {
+		return name, nil
+	}
+
+	output, err := session.Command(ctx, "players")
+	if err != nil {
+		return "", fmt.Errorf("listing players: %w", err)
+	}
+	for _, player := range bercon.ParsePlayers(output) {
+		if player.Name == name {
+			return strconv.Itoa(player.Number), nil
+		}
+	}
+	return "", fmt.Errorf("player %q is not connected", name)
+}
@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+	"github.com/templarfelix/gameserver-operator/internal/rcon/rcontest"
+)
+
+var _ = Describe("RCON profiles", func() {
+	sourceProfile := NewSourceProfile(27015, SourceCommands{
+		gameserverv1alpha1.CommandSay:  "servermsg \"{message}\"",
+		gameserverv1alpha1.CommandKick: "kickuser \"{player}\" -r \"{reason}\"",
+		gameserverv1alpha1.CommandSave: "save",
+	})
+
+	It("should render Source commands from templates", func() {
+		Expect(sourceProfile.RenderCommand(&gameserverv1alpha1.GameServerCommandSpec{Command: gameserverv1alpha1.CommandSay, Message: "Restart soon"}, "")).
+			To(Equal(`servermsg "Restart soon"`))
+		Expect(sourceProfile.RenderCommand(&gameserverv1alpha1.GameServerCommandSpec{Command: gameserverv1alpha1.CommandKick, Reason: "AFK"}, "Survivor")).
+			To(Equal(`kickuser "Survivor" -r "AFK"`))
+		Expect(sourceProfile.RenderCommand(&gameserverv1alpha1.GameServerCommandSpec{Command: gameserverv1alpha1.CommandSave}, "")).To(Equal("save"))
+	})
+
+	It("should reject commands a game has no console command for", func() {
+		_, err := sourceProfile.RenderCommand(&gameserverv1alpha1.GameServerCommandSpec{Command: gameserverv1alpha1.CommandLock}, "")
+		Expect(err).To(MatchError(ErrCommandNotSupported))
+
+		_, err = BattlEyeProfile.RenderCommand(&gameserverv1alpha1.GameServerCommandSpec{Command: gameserverv1alpha1.CommandSave}, "")
+		Expect(err).To(MatchError(ErrCommandNotSupported))
+	})
+
+	It("should send raw commands as-is for every protocol", func() {
+		spec := &gameserverv1alpha1.GameServerCommandSpec{Command: gameserverv1alpha1.CommandRaw, Raw: "#exec ban 1"}
+		Expect(BattlEyeProfile.RenderCommand(spec, "")).To(Equal("#exec ban 1"))
+		Expect(sourceProfile.RenderCommand(spec, "")).To(Equal("#exec ban 1"))
+	})
+
+	It("should use the srcds defaults", func() {
+		profile := NewSourceProfile(27015, DefaultSourceCommands)
+		Expect(profile.RenderCommand(&gameserverv1alpha1.GameServerCommandSpec{Command: gameserverv1alpha1.CommandBan, BanMinutes: 30}, "#12")).
+			To(Equal("banid 30 #12 kick"))
+	})
+
+	Describe("OpenRConSession", func() {
+		var (
+			ctx    context.Context
+			server *rcontest.Server
+			owner  *corev1.ConfigMap
+			base   *gameserverv1alpha1.Base
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			var err error
+			server, err = rcontest.NewServer("rcon-secret", func(command string) string { return "Saved" })
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(server.Close)
+
+			owner = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "source-server", Namespace: "default"}}
+			base = &gameserverv1alpha1.Base{RCon: gameserverv1alpha1.RCon{
+				Port: server.Port(),
+				PasswordSecretRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "source-rcon"},
+					Key:                  "password",
+				},
+			}}
+		})
+
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "source-rcon", Namespace: "default"},
+			Data:       map[string][]byte{"password": []byte("rcon-secret")},
+		}
+
+		It("should connect to the running pod with the game protocol", func() {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "source-server-abc", Namespace: "default", Labels: map[string]string{"app": "source-server"}},
+				Status:     corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "127.0.0.1"},
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(secret, pod).Build()
+
+			session, err := OpenRConSession(ctx, fakeClient, owner, base, sourceProfile, time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			defer session.Close()
+
+			Expect(session.Command(ctx, "save")).To(Equal("Saved"))
+			Expect(server.Commands()).To(Equal([]string{"save"}))
+		})
+
+		It("should report a missing pod", func() {
+			fakeClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(secret).Build()
+
+			_, err := OpenRConSession(ctx, fakeClient, owner, base, sourceProfile, time.Second)
+			Expect(err).To(MatchError(ErrNoRunningPod))
+		})
+	})
+})
@@ -0,0 +1,176 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+)
+
+const (
+	// RestartedAtAnnotation on the pod template records the last scheduled restart, bumping it replaces the game pod
+	RestartedAtAnnotation = "gameserver.templarfelix.com/restartedAt"
+
+	// ConditionUpdatePending is true while a change replacing the game pod waits for a maintenance window
+	ConditionUpdatePending = "UpdatePending"
+
+	// ReasonWaitingForMaintenanceWindow means the change is rolled out in the next maintenance window
+	ReasonWaitingForMaintenanceWindow = "WaitingForMaintenanceWindow"
+
+	// maxMissedRestarts bounds the search for the latest restart missed while the operator was down
+	maxMissedRestarts = 10000
+)
+
+// RolloutPlan is when the game pod of a server may be replaced, as decided by its schedule
+type RolloutPlan struct {
+	// RestartedAt is the value of the RestartedAtAnnotation for the pod template, it changes when a restart is due
+	RestartedAt string
+
+	// RestartDue is true when a scheduled restart is due
+	RestartDue bool
+
+	// NextRestart is the next scheduled restart, zero without restart schedule
+	NextRestart time.Time
+
+	// InWindow is true when changes may be rolled out now
+	InWindow bool
+
+	// NextWindow is the start of the next maintenance window, zero while in a window or without windows
+	NextWindow time.Time
+
+	// Allocated is true while a GameServerAllocation holds the server, changes and scheduled restarts
+	// wait until it is released
+	Allocated bool
+}
+
+// ParseCron parses a five-field cron expression evaluated in timeZone, UTC when empty
+func ParseCron(expression, timeZone string) (cron.Schedule, error) {
+	if timeZone == "" {
+		timeZone = "UTC"
+	}
+	if _, err := time.LoadLocation(timeZone); err != nil {
+		return nil, fmt.Errorf("unknown time zone %q", timeZone)
+	}
+	return cron.ParseStandard("CRON_TZ=" + timeZone + " " + expression)
+}
+
+// PlanRollout decides whether a scheduled restart is due and whether changes may be rolled out at
+// now. scheduled is the restart recorded in status.nextRestartTime, it stays there until the pod
+// template of found carries its RestartedAtAnnotation. Restarts missed while the operator was down
+// are done once.
+func PlanRollout(schedule *gameserverv1alpha1.Schedule, found *appsv1.Deployment, scheduled *metav1.Time, now time.Time) (RolloutPlan, error) {
+	plan := RolloutPlan{InWindow: true}
+	if found != nil {
+		plan.RestartedAt = found.Spec.Template.Annotations[RestartedAtAnnotation]
+	}
+
+	if schedule.Restarts != "" {
+		restarts, err := ParseCron(schedule.Restarts, schedule.TimeZone)
+		if err != nil {
+			return plan, fmt.Errorf("schedule.restarts: %w", err)
+		}
+		plan.NextRestart = restarts.Next(now)
+
+		if scheduled != nil && !scheduled.After(now) {
+			due := scheduled.Time
+			for i := 0; i < maxMissedRestarts; i++ {
+				following := restarts.Next(due)
+				if following.After(now) {
+					break
+				}
+				due = following
+			}
+
+			lastRestart, err := time.Parse(time.RFC3339, plan.RestartedAt)
+			if err != nil || lastRestart.Before(due) {
+				plan.RestartDue = true
+				plan.RestartedAt = due.UTC().Format(time.RFC3339)
+				plan.NextRestart = due
+			}
+		}
+	}
+
+	if len(schedule.MaintenanceWindows) > 0 {
+		plan.InWindow = false
+		for i, window := range schedule.MaintenanceWindows {
+			start, err := ParseCron(window.Start, schedule.TimeZone)
+			if err != nil {
+				return plan, fmt.Errorf("schedule.maintenanceWindows[%d].start: %w", i, err)
+			}
+
+			// The window is open when it started within the last duration
+			duration := time.Duration(window.DurationMinutes) * time.Minute
+			opened := start.Next(now.Add(-duration))
+			if !opened.After(now) {
+				plan.InWindow = true
+				plan.NextWindow = time.Time{}
+				break
+			}
+			if plan.NextWindow.IsZero() || opened.Before(plan.NextWindow) {
+				plan.NextWindow = opened
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+// SetRestartedAt writes the restart annotation of plan to the pod template of deployment
+func SetRestartedAt(deployment *appsv1.Deployment, plan RolloutPlan) {
+	setTemplateAnnotation(deployment, RestartedAtAnnotation, plan.RestartedAt)
+}
+
+// Wait returns how long until the next scheduled restart or maintenance window, zero when none is scheduled
+func (p RolloutPlan) Wait(now time.Time) time.Duration {
+	var wait time.Duration
+	for _, next := range []time.Time{p.NextRestart, p.NextWindow} {
+		if until := next.Sub(now); !next.IsZero() && until > 0 && (wait == 0 || until < wait) {
+			wait = until
+		}
+	}
+	return wait
+}
+
+// DeferRollout reports whether a change replacing the game pod has to wait for the next maintenance
+// window, scheduled restarts roll out pending changes as well. Allocated servers wait until they are
+// released. It records the next restart and the UpdatePending condition in status.
+func DeferRollout(plan RolloutPlan, podTemplateChanged bool, generation int64, status *gameserverv1alpha1.BaseStatus) bool {
+	status.NextRestartTime = nil
+	if !plan.NextRestart.IsZero() {
+		next := metav1.NewTime(plan.NextRestart)
+		status.NextRestartTime = &next
+	}
+
+	if podTemplateChanged && plan.Allocated {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               ConditionUpdatePending,
+			Status:             metav1.ConditionTrue,
+			Reason:             ReasonAllocated,
+			Message:            "Changes replacing the game pod are rolled out once the allocation of the server is released",
+			ObservedGeneration: generation,
+		})
+		return true
+	}
+	if !podTemplateChanged || plan.RestartDue || plan.InWindow {
+		meta.RemoveStatusCondition(&status.Conditions, ConditionUpdatePending)
+		return false
+	}
+
+	rollout := plan.NextWindow
+	if !plan.NextRestart.IsZero() && (rollout.IsZero() || plan.NextRestart.Before(rollout)) {
+		rollout = plan.NextRestart
+	}
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               ConditionUpdatePending,
+		Status:             metav1.ConditionTrue,
+		Reason:             ReasonWaitingForMaintenanceWindow,
+		Message:            fmt.Sprintf("Changes replacing the game pod are rolled out at %s", rollout.UTC().Format(time.RFC3339)),
+		ObservedGeneration: generation,
+	})
+	return true
+}
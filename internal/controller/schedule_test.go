@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+)
+
+var _ = Describe("Schedule", func() {
+	at := func(value string) time.Time {
+		t, err := time.Parse(time.RFC3339, value)
+		Expect(err).NotTo(HaveOccurred())
+		return t
+	}
+	restartedAt := func(value string) *appsv1.Deployment {
+		deployment := &appsv1.Deployment{}
+		deployment.Spec.Template.Annotations = map[string]string{RestartedAtAnnotation: value}
+		return deployment
+	}
+	scheduled := func(value string) *metav1.Time {
+		t := metav1.NewTime(at(value))
+		return &t
+	}
+
+	daily := &gameserverv1alpha1.Schedule{Restarts: "0 6 * * *"}
+
+	It("should roll out right away without schedule", func() {
+		plan, err := PlanRollout(&gameserverv1alpha1.Schedule{}, nil, nil, at("2024-06-01T04:00:00Z"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plan.InWindow).To(BeTrue())
+		Expect(plan.RestartDue).To(BeFalse())
+		Expect(plan.Wait(at("2024-06-01T04:00:00Z"))).To(BeZero())
+	})
+
+	It("should plan the next restart", func() {
+		now := at("2024-06-01T04:00:00Z")
+		plan, err := PlanRollout(daily, restartedAt("2024-05-31T06:00:00Z"), nil, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plan.RestartDue).To(BeFalse())
+		Expect(plan.RestartedAt).To(Equal("2024-05-31T06:00:00Z"))
+		Expect(plan.NextRestart).To(BeTemporally("==", at("2024-06-01T06:00:00Z")))
+		Expect(plan.Wait(now)).To(Equal(2 * time.Hour))
+	})
+
+	It("should bump the restart annotation once the scheduled restart is due", func() {
+		plan, err := PlanRollout(daily, &appsv1.Deployment{}, scheduled("2024-06-01T06:00:00Z"), at("2024-06-01T06:00:30Z"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plan.RestartDue).To(BeTrue())
+		Expect(plan.RestartedAt).To(Equal("2024-06-01T06:00:00Z"))
+		Expect(plan.NextRestart).To(BeTemporally("==", at("2024-06-01T06:00:00Z")))
+
+		deployment := &appsv1.Deployment{}
+		SetRestartedAt(deployment, plan)
+		Expect(deployment.Spec.Template.Annotations).To(HaveKeyWithValue(RestartedAtAnnotation, "2024-06-01T06:00:00Z"))
+	})
+
+	It("should plan the following restart once the pod was restarted", func() {
+		plan, err := PlanRollout(daily, restartedAt("2024-06-01T06:00:00Z"), scheduled("2024-06-01T06:00:00Z"), at("2024-06-01T06:05:00Z"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plan.RestartDue).To(BeFalse())
+		Expect(plan.NextRestart).To(BeTemporally("==", at("2024-06-02T06:00:00Z")))
+	})
+
+	It("should restart once for restarts missed while the operator was down", func() {
+		plan, err := PlanRollout(daily, restartedAt("2024-05-31T06:00:00Z"), scheduled("2024-06-01T06:00:00Z"), at("2024-06-03T07:00:00Z"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plan.RestartDue).To(BeTrue())
+		Expect(plan.RestartedAt).To(Equal("2024-06-03T06:00:00Z"))
+	})
+
+	It("should hold restarts and changes of an allocated server until it is released", func() {
+		plan, err := PlanRollout(daily, &appsv1.Deployment{}, scheduled("2024-06-01T06:00:00Z"), at("2024-06-01T06:00:30Z"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plan.RestartDue).To(BeTrue())
+		plan.Allocated = true
+
+		status := &gameserverv1alpha1.BaseStatus{}
+		Expect(DeferRollout(plan, true, 2, status)).To(BeTrue())
+		condition := meta.FindStatusCondition(status.Conditions, ConditionUpdatePending)
+		Expect(condition.Reason).To(Equal(ReasonAllocated))
+		Expect(status.NextRestartTime.Time).To(BeTemporally("==", at("2024-06-01T06:00:00Z")))
+
+		plan.Allocated = false
+		Expect(DeferRollout(plan, true, 2, status)).To(BeFalse())
+		Expect(meta.FindStatusCondition(status.Conditions, ConditionUpdatePending)).To(BeNil())
+	})
+
+	It("should evaluate the schedule in its time zone", func() {
+		schedule := &gameserverv1alpha1.Schedule{Restarts: "0 6 * * *", TimeZone: "Europe/Berlin"}
+		plan, err := PlanRollout(schedule, nil, nil, at("2024-06-01T00:00:00Z"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plan.NextRestart).To(BeTemporally("==", at("2024-06-01T04:00:00Z")))
+	})
+
+	Describe("maintenance windows", func() {
+		schedule := &gameserverv1alpha1.Schedule{
+			MaintenanceWindows: []gameserverv1alpha1.MaintenanceWindow{
+				{Start: "0 4 * * *", DurationMinutes: 60},
+				{Start: "0 12 * * 6", DurationMinutes: 30},
+			},
+		}
+
+		It("should roll out inside a window", func() {
+			plan, err := PlanRollout(schedule, nil, nil, at("2024-06-01T04:30:00Z"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(plan.InWindow).To(BeTrue())
+		})
+
+		It("should defer changes to the next window", func() {
+			now := at("2024-06-01T06:00:00Z")
+			plan, err := PlanRollout(schedule, nil, nil, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(plan.InWindow).To(BeFalse())
+			Expect(plan.NextWindow).To(BeTemporally("==", at("2024-06-01T12:00:00Z")))
+			Expect(plan.Wait(now)).To(Equal(6 * time.Hour))
+
+			status := &gameserverv1alpha1.BaseStatus{}
+			Expect(DeferRollout(plan, true, 3, status)).To(BeTrue())
+			condition := meta.FindStatusCondition(status.Conditions, ConditionUpdatePending)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Reason).To(Equal(ReasonWaitingForMaintenanceWindow))
+			Expect(condition.Message).To(ContainSubstring("2024-06-01T12:00:00Z"))
+
+			Expect(DeferRollout(plan, false, 3, status)).To(BeFalse())
+			Expect(meta.FindStatusCondition(status.Conditions, ConditionUpdatePending)).To(BeNil())
+		})
+
+		It("should roll out pending changes with a scheduled restart", func() {
+			withRestarts := schedule.DeepCopy()
+			withRestarts.Restarts = "0 6 * * *"
+			plan, err := PlanRollout(withRestarts, &appsv1.Deployment{}, scheduled("2024-06-01T06:00:00Z"), at("2024-06-01T06:00:10Z"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(plan.InWindow).To(BeFalse())
+
+			status := &gameserverv1alpha1.BaseStatus{}
+			Expect(DeferRollout(plan, true, 1, status)).To(BeFalse())
+			Expect(status.NextRestartTime.Time).To(BeTemporally("==", at("2024-06-01T06:00:00Z")))
+		})
+	})
+})
@@ -0,0 +1,167 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+)
+
+const (
+	// DefaultShutdownGracePeriodSeconds is the pod termination grace period when spec.shutdown.gracePeriodSeconds is unset
+	DefaultShutdownGracePeriodSeconds int32 = 120
+
+	// DefaultShutdownMessage is broadcast at each countdown mark when spec.shutdown.message is unset
+	DefaultShutdownMessage = "Server restarting in {seconds} seconds"
+)
+
+// ShutdownGracePeriod returns the termination grace period of the game pod
+func ShutdownGracePeriod(shutdown *gameserverv1alpha1.Shutdown) *int64 {
+	seconds := int64(DefaultShutdownGracePeriodSeconds)
+	if shutdown.GracePeriodSeconds > 0 {
+		seconds = int64(shutdown.GracePeriodSeconds)
+	}
+	return &seconds
+}
+
+// GetLinuxGSMPreStopHook returns a preStop hook stopping the LinuxGSM server script as the linuxgsm
+// user, so the game saves and exits before the container receives SIGTERM
+func GetLinuxGSMPreStopHook(script string) *corev1.Lifecycle {
+	return &corev1.Lifecycle{
+		PreStop: &corev1.LifecycleHandler{
+			Exec: &corev1.ExecAction{
+				Command: []string{"sh", "-c", "gosu linuxgsm /app/" + script + " stop || true"},
+			},
+		},
+	}
+}
+
+// RunShutdownCountdown warns the players of server over RCON before its pod is replaced, the
+// progress is kept in status.shutdown across reconciles. It returns zero once the pod may be
+// replaced, otherwise how long to wait before calling it again. The countdown is skipped when no
+// warnings or RCON are configured, no pod is running or the console cannot be reached, so a
+// broken console never blocks a rollout.
+func RunShutdownCountdown(ctx context.Context, c client.Client, server client.Object, base *gameserverv1alpha1.Base, profile RConProfile, status *gameserverv1alpha1.BaseStatus, now time.Time) (time.Duration, error) {
+	logger := log.FromContext(ctx)
+
+	marks := shutdownMarks(&base.Shutdown)
+	if len(marks) == 0 || base.RCon.PasswordSecretRef == nil {
+		status.Shutdown = nil
+		return 0, nil
+	}
+
+	if status.Shutdown == nil {
+		pod, err := findRunningPod(ctx, c, server)
+		if err != nil {
+			return 0, err
+		}
+		if pod == nil {
+			return 0, nil
+		}
+		status.Shutdown = &gameserverv1alpha1.ShutdownStatus{StartTime: metav1.NewTime(now)}
+	}
+
+	deadline := status.Shutdown.StartTime.Add(time.Duration(marks[0]) * time.Second)
+	remaining := deadline.Sub(now)
+
+	// Only the latest due mark is announced, marks missed while the operator was down are skipped
+	due := int32(0)
+	for due < int32(len(marks)) && remaining <= time.Duration(marks[due])*time.Second {
+		due++
+	}
+
+	var command string
+	switch {
+	case remaining <= 0:
+		command = shutdownSaveCommand(profile)
+	case due > status.Shutdown.WarningsSent:
+		message := shutdownMessage(&base.Shutdown, remaining)
+		rendered, err := profile.RenderCommand(&gameserverv1alpha1.GameServerCommandSpec{Command: gameserverv1alpha1.CommandSay, Message: message}, "")
+		if err != nil {
+			logger.Error(err, "Game has no broadcast command, skipping shutdown countdown")
+			status.Shutdown = nil
+			return 0, nil
+		}
+		command = rendered
+	}
+
+	if command != "" {
+		if err := sendShutdownCommand(ctx, c, server, base, profile, command); err != nil {
+			if !errors.Is(err, ErrNoRunningPod) {
+				logger.Error(err, "Failed to send shutdown warning over RCON, replacing the pod without countdown")
+			}
+			status.Shutdown = nil
+			return 0, nil
+		}
+	}
+
+	if remaining <= 0 {
+		status.Shutdown = nil
+		return 0, nil
+	}
+	status.Shutdown.WarningsSent = due
+
+	// Wake up for the next mark or the end of the countdown
+	next := remaining
+	if due < int32(len(marks)) {
+		next = remaining - time.Duration(marks[due])*time.Second
+	}
+	return next, nil
+}
+
+// shutdownMarks returns the positive countdown marks in descending order without duplicates
+func shutdownMarks(shutdown *gameserverv1alpha1.Shutdown) []int32 {
+	var marks []int32
+	for _, mark := range shutdown.WarningSeconds {
+		if mark > 0 {
+			marks = append(marks, mark)
+		}
+	}
+	slices.Sort(marks)
+	marks = slices.Compact(marks)
+	slices.Reverse(marks)
+	return marks
+}
+
+// shutdownMessage renders the countdown message for the remaining time, rounded to whole seconds
+func shutdownMessage(shutdown *gameserverv1alpha1.Shutdown, remaining time.Duration) string {
+	message := shutdown.Message
+	if message == "" {
+		message = DefaultShutdownMessage
+	}
+	seconds := int(remaining.Round(time.Second) / time.Second)
+	return strings.ReplaceAll(message, "{seconds}", strconv.Itoa(seconds))
+}
+
+// shutdownSaveCommand returns the console command that persists the world before the pod stops:
+// save where the game has one, otherwise its shutdown command
+func shutdownSaveCommand(profile RConProfile) string {
+	for _, commandType := range []gameserverv1alpha1.GameServerCommandType{gameserverv1alpha1.CommandSave, gameserverv1alpha1.CommandShutdown} {
+		command, err := profile.RenderCommand(&gameserverv1alpha1.GameServerCommandSpec{Command: commandType}, "")
+		if err == nil {
+			return command
+		}
+	}
+	return ""
+}
+
+// sendShutdownCommand runs a single console command on server
+func sendShutdownCommand(ctx context.Context, c client.Client, server client.Object, base *gameserverv1alpha1.Base, profile RConProfile, command string) error {
+	session, err := OpenRConSession(ctx, c, server, base, profile, 0)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	_, err = session.Command(ctx, command)
+	return err
+}
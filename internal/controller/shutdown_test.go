@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+	"github.com/templarfelix/gameserver-operator/internal/rcon/rcontest"
+)
+
+var _ = Describe("Shutdown", func() {
+	var (
+		ctx     context.Context
+		server  *rcontest.Server
+		owner   *corev1.ConfigMap
+		base    *gameserverv1alpha1.Base
+		status  *gameserverv1alpha1.BaseStatus
+		profile RConProfile
+		start   time.Time
+	)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "shutdown-rcon", Namespace: "default"},
+		Data:       map[string][]byte{"password": []byte("rcon-secret")},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "shutdown-server-abc", Namespace: "default", Labels: map[string]string{"app": "shutdown-server"}},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "127.0.0.1"},
+	}
+
+	newClient := func(objects ...client.Object) client.Client {
+		return fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(objects...).Build()
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		start = time.Date(2024, 6, 1, 4, 0, 0, 0, time.UTC)
+
+		var err error
+		server, err = rcontest.NewServer("rcon-secret", nil)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(server.Close)
+
+		owner = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "shutdown-server", Namespace: "default"}}
+		base = &gameserverv1alpha1.Base{
+			RCon: gameserverv1alpha1.RCon{
+				Port: server.Port(),
+				PasswordSecretRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "shutdown-rcon"},
+					Key:                  "password",
+				},
+			},
+			Shutdown: gameserverv1alpha1.Shutdown{WarningSeconds: []int32{10, 60}},
+		}
+		status = &gameserverv1alpha1.BaseStatus{}
+		profile = NewSourceProfile(27015, SourceCommands{
+			gameserverv1alpha1.CommandSay:  "say {message}",
+			gameserverv1alpha1.CommandSave: "save",
+		})
+	})
+
+	It("should default the grace period", func() {
+		Expect(*ShutdownGracePeriod(&gameserverv1alpha1.Shutdown{})).To(Equal(int64(120)))
+		Expect(*ShutdownGracePeriod(&gameserverv1alpha1.Shutdown{GracePeriodSeconds: 300})).To(Equal(int64(300)))
+	})
+
+	It("should warn at each mark and save before the pod is replaced", func() {
+		c := newClient(secret, pod)
+
+		wait, err := RunShutdownCountdown(ctx, c, owner, base, profile, status, start)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wait).To(Equal(50 * time.Second))
+		Expect(status.Shutdown).NotTo(BeNil())
+		Expect(status.Shutdown.WarningsSent).To(Equal(int32(1)))
+
+		// Reconciles between marks announce nothing
+		wait, err = RunShutdownCountdown(ctx, c, owner, base, profile, status, start.Add(20*time.Second))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wait).To(Equal(30 * time.Second))
+
+		wait, err = RunShutdownCountdown(ctx, c, owner, base, profile, status, start.Add(50*time.Second))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wait).To(Equal(10 * time.Second))
+
+		wait, err = RunShutdownCountdown(ctx, c, owner, base, profile, status, start.Add(60*time.Second))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wait).To(BeZero())
+		Expect(status.Shutdown).To(BeNil())
+
+		Expect(server.Commands()).To(Equal([]string{
+			"say Server restarting in 60 seconds",
+			"say Server restarting in 10 seconds",
+			"save",
+		}))
+	})
+
+	It("should fall back to the shutdown command for games without save", func() {
+		c := newClient(secret, pod)
+		profile = NewSourceProfile(27015, DefaultSourceCommands)
+		base.Shutdown.WarningSeconds = []int32{30}
+		base.Shutdown.Message = "Restart in {seconds}s"
+
+		_, err := RunShutdownCountdown(ctx, c, owner, base, profile, status, start)
+		Expect(err).NotTo(HaveOccurred())
+		wait, err := RunShutdownCountdown(ctx, c, owner, base, profile, status, start.Add(45*time.Second))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wait).To(BeZero())
+
+		Expect(server.Commands()).To(Equal([]string{"say Restart in 30s", "quit"}))
+	})
+
+	It("should replace the pod right away without warnings", func() {
+		base.Shutdown.WarningSeconds = nil
+		status.Shutdown = &gameserverv1alpha1.ShutdownStatus{StartTime: metav1.NewTime(start)}
+
+		wait, err := RunShutdownCountdown(ctx, newClient(secret, pod), owner, base, profile, status, start)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wait).To(BeZero())
+		Expect(status.Shutdown).To(BeNil())
+		Expect(server.Commands()).To(BeEmpty())
+	})
+
+	It("should replace the pod right away when no pod is running", func() {
+		wait, err := RunShutdownCountdown(ctx, newClient(secret), owner, base, profile, status, start)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wait).To(BeZero())
+		Expect(status.Shutdown).To(BeNil())
+	})
+
+	It("should not block the rollout when the console rejects the login", func() {
+		wrong := secret.DeepCopy()
+		wrong.Data["password"] = []byte("wrong")
+
+		wait, err := RunShutdownCountdown(ctx, newClient(wrong, pod), owner, base, profile, status, start)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wait).To(BeZero())
+		Expect(status.Shutdown).To(BeNil())
+	})
+
+	It("should stop the server through LinuxGSM before SIGTERM", func() {
+		hook := GetLinuxGSMPreStopHook("dayzserver")
+		Expect(hook.PreStop.Exec.Command).To(Equal([]string{"sh", "-c", "gosu linuxgsm /app/dayzserver stop || true"}))
+	})
+})
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// SourceImage runs Git, wget, tar and unzip in the source init containers
+	SourceImage = "alpine/git:latest"
+
+	// SourceCacheDir keeps the clones of Git sources on the data volume, so a start only fetches the
+	// changes
+	SourceCacheDir = "/data/sources"
+
+	// GitUsernameKey and GitPasswordKey are the keys of the Git credentials Secret
+	GitUsernameKey = "username"
+	GitPasswordKey = "password"
+)
+
+// Source is content downloaded by a source init container, from a Git repository when GitURL is set
+// and from an archive otherwise
+type Source struct {
+	// GitURL is the repository cloned, GitRef the branch, tag or commit checked out (default: HEAD)
+	GitURL string
+	GitRef string
+
+	// GitCredentials references a Secret with the keys username and password, nil for public repositories
+	GitCredentials *corev1.LocalObjectReference
+
+	// ArchiveURL is the zip or tar archive downloaded, checked against ArchiveSHA256 when set
+	ArchiveURL    string
+	ArchiveSHA256 string
+
+	// Path is the folder of the content in the repository or the archive, empty for the root
+	Path string
+}
+
+// GetSourceInitContainer returns the init container called name copying source into dir before each
+// start. Files of dir the source does not have are kept, so the source can be copied over files the
+// game writes, such as saves. The source is passed in environment variables to keep it out of the script.
+func GetSourceInitContainer(name string, source Source, dir string) corev1.Container {
+	env := []corev1.EnvVar{
+		{Name: "SOURCE_DIR", Value: dir},
+		{Name: "SOURCE_PATH", Value: source.Path},
+	}
+
+	script := "set -eu\n"
+	if source.GitURL != "" {
+		env = append(env,
+			corev1.EnvVar{Name: "GIT_URL", Value: source.GitURL},
+			corev1.EnvVar{Name: "GIT_REF", Value: source.GitRef},
+			corev1.EnvVar{Name: "GIT_CLONE", Value: fmt.Sprintf("%s/%s", SourceCacheDir, name)},
+		)
+		git := "git"
+		if source.GitCredentials != nil {
+			secretKey := func(key string) *corev1.EnvVarSource {
+				return &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: *source.GitCredentials, Key: key}}
+			}
+			env = append(env,
+				corev1.EnvVar{Name: "GIT_USERNAME", ValueFrom: secretKey(GitUsernameKey)},
+				corev1.EnvVar{Name: "GIT_PASSWORD", ValueFrom: secretKey(GitPasswordKey)},
+			)
+			git += ` -c credential.helper='!f() { echo "username=$GIT_USERNAME"; echo "password=$GIT_PASSWORD"; }; f'`
+		}
+		script += "git init -q \"$GIT_CLONE\"\n"
+		script += "git -C \"$GIT_CLONE\" remote remove origin 2>/dev/null || true\n"
+		script += "git -C \"$GIT_CLONE\" remote add origin \"$GIT_URL\"\n"
+		script += git + " -C \"$GIT_CLONE\" fetch -q --depth 1 origin \"${GIT_REF:-HEAD}\"\n"
+		script += "git -C \"$GIT_CLONE\" checkout -q -f FETCH_HEAD\n"
+		script += "content=\"$GIT_CLONE\"\n"
+	} else {
+		env = append(env,
+			corev1.EnvVar{Name: "ARCHIVE_URL", Value: source.ArchiveURL},
+			corev1.EnvVar{Name: "ARCHIVE_SHA256", Value: source.ArchiveSHA256},
+		)
+		script += "wget -q -O /tmp/source.archive \"$ARCHIVE_URL\"\n"
+		script += "if [ -n \"$ARCHIVE_SHA256\" ]; then echo \"$ARCHIVE_SHA256  /tmp/source.archive\" | sha256sum -c -; fi\n"
+		script += "mkdir -p /tmp/source\n"
+		script += "if unzip -l /tmp/source.archive >/dev/null 2>&1; then unzip -q -o /tmp/source.archive -d /tmp/source; " +
+			"else tar -xf /tmp/source.archive -C /tmp/source; fi\n"
+		script += "content=/tmp/source\n"
+	}
+
+	script += "test -d \"$content/$SOURCE_PATH\"\n"
+	script += "mkdir -p \"$SOURCE_DIR\"\n"
+	script += "tar -C \"$content/$SOURCE_PATH\" --exclude=.git -cf - . | tar -C \"$SOURCE_DIR\" -xf -\n"
+	script += "chown -R 1000:1000 \"$SOURCE_DIR\"\n"
+
+	return corev1.Container{
+		Name:    name,
+		Image:   SourceImage,
+		Command: []string{"sh", "-c"},
+		Args:    []string{script},
+		Env:     env,
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: DataVolumeName, MountPath: DataMountPath},
+		},
+	}
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("Source", func() {
+	env := func(container corev1.Container) map[string]string {
+		values := map[string]string{}
+		for _, e := range container.Env {
+			values[e.Name] = e.Value
+		}
+		return values
+	}
+
+	It("should fetch a Git ref into the folder", func() {
+		container := GetSourceInitContainer("mission", Source{
+			GitURL:         "https://github.com/example/missions.git",
+			GitRef:         "v1.2",
+			GitCredentials: &corev1.LocalObjectReference{Name: "git"},
+			Path:           "dayzOffline.custom",
+		}, "/data/serverfiles/mpmissions/dayzOffline.custom")
+
+		Expect(container.Name).To(Equal("mission"))
+		Expect(container.Image).To(Equal(SourceImage))
+		Expect(env(container)).To(Equal(map[string]string{
+			"SOURCE_DIR":   "/data/serverfiles/mpmissions/dayzOffline.custom",
+			"SOURCE_PATH":  "dayzOffline.custom",
+			"GIT_URL":      "https://github.com/example/missions.git",
+			"GIT_REF":      "v1.2",
+			"GIT_CLONE":    "/data/sources/mission",
+			"GIT_USERNAME": "",
+			"GIT_PASSWORD": "",
+		}))
+		Expect(container.Env[6].ValueFrom.SecretKeyRef.Name).To(Equal("git"))
+		Expect(container.Env[6].ValueFrom.SecretKeyRef.Key).To(Equal(GitPasswordKey))
+
+		script := container.Args[0]
+		Expect(script).To(ContainSubstring(`credential.helper='!f() { echo "username=$GIT_USERNAME"; echo "password=$GIT_PASSWORD"; }; f' -C "$GIT_CLONE" fetch -q --depth 1 origin "${GIT_REF:-HEAD}"`))
+		Expect(script).To(ContainSubstring(`--exclude=.git -cf - . | tar -C "$SOURCE_DIR" -xf -`))
+		Expect(script).NotTo(ContainSubstring("wget"))
+	})
+
+	It("should download and check an archive", func() {
+		container := GetSourceInitContainer("mission", Source{
+			ArchiveURL:    "https://example.com/mission.zip",
+			ArchiveSHA256: "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		}, "/data/serverfiles/mpmissions/dayzOffline.custom")
+
+		Expect(env(container)).To(HaveKeyWithValue("ARCHIVE_URL", "https://example.com/mission.zip"))
+		script := container.Args[0]
+		Expect(script).To(ContainSubstring(`sha256sum -c -`))
+		Expect(script).To(ContainSubstring(`unzip -q -o /tmp/source.archive -d /tmp/source`))
+		Expect(script).NotTo(ContainSubstring("fetch"))
+	})
+})
@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+)
+
+const (
+	// BuildIDAnnotation on the pod template records the game build the pod installs when it starts
+	BuildIDAnnotation = "gameserver.templarfelix.com/buildID"
+
+	// DefaultUpdateCheckInterval is the interval between two update checks when spec.updates.checkIntervalMinutes is unset
+	DefaultUpdateCheckInterval = time.Hour
+)
+
+// LinuxGSMUpdateCheckEnv disables the update cron of the LinuxGSM container, which restarts the game
+// without warning players. Updates are installed by LinuxGSM when the pod starts instead.
+var LinuxGSMUpdateCheckEnv = corev1.EnvVar{Name: "UPDATE_CHECK", Value: "0"}
+
+// BuildChecker returns the latest public build of a Steam app
+type BuildChecker interface {
+	LatestBuild(ctx context.Context, appID int) (string, error)
+}
+
+// UpdatePolicy returns the configured update policy or the default OnRestart
+func UpdatePolicy(updates *gameserverv1alpha1.Updates) gameserverv1alpha1.UpdatePolicy {
+	if updates.Policy == "" {
+		return gameserverv1alpha1.UpdatePolicyOnRestart
+	}
+	return updates.Policy
+}
+
+// UpdateCheckInterval returns how often the latest build is checked
+func UpdateCheckInterval(updates *gameserverv1alpha1.Updates) time.Duration {
+	if updates.CheckIntervalMinutes > 0 {
+		return time.Duration(updates.CheckIntervalMinutes) * time.Minute
+	}
+	return DefaultUpdateCheckInterval
+}
+
+// CheckForUpdate records the latest build of appID in status.updates once the check interval has
+// passed and returns how long until the next check. Lookup failures are logged and retried at the
+// next interval, they never fail the reconcile.
+func CheckForUpdate(ctx context.Context, checker BuildChecker, updates *gameserverv1alpha1.Updates, appID int, status *gameserverv1alpha1.BaseStatus, now time.Time) time.Duration {
+	if UpdatePolicy(updates) == gameserverv1alpha1.UpdatePolicyNever {
+		status.Updates = nil
+		return 0
+	}
+	if status.Updates == nil {
+		status.Updates = &gameserverv1alpha1.UpdateStatus{}
+	}
+
+	interval := UpdateCheckInterval(updates)
+	if last := status.Updates.LastCheckTime; last != nil && now.Before(last.Add(interval)) {
+		return last.Add(interval).Sub(now)
+	}
+
+	checked := metav1.NewTime(now)
+	status.Updates.LastCheckTime = &checked
+	build, err := checker.LatestBuild(ctx, appID)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to check for game updates", "appID", appID)
+		return interval
+	}
+	status.Updates.AvailableBuildID = build
+	return interval
+}
+
+// SetBuildID annotates the pod template of desired with the build its pod installs and sets the pod
+// template hash. The pod of found keeps its build unless it is replaced anyway, which installs the
+// available build, or the policy is Auto, in which case an available update replaces it. The build
+// running in found is recorded as installed in status.
+func SetBuildID(found, desired *appsv1.Deployment, updates *gameserverv1alpha1.Updates, status *gameserverv1alpha1.BaseStatus) error {
+	current := ""
+	if found != nil {
+		current = found.Spec.Template.Annotations[BuildIDAnnotation]
+	}
+	setTemplateAnnotation(desired, BuildIDAnnotation, current)
+	if err := SetPodTemplateHash(desired); err != nil {
+		return err
+	}
+	if status.Updates == nil {
+		return nil
+	}
+
+	available := status.Updates.AvailableBuildID
+	installed := current
+	if installed == "" {
+		installed = status.Updates.InstalledBuildID
+	}
+	if installed == "" {
+		// Without record the running pod installed the latest build when it started
+		installed = available
+	}
+	status.Updates.InstalledBuildID = installed
+
+	replaced := found == nil || PodTemplateChanged(found, desired)
+	update := available != "" && available != installed && UpdatePolicy(updates) == gameserverv1alpha1.UpdatePolicyAuto
+	if available == "" || available == current || !(replaced || update) {
+		return nil
+	}
+	setTemplateAnnotation(desired, BuildIDAnnotation, available)
+	return SetPodTemplateHash(desired)
+}
+
+// setTemplateAnnotation sets a pod template annotation of deployment, empty values are not written
+func setTemplateAnnotation(deployment *appsv1.Deployment, key, value string) {
+	if value == "" {
+		delete(deployment.Spec.Template.Annotations, key)
+		return
+	}
+	if deployment.Spec.Template.Annotations == nil {
+		deployment.Spec.Template.Annotations = map[string]string{}
+	}
+	deployment.Spec.Template.Annotations[key] = value
+}
@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+)
+
+// fakeBuildChecker returns a fixed build and counts lookups
+type fakeBuildChecker struct {
+	build string
+	err   error
+	calls int
+}
+
+func (f *fakeBuildChecker) LatestBuild(_ context.Context, _ int) (string, error) {
+	f.calls++
+	return f.build, f.err
+}
+
+var _ = Describe("Updates", func() {
+	var (
+		ctx     context.Context
+		checker *fakeBuildChecker
+		status  *gameserverv1alpha1.BaseStatus
+		now     time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		checker = &fakeBuildChecker{build: "200"}
+		status = &gameserverv1alpha1.BaseStatus{}
+		now = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	})
+
+	Describe("CheckForUpdate", func() {
+		It("should record the available build once per interval", func() {
+			updates := &gameserverv1alpha1.Updates{CheckIntervalMinutes: 30}
+
+			Expect(CheckForUpdate(ctx, checker, updates, 223350, status, now)).To(Equal(30 * time.Minute))
+			Expect(status.Updates.AvailableBuildID).To(Equal("200"))
+			Expect(status.Updates.LastCheckTime.Time).To(Equal(now))
+
+			Expect(CheckForUpdate(ctx, checker, updates, 223350, status, now.Add(10*time.Minute))).To(Equal(20 * time.Minute))
+			Expect(checker.calls).To(Equal(1))
+		})
+
+		It("should keep the last known build when the lookup fails", func() {
+			status.Updates = &gameserverv1alpha1.UpdateStatus{AvailableBuildID: "100"}
+			checker.err = errors.New("unreachable")
+
+			Expect(CheckForUpdate(ctx, checker, &gameserverv1alpha1.Updates{}, 223350, status, now)).To(Equal(DefaultUpdateCheckInterval))
+			Expect(status.Updates.AvailableBuildID).To(Equal("100"))
+			Expect(status.Updates.LastCheckTime).NotTo(BeNil())
+		})
+
+		It("should not check with the Never policy", func() {
+			status.Updates = &gameserverv1alpha1.UpdateStatus{AvailableBuildID: "100"}
+
+			Expect(CheckForUpdate(ctx, checker, &gameserverv1alpha1.Updates{Policy: gameserverv1alpha1.UpdatePolicyNever}, 223350, status, now)).To(BeZero())
+			Expect(status.Updates).To(BeNil())
+			Expect(checker.calls).To(BeZero())
+		})
+	})
+
+	Describe("SetBuildID", func() {
+		deployment := func(image, build string) *appsv1.Deployment {
+			d := &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "dayz"}},
+						Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "server", Image: image}}},
+					},
+				},
+			}
+			setTemplateAnnotation(d, BuildIDAnnotation, build)
+			Expect(SetPodTemplateHash(d)).To(Succeed())
+			return d
+		}
+		onRestart := &gameserverv1alpha1.Updates{}
+		auto := &gameserverv1alpha1.Updates{Policy: gameserverv1alpha1.UpdatePolicyAuto}
+
+		It("should assume the running pod has the latest build without record", func() {
+			found := deployment("dayz", "")
+			desired := deployment("dayz", "")
+			status.Updates = &gameserverv1alpha1.UpdateStatus{AvailableBuildID: "200"}
+
+			Expect(SetBuildID(found, desired, auto, status)).To(Succeed())
+			Expect(status.Updates.InstalledBuildID).To(Equal("200"))
+			Expect(PodTemplateChanged(found, desired)).To(BeFalse())
+		})
+
+		It("should keep the running build with the OnRestart policy", func() {
+			found := deployment("dayz", "100")
+			desired := deployment("dayz", "")
+			status.Updates = &gameserverv1alpha1.UpdateStatus{AvailableBuildID: "200"}
+
+			Expect(SetBuildID(found, desired, onRestart, status)).To(Succeed())
+			Expect(status.Updates.InstalledBuildID).To(Equal("100"))
+			Expect(desired.Spec.Template.Annotations).To(HaveKeyWithValue(BuildIDAnnotation, "100"))
+			Expect(PodTemplateChanged(found, desired)).To(BeFalse())
+		})
+
+		It("should install the available build when the pod is replaced anyway", func() {
+			found := deployment("dayz", "100")
+			desired := deployment("dayz:v2", "")
+			status.Updates = &gameserverv1alpha1.UpdateStatus{AvailableBuildID: "200"}
+
+			Expect(SetBuildID(found, desired, onRestart, status)).To(Succeed())
+			Expect(desired.Spec.Template.Annotations).To(HaveKeyWithValue(BuildIDAnnotation, "200"))
+			Expect(PodTemplateChanged(found, desired)).To(BeTrue())
+		})
+
+		It("should replace the pod for an update with the Auto policy", func() {
+			found := deployment("dayz", "100")
+			desired := deployment("dayz", "")
+			status.Updates = &gameserverv1alpha1.UpdateStatus{AvailableBuildID: "200"}
+
+			Expect(SetBuildID(found, desired, auto, status)).To(Succeed())
+			Expect(desired.Spec.Template.Annotations).To(HaveKeyWithValue(BuildIDAnnotation, "200"))
+			Expect(PodTemplateChanged(found, desired)).To(BeTrue())
+
+			// Once rolled out the new build is reported as installed
+			Expect(SetBuildID(desired.DeepCopy(), deployment("dayz", ""), auto, status)).To(Succeed())
+			Expect(status.Updates.InstalledBuildID).To(Equal("200"))
+		})
+
+		It("should leave the pod template alone without update checks", func() {
+			desired := deployment("dayz", "")
+
+			Expect(SetBuildID(nil, desired, &gameserverv1alpha1.Updates{Policy: gameserverv1alpha1.UpdatePolicyNever}, status)).To(Succeed())
+			Expect(desired.Spec.Template.Annotations).NotTo(HaveKey(BuildIDAnnotation))
+			Expect(desired.Annotations).To(HaveKey(PodTemplateHashAnnotation))
+		})
+	})
+})
@@ -2,6 +2,9 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
 	"reflect"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -13,6 +16,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
 )
 
 // Game Server Configuration Constants
@@ -21,6 +26,16 @@ const (
 	GameServerUserID  int64 = 1000
 	GameServerGroupID int64 = 1000
 
+	// DefaultStorageSize is the size of the game data volume when none is configured
+	DefaultStorageSize = "10G"
+
+	// DataMountPath is where the game data volume is mounted, config files must live below it
+	DataMountPath = "/data"
+
+	// DefaultOperatorImage is the image of the operator, it ships the wake proxy, economy and logs binaries
+	// the game pods run. The manager uses its own image from OPERATOR_IMAGE instead when set
+	DefaultOperatorImage = "controller:latest"
+
 	// InitContainer configuration
 	SetupContainerImage = "alpine:latest"
 	SetupContainerName  = "config-setup"
@@ -31,6 +46,19 @@ const (
 	ConfigServerVolumeName = "config-server"
 	ConfigGsmVolumeName    = "config-gsm"
 	DataVolumeName         = "data"
+
+	// Code-server editor password Secret
+	EditorSecretSuffix = "-editor"
+	EditorPasswordKey  = "password"
+
+	// EditorServiceSuffix names the Service and the Ingress publishing the code-server editor
+	EditorServiceSuffix = "-editor"
+
+	// Code-server editor sidecar defaults
+	CodeServerContainerName = "code-server"
+	CodeServerImage         = "codercom/code-server"
+	CodeServerTag           = "latest"
+	CodeServerPort          = 8080
 )
 
 // getGameServerSecurityContext returns the security context for game server containers
@@ -75,8 +103,8 @@ func getProjectZomboidSetupInitContainer() corev1.Container {
 	}
 }
 
-// GetCodeServerContainer creates a code-server container
-func GetCodeServerContainer(password string) corev1.Container {
+// GetCodeServerContainer creates a code-server container reading its password from a Secret
+func GetCodeServerContainer(passwordRef corev1.SecretKeySelector) corev1.Container {
 	return corev1.Container{
 		Name:  "code-server",
 		Image: "codercom/code-server:latest",
@@ -86,8 +114,10 @@ func GetCodeServerContainer(password string) corev1.Container {
 			Protocol:      corev1.ProtocolTCP,
 		}},
 		Env: []corev1.EnvVar{{
-			Name:  "PASSWORD",
-			Value: password,
+			Name: "PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &passwordRef,
+			},
 		}},
 		VolumeMounts: []corev1.VolumeMount{{
 			Name:      "data",
@@ -149,6 +179,41 @@ func CompareDeployments(a, b *appsv1.Deployment) bool {
 	return true
 }
 
+// EditorSecretVersionAnnotation records on the pod template the resourceVersion of the editor password
+// Secret. The password is read into an environment variable when code-server starts, so a change of the
+// Secret has to replace the pod.
+const EditorSecretVersionAnnotation = "gameserver.templarfelix.com/editor-secret-version"
+
+// SetEditorSecretVersion writes the resourceVersion of the editor password Secret to the pod template of deployment
+func SetEditorSecretVersion(deployment *appsv1.Deployment, version string) {
+	setTemplateAnnotation(deployment, EditorSecretVersionAnnotation, version)
+}
+
+// PodTemplateHashAnnotation records on a Deployment the hash of the pod template generated by the operator
+const PodTemplateHashAnnotation = "gameserver.templarfelix.com/pod-template-hash"
+
+// SetPodTemplateHash annotates deployment with the hash of its pod template. Unlike the template read
+// back from the API server, the generated one carries no defaulted fields and hashes stably.
+func SetPodTemplateHash(deployment *appsv1.Deployment) error {
+	data, err := json.Marshal(deployment.Spec.Template)
+	if err != nil {
+		return err
+	}
+	hash := fnv.New32a()
+	_, _ = hash.Write(data)
+
+	if deployment.Annotations == nil {
+		deployment.Annotations = map[string]string{}
+	}
+	deployment.Annotations[PodTemplateHashAnnotation] = fmt.Sprintf("%08x", hash.Sum32())
+	return nil
+}
+
+// PodTemplateChanged reports whether updating found to desired replaces the game pod
+func PodTemplateChanged(found, desired *appsv1.Deployment) bool {
+	return found.Annotations[PodTemplateHashAnnotation] != desired.Annotations[PodTemplateHashAnnotation]
+}
+
 // CompareConfigMaps checks if two ConfigMaps have the same data
 func CompareConfigMaps(a, b *corev1.ConfigMap) bool {
 	if len(a.Data) != len(b.Data) {
@@ -191,43 +256,50 @@ func applyResourceDefaults(resources corev1.ResourceRequirements) corev1.Resourc
 	return resources
 }
 
-// GetSecureCodeServerContainer returns a code-server container
-func GetSecureCodeServerContainer(password string) corev1.Container {
+// GetSecureCodeServerContainer returns a code-server container reading its password from a Secret
+func GetSecureCodeServerContainer(editor *gameserverv1alpha1.Editor, passwordRef corev1.SecretKeySelector) corev1.Container {
+	resources := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("100m"),
+			corev1.ResourceMemory: resource.MustParse("128Mi"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("500m"),
+			corev1.ResourceMemory: resource.MustParse("512Mi"),
+		},
+	}
+	if editor.Resources != nil {
+		resources = *editor.Resources
+	}
+
 	return corev1.Container{
-		Name:  "code-server",
-		Image: "codercom/code-server:latest",
+		Name:  CodeServerContainerName,
+		Image: GetEditorImage(editor),
 		SecurityContext: &corev1.SecurityContext{
 			RunAsUser:  func(i int64) *int64 { return &i }(1000),
 			RunAsGroup: func(i int64) *int64 { return &i }(1000),
 		},
 		Ports: []corev1.ContainerPort{{
-			ContainerPort: 8080,
-			Name:          "code-server",
+			ContainerPort: CodeServerPort,
+			Name:          CodeServerContainerName,
 			Protocol:      corev1.ProtocolTCP,
 		}},
 		Env: []corev1.EnvVar{{
-			Name:  "PASSWORD",
-			Value: password,
+			Name: "PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &passwordRef,
+			},
 		}},
 		VolumeMounts: []corev1.VolumeMount{{
 			Name:      "data",
 			MountPath: "/data",
 		}},
-		Resources: corev1.ResourceRequirements{
-			Requests: corev1.ResourceList{
-				corev1.ResourceCPU:    resource.MustParse("100m"),
-				corev1.ResourceMemory: resource.MustParse("128Mi"),
-			},
-			Limits: corev1.ResourceList{
-				corev1.ResourceCPU:    resource.MustParse("500m"),
-				corev1.ResourceMemory: resource.MustParse("512Mi"),
-			},
-		},
+		Resources: resources,
 		ReadinessProbe: &corev1.Probe{
 			ProbeHandler: corev1.ProbeHandler{
 				HTTPGet: &corev1.HTTPGetAction{
 					Path: "/",
-					Port: intstr.FromInt32(8080),
+					Port: intstr.FromInt32(CodeServerPort),
 				},
 			},
 			InitialDelaySeconds: 5,
@@ -237,7 +309,7 @@ func GetSecureCodeServerContainer(password string) corev1.Container {
 			ProbeHandler: corev1.ProbeHandler{
 				HTTPGet: &corev1.HTTPGetAction{
 					Path: "/",
-					Port: intstr.FromInt32(8080),
+					Port: intstr.FromInt32(CodeServerPort),
 				},
 			},
 			InitialDelaySeconds: 15,
@@ -246,6 +318,29 @@ func GetSecureCodeServerContainer(password string) corev1.Container {
 	}
 }
 
+// IsEditorEnabled reports whether the code-server sidecar should run, it is enabled unless explicitly disabled
+func IsEditorEnabled(editor *gameserverv1alpha1.Editor) bool {
+	return editor.Enabled == nil || *editor.Enabled
+}
+
+// IsTrue reports whether the optional flag is set to true, an unset flag is false
+func IsTrue(flag *bool) bool {
+	return flag != nil && *flag
+}
+
+// GetEditorImage returns the code-server image reference with defaults applied
+func GetEditorImage(editor *gameserverv1alpha1.Editor) string {
+	image := editor.Image
+	if image == "" {
+		image = CodeServerImage
+	}
+	tag := editor.Tag
+	if tag == "" {
+		tag = CodeServerTag
+	}
+	return image + ":" + tag
+}
+
 // GetSecureGameServerContainer returns a game server container - let LinuxGSM handle user setup
 func GetSecureGameServerContainer(name, image string, resources corev1.ResourceRequirements, ports []corev1.ContainerPort) corev1.Container {
 	return corev1.Container{
@@ -8,6 +8,8 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
 )
 
 var _ = Describe("Utils", func() {
@@ -71,7 +73,10 @@ var _ = Describe("Utils", func() {
 
 	Describe("GetSecureCodeServerContainer", func() {
 		It("should create a secure code-server container", func() {
-			container := GetSecureCodeServerContainer("test-password")
+			container := GetSecureCodeServerContainer(&gameserverv1alpha1.Editor{}, corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "test-editor"},
+				Key:                  EditorPasswordKey,
+			})
 
 			Expect(container.Name).To(Equal("code-server"))
 			Expect(container.Image).To(Equal("codercom/code-server:latest"))
@@ -80,6 +85,44 @@ var _ = Describe("Utils", func() {
 			// This field is not explicitly set, so it should be nil
 			Expect(container.SecurityContext.RunAsNonRoot).To(BeNil())
 		})
+
+		It("should read the password from a Secret instead of a literal value", func() {
+			container := GetSecureCodeServerContainer(&gameserverv1alpha1.Editor{}, corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "test-editor"},
+				Key:                  EditorPasswordKey,
+			})
+
+			Expect(container.Env).To(HaveLen(1))
+			Expect(container.Env[0].Name).To(Equal("PASSWORD"))
+			Expect(container.Env[0].Value).To(BeEmpty())
+			Expect(container.Env[0].ValueFrom.SecretKeyRef.Name).To(Equal("test-editor"))
+			Expect(container.Env[0].ValueFrom.SecretKeyRef.Key).To(Equal(EditorPasswordKey))
+		})
+	})
+
+	Describe("Editor configuration", func() {
+		It("should enable the editor unless explicitly disabled", func() {
+			disabled := false
+			Expect(IsEditorEnabled(&gameserverv1alpha1.Editor{})).To(BeTrue())
+			Expect(IsEditorEnabled(&gameserverv1alpha1.Editor{Enabled: &disabled})).To(BeFalse())
+		})
+
+		It("should pin the configured image and tag", func() {
+			Expect(GetEditorImage(&gameserverv1alpha1.Editor{})).To(Equal("codercom/code-server:latest"))
+			Expect(GetEditorImage(&gameserverv1alpha1.Editor{Image: "registry.local/code-server", Tag: "4.23.1"})).
+				To(Equal("registry.local/code-server:4.23.1"))
+		})
+
+		It("should use the configured resources", func() {
+			editor := &gameserverv1alpha1.Editor{
+				Resources: &corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+				},
+			}
+
+			container := GetSecureCodeServerContainer(editor, corev1.SecretKeySelector{})
+			Expect(container.Resources.Limits.Memory().String()).To(Equal("1Gi"))
+		})
 	})
 
 	Describe("CompareDeployments", func() {
@@ -99,6 +142,20 @@ var _ = Describe("Utils", func() {
 			Expect(result).To(BeFalse())
 		})
 	})
+
+	Describe("PodTemplateChanged", func() {
+		It("should detect pod template changes through the template hash", func() {
+			found := createTestDeployment(1)
+			desired := createTestDeployment(2)
+			Expect(SetPodTemplateHash(found)).To(Succeed())
+			Expect(SetPodTemplateHash(desired)).To(Succeed())
+			Expect(PodTemplateChanged(found, desired)).To(BeFalse())
+
+			desired.Spec.Template.Spec.TerminationGracePeriodSeconds = ShutdownGracePeriod(&gameserverv1alpha1.Shutdown{})
+			Expect(SetPodTemplateHash(desired)).To(Succeed())
+			Expect(PodTemplateChanged(found, desired)).To(BeTrue())
+		})
+	})
 })
 
 func createTestDeployment(replicas int32) *appsv1.Deployment {
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+)
+
+// ValidateBase validates the common game server fields shared by every game CRD. It is run on the
+// effective Base merged from the GameServerTemplate and GameServerClass, as fields may depend on each
+// other across them.
+func ValidateBase(base *gameserverv1alpha1.Base, specPath *field.Path) field.ErrorList {
+	allErrs := ValidateBaseFields(base, specPath)
+
+	editorPath := specPath.Child("editor")
+	if base.Editor.Exposure == gameserverv1alpha1.EditorExposureIngress && (base.Editor.Ingress == nil || base.Editor.Ingress.Host == "") {
+		allErrs = append(allErrs, field.Required(editorPath.Child("ingress", "host"), "required when exposure is Ingress"))
+	}
+
+	if len(base.Shutdown.WarningSeconds) > 0 && base.RCon.PasswordSecretRef == nil {
+		allErrs = append(allErrs, field.Required(specPath.Child("rcon", "passwordSecretRef"), "required to send shutdown warnings"))
+	}
+
+	return allErrs
+}
+
+// ValidateBaseFields validates the fields of base which are valid or not on their own, it applies to
+// GameServerTemplates and to specs whose template or class is missing
+func ValidateBaseFields(base *gameserverv1alpha1.Base, specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	sizePath := specPath.Child("persistence", "storageConfig", "size")
+	if size := base.Persistence.StorageConfig.Size; size != "" {
+		quantity, err := resource.ParseQuantity(size)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(sizePath, size, "must be a valid quantity such as 10G or 20Gi"))
+		} else if quantity.Sign() <= 0 {
+			allErrs = append(allErrs, field.Invalid(sizePath, size, "must be greater than zero"))
+		}
+	}
+
+	portsPath := specPath.Child("ports")
+	names := make(map[string]bool, len(base.Ports))
+	numbers := make(map[string]bool, len(base.Ports))
+	for i, port := range base.Ports {
+		if port.Name != "" {
+			if names[port.Name] {
+				allErrs = append(allErrs, field.Duplicate(portsPath.Index(i).Child("name"), port.Name))
+			}
+			names[port.Name] = true
+		}
+
+		key := fmt.Sprintf("%d/%s", port.Port, port.Protocol)
+		if numbers[key] {
+			allErrs = append(allErrs, field.Duplicate(portsPath.Index(i).Child("port"), key))
+		}
+		numbers[key] = true
+	}
+
+	return append(allErrs, validateSchedule(&base.Schedule, specPath.Child("schedule"))...)
+}
+
+// validateSchedule checks the time zone and cron expressions of the restart schedule and maintenance windows
+func validateSchedule(schedule *gameserverv1alpha1.Schedule, schedulePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if schedule.TimeZone != "" {
+		if _, err := time.LoadLocation(schedule.TimeZone); err != nil {
+			return append(allErrs, field.Invalid(schedulePath.Child("timeZone"), schedule.TimeZone, "must be an IANA time zone such as Europe/Berlin"))
+		}
+	}
+
+	if schedule.Restarts != "" {
+		if _, err := ParseCron(schedule.Restarts, schedule.TimeZone); err != nil {
+			allErrs = append(allErrs, field.Invalid(schedulePath.Child("restarts"), schedule.Restarts, err.Error()))
+		}
+	}
+	for i, window := range schedule.MaintenanceWindows {
+		if _, err := ParseCron(window.Start, schedule.TimeZone); err != nil {
+			allErrs = append(allErrs, field.Invalid(schedulePath.Child("maintenanceWindows").Index(i).Child("start"), window.Start, err.Error()))
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateBaseUpdate rejects changes to fields that cannot be applied to existing resources
+func ValidateBaseUpdate(oldBase, base *gameserverv1alpha1.Base, specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	storagePath := specPath.Child("persistence", "storageConfig")
+
+	if oldBase.Persistence.StorageConfig.StorageClassName != base.Persistence.StorageConfig.StorageClassName {
+		allErrs = append(allErrs, field.Forbidden(storagePath.Child("storageClassName"), "field is immutable"))
+	}
+
+	oldSize, oldErr := resource.ParseQuantity(oldBase.Persistence.StorageConfig.Size)
+	newSize, newErr := resource.ParseQuantity(base.Persistence.StorageConfig.Size)
+	if oldErr == nil && newErr == nil && newSize.Cmp(oldSize) < 0 {
+		allErrs = append(allErrs, field.Forbidden(storagePath.Child("size"), "volumes cannot be shrunk"))
+	}
+
+	return allErrs
+}
+
+// ValidateConfigPaths ensures config files are written below the data volume. Paths are embedded
+// in the config setup script, so quotes are rejected as well.
+func ValidateConfigPaths(config map[string]string, configPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	filePaths := make([]string, 0, len(config))
+	for filePath := range config {
+		filePaths = append(filePaths, filePath)
+	}
+	sort.Strings(filePaths)
+
+	for _, filePath := range filePaths {
+		cleaned := path.Clean(filePath)
+		switch {
+		case !path.IsAbs(filePath):
+			allErrs = append(allErrs, field.Invalid(configPath.Key(filePath), filePath, "must be an absolute path"))
+		case !strings.HasPrefix(cleaned, DataMountPath+"/"):
+			allErrs = append(allErrs, field.Invalid(configPath.Key(filePath), filePath, "must be inside "+DataMountPath))
+		case strings.ContainsAny(filePath, "'\n"):
+			allErrs = append(allErrs, field.Invalid(configPath.Key(filePath), filePath, "must not contain quotes or newlines"))
+		}
+	}
+
+	return allErrs
+}
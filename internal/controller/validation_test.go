@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+)
+
+var _ = Describe("Validation", func() {
+	ctx := context.Background()
+	specPath := field.NewPath("spec")
+
+	Describe("ValidateBase", func() {
+		It("should accept a valid base", func() {
+			base := &gameserverv1alpha1.Base{
+				Persistence: gameserverv1alpha1.Persistence{
+					StorageConfig: gameserverv1alpha1.StorageConfig{Size: "20Gi"},
+				},
+				Ports: []corev1.ServicePort{
+					{Name: "game-udp", Port: 2302, Protocol: corev1.ProtocolUDP},
+					{Name: "query-udp", Port: 27016, Protocol: corev1.ProtocolUDP},
+					{Name: "query-tcp", Port: 27016, Protocol: corev1.ProtocolTCP},
+				},
+			}
+
+			Expect(ValidateBase(base, specPath)).To(BeEmpty())
+		})
+
+		It("should reject invalid storage quantities", func() {
+			base := &gameserverv1alpha1.Base{
+				Persistence: gameserverv1alpha1.Persistence{
+					StorageConfig: gameserverv1alpha1.StorageConfig{Size: "ten gigs"},
+				},
+			}
+
+			errs := ValidateBase(base, specPath)
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Field).To(Equal("spec.persistence.storageConfig.size"))
+		})
+
+		It("should reject duplicate port names and numbers", func() {
+			base := &gameserverv1alpha1.Base{
+				Ports: []corev1.ServicePort{
+					{Name: "game", Port: 2302, Protocol: corev1.ProtocolUDP},
+					{Name: "game", Port: 2304, Protocol: corev1.ProtocolUDP},
+					{Name: "other", Port: 2302, Protocol: corev1.ProtocolUDP},
+				},
+			}
+
+			errs := ValidateBase(base, specPath)
+			Expect(errs).To(HaveLen(2))
+			Expect(errs[0].Type).To(Equal(field.ErrorTypeDuplicate))
+			Expect(errs[1].Field).To(Equal("spec.ports[2].port"))
+		})
+
+		It("should reject invalid schedules", func() {
+			base := &gameserverv1alpha1.Base{
+				Schedule: gameserverv1alpha1.Schedule{
+					Restarts: "0 25 * * *",
+					MaintenanceWindows: []gameserverv1alpha1.MaintenanceWindow{
+						{Start: "0 4 * * 1", DurationMinutes: 60},
+						{Start: "every night", DurationMinutes: 60},
+					},
+				},
+			}
+
+			errs := ValidateBase(base, specPath)
+			Expect(errs).To(HaveLen(2))
+			Expect(errs[0].Field).To(Equal("spec.schedule.restarts"))
+			Expect(errs[1].Field).To(Equal("spec.schedule.maintenanceWindows[1].start"))
+
+			base.Schedule.TimeZone = "Mars/Olympus_Mons"
+			errs = ValidateBase(base, specPath)
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Field).To(Equal("spec.schedule.timeZone"))
+		})
+
+		It("should require RCON for shutdown warnings", func() {
+			base := &gameserverv1alpha1.Base{
+				Shutdown: gameserverv1alpha1.Shutdown{WarningSeconds: []int32{60, 10}},
+			}
+
+			errs := ValidateBase(base, specPath)
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Field).To(Equal("spec.rcon.passwordSecretRef"))
+		})
+
+		It("should require an ingress host for Ingress exposure", func() {
+			base := &gameserverv1alpha1.Base{
+				Editor: gameserverv1alpha1.Editor{Exposure: gameserverv1alpha1.EditorExposureIngress},
+			}
+
+			Expect(ValidateBase(base, specPath)).To(HaveLen(1))
+		})
+	})
+
+	Describe("ValidateBaseUpdate", func() {
+		It("should reject storage class changes and shrinking volumes", func() {
+			oldBase := &gameserverv1alpha1.Base{
+				Persistence: gameserverv1alpha1.Persistence{
+					StorageConfig: gameserverv1alpha1.StorageConfig{Size: "20G", StorageClassName: "standard"},
+				},
+			}
+			base := &gameserverv1alpha1.Base{
+				Persistence: gameserverv1alpha1.Persistence{
+					StorageConfig: gameserverv1alpha1.StorageConfig{Size: "10G", StorageClassName: "premium"},
+				},
+			}
+
+			Expect(ValidateBaseUpdate(oldBase, base, specPath)).To(HaveLen(2))
+		})
+
+		It("should allow growing volumes", func() {
+			oldBase := &gameserverv1alpha1.Base{
+				Persistence: gameserverv1alpha1.Persistence{StorageConfig: gameserverv1alpha1.StorageConfig{Size: "10G"}},
+			}
+			base := &gameserverv1alpha1.Base{
+				Persistence: gameserverv1alpha1.Persistence{StorageConfig: gameserverv1alpha1.StorageConfig{Size: "20G"}},
+			}
+
+			Expect(ValidateBaseUpdate(oldBase, base, specPath)).To(BeEmpty())
+		})
+	})
+
+	Describe("GameServerTemplateValidator", func() {
+		It("should reject fields no game server could use", func() {
+			template := &gameserverv1alpha1.GameServerTemplate{}
+			template.Spec.Persistence.StorageConfig.Size = "lots"
+			template.Spec.Schedule.Restarts = "every morning"
+			template.Spec.Shutdown.WarningSeconds = []int32{60}
+
+			_, err := (&GameServerTemplateValidator{}).ValidateCreate(ctx, template)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.persistence.storageConfig.size"))
+			Expect(err.Error()).To(ContainSubstring("spec.schedule.restarts"))
+			Expect(err.Error()).NotTo(ContainSubstring("spec.rcon.passwordSecretRef"))
+		})
+	})
+
+	Describe("GameServerClassValidator", func() {
+		It("should reject invalid labels and annotations", func() {
+			class := &gameserverv1alpha1.GameServerClass{}
+			class.Spec.Scheduling.NodeSelector = map[string]string{"pool": "game servers"}
+			class.Spec.Exposure.ServiceAnnotations = map[string]string{"metallb universe/address-pool": "games"}
+
+			_, err := (&GameServerClassValidator{}).ValidateCreate(ctx, class)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.scheduling.nodeSelector"))
+			Expect(err.Error()).To(ContainSubstring("spec.exposure.serviceAnnotations"))
+
+			class.Spec.Scheduling.NodeSelector["pool"] = "games"
+			class.Spec.Exposure.ServiceAnnotations = map[string]string{"metallb.universe.tf/address-pool": "games"}
+			_, err = (&GameServerClassValidator{}).ValidateCreate(ctx, class)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("ValidateConfigPaths", func() {
+		It("should only accept absolute paths inside /data", func() {
+			config := map[string]string{
+				"/data/serverfiles/cfg/dayzserver.server.cfg": "",
+				"/etc/passwd":              "",
+				"/data/../etc/shadow":      "",
+				"data/relative.cfg":        "",
+				"/data/config-lgsm/x'; rm": "",
+			}
+
+			errs := ValidateConfigPaths(config, specPath.Child("config"))
+			Expect(errs).To(HaveLen(4))
+		})
+	})
+})
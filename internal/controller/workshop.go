@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// WorkshopContainerName is the init container downloading Steam Workshop mods
+	WorkshopContainerName = "workshop-mods"
+
+	// WorkshopImage runs SteamCMD in the Workshop init container
+	WorkshopImage = "steamcmd/steamcmd:latest"
+
+	// WorkshopDir is where SteamCMD keeps the downloaded Workshop items on the data volume
+	WorkshopDir = "/data/workshop"
+
+	// SteamUsernameKey and SteamPasswordKey are the keys of the Steam credentials Secret
+	SteamUsernameKey = "username"
+	SteamPasswordKey = "password"
+)
+
+// WorkshopItem is a Workshop item downloaded by the Workshop init container
+type WorkshopItem struct {
+	// ID is the id of the Workshop item
+	ID string
+
+	// Link is the path the item folder is linked to, e.g. /data/serverfiles/@CF
+	Link string
+}
+
+// GetWorkshopInitContainer returns the init container downloading items of the Workshop of appID with
+// SteamCMD, logged in with the Secret credentials. Each item is linked to its Link and its BattlEye
+// keys are copied to keysDir. The installed versions are reported in the termination message, see
+// ParseWorkshopVersions.
+func GetWorkshopInitContainer(appID int, items []WorkshopItem, keysDir string, credentials corev1.LocalObjectReference) corev1.Container {
+	content := fmt.Sprintf("%s/steamapps/workshop/content/%d", WorkshopDir, appID)
+	manifest := fmt.Sprintf("%s/steamapps/workshop/appworkshop_%d.acf", WorkshopDir, appID)
+
+	script := "set -eu\n"
+	script += fmt.Sprintf("steamcmd +force_install_dir %s +login \"$STEAM_USERNAME\" \"$STEAM_PASSWORD\"", WorkshopDir)
+	for _, item := range items {
+		script += fmt.Sprintf(" +workshop_download_item %d %s validate", appID, item.ID)
+	}
+	script += " +quit\n"
+
+	script += fmt.Sprintf("mkdir -p '%s'\n", keysDir)
+	for _, item := range items {
+		dir := content + "/" + item.ID
+		script += fmt.Sprintf("test -d '%s'\n", dir)
+		script += fmt.Sprintf("ln -sfn '%s' '%s'\n", dir, item.Link)
+		script += fmt.Sprintf("find '%s' -iname '*.bikey' -exec cp {} '%s/' \\;\n", dir, keysDir)
+		script += fmt.Sprintf("chown -h 1000:1000 '%s'\n", item.Link)
+	}
+	script += fmt.Sprintf("chown -R 1000:1000 '%s' '%s'\n", WorkshopDir, keysDir)
+
+	// Report <id>=<timeupdated> of each installed item
+	script += fmt.Sprintf(`awk -F'"' '/"WorkshopItemsInstalled"/{s=1} /"WorkshopItemDetails"/{s=0} `+
+		`s && NF==3 && $2 ~ /^[0-9]+$/ {id=$2} s && $2=="timeupdated" {print id "=" $4}' '%s' > /dev/termination-log`+"\n", manifest)
+
+	secretKey := func(key string) *corev1.EnvVarSource {
+		return &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: credentials, Key: key}}
+	}
+	return corev1.Container{
+		Name:    WorkshopContainerName,
+		Image:   WorkshopImage,
+		Command: []string{"sh", "-c"},
+		Args:    []string{script},
+		Env: []corev1.EnvVar{
+			{Name: "STEAM_USERNAME", ValueFrom: secretKey(SteamUsernameKey)},
+			{Name: "STEAM_PASSWORD", ValueFrom: secretKey(SteamPasswordKey)},
+		},
+		TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: DataVolumeName, MountPath: "/data"},
+		},
+	}
+}
+
+// ParseWorkshopVersions parses the termination message of the Workshop init container into the
+// publication time of each installed item
+func ParseWorkshopVersions(message string) map[string]time.Time {
+	versions := map[string]time.Time{}
+	for _, line := range strings.Split(message, "\n") {
+		id, updated, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		seconds, err := strconv.ParseInt(updated, 10, 64)
+		if err != nil {
+			continue
+		}
+		versions[id] = time.Unix(seconds, 0).UTC()
+	}
+	return versions
+}
+
+// InstalledWorkshopVersions returns the Workshop items installed in the running game pod of owner,
+// nil when no pod is running
+func InstalledWorkshopVersions(ctx context.Context, c client.Client, owner client.Object) (map[string]time.Time, error) {
+	pod, err := findRunningPod(ctx, c, owner)
+	if err != nil || pod == nil {
+		return nil, err
+	}
+	for _, status := range pod.Status.InitContainerStatuses {
+		if status.Name == WorkshopContainerName && status.State.Terminated != nil {
+			return ParseWorkshopVersions(status.State.Terminated.Message), nil
+		}
+	}
+	return map[string]time.Time{}, nil
+}
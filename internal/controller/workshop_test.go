@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Workshop", func() {
+	It("should download, link and report the Workshop items", func() {
+		container := GetWorkshopInitContainer(221100, []WorkshopItem{
+			{ID: "1559212036", Link: "/data/serverfiles/@CF"},
+			{ID: "1564026768", Link: "/data/serverfiles/@COT"},
+		}, "/data/serverfiles/keys", corev1.LocalObjectReference{Name: "steam"})
+
+		script := container.Args[0]
+		Expect(script).To(ContainSubstring(`+login "$STEAM_USERNAME" "$STEAM_PASSWORD" +workshop_download_item 221100 1559212036 validate +workshop_download_item 221100 1564026768 validate +quit`))
+		Expect(script).To(ContainSubstring("ln -sfn '/data/workshop/steamapps/workshop/content/221100/1564026768' '/data/serverfiles/@COT'"))
+		Expect(script).To(ContainSubstring("-iname '*.bikey' -exec cp {} '/data/serverfiles/keys/'"))
+		Expect(script).To(ContainSubstring("appworkshop_221100.acf' > /dev/termination-log"))
+		Expect(container.Env[0].ValueFrom.SecretKeyRef.Name).To(Equal("steam"))
+		Expect(container.Env[1].ValueFrom.SecretKeyRef.Key).To(Equal(SteamPasswordKey))
+	})
+
+	It("should parse the installed versions", func() {
+		versions := ParseWorkshopVersions("1559212036=1700000000\n1564026768=1710000000\nbroken\n")
+		Expect(versions).To(HaveLen(2))
+		Expect(versions["1559212036"]).To(Equal(time.Unix(1700000000, 0).UTC()))
+	})
+
+	It("should read the versions from the running pod", func() {
+		ctx := context.Background()
+		owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "modded", Namespace: "default"}}
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "modded-abc", Namespace: "default", Labels: map[string]string{"app": "modded"}},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				PodIP: "10.0.0.1",
+				InitContainerStatuses: []corev1.ContainerStatus{{
+					Name:  WorkshopContainerName,
+					State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: "1559212036=1700000000\n"}},
+				}},
+			},
+		}
+
+		versions, err := InstalledWorkshopVersions(ctx, fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(pod).Build(), owner)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(versions).To(HaveKey("1559212036"))
+
+		versions, err = InstalledWorkshopVersions(ctx, fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build(), owner)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(versions).To(BeNil())
+	})
+})
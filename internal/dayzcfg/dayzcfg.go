@@ -0,0 +1,231 @@
+// Package dayzcfg parses and renders DayZ server configs (server.cfg), written in the property and
+// class syntax Bohemia Interactive games read their configs in. Comments and blank lines are kept
+// above the statement following them, so a hand written config can be merged with generated
+// settings and rendered again.
+package dayzcfg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Kind is the type of a property value
+type Kind int
+
+const (
+	// KindNumber is an integer or decimal number
+	KindNumber Kind = iota
+	// KindString is a double quoted string
+	KindString
+	// KindArray is a list of values in braces, assigned to array properties such as motd[]
+	KindArray
+)
+
+// Value is the value of a property
+type Value struct {
+	Kind Kind
+
+	// Text is the literal of a number or the content of a string
+	Text string
+
+	// Items are the elements of an array
+	Items []Value
+}
+
+// String returns a string value
+func String(s string) Value {
+	return Value{Kind: KindString, Text: s}
+}
+
+// Int returns an integer value
+func Int(n int64) Value {
+	return Value{Kind: KindNumber, Text: strconv.FormatInt(n, 10)}
+}
+
+// Bool returns 1 for true and 0 for false, the way DayZ configs spell switches
+func Bool(b bool) Value {
+	if b {
+		return Int(1)
+	}
+	return Int(0)
+}
+
+// Number returns a number value from its literal, e.g. 0.5
+func Number(literal string) (Value, error) {
+	if !isNumber(literal) {
+		return Value{}, fmt.Errorf("invalid number %q", literal)
+	}
+	return Value{Kind: KindNumber, Text: literal}, nil
+}
+
+// Array returns an array value
+func Array(items ...Value) Value {
+	return Value{Kind: KindArray, Items: items}
+}
+
+// Render returns the value as written in a config
+func (v Value) Render() string {
+	switch v.Kind {
+	case KindString:
+		return `"` + strings.ReplaceAll(v.Text, `"`, `""`) + `"`
+	case KindArray:
+		items := make([]string, len(v.Items))
+		for i, item := range v.Items {
+			items[i] = item.Render()
+		}
+		return "{" + strings.Join(items, ", ") + "}"
+	default:
+		return v.Text
+	}
+}
+
+// Statement is a property or a class
+type Statement struct {
+	// Comments are the comment lines above the statement, blank lines are empty strings
+	Comments []string
+
+	// Name of the property or class, without the [] of array properties
+	Name string
+
+	// Value of a property
+	Value Value
+
+	// Base is the class a class inherits from, empty if none
+	Base string
+
+	// Body of a class, nil for properties
+	Body *Config
+}
+
+// Config is a config file or the body of a class
+type Config struct {
+	Statements []Statement
+
+	// Comments are the comment lines after the last statement
+	Comments []string
+}
+
+// Get returns the property at path, class names and the property name separated by dots such
+// as Missions.DayZ.template. Names are matched case insensitively, like the game does.
+func (c *Config) Get(path string) (Value, bool) {
+	names := strings.Split(path, ".")
+	for _, name := range names[:len(names)-1] {
+		i := c.index(name)
+		if i < 0 || c.Statements[i].Body == nil {
+			return Value{}, false
+		}
+		c = c.Statements[i].Body
+	}
+	i := c.index(names[len(names)-1])
+	if i < 0 || c.Statements[i].Body != nil {
+		return Value{}, false
+	}
+	return c.Statements[i].Value, true
+}
+
+// Set sets the property at path, see Get, replacing its value in place or adding it at the end of
+// its class. Missing classes are added.
+func (c *Config) Set(path string, value Value) {
+	names := strings.Split(path, ".")
+	for _, name := range names[:len(names)-1] {
+		i := c.index(name)
+		if i < 0 || c.Statements[i].Body == nil {
+			c.remove(i)
+			c.Statements = append(c.Statements, Statement{Name: name, Body: &Config{}})
+			i = len(c.Statements) - 1
+		}
+		c = c.Statements[i].Body
+	}
+	name := names[len(names)-1]
+	if i := c.index(name); i >= 0 && c.Statements[i].Body == nil {
+		c.Statements[i].Value = value
+		return
+	} else if i >= 0 {
+		c.remove(i)
+	}
+	c.Statements = append(c.Statements, Statement{Name: name, Value: value})
+}
+
+// Delete removes the property or class at path, see Get, and reports whether it was set
+func (c *Config) Delete(path string) bool {
+	names := strings.Split(path, ".")
+	for _, name := range names[:len(names)-1] {
+		i := c.index(name)
+		if i < 0 || c.Statements[i].Body == nil {
+			return false
+		}
+		c = c.Statements[i].Body
+	}
+	i := c.index(names[len(names)-1])
+	c.remove(i)
+	return i >= 0
+}
+
+// String renders the config
+func (c *Config) String() string {
+	var b strings.Builder
+	c.render(&b, "")
+	return b.String()
+}
+
+func (c *Config) render(b *strings.Builder, indent string) {
+	writeComments := func(comments []string) {
+		for _, comment := range comments {
+			if comment == "" {
+				b.WriteString("\n")
+			} else {
+				b.WriteString(indent + comment + "\n")
+			}
+		}
+	}
+
+	for _, s := range c.Statements {
+		writeComments(s.Comments)
+		if s.Body == nil {
+			name := s.Name
+			if s.Value.Kind == KindArray {
+				name += "[]"
+			}
+			fmt.Fprintf(b, "%s%s = %s;\n", indent, name, s.Value.Render())
+			continue
+		}
+		header := "class " + s.Name
+		if s.Base != "" {
+			header += ": " + s.Base
+		}
+		fmt.Fprintf(b, "%s%s\n%s{\n", indent, header, indent)
+		s.Body.render(b, indent+"    ")
+		b.WriteString(indent + "};\n")
+	}
+	writeComments(c.Comments)
+}
+
+// index returns the index of the statement called name, -1 if there is none
+func (c *Config) index(name string) int {
+	for i, s := range c.Statements {
+		if strings.EqualFold(s.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// remove removes the statement at index i and its comments, the blank lines above it are kept
+func (c *Config) remove(i int) {
+	if i < 0 {
+		return
+	}
+	var comments []string
+	for _, comment := range c.Statements[i].Comments {
+		if comment == "" {
+			comments = append(comments, comment)
+		}
+	}
+	c.Statements = append(c.Statements[:i], c.Statements[i+1:]...)
+	if i < len(c.Statements) {
+		c.Statements[i].Comments = append(comments, c.Statements[i].Comments...)
+	} else {
+		c.Comments = append(comments, c.Comments...)
+	}
+}
@@ -0,0 +1,163 @@
+package dayzcfg_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/templarfelix/gameserver-operator/internal/dayzcfg"
+)
+
+const serverCfg = `// Server name
+hostname = "My ""DayZ"" server";
+passwordAdmin = "secret";
+
+// INGAME SETTINGS
+maxPlayers = 60;
+serverTimeAcceleration = 0.5;
+motd[] = {
+    "Welcome",
+    "Have fun"
+};
+
+/* Mission to load */
+class Missions
+{
+    class DayZ
+    {
+        template = "dayzOffline.chernarusplus";
+    };
+};
+`
+
+// get returns the property at path, which must be set
+func get(config *dayzcfg.Config, path string) dayzcfg.Value {
+	value, ok := config.Get(path)
+	ExpectWithOffset(1, ok).To(BeTrue(), path)
+	return value
+}
+
+var _ = Describe("Parse", func() {
+	It("should read properties, arrays and classes", func() {
+		config, err := dayzcfg.Parse(serverCfg)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(get(config, "hostname")).To(Equal(dayzcfg.String(`My "DayZ" server`)))
+		Expect(get(config, "MAXPLAYERS")).To(Equal(dayzcfg.Int(60)))
+		Expect(dayzcfg.Number("0.5")).To(Equal(get(config, "serverTimeAcceleration")))
+		Expect(get(config, "motd")).To(Equal(dayzcfg.Array(dayzcfg.String("Welcome"), dayzcfg.String("Have fun"))))
+		Expect(get(config, "Missions.DayZ.template")).To(Equal(dayzcfg.String("dayzOffline.chernarusplus")))
+
+		_, ok := config.Get("Missions.DayZ")
+		Expect(ok).To(BeFalse())
+		_, ok = config.Get("instanceId")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should render a parsed config with its comments", func() {
+		config, err := dayzcfg.Parse(serverCfg)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(config.String()).To(Equal(`// Server name
+hostname = "My ""DayZ"" server";
+passwordAdmin = "secret";
+
+// INGAME SETTINGS
+maxPlayers = 60;
+serverTimeAcceleration = 0.5;
+motd[] = {"Welcome", "Have fun"};
+
+/* Mission to load */
+class Missions
+{
+    class DayZ
+    {
+        template = "dayzOffline.chernarusplus";
+    };
+};
+`))
+
+		again, err := dayzcfg.Parse(config.String())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(again).To(Equal(config))
+	})
+
+	DescribeTable("should reject configs the game cannot read",
+		func(src string, message string) {
+			_, err := dayzcfg.Parse(src)
+			Expect(err).To(MatchError(message))
+			Expect(err).To(BeAssignableToTypeOf(&dayzcfg.SyntaxError{}))
+		},
+		Entry("missing semicolon", "hostname = \"a\";\nmaxPlayers = 60\nsteamQueryPort = 27016;\n",
+			"line 2: expected ';' after the value of maxPlayers, found 'steamQueryPort'"),
+		Entry("missing semicolon at the end", "maxPlayers = 60",
+			"line 1: expected ';' after the value of maxPlayers, found end of file"),
+		Entry("missing value", "maxPlayers = ;",
+			"line 1: expected a number, string or array as the value of maxPlayers, found ';'"),
+		Entry("unquoted string", "hostname = My server;",
+			"line 1: expected a number, string or array as the value of hostname, found 'My'"),
+		Entry("unterminated string", "hostname = \"My server;\n",
+			"line 1: unterminated string"),
+		Entry("array without brackets", "motd = {\"a\"};",
+			"line 1: motd is set to an array, array properties are named motd[]"),
+		Entry("brackets without array", "motd[] = \"a\";",
+			"line 1: motd[] must be set to an array such as {1, 2}"),
+		Entry("duplicate property", "maxPlayers = 60;\n\nMaxPlayers = 40;",
+			"line 3: MaxPlayers is already set on line 1"),
+		Entry("unclosed class", "class Missions\n{\n    template = \"a\";\n",
+			"line 4: missing '};' closing class Missions"),
+		Entry("class without semicolon", "class Missions\n{\n}\nmaxPlayers = 1;",
+			"line 3: expected ';' after the closing brace of class Missions, found 'maxPlayers'"),
+		Entry("stray character", "maxPlayers = 60;\n#define X",
+			"line 2: unexpected character '#'"),
+	)
+})
+
+var _ = Describe("Config", func() {
+	var config *dayzcfg.Config
+
+	BeforeEach(func() {
+		var err error
+		config, err = dayzcfg.Parse(serverCfg)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should replace properties in place", func() {
+		config.Set("MaxPlayers", dayzcfg.Int(40))
+		config.Set("Missions.DayZ.template", dayzcfg.String("dayzOffline.enoch"))
+
+		Expect(config.String()).To(ContainSubstring("// INGAME SETTINGS\nmaxPlayers = 40;\n"))
+		Expect(config.String()).To(ContainSubstring(`template = "dayzOffline.enoch";`))
+	})
+
+	It("should add missing properties and classes at the end", func() {
+		config.Set("instanceId", dayzcfg.Int(2))
+		empty := &dayzcfg.Config{}
+		empty.Set("Missions.DayZ.template", dayzcfg.String("dayzOffline.enoch"))
+		empty.Set("disable3rdPerson", dayzcfg.Bool(true))
+
+		Expect(config.String()).To(HaveSuffix("    };\n};\ninstanceId = 2;\n"))
+		Expect(empty.String()).To(Equal(`class Missions
+{
+    class DayZ
+    {
+        template = "dayzOffline.enoch";
+    };
+};
+disable3rdPerson = 1;
+`))
+	})
+
+	It("should delete properties with their comments", func() {
+		Expect(config.Delete("hostname")).To(BeTrue())
+		Expect(config.Delete("passwordAdmin")).To(BeTrue())
+		Expect(config.Delete("password")).To(BeFalse())
+		Expect(config.Delete("Missions.Other.template")).To(BeFalse())
+
+		Expect(config.String()).To(HavePrefix("\n// INGAME SETTINGS\nmaxPlayers = 60;\n"))
+	})
+
+	It("should reject invalid numbers", func() {
+		_, err := dayzcfg.Number("1,5")
+		Expect(err).To(HaveOccurred())
+	})
+})
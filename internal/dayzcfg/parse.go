@@ -0,0 +1,348 @@
+package dayzcfg
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SyntaxError is returned by Parse for a config the game cannot read
+type SyntaxError struct {
+	// Line is the 1-based line of the error
+	Line int
+
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenName
+	tokenNumber
+	tokenString
+	tokenPunct
+	tokenComment
+	tokenBlank
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+// describe names the token in error messages
+func (t token) describe() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of file"
+	case tokenString:
+		return "string"
+	default:
+		return fmt.Sprintf("'%s'", t.text)
+	}
+}
+
+// byteOrderMark starts configs saved by some Windows editors
+const byteOrderMark = "\uFEFF"
+
+var (
+	namePattern   = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*`)
+	numberPattern = regexp.MustCompile(`^-?(?:[0-9]+(?:\.[0-9]*)?|\.[0-9]+)(?:[eE][-+]?[0-9]+)?`)
+)
+
+// isNumber reports whether literal is a number literal
+func isNumber(literal string) bool {
+	return numberPattern.FindString(literal) == literal && literal != ""
+}
+
+// lex splits src into tokens. A blank token is emitted where blank lines separate tokens.
+func lex(src string) ([]token, error) {
+	var tokens []token
+	line, lastLine := 1, 0
+	emit := func(kind tokenKind, text string, startLine int) {
+		if lastLine > 0 && startLine > lastLine+1 {
+			tokens = append(tokens, token{kind: tokenBlank, line: startLine})
+		}
+		tokens = append(tokens, token{kind: kind, text: text, line: startLine})
+		lastLine = line
+	}
+
+	for i := 0; i < len(src); {
+		rest := src[i:]
+		switch c := src[i]; {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case strings.HasPrefix(rest, byteOrderMark):
+			i += len(byteOrderMark)
+		case strings.HasPrefix(rest, "//"):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			emit(tokenComment, strings.TrimRight(rest[:end], " \t\r"), line)
+			i += end
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest, "*/")
+			if end < 0 {
+				return nil, &SyntaxError{Line: line, Msg: "unterminated comment"}
+			}
+			start := line
+			line += strings.Count(rest[:end], "\n")
+			emit(tokenComment, rest[:end+2], start)
+			i += end + 2
+		case c == '"':
+			var b strings.Builder
+			j := 1
+			for {
+				if j >= len(rest) || rest[j] == '\n' {
+					return nil, &SyntaxError{Line: line, Msg: "unterminated string"}
+				}
+				if rest[j] == '"' {
+					// A doubled quote is a quote inside the string
+					if j+1 < len(rest) && rest[j+1] == '"' {
+						b.WriteByte('"')
+						j += 2
+						continue
+					}
+					break
+				}
+				b.WriteByte(rest[j])
+				j++
+			}
+			emit(tokenString, b.String(), line)
+			i += j + 1
+		case strings.ContainsRune("=;{}[],:", rune(c)):
+			emit(tokenPunct, string(c), line)
+			i++
+		default:
+			if number := numberPattern.FindString(rest); number != "" {
+				emit(tokenNumber, number, line)
+				i += len(number)
+			} else if name := namePattern.FindString(rest); name != "" {
+				emit(tokenName, name, line)
+				i += len(name)
+			} else {
+				return nil, &SyntaxError{Line: line, Msg: fmt.Sprintf("unexpected character %q", c)}
+			}
+		}
+	}
+	tokens = append(tokens, token{kind: tokenEOF, line: line})
+	return tokens, nil
+}
+
+// parser reads statements from tokens
+type parser struct {
+	tokens []token
+	pos    int
+
+	// line of the last consumed token
+	line int
+}
+
+// Parse parses a config. Properties and classes set twice in the same class are rejected, the
+// game silently uses one of them.
+func Parse(src string) (*Config, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	config, err := p.parseBody("")
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// comments consumes comment and blank tokens
+func (p *parser) comments() []string {
+	var comments []string
+	for {
+		switch t := p.tokens[p.pos]; t.kind {
+		case tokenComment:
+			comments = append(comments, t.text)
+		case tokenBlank:
+			comments = append(comments, "")
+		default:
+			return comments
+		}
+		p.pos++
+	}
+}
+
+// next returns the next token which is not a comment
+func (p *parser) next() token {
+	p.comments()
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+		p.line = t.line
+	}
+	return t
+}
+
+// peek returns the next token which is not a comment without consuming it
+func (p *parser) peek() token {
+	p.comments()
+	return p.tokens[p.pos]
+}
+
+// expect consumes the punctuation punct or fails with context. A missing punctuation is reported on
+// the line of the token it should follow.
+func (p *parser) expect(punct, context string) error {
+	line := p.line
+	if t := p.next(); t.kind != tokenPunct || t.text != punct {
+		return &SyntaxError{Line: line, Msg: fmt.Sprintf("expected '%s' %s, found %s", punct, context, t.describe())}
+	}
+	return nil
+}
+
+// parseBody parses statements until the closing brace of class, or the end of file for the top level
+func (p *parser) parseBody(class string) (*Config, error) {
+	config := &Config{}
+	lines := map[string]int{}
+	for {
+		comments := p.comments()
+		t := p.tokens[p.pos]
+		switch {
+		case t.kind == tokenEOF && class == "":
+			config.Comments = comments
+			return config, nil
+		case t.kind == tokenEOF:
+			return nil, &SyntaxError{Line: t.line, Msg: fmt.Sprintf("missing '};' closing class %s", class)}
+		case t.kind == tokenPunct && t.text == "}" && class != "":
+			p.next()
+			config.Comments = comments
+			return config, nil
+		case t.kind != tokenName:
+			return nil, &SyntaxError{Line: t.line, Msg: fmt.Sprintf("expected a property or class, found %s", t.describe())}
+		}
+
+		var statement Statement
+		var err error
+		if t.text == "class" {
+			statement, err = p.parseClass()
+		} else {
+			statement, err = p.parseProperty()
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		key := strings.ToLower(statement.Name)
+		if line, ok := lines[key]; ok {
+			return nil, &SyntaxError{Line: t.line, Msg: fmt.Sprintf("%s is already set on line %d", statement.Name, line)}
+		}
+		lines[key] = t.line
+		statement.Comments = comments
+		config.Statements = append(config.Statements, statement)
+	}
+}
+
+// parseClass parses class Name[: Base] { ... };
+func (p *parser) parseClass() (Statement, error) {
+	p.next()
+	name := p.next()
+	if name.kind != tokenName {
+		return Statement{}, &SyntaxError{Line: name.line, Msg: fmt.Sprintf("expected a class name, found %s", name.describe())}
+	}
+	statement := Statement{Name: name.text}
+
+	if t := p.peek(); t.kind == tokenPunct && t.text == ":" {
+		p.next()
+		base := p.next()
+		if base.kind != tokenName {
+			return Statement{}, &SyntaxError{Line: base.line, Msg: fmt.Sprintf("expected a base class name, found %s", base.describe())}
+		}
+		statement.Base = base.text
+	}
+
+	if err := p.expect("{", "after class "+name.text); err != nil {
+		return Statement{}, err
+	}
+	body, err := p.parseBody(name.text)
+	if err != nil {
+		return Statement{}, err
+	}
+	statement.Body = body
+	if err := p.expect(";", "after the closing brace of class "+name.text); err != nil {
+		return Statement{}, err
+	}
+	return statement, nil
+}
+
+// parseProperty parses name = value; and name[] = {...};
+func (p *parser) parseProperty() (Statement, error) {
+	name := p.next()
+	isArray := false
+	if t := p.peek(); t.kind == tokenPunct && t.text == "[" {
+		p.next()
+		if err := p.expect("]", "after "+name.text+"["); err != nil {
+			return Statement{}, err
+		}
+		isArray = true
+	}
+
+	if err := p.expect("=", "after "+name.text); err != nil {
+		return Statement{}, err
+	}
+	valueLine := p.peek().line
+	value, err := p.parseValue(name.text)
+	if err != nil {
+		return Statement{}, err
+	}
+	switch {
+	case isArray && value.Kind != KindArray:
+		return Statement{}, &SyntaxError{Line: valueLine, Msg: fmt.Sprintf("%s[] must be set to an array such as {1, 2}", name.text)}
+	case !isArray && value.Kind == KindArray:
+		return Statement{}, &SyntaxError{Line: valueLine, Msg: fmt.Sprintf("%s is set to an array, array properties are named %s[]", name.text, name.text)}
+	}
+
+	if err := p.expect(";", "after the value of "+name.text); err != nil {
+		return Statement{}, err
+	}
+	return Statement{Name: name.text, Value: value}, nil
+}
+
+// parseValue parses a number, string or array value of property
+func (p *parser) parseValue(property string) (Value, error) {
+	t := p.next()
+	switch {
+	case t.kind == tokenNumber:
+		return Value{Kind: KindNumber, Text: t.text}, nil
+	case t.kind == tokenString:
+		return String(t.text), nil
+	case t.kind == tokenPunct && t.text == "{":
+		array := Array()
+		for {
+			if next := p.peek(); next.kind == tokenPunct && next.text == "}" {
+				p.next()
+				return array, nil
+			}
+			item, err := p.parseValue(property)
+			if err != nil {
+				return Value{}, err
+			}
+			array.Items = append(array.Items, item)
+
+			next := p.next()
+			if next.kind == tokenPunct && next.text == "}" {
+				return array, nil
+			}
+			if next.kind != tokenPunct || next.text != "," {
+				return Value{}, &SyntaxError{Line: next.line, Msg: fmt.Sprintf("expected ',' or '}' in the array of %s, found %s", property, next.describe())}
+			}
+		}
+	default:
+		return Value{}, &SyntaxError{Line: t.line, Msg: fmt.Sprintf("expected a number, string or array as the value of %s, found %s", property, t.describe())}
+	}
+}
@@ -0,0 +1,14 @@
+package dayzcfg_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestDayzcfg(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "DayZ Config Suite")
+}
@@ -0,0 +1,174 @@
+// Package economy merges patches into the Central Economy files of DayZ missions, such as
+// db/types.xml which holds how many of each item spawn and for how long. Everything the patches do
+// not change is written back like it was read, including comments and indentation.
+package economy
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Type defaults of types added by a patch, the values DayZ uses for most loot
+const (
+	DefaultLifetime = 14400
+	DefaultQuantMin = -1
+	DefaultQuantMax = -1
+	DefaultCost     = 100
+)
+
+// TypePatch overrides the settings of a type in types.xml, or adds it when the mission has no type of
+// that name. Unset fields are kept, or take the type defaults for added types.
+type TypePatch struct {
+	// Name is the class name of the item, matched case insensitively like the game does
+	Name string `json:"name"`
+
+	// Nominal is the number of items the economy keeps on the map
+	Nominal *int32 `json:"nominal,omitempty"`
+
+	// Min is the number of items below which the economy respawns them
+	Min *int32 `json:"min,omitempty"`
+
+	// Lifetime is how long an item stays on the ground, in seconds
+	Lifetime *int32 `json:"lifetime,omitempty"`
+
+	// Restock is the time between two respawns of the item, in seconds
+	Restock *int32 `json:"restock,omitempty"`
+
+	// Category replaces the category of the item, e.g. weapons
+	Category string `json:"category,omitempty"`
+
+	// Usages replace the usages of the item, the kinds of places it spawns at
+	Usages []string `json:"usages,omitempty"`
+
+	// Values replace the values of the item, the map tiers it spawns in
+	Values []string `json:"values,omitempty"`
+}
+
+// Result counts the types changed by a merge
+type Result struct {
+	Updated int
+	Added   int
+}
+
+func (r Result) String() string {
+	return fmt.Sprintf("%d types updated, %d added", r.Updated, r.Added)
+}
+
+// MergeTypes applies patches to the types.xml document src. It returns a *MalformedError for a
+// document that is not well formed XML.
+func MergeTypes(src []byte, patches []TypePatch) ([]byte, Result, error) {
+	var result Result
+	doc, err := parseXML(src)
+	if err != nil {
+		return nil, result, err
+	}
+	types := doc.elements("types")
+	if len(types) != 1 {
+		return nil, result, fmt.Errorf("expected a single <types> root element")
+	}
+	root := types[0]
+
+	existing := map[string]*node{}
+	for _, t := range root.elements("type") {
+		existing[strings.ToLower(t.getAttr("name"))] = t
+	}
+
+	typeIndent := root.indent("", "    ")
+	for _, patch := range patches {
+		t, ok := existing[strings.ToLower(patch.Name)]
+		if ok {
+			result.Updated++
+		} else {
+			t = newType(patch.Name, typeIndent)
+			existing[strings.ToLower(patch.Name)] = t
+			root.appendElement(t, typeIndent, "")
+			result.Added++
+		}
+		applyTypePatch(t, patch, typeIndent)
+	}
+	return doc.render(), result, nil
+}
+
+// newType returns a type element with the type defaults, indented at indent
+func newType(name, indent string) *node {
+	fieldIndent := indent + "    "
+	t := &node{name: xml.Name{Local: "type"}, attr: []xml.Attr{{Name: xml.Name{Local: "name"}, Value: name}}}
+	for _, field := range []struct {
+		name  string
+		value int
+	}{
+		{"nominal", 0},
+		{"lifetime", DefaultLifetime},
+		{"restock", 0},
+		{"min", 0},
+		{"quantmin", DefaultQuantMin},
+		{"quantmax", DefaultQuantMax},
+		{"cost", DefaultCost},
+	} {
+		setField(t, field.name, strconv.Itoa(field.value), indent, fieldIndent)
+	}
+	flags := &node{name: xml.Name{Local: "flags"}}
+	for _, flag := range []string{"count_in_cargo", "count_in_hoarder", "count_in_map", "count_in_player", "crafted", "deloot"} {
+		value := "0"
+		if flag == "count_in_map" {
+			value = "1"
+		}
+		flags.attr = append(flags.attr, xml.Attr{Name: xml.Name{Local: flag}, Value: value})
+	}
+	t.appendElement(flags, fieldIndent, indent)
+	return t
+}
+
+// applyTypePatch sets the fields of patch on the type element t, indented at indent
+func applyTypePatch(t *node, patch TypePatch, indent string) {
+	fieldIndent := t.indent(indent, "    ")
+	for _, field := range []struct {
+		name  string
+		value *int32
+	}{
+		{"nominal", patch.Nominal},
+		{"lifetime", patch.Lifetime},
+		{"restock", patch.Restock},
+		{"min", patch.Min},
+	} {
+		if field.value != nil {
+			setField(t, field.name, strconv.Itoa(int(*field.value)), indent, fieldIndent)
+		}
+	}
+
+	if patch.Category != "" {
+		setNamed(t, "category", []string{patch.Category}, indent, fieldIndent)
+	}
+	if patch.Usages != nil {
+		setNamed(t, "usage", patch.Usages, indent, fieldIndent)
+	}
+	if patch.Values != nil {
+		setNamed(t, "value", patch.Values, indent, fieldIndent)
+	}
+}
+
+// setField sets the text of the child element name of t, adding it when missing
+func setField(t *node, name, value, indent, fieldIndent string) {
+	if fields := t.elements(name); len(fields) > 0 {
+		fields[0].setText(value)
+		return
+	}
+	field := &node{name: xml.Name{Local: name}}
+	field.setText(value)
+	t.appendElement(field, fieldIndent, indent)
+}
+
+// setNamed replaces the child elements name of t by one <name name="..."/> per value
+func setNamed(t *node, name string, values []string, indent, fieldIndent string) {
+	for _, element := range t.elements(name) {
+		t.removeElement(element)
+	}
+	for _, value := range values {
+		t.appendElement(&node{
+			name: xml.Name{Local: name},
+			attr: []xml.Attr{{Name: xml.Name{Local: "name"}, Value: value}},
+		}, fieldIndent, indent)
+	}
+}
@@ -0,0 +1,187 @@
+package economy_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/templarfelix/gameserver-operator/internal/economy"
+)
+
+const typesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes" ?>
+<types>
+    <!-- Weapons -->
+    <type name="AKM">
+        <nominal>10</nominal>
+        <lifetime>28800</lifetime>
+        <restock>0</restock>
+        <min>5</min>
+        <quantmin>-1</quantmin>
+        <quantmax>-1</quantmax>
+        <cost>100</cost>
+        <flags count_in_cargo="0" count_in_hoarder="0" count_in_map="1" count_in_player="0" crafted="0" deloot="0"/>
+        <category name="weapons"/>
+        <usage name="Military"/>
+        <value name="Tier3"/>
+        <value name="Tier4"/>
+    </type>
+    <type name="Apple">
+        <nominal>40</nominal>
+        <min>20</min>
+    </type>
+</types>
+`
+
+func int32Ptr(i int32) *int32 { return &i }
+
+var _ = Describe("MergeTypes", func() {
+	It("should write an unpatched file like it was read", func() {
+		merged, result, err := economy.MergeTypes([]byte(typesXML), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(merged)).To(Equal(typesXML))
+		Expect(result).To(Equal(economy.Result{}))
+	})
+
+	It("should override the fields of existing types", func() {
+		merged, result, err := economy.MergeTypes([]byte(typesXML), []economy.TypePatch{
+			{Name: "akm", Nominal: int32Ptr(2), Min: int32Ptr(1), Values: []string{"Tier4"}},
+			{Name: "Apple", Restock: int32Ptr(600), Usages: []string{"Farm", "Village"}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(economy.Result{Updated: 2}))
+		Expect(string(merged)).To(Equal(`<?xml version="1.0" encoding="UTF-8" standalone="yes" ?>
+<types>
+    <!-- Weapons -->
+    <type name="AKM">
+        <nominal>2</nominal>
+        <lifetime>28800</lifetime>
+        <restock>0</restock>
+        <min>1</min>
+        <quantmin>-1</quantmin>
+        <quantmax>-1</quantmax>
+        <cost>100</cost>
+        <flags count_in_cargo="0" count_in_hoarder="0" count_in_map="1" count_in_player="0" crafted="0" deloot="0"/>
+        <category name="weapons"/>
+        <usage name="Military"/>
+        <value name="Tier4"/>
+    </type>
+    <type name="Apple">
+        <nominal>40</nominal>
+        <min>20</min>
+        <restock>600</restock>
+        <usage name="Farm"/>
+        <usage name="Village"/>
+    </type>
+</types>
+`))
+	})
+
+	It("should add missing types with the type defaults", func() {
+		merged, result, err := economy.MergeTypes([]byte(typesXML), []economy.TypePatch{
+			{Name: "MyMod_Rifle", Nominal: int32Ptr(3), Category: "weapons"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(economy.Result{Added: 1}))
+		Expect(string(merged)).To(HaveSuffix(`    </type>
+    <type name="MyMod_Rifle">
+        <nominal>3</nominal>
+        <lifetime>14400</lifetime>
+        <restock>0</restock>
+        <min>0</min>
+        <quantmin>-1</quantmin>
+        <quantmax>-1</quantmax>
+        <cost>100</cost>
+        <flags count_in_cargo="0" count_in_hoarder="0" count_in_map="1" count_in_player="0" crafted="0" deloot="0"/>
+        <category name="weapons"/>
+    </type>
+</types>
+`))
+
+		// Merging again changes nothing
+		again, result, err := economy.MergeTypes(merged, []economy.TypePatch{
+			{Name: "MyMod_Rifle", Nominal: int32Ptr(3), Category: "weapons"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(economy.Result{Updated: 1}))
+		Expect(string(again)).To(Equal(string(merged)))
+	})
+
+	DescribeTable("should reject malformed XML with its line",
+		func(src string, line int) {
+			_, _, err := economy.MergeTypes([]byte(src), nil)
+			var malformed *economy.MalformedError
+			Expect(err).To(BeAssignableToTypeOf(malformed))
+			Expect(err.(*economy.MalformedError).Line).To(Equal(line))
+		},
+		Entry("mismatched end tag", "<types>\n  <type name=\"AKM\">\n    <nominal>10</min>\n  </type>\n</types>\n", 3),
+		Entry("unclosed element", "<types>\n  <type name=\"AKM\">\n</types>\n", 3),
+		Entry("unquoted attribute", "<types>\n  <type name=AKM>\n  </type>\n</types>\n", 2),
+		Entry("missing root end tag", "<types>\n  <type name=\"AKM\"/>\n", 3),
+	)
+
+	It("should require a types root element", func() {
+		_, _, err := economy.MergeTypes([]byte("<events></events>"), nil)
+		Expect(err).To(MatchError(ContainSubstring("<types>")))
+	})
+})
+
+var _ = Describe("MergeTypesFile", func() {
+	var path string
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "types.xml")
+	})
+
+	It("should replace the file with the merged types", func() {
+		Expect(os.WriteFile(path, []byte(typesXML), 0o640)).To(Succeed())
+
+		result, err := economy.MergeTypesFile(path, []economy.TypePatch{{Name: "AKM", Nominal: int32Ptr(2)}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(economy.Result{Updated: 1}))
+
+		merged, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(merged)).To(ContainSubstring("<nominal>2</nominal>"))
+		info, err := os.Stat(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0o640)))
+		Expect(os.ReadDir(filepath.Dir(path))).To(HaveLen(1))
+	})
+
+	It("should keep a malformed file", func() {
+		Expect(os.WriteFile(path, []byte("<types>"), 0o644)).To(Succeed())
+
+		_, err := economy.MergeTypesFile(path, []economy.TypePatch{{Name: "AKM", Nominal: int32Ptr(2)}})
+		reason, message := economy.Report(path, economy.Result{}, err)
+		Expect(reason).To(Equal(economy.ReasonMalformedXML))
+		Expect(message).To(Equal(path + ": line 1: element <types> is not closed"))
+		Expect(os.ReadFile(path)).To(Equal([]byte("<types>")))
+	})
+
+	It("should report a mission which is not installed yet", func() {
+		_, err := economy.MergeTypesFile(path, nil)
+		reason, _ := economy.Report(path, economy.Result{}, err)
+		Expect(reason).To(Equal(economy.ReasonMissionNotInstalled))
+	})
+})
+
+var _ = Describe("Report", func() {
+	It("should round trip through the termination message", func() {
+		reason, message := economy.Report("/types.xml", economy.Result{Updated: 2, Added: 1}, nil)
+		reason, message = economy.ParseReport(economy.FormatReport(reason, message))
+		Expect(reason).To(Equal(economy.ReasonMerged))
+		Expect(message).To(Equal("/types.xml: 2 types updated, 1 added"))
+	})
+
+	It("should report other errors and foreign messages as failed merges", func() {
+		reason, _ := economy.Report("/types.xml", economy.Result{}, errors.New("permission denied"))
+		Expect(reason).To(Equal(economy.ReasonMergeFailed))
+
+		reason, message := economy.ParseReport("exec format error\n")
+		Expect(reason).To(Equal(economy.ReasonMergeFailed))
+		Expect(message).To(Equal("exec format error"))
+	})
+})
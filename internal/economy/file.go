@@ -0,0 +1,99 @@
+package economy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Reasons reported by the economy init container, see Report
+const (
+	// ReasonMerged means the patches were merged
+	ReasonMerged = "Merged"
+	// ReasonMissionNotInstalled means the mission files do not exist yet, LinuxGSM installs them on the
+	// first start of the server and the patches are merged on the next one
+	ReasonMissionNotInstalled = "MissionNotInstalled"
+	// ReasonMalformedXML means a file of the mission is not well formed XML
+	ReasonMalformedXML = "MalformedXML"
+	// ReasonMalformedJSON means a file of the mission is not valid JSON
+	ReasonMalformedJSON = "MalformedJSON"
+	// ReasonMergeFailed means the patches could not be merged for another reason
+	ReasonMergeFailed = "MergeFailed"
+)
+
+// MergeTypesFile applies patches to the types.xml file at path. The file is replaced atomically and
+// left untouched on error.
+func MergeTypesFile(path string, patches []TypePatch) (Result, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return Result{}, err
+	}
+	merged, result, err := MergeTypes(src, patches)
+	if err != nil {
+		return result, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return result, err
+	}
+	return result, writeFile(path, merged, info.Mode())
+}
+
+// writeFile replaces the file at path with data through a temporary file renamed over it
+func writeFile(path string, data []byte, mode os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Report returns the reason and the message of the outcome of a merge of path, such as a Result or a
+// GameplayResult, err is the error of the merge or nil
+func Report(path string, result fmt.Stringer, err error) (reason, message string) {
+	var malformed *MalformedError
+	switch {
+	case err == nil:
+		return ReasonMerged, fmt.Sprintf("%s: %s", path, result)
+	case errors.Is(err, os.ErrNotExist):
+		return ReasonMissionNotInstalled, fmt.Sprintf("%s does not exist yet, it is patched on the next start", path)
+	case errors.As(err, &malformed) && malformed.Format == FormatJSON:
+		return ReasonMalformedJSON, fmt.Sprintf("%s: %v", path, err)
+	case errors.As(err, &malformed):
+		return ReasonMalformedXML, fmt.Sprintf("%s: %v", path, err)
+	default:
+		return ReasonMergeFailed, fmt.Sprintf("%s: %v", path, err)
+	}
+}
+
+// FormatReport joins the reason and the message of a report into a termination message
+func FormatReport(reason, message string) string {
+	return reason + ": " + message
+}
+
+// ParseReport splits a termination message written with FormatReport, a message in another format
+// is reported as ReasonMergeFailed
+func ParseReport(termination string) (reason, message string) {
+	reason, message, ok := strings.Cut(strings.TrimSpace(termination), ": ")
+	switch reason {
+	case ReasonMerged, ReasonMissionNotInstalled, ReasonMalformedXML, ReasonMalformedJSON, ReasonMergeFailed:
+		if ok {
+			return reason, message
+		}
+	}
+	return ReasonMergeFailed, strings.TrimSpace(termination)
+}
@@ -0,0 +1,94 @@
+package economy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+)
+
+// GameplayFile is the gameplay settings file of a mission, read by the server when
+// enableCfgGameplayFile is set in server.cfg
+const GameplayFile = "cfggameplay.json"
+
+// GameplayResult counts the operations applied to cfggameplay.json
+type GameplayResult struct {
+	Operations int
+}
+
+func (r GameplayResult) String() string {
+	return fmt.Sprintf("%d gameplay operations applied", r.Operations)
+}
+
+// PatchGameplay applies the JSON patch (RFC 6902) patch to the cfggameplay.json document src, keeping
+// the order of its keys and its indentation. Objects missing on the path of an add operation are
+// added. It returns a *MalformedError for a document that is not valid JSON.
+func PatchGameplay(src, patch []byte) ([]byte, GameplayResult, error) {
+	src = bytes.TrimPrefix(src, []byte("\ufeff"))
+	var raw json.RawMessage
+	if err := json.Unmarshal(src, &raw); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			line := bytes.Count(src[:syntaxErr.Offset], []byte("\n")) + 1
+			return nil, GameplayResult{}, &MalformedError{Format: FormatJSON, Line: line, Msg: syntaxErr.Error()}
+		}
+		return nil, GameplayResult{}, &MalformedError{Format: FormatJSON, Line: 1, Msg: err.Error()}
+	}
+
+	operations, err := jsonpatch.DecodePatch(patch)
+	if err != nil {
+		return nil, GameplayResult{}, fmt.Errorf("invalid patch: %w", err)
+	}
+	options := jsonpatch.NewApplyOptions()
+	options.EnsurePathExistsOnAdd = true
+	patched, err := operations.ApplyIndentWithOptions(src, jsonIndent(src), options)
+	if err != nil {
+		return nil, GameplayResult{}, err
+	}
+	return append(patched, '\n'), GameplayResult{Operations: len(operations)}, nil
+}
+
+// PatchGameplayFile applies patch to the cfggameplay.json file at path, see PatchGameplay. The file is
+// created from an empty object when its mission has none, and it is replaced atomically.
+func PatchGameplayFile(path string, patch []byte) (GameplayResult, error) {
+	mode := os.FileMode(0o644)
+	src, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		// A missing mission is reported as not installed, only the file is created
+		if _, err := os.Stat(filepath.Dir(path)); err != nil {
+			return GameplayResult{}, err
+		}
+		src = []byte("{}")
+	case err != nil:
+		return GameplayResult{}, err
+	default:
+		info, err := os.Stat(path)
+		if err != nil {
+			return GameplayResult{}, err
+		}
+		mode = info.Mode()
+	}
+
+	patched, result, err := PatchGameplay(src, patch)
+	if err != nil {
+		return result, err
+	}
+	return result, writeFile(path, patched, mode)
+}
+
+// jsonIndent returns the indentation of the first indented line of src, four spaces when src is not
+// indented
+func jsonIndent(src []byte) string {
+	for _, line := range bytes.Split(src, []byte("\n"))[1:] {
+		trimmed := bytes.TrimLeft(line, " \t")
+		if len(trimmed) > 0 && len(trimmed) < len(line) {
+			return string(line[:len(line)-len(trimmed)])
+		}
+	}
+	return "    "
+}
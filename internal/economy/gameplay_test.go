@@ -0,0 +1,98 @@
+package economy_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/templarfelix/gameserver-operator/internal/economy"
+)
+
+const gameplayJSON = `{
+	"version": 123,
+	"GeneralData": {
+		"disableBaseDamage": false,
+		"disableContainerDamage": false
+	},
+	"PlayerData": {
+		"spawnGearPresetFiles": []
+	}
+}
+`
+
+var _ = Describe("PatchGameplay", func() {
+	It("should apply the operations keeping the key order and the indentation", func() {
+		patched, result, err := economy.PatchGameplay([]byte(gameplayJSON), []byte(`[
+			{"op": "replace", "path": "/GeneralData/disableBaseDamage", "value": true},
+			{"op": "add", "path": "/PlayerData/spawnGearPresetFiles/-", "value": "custom/gear.json"}
+		]`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(economy.GameplayResult{Operations: 2}))
+		Expect(string(patched)).To(Equal(`{
+	"version": 123,
+	"GeneralData": {
+		"disableBaseDamage": true,
+		"disableContainerDamage": false
+	},
+	"PlayerData": {
+		"spawnGearPresetFiles": [
+			"custom/gear.json"
+		]
+	}
+}
+`))
+	})
+
+	It("should add the objects missing on the path of an add operation", func() {
+		patched, _, err := economy.PatchGameplay([]byte("{}"), []byte(`[{"op": "add", "path": "/WorldsData/lightingConfig", "value": 1}]`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(patched)).To(Equal("{\n    \"WorldsData\": {\n        \"lightingConfig\": 1\n    }\n}\n"))
+	})
+
+	It("should fail a test operation which does not match", func() {
+		_, _, err := economy.PatchGameplay([]byte(gameplayJSON), []byte(`[{"op": "test", "path": "/version", "value": 122}]`))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject malformed JSON with its line", func() {
+		_, _, err := economy.PatchGameplay([]byte("{\n\t\"version\": 123,\n}\n"), []byte("[]"))
+		var malformed *economy.MalformedError
+		Expect(err).To(BeAssignableToTypeOf(malformed))
+		Expect(err.(*economy.MalformedError).Line).To(Equal(3))
+		Expect(err.(*economy.MalformedError).Format).To(Equal(economy.FormatJSON))
+	})
+})
+
+var _ = Describe("PatchGameplayFile", func() {
+	var path string
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), economy.GameplayFile)
+	})
+
+	It("should create the file when the mission has none", func() {
+		result, err := economy.PatchGameplayFile(path, []byte(`[{"op": "add", "path": "/version", "value": 123}]`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(economy.GameplayResult{Operations: 1}))
+		Expect(os.ReadFile(path)).To(Equal([]byte("{\n    \"version\": 123\n}\n")))
+	})
+
+	It("should report a mission which is not installed yet", func() {
+		missing := filepath.Join(filepath.Dir(path), "dayzOffline.enoch", economy.GameplayFile)
+		_, err := economy.PatchGameplayFile(missing, []byte("[]"))
+		reason, _ := economy.Report(missing, economy.GameplayResult{}, err)
+		Expect(reason).To(Equal(economy.ReasonMissionNotInstalled))
+	})
+
+	It("should keep a malformed file", func() {
+		Expect(os.WriteFile(path, []byte("{"), 0o644)).To(Succeed())
+
+		_, err := economy.PatchGameplayFile(path, []byte("[]"))
+		reason, message := economy.Report(path, economy.GameplayResult{}, err)
+		Expect(reason).To(Equal(economy.ReasonMalformedJSON))
+		Expect(message).To(HavePrefix(path + ": line 1: "))
+		Expect(os.ReadFile(path)).To(Equal([]byte("{")))
+	})
+})
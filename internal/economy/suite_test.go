@@ -0,0 +1,14 @@
+package economy_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestEconomy(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Economy Suite")
+}
@@ -0,0 +1,215 @@
+package economy
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Formats of the files a MalformedError is returned for
+const (
+	FormatXML  = "XML"
+	FormatJSON = "JSON"
+)
+
+// MalformedError is returned for a file that is not well formed XML or not valid JSON
+type MalformedError struct {
+	// Format is FormatXML or FormatJSON
+	Format string
+
+	// Line is the 1-based line of the error
+	Line int
+
+	Msg string
+}
+
+func (e *MalformedError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// node is an element or, when token is set, the text, comment, processing instruction or
+// directive between elements. The tree keeps everything but the formatting inside tags, so a
+// file is rendered like it was read.
+type node struct {
+	name     xml.Name
+	attr     []xml.Attr
+	children []*node
+	token    xml.Token
+}
+
+// parseXML reads a document into a root node holding its top level nodes
+func parseXML(src []byte) (*node, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(src))
+	root := &node{}
+	stack := []*node{root}
+	for {
+		line, _ := decoder.InputPos()
+		t, err := decoder.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var syntaxErr *xml.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				return nil, &MalformedError{Format: FormatXML, Line: syntaxErr.Line, Msg: syntaxErr.Msg}
+			}
+			return nil, &MalformedError{Format: FormatXML, Line: line, Msg: err.Error()}
+		}
+
+		parent := stack[len(stack)-1]
+		switch t := t.(type) {
+		case xml.StartElement:
+			element := &node{name: t.Name, attr: t.Attr}
+			parent.children = append(parent.children, element)
+			stack = append(stack, element)
+		case xml.EndElement:
+			if len(stack) == 1 || parent.name != t.Name {
+				return nil, &MalformedError{Format: FormatXML, Line: line, Msg: fmt.Sprintf("unexpected end element </%s>", qualifiedName(t.Name))}
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			parent.children = append(parent.children, &node{token: xml.CopyToken(t)})
+		}
+	}
+	if len(stack) > 1 {
+		line, _ := decoder.InputPos()
+		return nil, &MalformedError{Format: FormatXML, Line: line, Msg: fmt.Sprintf("element <%s> is not closed", qualifiedName(stack[len(stack)-1].name))}
+	}
+	return root, nil
+}
+
+// render writes the top level nodes of root
+func (n *node) render() []byte {
+	var b bytes.Buffer
+	for _, child := range n.children {
+		child.write(&b)
+	}
+	return b.Bytes()
+}
+
+func (n *node) write(b *bytes.Buffer) {
+	switch t := n.token.(type) {
+	case xml.CharData:
+		b.WriteString(textEscaper.Replace(string(t)))
+		return
+	case xml.Comment:
+		b.WriteString("<!--" + string(t) + "-->")
+		return
+	case xml.ProcInst:
+		b.WriteString("<?" + t.Target)
+		if len(t.Inst) > 0 {
+			b.WriteString(" " + string(t.Inst))
+		}
+		b.WriteString("?>")
+		return
+	case xml.Directive:
+		b.WriteString("<!" + string(t) + ">")
+		return
+	}
+
+	b.WriteString("<" + qualifiedName(n.name))
+	for _, attr := range n.attr {
+		b.WriteString(" " + qualifiedName(attr.Name) + `="` + attrEscaper.Replace(attr.Value) + `"`)
+	}
+	if len(n.children) == 0 {
+		b.WriteString("/>")
+		return
+	}
+	b.WriteString(">")
+	for _, child := range n.children {
+		child.write(b)
+	}
+	b.WriteString("</" + qualifiedName(n.name) + ">")
+}
+
+// textEscaper and attrEscaper escape what has to be, unlike xml.EscapeText which also escapes the
+// line breaks of the indentation
+var (
+	textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;", "\n", "&#xA;", "\t", "&#x9;")
+)
+
+func qualifiedName(name xml.Name) string {
+	if name.Space != "" {
+		return name.Space + ":" + name.Local
+	}
+	return name.Local
+}
+
+// elements returns the child elements called name
+func (n *node) elements(name string) []*node {
+	var elements []*node
+	for _, child := range n.children {
+		if child.token == nil && child.name.Local == name {
+			elements = append(elements, child)
+		}
+	}
+	return elements
+}
+
+// getAttr returns the value of the attribute called name
+func (n *node) getAttr(name string) string {
+	for _, attr := range n.attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// setText replaces the content of the element with text
+func (n *node) setText(text string) {
+	n.children = []*node{{token: xml.CharData(text)}}
+}
+
+// indent returns the indentation of the child elements, the whitespace after the last line break
+// before the first child element, or the indentation of n plus step when n has no child elements
+func (n *node) indent(own, step string) string {
+	for i, child := range n.children {
+		if child.token != nil || i == 0 {
+			continue
+		}
+		if data, ok := n.children[i-1].token.(xml.CharData); ok {
+			if at := strings.LastIndexByte(string(data), '\n'); at >= 0 {
+				return string(data[at+1:])
+			}
+		}
+	}
+	return own + step
+}
+
+// appendElement adds element as the last child element, on its own line at indent. own is the
+// indentation of n, the closing tag of n stays on its own line.
+func (n *node) appendElement(element *node, indent, own string) {
+	// Drop the whitespace before the closing tag, it is written again after the new element
+	if last := len(n.children) - 1; last >= 0 {
+		if data, ok := n.children[last].token.(xml.CharData); ok && strings.TrimSpace(string(data)) == "" {
+			n.children = n.children[:last]
+		}
+	}
+	n.children = append(n.children,
+		&node{token: xml.CharData("\n" + indent)},
+		element,
+		&node{token: xml.CharData("\n" + own)},
+	)
+}
+
+// removeElement removes child and the whitespace before it
+func (n *node) removeElement(child *node) {
+	for i, c := range n.children {
+		if c != child {
+			continue
+		}
+		start := i
+		if i > 0 {
+			if data, ok := n.children[i-1].token.(xml.CharData); ok && strings.TrimSpace(string(data)) == "" {
+				start = i - 1
+			}
+		}
+		n.children = append(n.children[:start], n.children[i+1:]...)
+		return
+	}
+}
@@ -0,0 +1,101 @@
+package gamelog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// EventType is the kind of a player event, it is the reason of the Kubernetes Event
+type EventType string
+
+const (
+	// PlayerConnected means a player joined the server
+	PlayerConnected EventType = "PlayerConnected"
+	// PlayerDisconnected means a player left the server
+	PlayerDisconnected EventType = "PlayerDisconnected"
+	// PlayerKilled means a player was killed, by another player or the environment
+	PlayerKilled EventType = "PlayerKilled"
+)
+
+// Event is a player event parsed from a log line
+type Event struct {
+	Type EventType
+
+	// Time is the time of day of the line, the DayZ admin log only writes the date when it starts
+	Time string
+
+	Player string
+	// PlayerID is the id of the player in the log, DayZ logs a hash of the Steam id
+	PlayerID string
+
+	// Killer is the name of the player who killed Player, or what killed Player, e.g. Infected
+	Killer   string
+	KillerID string
+	Weapon   string
+	// Distance in meters between the killer and Player
+	Distance string
+}
+
+// Message describes the event for a Kubernetes Event
+func (e Event) Message() string {
+	switch e.Type {
+	case PlayerConnected:
+		return fmt.Sprintf("Player %q connected", e.Player)
+	case PlayerDisconnected:
+		return fmt.Sprintf("Player %q disconnected", e.Player)
+	}
+	message := fmt.Sprintf("Player %q was killed by %s", e.Player, e.Killer)
+	if e.KillerID != "" {
+		message = fmt.Sprintf("Player %q was killed by player %q", e.Player, e.Killer)
+	}
+	if e.Weapon != "" {
+		message += " with " + e.Weapon
+	}
+	if e.Distance != "" {
+		message += " from " + e.Distance + " meters"
+	}
+	return message
+}
+
+// admPlayer matches a player of the DayZ admin log, its name and id: Player "Survivor" (DEAD) (id=... pos=<...>)
+const admPlayer = `Player "(.+?)"\s*(?:\(DEAD\)\s*)?\(id=([^ )]*)[^)]*\)`
+
+var (
+	admLine         = regexp.MustCompile(`^(\d{1,2}:\d{2}:\d{2}) \| (.+)$`)
+	admConnected    = regexp.MustCompile(`^Player "(.+?)"\s*is connected \(id=([^ )]*)[^)]*\)$`)
+	admDisconnected = regexp.MustCompile(`^` + admPlayer + ` has been disconnected$`)
+	admKilled       = regexp.MustCompile(`^` + admPlayer + ` killed by (.+)$`)
+	admKiller       = regexp.MustCompile(`^` + admPlayer + `(?: with (.+?))?(?: from ([0-9.]+) meters)?$`)
+)
+
+// ParseADM parses a line of the DayZ admin log, *.ADM in the profiles directory. It reports false for
+// lines which are not player connections, disconnections or kills.
+func ParseADM(line string) (Event, bool) {
+	match := admLine.FindStringSubmatch(strings.TrimSpace(line))
+	if match == nil {
+		return Event{}, false
+	}
+	event := Event{Time: match[1]}
+	text := match[2]
+
+	if player := admConnected.FindStringSubmatch(text); player != nil {
+		event.Type, event.Player, event.PlayerID = PlayerConnected, player[1], player[2]
+		return event, true
+	}
+	if player := admDisconnected.FindStringSubmatch(text); player != nil {
+		event.Type, event.Player, event.PlayerID = PlayerDisconnected, player[1], player[2]
+		return event, true
+	}
+	kill := admKilled.FindStringSubmatch(text)
+	if kill == nil {
+		return Event{}, false
+	}
+	event.Type, event.Player, event.PlayerID = PlayerKilled, kill[1], kill[2]
+	if killer := admKiller.FindStringSubmatch(kill[3]); killer != nil {
+		event.Killer, event.KillerID, event.Weapon, event.Distance = killer[1], killer[2], killer[3], killer[4]
+	} else {
+		event.Killer = kill[3]
+	}
+	return event, true
+}
@@ -0,0 +1,58 @@
+package gamelog_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/templarfelix/gameserver-operator/internal/gamelog"
+)
+
+var _ = Describe("ParseADM", func() {
+	It("should parse connections and disconnections", func() {
+		event, ok := gamelog.ParseADM(`17:16:00 | Player "Survivor" is connected (id=Xb2mD0c8xU7oQ1fL3qk9jY5sVw4Nr6ZtPaEeHgC1iT0=)`)
+		Expect(ok).To(BeTrue())
+		Expect(event).To(Equal(gamelog.Event{
+			Type: gamelog.PlayerConnected, Time: "17:16:00",
+			Player: "Survivor", PlayerID: "Xb2mD0c8xU7oQ1fL3qk9jY5sVw4Nr6ZtPaEeHgC1iT0=",
+		}))
+		Expect(event.Message()).To(Equal(`Player "Survivor" connected`))
+
+		event, ok = gamelog.ParseADM(`17:20:00 | Player "Survivor"(id=Xb2mD0c8xU7oQ1fL3qk9jY5sVw4Nr6ZtPaEeHgC1iT0=) has been disconnected`)
+		Expect(ok).To(BeTrue())
+		Expect(event.Type).To(Equal(gamelog.PlayerDisconnected))
+		Expect(event.PlayerID).To(Equal("Xb2mD0c8xU7oQ1fL3qk9jY5sVw4Nr6ZtPaEeHgC1iT0="))
+	})
+
+	It("should parse kills by players", func() {
+		event, ok := gamelog.ParseADM(`17:18:12 | Player "Victim" (DEAD) (id=VictimID= pos=<7500.1, 3000.2, 250.3>) ` +
+			`killed by Player "Bandit" (id=BanditID= pos=<7520.0, 3010.0, 251.0>) with M4-A1 from 22.4 meters`)
+		Expect(ok).To(BeTrue())
+		Expect(event).To(Equal(gamelog.Event{
+			Type: gamelog.PlayerKilled, Time: "17:18:12",
+			Player: "Victim", PlayerID: "VictimID=",
+			Killer: "Bandit", KillerID: "BanditID=", Weapon: "M4-A1", Distance: "22.4",
+		}))
+		Expect(event.Message()).To(Equal(`Player "Victim" was killed by player "Bandit" with M4-A1 from 22.4 meters`))
+	})
+
+	It("should parse kills by the environment", func() {
+		event, ok := gamelog.ParseADM(`09:05:41 | Player "Survivor" (DEAD) (id=SurvivorID= pos=<1.0, 2.0, 3.0>) killed by Infected`)
+		Expect(ok).To(BeTrue())
+		Expect(event.Killer).To(Equal("Infected"))
+		Expect(event.KillerID).To(BeEmpty())
+		Expect(event.Message()).To(Equal(`Player "Survivor" was killed by Infected`))
+	})
+
+	It("should ignore other lines", func() {
+		for _, line := range []string{
+			"AdminLog started on 2024-05-01 at 17:15:31",
+			`17:17:00 | Player "Survivor" (id=SurvivorID= pos=<1.0, 2.0, 3.0>)[HP: 87.5] hit by Infected into Torso(12) for 12.5 damage (MeleeInfected)`,
+			`17:19:00 | Player "Survivor" (DEAD) (id=SurvivorID= pos=<1.0, 2.0, 3.0>) died. Stats> Water: 1000 Energy: 20 Bleed sources: 0`,
+			"##### PlayerList log: 0 players",
+			"",
+		} {
+			_, ok := gamelog.ParseADM(line)
+			Expect(ok).To(BeFalse(), line)
+		}
+	})
+})
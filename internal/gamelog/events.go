@@ -0,0 +1,49 @@
+package gamelog
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// EventRecorder records player events as Kubernetes Events on a game server
+type EventRecorder struct {
+	Client client.Client
+
+	// Object is the game server the Events are recorded on
+	Object corev1.ObjectReference
+
+	// Component is the source of the Events
+	Component string
+
+	// Now returns the current time, time.Now is used when nil
+	Now func() time.Time
+}
+
+// Record creates a Normal Event for event. Every event is its own Event, players connecting again are
+// not counted into an earlier one.
+func (r *EventRecorder) Record(ctx context.Context, event Event) error {
+	now := time.Now
+	if r.Now != nil {
+		now = r.Now
+	}
+	timestamp := metav1.NewTime(now())
+	return r.Client.Create(ctx, &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", r.Object.Name, timestamp.UnixNano()),
+			Namespace: r.Object.Namespace,
+		},
+		InvolvedObject: r.Object,
+		Reason:         string(event.Type),
+		Message:        event.Message(),
+		Type:           corev1.EventTypeNormal,
+		Source:         corev1.EventSource{Component: r.Component},
+		FirstTimestamp: timestamp,
+		LastTimestamp:  timestamp,
+		Count:          1,
+	})
+}
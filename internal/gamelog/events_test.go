@@ -0,0 +1,44 @@
+package gamelog_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/templarfelix/gameserver-operator/internal/gamelog"
+)
+
+var _ = Describe("EventRecorder", func() {
+	It("should record an Event on the game server", func() {
+		ctx := context.Background()
+		c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+		object := corev1.ObjectReference{
+			APIVersion: "gameserver.templarfelix.com/v1alpha1", Kind: "Dayz",
+			Name: "dayz-sample", Namespace: "games", UID: "1234",
+		}
+		recorder := &gamelog.EventRecorder{
+			Client: c, Object: object, Component: "gameserver-logs",
+			Now: func() time.Time { return time.Unix(1700000000, 0) },
+		}
+
+		Expect(recorder.Record(ctx, gamelog.Event{Type: gamelog.PlayerConnected, Player: "Survivor"})).To(Succeed())
+
+		events := &corev1.EventList{}
+		Expect(c.List(ctx, events, client.InNamespace("games"))).To(Succeed())
+		Expect(events.Items).To(HaveLen(1))
+		event := events.Items[0]
+		Expect(event.InvolvedObject).To(Equal(object))
+		Expect(event.Reason).To(Equal("PlayerConnected"))
+		Expect(event.Message).To(Equal(`Player "Survivor" connected`))
+		Expect(event.Type).To(Equal(corev1.EventTypeNormal))
+		Expect(event.Source.Component).To(Equal("gameserver-logs"))
+		Expect(event.LastTimestamp.Unix()).To(Equal(int64(1700000000)))
+	})
+})
@@ -0,0 +1,149 @@
+// Package gamelog streams the log files game servers write to their volume and parses player events
+// from them.
+package gamelog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultPoll is how often a Follower reads its file and looks for a newer one
+const DefaultPoll = time.Second
+
+// Follower follows the newest file matching a glob, like tail -F on a log the game starts anew at
+// each start
+type Follower struct {
+	// Pattern is a glob of the log files, the most recently modified one is followed
+	Pattern string
+
+	// Poll is how often the file is read and the glob checked for a newer file, DefaultPoll when zero
+	Poll time.Duration
+}
+
+// Follow calls line for each line appended to the followed file until ctx is done. The file found at
+// the start is followed from its end, newer files from their start. A line is passed once it ends.
+func (f *Follower) Follow(ctx context.Context, line func(string)) error {
+	poll := f.Poll
+	if poll == 0 {
+		poll = DefaultPoll
+	}
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	current := &followedFile{}
+	defer current.close()
+	start := true
+	for {
+		newest, err := newestFile(f.Pattern)
+		if err != nil {
+			return err
+		}
+		if newest != "" && newest != current.path {
+			// The rest of the previous file is read before switching
+			current.read(line)
+			current.close()
+			current = openFollowed(newest, start)
+		}
+		start = false
+		current.read(line)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// followedFile is the file a Follower reads, with the line it did not finish yet
+type followedFile struct {
+	path    string
+	file    *os.File
+	offset  int64
+	partial []byte
+	started bool
+}
+
+// openFollowed opens the file at path, positioned at its end with atEnd. A file which cannot be opened
+// is retried by the next poll.
+func openFollowed(path string, atEnd bool) *followedFile {
+	file, err := os.Open(path)
+	if err != nil {
+		return &followedFile{}
+	}
+	followed := &followedFile{path: path, file: file}
+	if atEnd {
+		if followed.offset, err = file.Seek(0, io.SeekEnd); err != nil {
+			followed.offset = 0
+		}
+		followed.started = followed.offset > 0
+	}
+	return followed
+}
+
+// read passes the complete lines appended since the last read to line
+func (f *followedFile) read(line func(string)) {
+	if f.file == nil {
+		return
+	}
+	// A truncated file is read again from its start
+	if info, err := f.file.Stat(); err == nil && info.Size() < f.offset {
+		if _, err := f.file.Seek(0, io.SeekStart); err == nil {
+			f.offset, f.partial, f.started = 0, nil, false
+		}
+	}
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := f.file.Read(buf)
+		f.offset += int64(n)
+		f.partial = append(f.partial, buf[:n]...)
+		for {
+			end := bytes.IndexByte(f.partial, '\n')
+			if end < 0 {
+				break
+			}
+			text := bytes.TrimSuffix(f.partial[:end], []byte("\r"))
+			if !f.started {
+				text = bytes.TrimPrefix(text, []byte("\ufeff"))
+				f.started = true
+			}
+			line(string(text))
+			f.partial = f.partial[end+1:]
+		}
+		if err != nil || n == 0 {
+			return
+		}
+	}
+}
+
+func (f *followedFile) close() {
+	if f.file != nil {
+		f.file.Close()
+	}
+}
+
+// newestFile returns the most recently modified file matching pattern, "" when there is none
+func newestFile(pattern string) (string, error) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return "", err
+	}
+	var newest string
+	var newestTime time.Time
+	for _, match := range matches {
+		// A file removed since the glob, or which cannot be read, is skipped
+		info, err := os.Stat(match)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		if newest == "" || info.ModTime().After(newestTime) || (info.ModTime().Equal(newestTime) && match > newest) {
+			newest, newestTime = match, info.ModTime()
+		}
+	}
+	return newest, nil
+}
@@ -0,0 +1,96 @@
+package gamelog_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/templarfelix/gameserver-operator/internal/gamelog"
+)
+
+var _ = Describe("Follower", func() {
+	var (
+		dir   string
+		mu    sync.Mutex
+		lines []string
+	)
+
+	received := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), lines...)
+	}
+	write := func(name, content string, modTime time.Time) {
+		path := filepath.Join(dir, name)
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = file.WriteString(content)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(file.Close()).To(Succeed())
+		Expect(os.Chtimes(path, modTime, modTime)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		lines = nil
+	})
+
+	follow := func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		follower := &gamelog.Follower{Pattern: filepath.Join(dir, "*.ADM"), Poll: 10 * time.Millisecond}
+		go func() {
+			done <- follower.Follow(ctx, func(line string) {
+				mu.Lock()
+				defer mu.Unlock()
+				lines = append(lines, line)
+			})
+		}()
+		DeferCleanup(func() {
+			cancel()
+			Eventually(done).Should(Receive(BeNil()))
+		})
+		// Files written from now on are written after the follower started
+		Consistently(received, "50ms").Should(BeEmpty())
+	}
+
+	It("should follow the newest file from its end", func() {
+		now := time.Now()
+		write("DayZServer_x64_1.ADM", "old run\n", now.Add(-2*time.Hour))
+		write("DayZServer_x64_2.ADM", "\ufeffwritten before the start\n", now.Add(-time.Hour))
+		follow()
+
+		write("DayZServer_x64_2.ADM", "first\r\nsec", now.Add(-time.Hour))
+		Eventually(received).Should(Equal([]string{"first"}))
+		write("DayZServer_x64_2.ADM", "ond\n", now.Add(-time.Hour))
+		Eventually(received).Should(Equal([]string{"first", "second"}))
+	})
+
+	It("should switch to a newer file from its start", func() {
+		follow()
+
+		write("DayZServer_x64_1.ADM", "\ufeffAdminLog started\n", time.Now().Add(-time.Minute))
+		Eventually(received).Should(Equal([]string{"AdminLog started"}))
+
+		write("DayZServer_x64_1.ADM", "last line\n", time.Now().Add(-time.Minute))
+		write("DayZServer_x64_2.ADM", "next run\n", time.Now())
+		Eventually(received).Should(Equal([]string{"AdminLog started", "last line", "next run"}))
+	})
+
+	It("should read a truncated file again", func() {
+		write("console.ADM", "", time.Now())
+		follow()
+
+		write("console.ADM", "before the truncation\n", time.Now())
+		Eventually(received).Should(HaveLen(1))
+
+		Expect(os.Truncate(filepath.Join(dir, "console.ADM"), 0)).To(Succeed())
+		write("console.ADM", "after\n", time.Now())
+		Eventually(received).Should(Equal([]string{"before the truncation", "after"}))
+	})
+})
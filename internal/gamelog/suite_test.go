@@ -0,0 +1,14 @@
+package gamelog_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestGamelog(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Gamelog Suite")
+}
@@ -0,0 +1,143 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// SignatureHeader carries the HMAC-SHA256 of the body of generic webhooks
+const SignatureHeader = "X-Gameserver-Signature"
+
+// Channel types, matching the types of the NotificationChannel API
+const (
+	TypeDiscord = "discord"
+	TypeSlack   = "slack"
+	TypeWebhook = "webhook"
+)
+
+// Notification is an event of a game server delivered to a channel
+type Notification struct {
+	// Event is the subscribed event, e.g. started or players
+	Event string
+
+	// Kind, Namespace and Name identify the game server
+	Kind      string
+	Namespace string
+	Name      string
+
+	// Message describes the event for chat channels
+	Message string
+
+	// Players is the player count when the event happened
+	Players int32
+
+	// Time is when the event happened
+	Time time.Time
+}
+
+// gameServer identifies the game server in generic webhook payloads
+type gameServer struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// webhookPayload is the JSON document posted to generic webhooks
+type webhookPayload struct {
+	Event      string     `json:"event"`
+	GameServer gameServer `json:"gameServer"`
+	Message    string     `json:"message"`
+	Players    int32      `json:"players"`
+	Time       time.Time  `json:"time"`
+}
+
+// Payload renders notification as the body posted to a channel of channelType
+func Payload(channelType string, notification Notification) ([]byte, error) {
+	switch channelType {
+	case TypeDiscord:
+		return json.Marshal(map[string]string{"content": notification.Message})
+	case TypeSlack:
+		return json.Marshal(map[string]string{"text": notification.Message})
+	case TypeWebhook:
+		return json.Marshal(webhookPayload{
+			Event: notification.Event,
+			GameServer: gameServer{
+				Kind:      notification.Kind,
+				Namespace: notification.Namespace,
+				Name:      notification.Name,
+			},
+			Message: notification.Message,
+			Players: notification.Players,
+			Time:    notification.Time.UTC(),
+		})
+	}
+	return nil, Permanent(fmt.Errorf("unsupported channel type %q", channelType))
+}
+
+// Sign returns the SignatureHeader value of body signed with key
+func Sign(key, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// permanentError is a delivery failure retrying does not fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not worth retrying
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked by Permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// Deliver posts notification to url in the format of channelType, signed with signingKey when it is
+// not empty. Responses other than 2xx fail, client errors other than 429 permanently.
+func Deliver(ctx context.Context, httpClient *http.Client, url, channelType string, signingKey []byte, notification Notification) error {
+	body, err := Payload(channelType, notification)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(signingKey) > 0 {
+		req.Header.Set(SignatureHeader, Sign(signingKey, body))
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	err = fmt.Errorf("channel responded %s", resp.Status)
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return Permanent(err)
+	}
+	return err
+}
@@ -0,0 +1,92 @@
+package notify_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/templarfelix/gameserver-operator/internal/notify"
+)
+
+// received is a request posted to the local channel stand-in
+type received struct {
+	body      []byte
+	signature string
+}
+
+var _ = Describe("Deliver", func() {
+	ctx := context.Background()
+	notification := notify.Notification{
+		Event: "players", Kind: "Dayz", Namespace: "games", Name: "dayz-sample",
+		Message: "Dayz games/dayz-sample reached 10 players", Players: 12, Time: time.Unix(1700000000, 0),
+	}
+
+	serve := func(statuses ...int) (*httptest.Server, chan received) {
+		requests := make(chan received, 10)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			requests <- received{body: body, signature: r.Header.Get(notify.SignatureHeader)}
+			status := http.StatusNoContent
+			if len(statuses) > 0 {
+				status, statuses = statuses[0], statuses[1:]
+			}
+			w.WriteHeader(status)
+		}))
+		DeferCleanup(server.Close)
+		return server, requests
+	}
+
+	It("should post the message as Discord content", func() {
+		server, requests := serve()
+		Expect(notify.Deliver(ctx, server.Client(), server.URL, notify.TypeDiscord, nil, notification)).To(Succeed())
+		request := <-requests
+		Expect(request.body).To(MatchJSON(`{"content":"Dayz games/dayz-sample reached 10 players"}`))
+		Expect(request.signature).To(BeEmpty())
+	})
+
+	It("should post the message as Slack text", func() {
+		server, requests := serve()
+		Expect(notify.Deliver(ctx, server.Client(), server.URL, notify.TypeSlack, nil, notification)).To(Succeed())
+		Expect((<-requests).body).To(MatchJSON(`{"text":"Dayz games/dayz-sample reached 10 players"}`))
+	})
+
+	It("should post a signed JSON document to generic webhooks", func() {
+		server, requests := serve()
+		Expect(notify.Deliver(ctx, server.Client(), server.URL, notify.TypeWebhook, []byte("s3cret"), notification)).To(Succeed())
+		request := <-requests
+		Expect(request.body).To(MatchJSON(`{
+			"event": "players",
+			"gameServer": {"kind": "Dayz", "namespace": "games", "name": "dayz-sample"},
+			"message": "Dayz games/dayz-sample reached 10 players",
+			"players": 12,
+			"time": "2023-11-14T22:13:20Z"
+		}`))
+		Expect(request.signature).To(Equal(notify.Sign([]byte("s3cret"), request.body)))
+		Expect(request.signature).To(HavePrefix("sha256="))
+	})
+
+	It("should fail permanently on client errors other than 429", func() {
+		server, _ := serve(http.StatusBadRequest, http.StatusTooManyRequests, http.StatusBadGateway)
+		err := notify.Deliver(ctx, server.Client(), server.URL, notify.TypeDiscord, nil, notification)
+		Expect(err).To(MatchError(ContainSubstring("400")))
+		Expect(notify.IsPermanent(err)).To(BeTrue())
+
+		err = notify.Deliver(ctx, server.Client(), server.URL, notify.TypeDiscord, nil, notification)
+		Expect(err).To(HaveOccurred())
+		Expect(notify.IsPermanent(err)).To(BeFalse())
+
+		err = notify.Deliver(ctx, server.Client(), server.URL, notify.TypeDiscord, nil, notification)
+		Expect(err).To(HaveOccurred())
+		Expect(notify.IsPermanent(err)).To(BeFalse())
+	})
+
+	It("should sign with HMAC-SHA256", func() {
+		// printf '{}' | openssl dgst -sha256 -hmac key
+		Expect(notify.Sign([]byte("key"), []byte("{}"))).To(Equal("sha256=a777724d943eb48dc69bca8a4a6d57a04db3f9ec7e1de4e581e860265bdf3032"))
+	})
+})
@@ -0,0 +1,201 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+)
+
+// Defaults of the Dispatcher
+const (
+	DefaultBackoff     = 5 * time.Second
+	DefaultMaxBackoff  = 5 * time.Minute
+	DefaultMaxAttempts = 8
+	DefaultTimeout     = 10 * time.Second
+)
+
+// delivery is a notification queued for a NotificationChannel, queued by pointer so equal
+// notifications are delivered once each
+type delivery struct {
+	channel      types.NamespacedName
+	notification Notification
+}
+
+//+kubebuilder:rbac:groups=gameserver.templarfelix.com,resources=notificationchannels,verbs=get;list;watch
+//+kubebuilder:rbac:groups=gameserver.templarfelix.com,resources=notificationchannels/status,verbs=get;update;patch
+
+// Dispatcher delivers notifications to NotificationChannels in the background, retrying failed
+// deliveries with exponential backoff. It runs as a manager Runnable.
+type Dispatcher struct {
+	Client client.Client
+
+	// HTTPClient posts the notifications, a client with DefaultTimeout is used when nil
+	HTTPClient *http.Client
+
+	// Backoff is the wait before the first retry, doubled for every further one up to MaxBackoff.
+	// DefaultBackoff and DefaultMaxBackoff are used when zero.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	// MaxAttempts is how often a notification is posted before it is dropped, DefaultMaxAttempts when zero
+	MaxAttempts int
+
+	// Workers is the number of concurrent deliveries, 1 when zero
+	Workers int
+
+	init  sync.Once
+	queue workqueue.RateLimitingInterface
+}
+
+// Enqueue queues notification for the NotificationChannel channel in namespace
+func (d *Dispatcher) Enqueue(namespace, channel string, notification Notification) {
+	d.initQueue()
+	d.queue.Add(&delivery{channel: types.NamespacedName{Namespace: namespace, Name: channel}, notification: notification})
+}
+
+// Start delivers the queued notifications until ctx is done
+func (d *Dispatcher) Start(ctx context.Context) error {
+	d.initQueue()
+	workers := d.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for d.processNext(ctx) {
+			}
+		}()
+	}
+	<-ctx.Done()
+	d.queue.ShutDown()
+	wg.Wait()
+	return nil
+}
+
+func (d *Dispatcher) initQueue() {
+	d.init.Do(func() {
+		backoff, maxBackoff := d.Backoff, d.MaxBackoff
+		if backoff <= 0 {
+			backoff = DefaultBackoff
+		}
+		if maxBackoff <= 0 {
+			maxBackoff = DefaultMaxBackoff
+		}
+		d.queue = workqueue.NewRateLimitingQueueWithConfig(
+			workqueue.NewItemExponentialFailureRateLimiter(backoff, maxBackoff),
+			workqueue.RateLimitingQueueConfig{Name: "notifications"})
+	})
+}
+
+// processNext delivers the next queued notification, it returns false once the queue shuts down
+func (d *Dispatcher) processNext(ctx context.Context) bool {
+	item, shutdown := d.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer d.queue.Done(item)
+	next := item.(*delivery)
+	logger := log.FromContext(ctx).WithValues("notificationChannel", next.channel, "event", next.notification.Event)
+
+	channel, err := d.deliver(ctx, next)
+	if err == nil {
+		d.queue.Forget(item)
+		d.updateStatus(ctx, channel, func(status *gameserverv1alpha1.NotificationChannelStatus) {
+			now := metav1.Now()
+			status.LastDeliveryTime = &now
+		})
+		return true
+	}
+
+	maxAttempts := d.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	if !IsPermanent(err) && d.queue.NumRequeues(item)+1 < maxAttempts {
+		logger.Info("Notification failed, retrying", "error", err.Error())
+		d.queue.AddRateLimited(item)
+		return true
+	}
+
+	logger.Error(err, "Dropping notification")
+	d.queue.Forget(item)
+	d.updateStatus(ctx, channel, func(status *gameserverv1alpha1.NotificationChannelStatus) {
+		now := metav1.Now()
+		status.LastFailureTime = &now
+		status.LastError = err.Error()
+	})
+	return true
+}
+
+// deliver resolves the channel and its secrets and posts the notification. The channel is returned
+// when it exists, also on failed deliveries.
+func (d *Dispatcher) deliver(ctx context.Context, next *delivery) (*gameserverv1alpha1.NotificationChannel, error) {
+	channel := &gameserverv1alpha1.NotificationChannel{}
+	if err := d.Client.Get(ctx, next.channel, channel); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, Permanent(fmt.Errorf("NotificationChannel %s not found", next.channel))
+		}
+		return nil, err
+	}
+
+	url, err := d.secretValue(ctx, channel.Namespace, &channel.Spec.URLSecretRef)
+	if err != nil {
+		return channel, err
+	}
+	var signingKey []byte
+	if channel.Spec.SigningSecretRef != nil {
+		if signingKey, err = d.secretValue(ctx, channel.Namespace, channel.Spec.SigningSecretRef); err != nil {
+			return channel, err
+		}
+	}
+
+	httpClient := d.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DefaultTimeout}
+	}
+	return channel, Deliver(ctx, httpClient, string(url), string(channel.Spec.Type), signingKey, next.notification)
+}
+
+// secretValue reads the key of ref, missing Secrets and keys fail permanently
+func (d *Dispatcher) secretValue(ctx context.Context, namespace string, ref *corev1.SecretKeySelector) ([]byte, error) {
+	secret := &corev1.Secret{}
+	if err := d.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, Permanent(fmt.Errorf("secret %s not found", ref.Name))
+		}
+		return nil, err
+	}
+	value, ok := secret.Data[ref.Key]
+	if !ok || len(value) == 0 {
+		return nil, Permanent(fmt.Errorf("secret %s has no key %s", ref.Name, ref.Key))
+	}
+	return value, nil
+}
+
+// updateStatus patches the status of channel, failures are only logged as the delivery is done
+func (d *Dispatcher) updateStatus(ctx context.Context, channel *gameserverv1alpha1.NotificationChannel, mutate func(*gameserverv1alpha1.NotificationChannelStatus)) {
+	if channel == nil {
+		return
+	}
+	patch := client.MergeFrom(channel.DeepCopy())
+	mutate(&channel.Status)
+	if err := d.Client.Status().Patch(ctx, channel, patch); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to update NotificationChannel status", "notificationChannel", channel.Name)
+	}
+}
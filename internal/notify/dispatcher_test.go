@@ -0,0 +1,112 @@
+package notify_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	gameserverv1alpha1 "github.com/templarfelix/gameserver-operator/api/v1alpha1"
+	"github.com/templarfelix/gameserver-operator/internal/notify"
+)
+
+var _ = Describe("Dispatcher", func() {
+	var (
+		c        client.Client
+		requests atomic.Int32
+		statuses chan int
+	)
+
+	BeforeEach(func() {
+		requests.Store(0)
+		statuses = make(chan int, 10)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			select {
+			case status := <-statuses:
+				w.WriteHeader(status)
+			default:
+				w.WriteHeader(http.StatusNoContent)
+			}
+		}))
+		DeferCleanup(server.Close)
+
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(gameserverv1alpha1.AddToScheme(scheme)).To(Succeed())
+		channel := &gameserverv1alpha1.NotificationChannel{
+			ObjectMeta: metav1.ObjectMeta{Name: "discord", Namespace: "games"},
+			Spec: gameserverv1alpha1.NotificationChannelSpec{
+				Type:         gameserverv1alpha1.NotificationChannelDiscord,
+				URLSecretRef: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "discord-webhook"}, Key: "url"},
+			},
+		}
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "discord-webhook", Namespace: "games"},
+			Data:       map[string][]byte{"url": []byte(server.URL)},
+		}
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(channel, secret).WithStatusSubresource(channel).Build()
+	})
+
+	start := func(maxAttempts int) *notify.Dispatcher {
+		dispatcher := &notify.Dispatcher{Client: c, Backoff: 10 * time.Millisecond, MaxBackoff: 20 * time.Millisecond, MaxAttempts: maxAttempts}
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- dispatcher.Start(ctx) }()
+		DeferCleanup(func() {
+			cancel()
+			Eventually(done).Should(Receive(BeNil()))
+		})
+		return dispatcher
+	}
+	channelStatus := func() gameserverv1alpha1.NotificationChannelStatus {
+		channel := &gameserverv1alpha1.NotificationChannel{}
+		Expect(c.Get(context.Background(), client.ObjectKey{Namespace: "games", Name: "discord"}, channel)).To(Succeed())
+		return channel.Status
+	}
+
+	It("should retry failed deliveries with backoff", func() {
+		statuses <- http.StatusInternalServerError
+		statuses <- http.StatusBadGateway
+		start(5).Enqueue("games", "discord", notify.Notification{Event: "started", Message: "Dayz games/dayz-sample started"})
+
+		Eventually(channelStatus).Should(HaveField("LastDeliveryTime", Not(BeNil())))
+		Expect(requests.Load()).To(Equal(int32(3)))
+		Expect(channelStatus().LastError).To(BeEmpty())
+	})
+
+	It("should drop notifications after the last attempt", func() {
+		for i := 0; i < 3; i++ {
+			statuses <- http.StatusServiceUnavailable
+		}
+		start(3).Enqueue("games", "discord", notify.Notification{Event: "crashed"})
+
+		Eventually(channelStatus).Should(HaveField("LastError", ContainSubstring("503")))
+		Expect(requests.Load()).To(Equal(int32(3)))
+		Expect(channelStatus().LastDeliveryTime).To(BeNil())
+	})
+
+	It("should not retry permanent failures", func() {
+		statuses <- http.StatusNotFound
+		start(5).Enqueue("games", "discord", notify.Notification{Event: "stopped"})
+
+		Eventually(channelStatus).Should(HaveField("LastError", ContainSubstring("404")))
+		Consistently(requests.Load, "100ms").Should(Equal(int32(1)))
+	})
+
+	It("should drop notifications for missing channels", func() {
+		start(5).Enqueue("games", "missing", notify.Notification{Event: "started"})
+		Consistently(requests.Load, "100ms").Should(BeZero())
+	})
+})
@@ -0,0 +1,63 @@
+// Package rcon provides remote console sessions to game servers independent of the wire protocol.
+// Source RCON (TCP) is implemented here, BattlEye RCon (UDP) by package bercon.
+// Protocol reference: https://developer.valvesoftware.com/wiki/Source_RCON_Protocol
+package rcon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/templarfelix/gameserver-operator/internal/bercon"
+)
+
+// Protocol is an RCON wire protocol
+type Protocol string
+
+const (
+	// ProtocolBattlEye is the BattlEye RCon protocol over UDP used by DayZ and Arma
+	ProtocolBattlEye Protocol = "BattlEye"
+	// ProtocolSource is the Valve Source RCON protocol over TCP used by srcds games, Rust, Ark and others
+	ProtocolSource Protocol = "Source"
+
+	// DefaultTimeout bounds login and each command round trip
+	DefaultTimeout = 5 * time.Second
+)
+
+var (
+	// ErrAuthFailed is returned when the server rejects the RCON password
+	ErrAuthFailed = errors.New("rcon: authentication failed")
+	// ErrMalformedPacket is returned for packets that cannot be decoded
+	ErrMalformedPacket = errors.New("rcon: malformed packet")
+)
+
+// Session is an authenticated remote console connection
+type Session interface {
+	// Command runs command and returns the complete response
+	Command(ctx context.Context, command string) (string, error)
+	// Close ends the session
+	Close() error
+}
+
+var _ Session = &SourceClient{}
+var _ Session = &bercon.Client{}
+
+// Dial opens an authenticated session to addr (host:port) with the given protocol
+func Dial(ctx context.Context, protocol Protocol, addr, password string, timeout time.Duration) (Session, error) {
+	switch protocol {
+	case ProtocolSource:
+		return DialSource(ctx, addr, password, timeout)
+	case ProtocolBattlEye:
+		session, err := bercon.Dial(ctx, addr, password, timeout)
+		if errors.Is(err, bercon.ErrLoginFailed) {
+			return nil, fmt.Errorf("%w: %w", ErrAuthFailed, err)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return session, nil
+	default:
+		return nil, fmt.Errorf("rcon: unsupported protocol %q", protocol)
+	}
+}
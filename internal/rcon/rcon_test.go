@@ -0,0 +1,106 @@
+package rcon_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/templarfelix/gameserver-operator/internal/bercon/berconttest"
+	"github.com/templarfelix/gameserver-operator/internal/rcon"
+	"github.com/templarfelix/gameserver-operator/internal/rcon/rcontest"
+)
+
+var _ = Describe("Source RCON", func() {
+	var (
+		ctx    context.Context
+		server *rcontest.Server
+		status string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		status = strings.Repeat("player line\n", 1000)
+
+		var err error
+		server, err = rcontest.NewServer("secret", func(command string) string {
+			if command == "status" {
+				return status
+			}
+			return "ran " + command
+		})
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(server.Close)
+	})
+
+	It("should authenticate and run commands", func() {
+		session, err := rcon.Dial(ctx, rcon.ProtocolSource, server.Addr(), "secret", time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		defer session.Close()
+
+		response, err := session.Command(ctx, "say Restart in 5 minutes")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response).To(Equal("ran say Restart in 5 minutes"))
+
+		response, err = session.Command(ctx, "save")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response).To(Equal("ran save"))
+
+		Expect(server.Commands()).To(Equal([]string{"say Restart in 5 minutes", "save"}))
+	})
+
+	It("should collect responses split over several packets", func() {
+		server.SetSplitSize(500)
+		session, err := rcon.Dial(ctx, rcon.ProtocolSource, server.Addr(), "secret", time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		defer session.Close()
+
+		response, err := session.Command(ctx, "status")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response).To(Equal(status))
+
+		// The srcds trailer of the previous command must not leak into the next response
+		response, err = session.Command(ctx, "save")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response).To(Equal("ran save"))
+	})
+
+	It("should reject a wrong password", func() {
+		_, err := rcon.Dial(ctx, rcon.ProtocolSource, server.Addr(), "wrong", time.Second)
+		Expect(err).To(MatchError(rcon.ErrAuthFailed))
+	})
+
+	It("should round trip packets", func() {
+		var buf bytes.Buffer
+		Expect(rcon.WriteSourcePacket(&buf, rcon.SourcePacket{ID: 7, Type: rcon.SourceExecCommand, Body: "status"})).To(Succeed())
+		Expect(buf.Len()).To(Equal(4 + 10 + len("status")))
+
+		packet, err := rcon.ReadSourcePacket(&buf)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(packet).To(Equal(rcon.SourcePacket{ID: 7, Type: rcon.SourceExecCommand, Body: "status"}))
+	})
+})
+
+var _ = Describe("Dial", func() {
+	It("should open BattlEye sessions through the same interface", func() {
+		server, err := berconttest.NewServer("secret", func(command string) string { return "ok" })
+		Expect(err).NotTo(HaveOccurred())
+		defer server.Close()
+
+		session, err := rcon.Dial(context.Background(), rcon.ProtocolBattlEye, server.Addr(), "secret", time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		defer session.Close()
+		Expect(session.Command(context.Background(), "#lock")).To(Equal("ok"))
+
+		_, err = rcon.Dial(context.Background(), rcon.ProtocolBattlEye, server.Addr(), "wrong", time.Second)
+		Expect(err).To(MatchError(rcon.ErrAuthFailed))
+	})
+
+	It("should reject unknown protocols", func() {
+		_, err := rcon.Dial(context.Background(), "Telnet", "127.0.0.1:1", "", time.Second)
+		Expect(err).To(MatchError(ContainSubstring("unsupported protocol")))
+	})
+})
@@ -0,0 +1,165 @@
+// Package rcontest provides a local Source RCON server for testing clients and controllers
+// without a real game server.
+package rcontest
+
+import (
+	"bufio"
+	"net"
+	"sync"
+
+	"github.com/templarfelix/gameserver-operator/internal/rcon"
+)
+
+// Handler returns the response to an RCON command
+type Handler func(command string) string
+
+// Server answers Source RCON authentication and commands on a local TCP port
+type Server struct {
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	mu        sync.Mutex
+	password  string
+	handler   Handler
+	commands  []string
+	splitSize int
+	conns     map[net.Conn]bool
+}
+
+// NewServer starts a server on 127.0.0.1 accepting password and answering commands with handler
+func NewServer(password string, handler Handler) (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		listener:  listener,
+		password:  password,
+		handler:   handler,
+		splitSize: 4096,
+		conns:     map[net.Conn]bool{},
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Addr returns the host:port the server listens on
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Port returns the TCP port the server listens on
+func (s *Server) Port() int32 {
+	return int32(s.listener.Addr().(*net.TCPAddr).Port)
+}
+
+// Commands returns the commands received from authenticated clients
+func (s *Server) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+// SetSplitSize splits responses into packets of at most size bytes of body (default 4096)
+func (s *Server) SetSplitSize(size int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.splitSize = size
+}
+
+// Close stops the server and closes open connections
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[conn] = true
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	reader := bufio.NewReader(conn)
+	authenticated := false
+	for {
+		packet, err := rcon.ReadSourcePacket(reader)
+		if err != nil {
+			return
+		}
+
+		var responses []rcon.SourcePacket
+		switch {
+		case packet.Type == rcon.SourceAuth:
+			id := int32(-1)
+			if packet.Body == s.password {
+				id, authenticated = packet.ID, true
+			}
+			responses = []rcon.SourcePacket{
+				{ID: packet.ID, Type: rcon.SourceResponseValue},
+				{ID: id, Type: rcon.SourceAuthResponse},
+			}
+		case !authenticated:
+			return
+		case packet.Type == rcon.SourceExecCommand:
+			responses = s.execute(packet)
+		case packet.Type == rcon.SourceResponseValue:
+			// Mirror the empty packet followed by the undocumented srcds trailer
+			responses = []rcon.SourcePacket{
+				{ID: packet.ID, Type: rcon.SourceResponseValue},
+				{ID: packet.ID, Type: rcon.SourceResponseValue, Body: "\x00\x01\x00\x00"},
+			}
+		}
+
+		for _, response := range responses {
+			if err := rcon.WriteSourcePacket(conn, response); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *Server) execute(packet rcon.SourcePacket) []rcon.SourcePacket {
+	s.mu.Lock()
+	s.commands = append(s.commands, packet.Body)
+	handler, splitSize := s.handler, s.splitSize
+	s.mu.Unlock()
+
+	response := ""
+	if handler != nil {
+		response = handler(packet.Body)
+	}
+
+	packets := []rcon.SourcePacket{}
+	for len(response) > splitSize {
+		packets = append(packets, rcon.SourcePacket{ID: packet.ID, Type: rcon.SourceResponseValue, Body: response[:splitSize]})
+		response = response[splitSize:]
+	}
+	return append(packets, rcon.SourcePacket{ID: packet.ID, Type: rcon.SourceResponseValue, Body: response})
+}
@@ -0,0 +1,179 @@
+package rcon
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// Source RCON packet types, SERVERDATA_EXECCOMMAND and SERVERDATA_AUTH_RESPONSE share a value
+const (
+	SourceResponseValue int32 = 0
+	SourceExecCommand   int32 = 2
+	SourceAuthResponse  int32 = 2
+	SourceAuth          int32 = 3
+
+	// sourceMaxPacketSize bounds the size field of a packet, the protocol limit is 4096 bytes of body
+	sourceMaxPacketSize = 4096 + 10
+	// sourceMinPacketSize is the size of a packet with an empty body: id, type and two null bytes
+	sourceMinPacketSize = 10
+)
+
+// SourcePacket is a Source RCON packet
+type SourcePacket struct {
+	ID   int32
+	Type int32
+	Body string
+}
+
+// SourceClient is an authenticated Source RCON session over TCP. It is not safe for concurrent use.
+type SourceClient struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	nextID  int32
+	timeout time.Duration
+}
+
+// DialSource connects to addr (host:port) and authenticates with password
+func DialSource(ctx context.Context, addr, password string, timeout time.Duration) (*SourceClient, error) {
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	c := &SourceClient{conn: conn, reader: bufio.NewReader(conn), nextID: 1, timeout: timeout}
+
+	if err := c.auth(ctx, password); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Close ends the session
+func (c *SourceClient) Close() error {
+	return c.conn.Close()
+}
+
+// Command runs command and returns the response. Responses split over several packets are
+// collected until the server mirrors an empty packet sent right after the command.
+func (c *SourceClient) Command(ctx context.Context, command string) (string, error) {
+	if err := c.setDeadline(ctx); err != nil {
+		return "", err
+	}
+
+	commandID := c.id()
+	terminatorID := c.id()
+	if err := WriteSourcePacket(c.conn, SourcePacket{ID: commandID, Type: SourceExecCommand, Body: command}); err != nil {
+		return "", err
+	}
+	if err := WriteSourcePacket(c.conn, SourcePacket{ID: terminatorID, Type: SourceResponseValue}); err != nil {
+		return "", err
+	}
+
+	var response bytes.Buffer
+	for {
+		packet, err := ReadSourcePacket(c.reader)
+		if err != nil {
+			return "", err
+		}
+		switch packet.ID {
+		case commandID:
+			response.WriteString(packet.Body)
+		case terminatorID:
+			// The mirrored terminator may be followed by a second packet, drain it on the next read
+			return response.String(), nil
+		}
+	}
+}
+
+func (c *SourceClient) auth(ctx context.Context, password string) error {
+	if err := c.setDeadline(ctx); err != nil {
+		return err
+	}
+
+	id := c.id()
+	if err := WriteSourcePacket(c.conn, SourcePacket{ID: id, Type: SourceAuth, Body: password}); err != nil {
+		return err
+	}
+
+	// Servers send an empty SERVERDATA_RESPONSE_VALUE before the SERVERDATA_AUTH_RESPONSE
+	for {
+		packet, err := ReadSourcePacket(c.reader)
+		if err != nil {
+			return err
+		}
+		if packet.Type != SourceAuthResponse {
+			continue
+		}
+		if packet.ID == -1 {
+			return ErrAuthFailed
+		}
+		if packet.ID != id {
+			return fmt.Errorf("%w: unexpected auth response id %d", ErrMalformedPacket, packet.ID)
+		}
+		return nil
+	}
+}
+
+func (c *SourceClient) id() int32 {
+	id := c.nextID
+	c.nextID++
+	if c.nextID <= 0 {
+		c.nextID = 1
+	}
+	return id
+}
+
+func (c *SourceClient) setDeadline(ctx context.Context) error {
+	deadline := time.Now().Add(c.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	return c.conn.SetDeadline(deadline)
+}
+
+// WriteSourcePacket encodes packet: size, id, type, null terminated body and an empty null terminated string
+func WriteSourcePacket(w io.Writer, packet SourcePacket) error {
+	size := int32(len(packet.Body) + sourceMinPacketSize)
+	buf := bytes.NewBuffer(make([]byte, 0, size+4))
+	_ = binary.Write(buf, binary.LittleEndian, size)
+	_ = binary.Write(buf, binary.LittleEndian, packet.ID)
+	_ = binary.Write(buf, binary.LittleEndian, packet.Type)
+	buf.WriteString(packet.Body)
+	buf.Write([]byte{0x00, 0x00})
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// ReadSourcePacket decodes the next packet from r
+func ReadSourcePacket(r io.Reader) (SourcePacket, error) {
+	var size int32
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return SourcePacket{}, err
+	}
+	if size < sourceMinPacketSize || size > sourceMaxPacketSize {
+		return SourcePacket{}, fmt.Errorf("%w: invalid packet size %d", ErrMalformedPacket, size)
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return SourcePacket{}, err
+	}
+	// The terminators are not validated: srcds follows a mirrored empty packet with one whose
+	// body is 0x00 0x01 0x00 0x00, which is ignored by id anyway
+	return SourcePacket{
+		ID:   int32(binary.LittleEndian.Uint32(data[0:4])),
+		Type: int32(binary.LittleEndian.Uint32(data[4:8])),
+		Body: string(data[8 : size-2]),
+	}, nil
+}
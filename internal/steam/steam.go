@@ -0,0 +1,90 @@
+// Package steam reads the latest public build of Steam apps, used to tell whether a game server
+// runs an outdated build. It talks to the SteamCMD info API (https://www.steamcmd.net), any
+// service serving the same JSON can stand in for it through BaseURL.
+package steam
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultBaseURL is the public SteamCMD info API
+	DefaultBaseURL = "https://api.steamcmd.net/v1"
+
+	// DefaultTimeout bounds a build lookup
+	DefaultTimeout = 10 * time.Second
+
+	// publicBranch is the branch dedicated servers install unless a beta is selected
+	publicBranch = "public"
+)
+
+// ErrUnknownApp is returned when the API has no build for the app
+var ErrUnknownApp = errors.New("steam: unknown app")
+
+// Client looks up app builds
+type Client struct {
+	// BaseURL of the info API, DefaultBaseURL when empty
+	BaseURL string
+
+	// HTTPClient is used for requests, a client with DefaultTimeout when nil
+	HTTPClient *http.Client
+}
+
+// infoResponse is the part of the /info/<appid> response holding the branch build ids
+type infoResponse struct {
+	Status string `json:"status"`
+	Data   map[string]struct {
+		Depots struct {
+			Branches map[string]struct {
+				BuildID string `json:"buildid"`
+			} `json:"branches"`
+		} `json:"depots"`
+	} `json:"data"`
+}
+
+// LatestBuild returns the build id of the public branch of appID
+func (c *Client) LatestBuild(ctx context.Context, appID int) (string, error) {
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DefaultTimeout}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/info/%d", baseURL, appID), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("steam: info API returned %s", resp.Status)
+	}
+
+	var info infoResponse
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return "", fmt.Errorf("steam: decoding info response: %w", err)
+	}
+
+	app, ok := info.Data[strconv.Itoa(appID)]
+	if !ok {
+		return "", fmt.Errorf("%w: %d", ErrUnknownApp, appID)
+	}
+	branch, ok := app.Depots.Branches[publicBranch]
+	if !ok || branch.BuildID == "" {
+		return "", fmt.Errorf("%w: %d has no public build", ErrUnknownApp, appID)
+	}
+	return branch.BuildID, nil
+}
@@ -0,0 +1,57 @@
+package steam_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/templarfelix/gameserver-operator/internal/steam"
+)
+
+var _ = Describe("Client", func() {
+	var (
+		ctx    context.Context
+		server *httptest.Server
+		client *steam.Client
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/info/223350":
+				_, _ = w.Write([]byte(`{"status":"success","data":{"223350":{"common":{"name":"DayZ Server"},` +
+					`"depots":{"branches":{"public":{"buildid":"14950321","timeupdated":"1718000000"},` +
+					`"experimental":{"buildid":"14990000"}}}}}}`))
+			case "/info/1":
+				_, _ = w.Write([]byte(`{"status":"success","data":{"1":{"depots":{"branches":{}}}}}`))
+			case "/info/2":
+				w.WriteHeader(http.StatusBadGateway)
+			default:
+				_, _ = w.Write([]byte(`{"status":"success","data":{}}`))
+			}
+		}))
+		DeferCleanup(server.Close)
+		client = &steam.Client{BaseURL: server.URL}
+	})
+
+	It("should return the public build", func() {
+		Expect(client.LatestBuild(ctx, 223350)).To(Equal("14950321"))
+	})
+
+	It("should report apps without public build", func() {
+		_, err := client.LatestBuild(ctx, 1)
+		Expect(err).To(MatchError(steam.ErrUnknownApp))
+
+		_, err = client.LatestBuild(ctx, 42)
+		Expect(err).To(MatchError(steam.ErrUnknownApp))
+	})
+
+	It("should report API errors", func() {
+		_, err := client.LatestBuild(ctx, 2)
+		Expect(err).To(MatchError(ContainSubstring("502")))
+	})
+})
@@ -0,0 +1,474 @@
+// Package wakeproxy fronts the ports of a paused game server. It forwards traffic while the game is
+// up, and while it is down wakes the server on the first connection attempt, answers A2S queries with
+// the last known server info and holds TCP connections until the game answers again.
+package wakeproxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/templarfelix/gameserver-operator/internal/a2s"
+)
+
+const (
+	// DefaultProbeInterval is the interval between two queries of the upstream game server
+	DefaultProbeInterval = 5 * time.Second
+
+	// DefaultHoldTimeout bounds how long a TCP connection is held while the game server starts
+	DefaultHoldTimeout = 5 * time.Minute
+
+	// DefaultWakeInterval is the minimum interval between two wake requests
+	DefaultWakeInterval = 30 * time.Second
+
+	// DefaultSessionTimeout closes UDP sessions of clients that sent nothing for that long
+	DefaultSessionTimeout = 2 * time.Minute
+
+	// maxPacketSize is large enough for any UDP datagram
+	maxPacketSize = 65535
+)
+
+// Waker scales a paused game server back up
+type Waker interface {
+	Wake(ctx context.Context) error
+}
+
+// Querier reads the state of the upstream game server from its query port
+type Querier interface {
+	QueryInfo(ctx context.Context, addr string) (*a2s.Info, error)
+}
+
+// Port is a port the proxy listens on and the upstream port it forwards to
+type Port struct {
+	// Protocol is "TCP" or "UDP"
+	Protocol string
+
+	// Listen is the local port, 0 picks a free port
+	Listen int
+
+	// Upstream is the port of the game server
+	Upstream int
+}
+
+// ParsePort parses "<protocol>/<listen>[:<upstream>]", the upstream port defaults to the listen port
+func ParsePort(value string) (Port, error) {
+	protocol, ports, ok := strings.Cut(value, "/")
+	protocol = strings.ToUpper(protocol)
+	if !ok || (protocol != "TCP" && protocol != "UDP") {
+		return Port{}, fmt.Errorf("port %q: expected TCP/<port> or UDP/<port>", value)
+	}
+	listen, upstream, hasUpstream := strings.Cut(ports, ":")
+	if !hasUpstream {
+		upstream = listen
+	}
+
+	port := Port{Protocol: protocol}
+	var err error
+	if port.Listen, err = strconv.Atoi(listen); err != nil {
+		return Port{}, fmt.Errorf("port %q: %w", value, err)
+	}
+	if port.Upstream, err = strconv.Atoi(upstream); err != nil {
+		return Port{}, fmt.Errorf("port %q: %w", value, err)
+	}
+	return port, nil
+}
+
+// Proxy forwards the ports of a game server and wakes it when it is down
+type Proxy struct {
+	// Upstream is the host of the game server
+	Upstream string
+
+	// Ports are the forwarded ports
+	Ports []Port
+
+	// QueryPort is the upstream port answering A2S queries, queries on the matching UDP port are
+	// answered by the proxy while the game server is down
+	QueryPort int
+
+	// Waker wakes the game server
+	Waker Waker
+
+	// Querier probes the game server, an A2S client is used when nil
+	Querier Querier
+
+	// Fallback is the server info answered until the game server was seen once
+	Fallback a2s.Info
+
+	// ProbeInterval, HoldTimeout, WakeInterval and SessionTimeout default to the package defaults
+	ProbeInterval  time.Duration
+	HoldTimeout    time.Duration
+	WakeInterval   time.Duration
+	SessionTimeout time.Duration
+
+	up       atomic.Bool
+	upSignal chan struct{}
+
+	mu       sync.Mutex
+	info     a2s.Info
+	lastWake time.Time
+	udp      []*udpListener
+	tcp      []*tcpListener
+}
+
+type udpListener struct {
+	conn     *net.UDPConn
+	port     Port
+	sessions map[string]*udpSession
+}
+
+type udpSession struct {
+	conn     *net.UDPConn
+	lastSeen time.Time
+}
+
+type tcpListener struct {
+	listener net.Listener
+	port     Port
+}
+
+// Listen binds all ports of the proxy
+func (p *Proxy) Listen() error {
+	for _, port := range p.Ports {
+		addr := net.JoinHostPort("", strconv.Itoa(port.Listen))
+		switch port.Protocol {
+		case "UDP":
+			udpAddr, err := net.ResolveUDPAddr("udp", addr)
+			if err != nil {
+				return err
+			}
+			conn, err := net.ListenUDP("udp", udpAddr)
+			if err != nil {
+				p.Close()
+				return err
+			}
+			p.udp = append(p.udp, &udpListener{conn: conn, port: port, sessions: map[string]*udpSession{}})
+		case "TCP":
+			listener, err := net.Listen("tcp", addr)
+			if err != nil {
+				p.Close()
+				return err
+			}
+			p.tcp = append(p.tcp, &tcpListener{listener: listener, port: port})
+		default:
+			p.Close()
+			return fmt.Errorf("unsupported protocol %q", port.Protocol)
+		}
+	}
+	return nil
+}
+
+// Addr returns the local address of the listener forwarding to the upstream port, nil when there is none
+func (p *Proxy) Addr(protocol string, upstream int) net.Addr {
+	for _, l := range p.udp {
+		if protocol == "UDP" && l.port.Upstream == upstream {
+			return l.conn.LocalAddr()
+		}
+	}
+	for _, l := range p.tcp {
+		if protocol == "TCP" && l.port.Upstream == upstream {
+			return l.listener.Addr()
+		}
+	}
+	return nil
+}
+
+// Close closes all listeners and sessions
+func (p *Proxy) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, l := range p.udp {
+		l.conn.Close()
+		for _, session := range l.sessions {
+			session.conn.Close()
+		}
+	}
+	for _, l := range p.tcp {
+		l.listener.Close()
+	}
+}
+
+// Serve probes the game server and forwards traffic until ctx is done, Listen must be called first
+func (p *Proxy) Serve(ctx context.Context) error {
+	p.info = p.Fallback
+	p.upSignal = make(chan struct{})
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		p.Close()
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		p.probe(ctx)
+	}()
+	for _, l := range p.udp {
+		wg.Add(1)
+		go func(l *udpListener) {
+			defer wg.Done()
+			p.serveUDP(ctx, l)
+		}(l)
+	}
+	for _, l := range p.tcp {
+		wg.Add(1)
+		go func(l *tcpListener) {
+			defer wg.Done()
+			p.serveTCP(ctx, l)
+		}(l)
+	}
+	wg.Wait()
+	return nil
+}
+
+// probe queries the game server every probe interval and closes idle UDP sessions
+func (p *Proxy) probe(ctx context.Context) {
+	querier := p.Querier
+	if querier == nil {
+		querier = &a2s.Client{}
+	}
+	addr := net.JoinHostPort(p.Upstream, strconv.Itoa(p.QueryPort))
+	ticker := time.NewTicker(durationOr(p.ProbeInterval, DefaultProbeInterval))
+	defer ticker.Stop()
+
+	for {
+		info, err := querier.QueryInfo(ctx, addr)
+		p.mu.Lock()
+		if err == nil {
+			p.info = *info
+		}
+		wasUp := p.up.Swap(err == nil)
+		if err == nil && !wasUp {
+			close(p.upSignal)
+		} else if err != nil && wasUp {
+			p.upSignal = make(chan struct{})
+		}
+		p.mu.Unlock()
+		if err == nil && !wasUp {
+			log.FromContext(ctx).Info("Game server is up", "name", info.Name)
+		} else if err != nil && wasUp {
+			log.FromContext(ctx).Info("Game server is down", "reason", err.Error())
+		}
+
+		p.closeIdleSessions(time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// wake asks the Waker to scale the game server up, at most once per wake interval
+func (p *Proxy) wake(ctx context.Context) {
+	p.mu.Lock()
+	now := time.Now()
+	if !p.lastWake.IsZero() && now.Sub(p.lastWake) < durationOr(p.WakeInterval, DefaultWakeInterval) {
+		p.mu.Unlock()
+		return
+	}
+	p.lastWake = now
+	p.mu.Unlock()
+
+	logger := log.FromContext(ctx)
+	logger.Info("Waking the game server")
+	if err := p.Waker.Wake(ctx); err != nil {
+		logger.Error(err, "Failed to wake the game server")
+	}
+}
+
+// waitUp blocks until the game server is up, ctx is done or timeout passed
+func (p *Proxy) waitUp(ctx context.Context, timeout time.Duration) bool {
+	p.mu.Lock()
+	signal := p.upSignal
+	p.mu.Unlock()
+	if p.up.Load() {
+		return true
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-signal:
+		return true
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	return false
+}
+
+// serveUDP forwards the datagrams of each client through its own upstream socket while the game is up
+func (p *Proxy) serveUDP(ctx context.Context, l *udpListener) {
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, client, err := l.conn.ReadFromUDP(buf)
+		if err != nil {
+			if ctx.Err() == nil && !errors.Is(err, net.ErrClosed) {
+				log.FromContext(ctx).Error(err, "Failed to read UDP packet", "port", l.port.Listen)
+			}
+			return
+		}
+		packet := buf[:n]
+
+		if !p.up.Load() {
+			go p.wake(ctx)
+			if l.port.Upstream == p.QueryPort {
+				p.answerQuery(l.conn, client, packet)
+			}
+			continue
+		}
+
+		session, err := p.udpSession(ctx, l, client)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "Failed to open UDP session", "client", client.String())
+			continue
+		}
+		_, _ = session.conn.Write(packet)
+	}
+}
+
+// answerQuery answers A2S requests for a game server that is down, other packets are dropped
+func (p *Proxy) answerQuery(conn *net.UDPConn, client *net.UDPAddr, packet []byte) {
+	var response []byte
+	switch {
+	case a2s.IsInfoRequest(packet):
+		p.mu.Lock()
+		info := p.info
+		p.mu.Unlock()
+		info.Players = 0
+		info.Bots = 0
+		response = a2s.EncodeInfo(&info)
+	case a2s.IsPlayerRequest(packet):
+		response = a2s.EncodePlayers(nil)
+	default:
+		return
+	}
+	_, _ = conn.WriteToUDP(response, client)
+}
+
+// udpSession returns the upstream socket of client, relaying its responses back to the client
+func (p *Proxy) udpSession(ctx context.Context, l *udpListener, client *net.UDPAddr) (*udpSession, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := client.String()
+	if session, ok := l.sessions[key]; ok {
+		session.lastSeen = time.Now()
+		return session, nil
+	}
+
+	upstream, err := net.ResolveUDPAddr("udp", net.JoinHostPort(p.Upstream, strconv.Itoa(l.port.Upstream)))
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.DialUDP("udp", nil, upstream)
+	if err != nil {
+		return nil, err
+	}
+	session := &udpSession{conn: conn, lastSeen: time.Now()}
+	l.sessions[key] = session
+
+	go func() {
+		buf := make([]byte, maxPacketSize)
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				if errors.Is(err, net.ErrClosed) {
+					return
+				}
+				// Refused datagrams are reported on the next read while the game restarts
+				if ctx.Err() != nil {
+					return
+				}
+				continue
+			}
+			if _, err := l.conn.WriteToUDP(buf[:n], client); err != nil {
+				return
+			}
+		}
+	}()
+	return session, nil
+}
+
+// closeIdleSessions closes the UDP sessions of clients that sent nothing for the session timeout
+func (p *Proxy) closeIdleSessions(now time.Time) {
+	timeout := durationOr(p.SessionTimeout, DefaultSessionTimeout)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, l := range p.udp {
+		for key, session := range l.sessions {
+			if now.Sub(session.lastSeen) > timeout {
+				session.conn.Close()
+				delete(l.sessions, key)
+			}
+		}
+	}
+}
+
+// serveTCP accepts connections, holding them until the game server is up
+func (p *Proxy) serveTCP(ctx context.Context, l *tcpListener) {
+	for {
+		conn, err := l.listener.Accept()
+		if err != nil {
+			if ctx.Err() == nil && !errors.Is(err, net.ErrClosed) {
+				log.FromContext(ctx).Error(err, "Failed to accept TCP connection", "port", l.port.Listen)
+			}
+			return
+		}
+		go p.forwardTCP(ctx, conn, l.port)
+	}
+}
+
+// forwardTCP splices conn with a connection to the game server once it is up
+func (p *Proxy) forwardTCP(ctx context.Context, conn net.Conn, port Port) {
+	defer conn.Close()
+
+	if !p.up.Load() {
+		go p.wake(ctx)
+		if !p.waitUp(ctx, durationOr(p.HoldTimeout, DefaultHoldTimeout)) {
+			return
+		}
+	}
+
+	var dialer net.Dialer
+	upstream, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(p.Upstream, strconv.Itoa(port.Upstream)))
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to connect to the game server", "port", port.Upstream)
+		return
+	}
+	defer upstream.Close()
+
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(upstream, conn)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(conn, upstream)
+		done <- struct{}{}
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
+func durationOr(value, fallback time.Duration) time.Duration {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
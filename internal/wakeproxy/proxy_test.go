@@ -0,0 +1,258 @@
+package wakeproxy_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/templarfelix/gameserver-operator/internal/a2s"
+	"github.com/templarfelix/gameserver-operator/internal/wakeproxy"
+)
+
+// fakeQuerier reports the game server up or down
+type fakeQuerier struct {
+	up atomic.Bool
+}
+
+func (f *fakeQuerier) QueryInfo(_ context.Context, _ string) (*a2s.Info, error) {
+	if !f.up.Load() {
+		return nil, errors.New("no response")
+	}
+	return &a2s.Info{Name: "Live Server", Map: "chernarusplus", Players: 5, MaxPlayers: 60}, nil
+}
+
+// fakeWaker counts wake requests
+type fakeWaker struct {
+	calls atomic.Int32
+}
+
+func (f *fakeWaker) Wake(_ context.Context) error {
+	f.calls.Add(1)
+	return nil
+}
+
+var _ = Describe("Proxy", func() {
+	var (
+		ctx      context.Context
+		querier  *fakeQuerier
+		waker    *fakeWaker
+		proxy    *wakeproxy.Proxy
+		udpEcho  *net.UDPConn
+		tcpEcho  net.Listener
+		udpPort  int
+		tcpPort  int
+		queryUDP int
+	)
+
+	BeforeEach(func() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(context.Background())
+		DeferCleanup(cancel)
+
+		var err error
+		udpEcho, err = net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(udpEcho.Close)
+		go func() {
+			buf := make([]byte, 1500)
+			for {
+				n, addr, err := udpEcho.ReadFromUDP(buf)
+				if err != nil {
+					return
+				}
+				_, _ = udpEcho.WriteToUDP(buf[:n], addr)
+			}
+		}()
+
+		tcpEcho, err = net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(tcpEcho.Close)
+		go func() {
+			for {
+				conn, err := tcpEcho.Accept()
+				if err != nil {
+					return
+				}
+				go func() {
+					defer conn.Close()
+					_, _ = io.Copy(conn, conn)
+				}()
+			}
+		}()
+
+		udpPort = udpEcho.LocalAddr().(*net.UDPAddr).Port
+		tcpPort = tcpEcho.Addr().(*net.TCPAddr).Port
+		// The query port is not served upstream, the proxy answers it while the game is down
+		queryUDP = udpPort + 1
+
+		querier = &fakeQuerier{}
+		waker = &fakeWaker{}
+		proxy = &wakeproxy.Proxy{
+			Upstream: "127.0.0.1",
+			Ports: []wakeproxy.Port{
+				{Protocol: "UDP", Upstream: udpPort},
+				{Protocol: "UDP", Upstream: queryUDP},
+				{Protocol: "TCP", Upstream: tcpPort},
+			},
+			QueryPort:     queryUDP,
+			Waker:         waker,
+			Querier:       querier,
+			Fallback:      a2s.Info{Name: "Sleeping Server", MaxPlayers: 60},
+			ProbeInterval: 20 * time.Millisecond,
+			HoldTimeout:   time.Second,
+		}
+		Expect(proxy.Listen()).To(Succeed())
+		go func() {
+			defer GinkgoRecover()
+			Expect(proxy.Serve(ctx)).To(Succeed())
+		}()
+	})
+
+	dialUDP := func(upstream int) net.Conn {
+		conn, err := net.Dial("udp", proxy.Addr("UDP", upstream).String())
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+		return conn
+	}
+
+	It("should answer queries and wake the server while it is down", func() {
+		client := &a2s.Client{Timeout: time.Second}
+		info, err := client.QueryInfo(ctx, proxy.Addr("UDP", queryUDP).String())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Name).To(Equal("Sleeping Server"))
+		Expect(info.MaxPlayers).To(Equal(uint8(60)))
+		Expect(info.Players).To(BeZero())
+
+		players, err := client.QueryPlayers(ctx, proxy.Addr("UDP", queryUDP).String())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(players).To(BeEmpty())
+
+		// Wake requests are debounced
+		Eventually(waker.calls.Load).Should(Equal(int32(1)))
+		Consistently(waker.calls.Load, 100*time.Millisecond).Should(Equal(int32(1)))
+	})
+
+	It("should report the last known server without players once it went down", func() {
+		queryInfo := func() (*a2s.Info, error) {
+			return (&a2s.Client{Timeout: 200 * time.Millisecond}).QueryInfo(ctx, proxy.Addr("UDP", queryUDP).String())
+		}
+
+		// Queries are forwarded while the server is up, nothing answers them upstream in this test
+		querier.up.Store(true)
+		Eventually(func() error {
+			_, err := queryInfo()
+			return err
+		}).Should(HaveOccurred())
+
+		querier.up.Store(false)
+		Eventually(func() string {
+			info, err := queryInfo()
+			if err != nil || info.Players != 0 {
+				return ""
+			}
+			return info.Name
+		}).Should(Equal("Live Server"))
+	})
+
+	It("should drop game traffic while down and forward it once up", func() {
+		conn := dialUDP(udpPort)
+		Expect(conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))).To(Succeed())
+		_, err := conn.Write([]byte("hello"))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = conn.Read(make([]byte, 16))
+		Expect(err).To(HaveOccurred())
+		Eventually(waker.calls.Load).Should(Equal(int32(1)))
+
+		querier.up.Store(true)
+		Eventually(func() string {
+			_ = conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+			if _, err := conn.Write([]byte("hello")); err != nil {
+				return ""
+			}
+			buf := make([]byte, 16)
+			n, _ := conn.Read(buf)
+			return string(buf[:n])
+		}).Should(Equal("hello"))
+	})
+
+	It("should hold TCP connections until the server is up", func() {
+		conn, err := net.Dial("tcp", proxy.Addr("TCP", tcpPort).String())
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+
+		_, err = conn.Write([]byte("ping"))
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(waker.calls.Load).Should(Equal(int32(1)))
+
+		time.Sleep(100 * time.Millisecond)
+		querier.up.Store(true)
+
+		Expect(conn.SetReadDeadline(time.Now().Add(time.Second))).To(Succeed())
+		buf := make([]byte, 4)
+		_, err = io.ReadFull(conn, buf)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(buf)).To(Equal("ping"))
+	})
+
+	It("should close held TCP connections when the server does not come up", func() {
+		conn, err := net.Dial("tcp", proxy.Addr("TCP", tcpPort).String())
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+
+		Expect(conn.SetReadDeadline(time.Now().Add(3 * time.Second))).To(Succeed())
+		_, err = conn.Read(make([]byte, 1))
+		Expect(err).To(MatchError(io.EOF))
+	})
+})
+
+var _ = Describe("ParsePort", func() {
+	It("should parse the protocol and both ports", func() {
+		Expect(wakeproxy.ParsePort("udp/2302")).To(Equal(wakeproxy.Port{Protocol: "UDP", Listen: 2302, Upstream: 2302}))
+		Expect(wakeproxy.ParsePort("TCP/8080:27016")).To(Equal(wakeproxy.Port{Protocol: "TCP", Listen: 8080, Upstream: 27016}))
+	})
+
+	It("should reject unknown protocols and ports", func() {
+		_, err := wakeproxy.ParsePort("SCTP/2302")
+		Expect(err).To(HaveOccurred())
+		_, err = wakeproxy.ParsePort("UDP/game")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("KubernetesWaker", func() {
+	It("should annotate the game server with the wake time", func() {
+		resource := schema.GroupVersionResource{Group: "gameserver.templarfelix.com", Version: "v1alpha1", Resource: "dayzs"}
+		server := &unstructured.Unstructured{}
+		server.SetAPIVersion("gameserver.templarfelix.com/v1alpha1")
+		server.SetKind("Dayz")
+		server.SetNamespace("default")
+		server.SetName("dayz")
+		client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{resource: "DayzList"}, server)
+
+		waker := &wakeproxy.KubernetesWaker{
+			Client:    client,
+			Resource:  resource,
+			Namespace: "default",
+			Name:      "dayz",
+			Now:       func() time.Time { return time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC) },
+		}
+		Expect(waker.Wake(context.Background())).To(Succeed())
+
+		woken, err := client.Resource(resource).Namespace("default").Get(context.Background(), "dayz", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(woken.GetAnnotations()).To(HaveKeyWithValue(wakeproxy.WakeAnnotation, "2024-06-01T12:00:00Z"))
+	})
+})
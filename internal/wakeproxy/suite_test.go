@@ -0,0 +1,14 @@
+package wakeproxy_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestWakeProxy(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Wake Proxy Suite")
+}
@@ -0,0 +1,45 @@
+package wakeproxy
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// WakeAnnotation on a game server records the last connection attempt seen by its wake proxy, the
+// operator resumes a server paused for being idle when it is newer than the pause
+const WakeAnnotation = "gameserver.templarfelix.com/wake"
+
+// KubernetesWaker wakes a game server by annotating its custom resource
+type KubernetesWaker struct {
+	Client    dynamic.Interface
+	Resource  schema.GroupVersionResource
+	Namespace string
+	Name      string
+
+	// Now returns the current time, time.Now is used when nil
+	Now func() time.Time
+}
+
+// Wake sets the WakeAnnotation of the game server to the current time
+func (w *KubernetesWaker) Wake(ctx context.Context) error {
+	now := time.Now
+	if w.Now != nil {
+		now = w.Now
+	}
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{WakeAnnotation: now().UTC().Format(time.RFC3339)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = w.Client.Resource(w.Resource).Namespace(w.Namespace).Patch(ctx, w.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}